@@ -233,6 +233,7 @@ func main() {
 			mainMux := http.NewServeMux()
 			mux := http.NewServeMux()
 			http.HandleFunc(log.SetLogLevelPath, log.SetLogLevel)
+			http.HandleFunc(log.GetLogLevelPath, log.GetLogLevel)
 			mux.Handle("/debug/pprof", http.HandlerFunc(pprof.Index))
 			mux.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
 			mux.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
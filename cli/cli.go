@@ -17,6 +17,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/cubefs/cubefs/cli/cmd"
 	"github.com/cubefs/cubefs/sdk/master"
@@ -36,6 +37,7 @@ func runCLI() (err error) {
 func setupCommands(cfg *cmd.Config) *cobra.Command {
 	mc := master.NewMasterClient(cfg.MasterAddr, false)
 	mc.SetTimeout(cfg.Timeout)
+	mc.SetDeadline(time.Duration(cfg.Deadline) * time.Second)
 	mc.SetClientIDKey(cfg.ClientIDKey)
 	cfsRootCmd := cmd.NewRootCmd(mc)
 	//	var completionCmd = &cobra.Command{
@@ -47,6 +47,7 @@ var (
 type Config struct {
 	MasterAddr  []string `json:"masterAddr"`
 	Timeout     uint16   `json:"timeout"`
+	Deadline    uint16   `json:"deadline"`
 	ClientIDKey string   `json:"clientIDKey"`
 }
 
@@ -68,6 +69,7 @@ const (
 func newConfigSetCmd() *cobra.Command {
 	var optMasterHosts string
 	var optTimeout string
+	var optDeadline uint16
 	cmd := &cobra.Command{
 		Use:   CliOpSet,
 		Short: cmdConfigSetShort,
@@ -93,7 +95,12 @@ func newConfigSetCmd() *cobra.Command {
 				return
 			}
 
-			if err = setConfig(optMasterHosts, timeOut); err != nil {
+			// the deadline is kept unless it is given, 0 clears it
+			var deadline *uint16
+			if cmd.Flags().Changed("deadline") {
+				deadline = &optDeadline
+			}
+			if err = setConfig(optMasterHosts, timeOut, deadline); err != nil {
 				return
 			}
 			stdout("Config has been set successfully!\n")
@@ -102,6 +109,7 @@ func newConfigSetCmd() *cobra.Command {
 	cmd.Flags().StringVar(&optMasterHosts, "addr", "",
 		"Specify master address {HOST}:{PORT}[,{HOST}:{PORT}]")
 	cmd.Flags().StringVar(&optTimeout, "timeout", "60", "Specify timeout for requests [Unit: s]")
+	cmd.Flags().Uint16Var(&optDeadline, "deadline", 0, "Specify deadline for an operation including retries, 0 means no deadline [Unit: s]")
 	return cmd
 }
 
@@ -126,9 +134,10 @@ func printConfigInfo(config *Config) {
 	stdout("Config info:\n")
 	stdout("  Master  Address    : %v\n", config.MasterAddr)
 	stdout("  Request Timeout [s]: %v\n", config.Timeout)
+	stdout("  Deadline        [s]: %v\n", config.Deadline)
 }
 
-func setConfig(masterHosts string, timeout uint16) (err error) {
+func setConfig(masterHosts string, timeout uint16, deadline *uint16) (err error) {
 	var config *Config
 	if config, err = LoadConfig(); err != nil {
 		return
@@ -140,6 +149,9 @@ func setConfig(masterHosts string, timeout uint16) (err error) {
 	if timeout != 0 {
 		config.Timeout = timeout
 	}
+	if deadline != nil {
+		config.Deadline = *deadline
+	}
 	var configData []byte
 	if configData, err = json.Marshal(config); err != nil {
 		return
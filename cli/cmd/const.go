@@ -44,6 +44,8 @@ const (
 	CliOpGetDiscard              = "get-discard"
 	CliOpSetDiscard              = "set-discard"
 	CliOpForbidMpDecommission    = "forbid-mp-decommission"
+	CliOpDecode                  = "decode"
+	CliOpExtents                 = "extents"
 
 	// Shorthand format of operation name
 	CliOpDecommissionShortHand = "dec"
@@ -76,6 +78,7 @@ const (
 	CliFlagSize                = "size"
 	CliFlagVolType             = "vol-type"
 	CliFlagFollowerRead        = "follower-read"
+	CliFlagNearRead            = "near-read"
 	CliFlagCacheRuleKey        = "cache-rule-key"
 	CliFlagEbsBlkSize          = "ebs-blk-size"
 	CliFlagCacheCapacity       = "cache-capacity"
@@ -15,9 +15,16 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/sdk/master"
@@ -43,6 +50,7 @@ func newDataPartitionCmd(client *master.MasterClient) *cobra.Command {
 		newDataPartitionGetDiscardCmd(client),
 		newDataPartitionSetDiscardCmd(client),
 		newDataPartitionQueryDecommissionProgress(client),
+		newDataPartitionExtentsCmd(client),
 	)
 	return cmd
 }
@@ -56,6 +64,7 @@ const (
 	cmdDataPartitionGetDiscardShort                = "Display all discard data partitions"
 	cmdDataPartitionSetDiscardShort                = "Set discard flag for data partition"
 	cmdDataPartitionQueryDecommissionProgressShort = "Query data partition decommission progress"
+	cmdDataPartitionExtentsShort                   = "List the extents of a data partition and check the replicas"
 )
 
 func newDataPartitionGetCmd(client *master.MasterClient) *cobra.Command {
@@ -457,3 +466,196 @@ func newDataPartitionQueryDecommissionProgress(client *master.MasterClient) *cob
 	}
 	return cmd
 }
+
+const defaultDataNodeProfPort = 17320
+
+func newDataPartitionExtentsCmd(client *master.MasterClient) *cobra.Command {
+	var (
+		profPort    uint16
+		concurrency int
+	)
+	cmd := &cobra.Command{
+		Use:   CliOpExtents + " [DATA PARTITION ID]",
+		Short: cmdDataPartitionExtentsShort,
+		Long: `List the extents on every replica of the data partition by the /partition API of the data nodes,
+and compare the size and crc of each extent across the replicas.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				err         error
+				partitionID uint64
+				partition   *proto.DataPartitionInfo
+			)
+			defer func() {
+				errout(err)
+			}()
+			if partitionID, err = strconv.ParseUint(args[0], 10, 64); err != nil {
+				return
+			}
+			if partition, err = client.AdminAPI().GetDataPartition("", partitionID); err != nil {
+				return
+			}
+			if concurrency <= 0 {
+				err = fmt.Errorf("concurrency should be positive, but got %v", concurrency)
+				return
+			}
+
+			var leader string
+			for _, replica := range partition.Replicas {
+				if replica.IsLeader {
+					leader = replica.Addr
+				}
+			}
+			var (
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+				replicas = make(map[string][]*dataNodeExtentInfo, len(partition.Hosts))
+				tokens   = make(chan struct{}, concurrency)
+			)
+			for _, host := range partition.Hosts {
+				wg.Add(1)
+				go func(host string) {
+					defer wg.Done()
+					tokens <- struct{}{}
+					defer func() { <-tokens }()
+					extents, e := getDataNodeExtents(host, profPort, partitionID)
+					if e != nil {
+						stdoutlnf("get extents from replica(%v) failed: %v", host, e)
+						return
+					}
+					mu.Lock()
+					replicas[host] = extents
+					mu.Unlock()
+				}(host)
+			}
+			wg.Wait()
+			stdout("%v", formatDataPartitionExtents(checkDataPartitionExtents(partition.Hosts, leader, replicas)))
+		},
+	}
+	cmd.Flags().Uint16Var(&profPort, "port", defaultDataNodeProfPort, "the prof port of the data nodes")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 3, "the number of replicas to query at the same time")
+	return cmd
+}
+
+// dataNodeExtentInfo is the extent reported by the /partition API of the data node.
+type dataNodeExtentInfo struct {
+	FileID    uint64 `json:"fileId"`
+	Size      uint64 `json:"size"`
+	Crc       uint32 `json:"Crc"`
+	IsDeleted bool   `json:"deleted"`
+}
+
+func getDataNodeExtents(host string, profPort uint16, partitionID uint64) (extents []*dataNodeExtentInfo, err error) {
+	ip, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return
+	}
+	httpClient := &http.Client{Timeout: time.Minute}
+	resp, err := httpClient.Get(fmt.Sprintf("http://%v/partition?id=%v", net.JoinHostPort(ip, strconv.Itoa(int(profPort))), partitionID))
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+	reply := &struct {
+		Code int32  `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			Extents []*dataNodeExtentInfo `json:"extents"`
+		} `json:"data"`
+	}{}
+	if err = json.Unmarshal(data, reply); err != nil {
+		return nil, fmt.Errorf("unmarshal reply failed, status(%v) err(%v)", resp.Status, err)
+	}
+	if reply.Code != http.StatusOK {
+		return nil, fmt.Errorf("code(%v) msg(%v)", reply.Code, reply.Msg)
+	}
+	for _, extent := range reply.Data.Extents {
+		if !extent.IsDeleted {
+			extents = append(extents, extent)
+		}
+	}
+	return
+}
+
+type dataPartitionExtent struct {
+	ExtentID uint64
+	Size     uint64
+	Crc      uint32
+	Replicas int
+	Status   string
+}
+
+const (
+	extentStatusOK          = "ok"
+	extentStatusSizeDiffers = "size differs"
+	extentStatusCrcDiffers  = "crc differs"
+)
+
+// checkDataPartitionExtents merges the extents of the replicas, the size and crc of the leader are shown if it is available.
+// The crcs are compared among the replicas that know them.
+// The hosts which fail to respond are reported as unknown rather than missing.
+func checkDataPartitionExtents(hosts []string, leader string, replicas map[string][]*dataNodeExtentInfo) []*dataPartitionExtent {
+	merged := make(map[uint64]map[string]*dataNodeExtentInfo)
+	for host, extents := range replicas {
+		for _, extent := range extents {
+			if merged[extent.FileID] == nil {
+				merged[extent.FileID] = make(map[string]*dataNodeExtentInfo)
+			}
+			merged[extent.FileID][host] = extent
+		}
+	}
+
+	result := make([]*dataPartitionExtent, 0, len(merged))
+	for extentID, byHost := range merged {
+		var (
+			missing, unknown []string
+			shown            bool
+			sizes            = make(map[uint64]bool)
+			crcs             = make(map[uint32]bool)
+		)
+		extent := &dataPartitionExtent{ExtentID: extentID, Replicas: len(byHost)}
+		for _, host := range hosts {
+			info, ok := byHost[host]
+			if !ok {
+				if _, responded := replicas[host]; responded {
+					missing = append(missing, host)
+				} else {
+					unknown = append(unknown, host)
+				}
+				continue
+			}
+			sizes[info.Size] = true
+			// the crc is not computed for the tiny or recently written extents, zero is unknown
+			if info.Crc != 0 {
+				crcs[info.Crc] = true
+			}
+			if host == leader || !shown {
+				extent.Size, extent.Crc = info.Size, info.Crc
+				shown = true
+			}
+		}
+		var status []string
+		if len(missing) > 0 {
+			status = append(status, "missing on "+strings.Join(missing, ","))
+		}
+		if len(sizes) > 1 {
+			status = append(status, extentStatusSizeDiffers)
+		} else if len(crcs) > 1 {
+			status = append(status, extentStatusCrcDiffers)
+		}
+		if len(unknown) > 0 {
+			status = append(status, "unknown on "+strings.Join(unknown, ","))
+		}
+		if len(status) == 0 {
+			status = append(status, extentStatusOK)
+		}
+		extent.Status = strings.Join(status, "; ")
+		result = append(result, extent)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ExtentID < result[j].ExtentID })
+	return result
+}
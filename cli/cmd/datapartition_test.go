@@ -0,0 +1,85 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDataNodeExtents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/partition" || r.URL.Query().Get("id") != "10" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"msg":"partition not exist","data":null}`))
+			return
+		}
+		w.Write([]byte(`{"code":200,"msg":"","data":{"volName":"vol","id":10,"extents":[` +
+			`{"fileId":1025,"size":4096,"Crc":123,"deleted":false,"modTime":1,"src":"none"},` +
+			`{"fileId":1026,"size":0,"Crc":0,"deleted":true}],"fileCount":2}}`))
+	}))
+	defer server.Close()
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	profPort, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	// the data port of the replica is replaced by the prof port
+	extents, err := getDataNodeExtents("127.0.0.1:17310", uint16(profPort), 10)
+	require.NoError(t, err)
+	require.Equal(t, []*dataNodeExtentInfo{{FileID: 1025, Size: 4096, Crc: 123}}, extents)
+
+	_, err = getDataNodeExtents("127.0.0.1:17310", uint16(profPort), 11)
+	require.Error(t, err)
+}
+
+func TestCheckDataPartitionExtents(t *testing.T) {
+	hosts := []string{"a:1", "b:1", "c:1"}
+	replicas := map[string][]*dataNodeExtentInfo{
+		"a:1": {{FileID: 1, Size: 10, Crc: 1}, {FileID: 2, Size: 20, Crc: 2}, {FileID: 3, Size: 30, Crc: 3}, {FileID: 4, Size: 40, Crc: 4}},
+		"b:1": {{FileID: 1, Size: 10, Crc: 1}, {FileID: 2, Size: 20, Crc: 2}, {FileID: 3, Size: 31, Crc: 3}, {FileID: 4, Size: 40, Crc: 5}},
+	}
+	extents := checkDataPartitionExtents(hosts, "b:1", replicas)
+	require.Len(t, extents, 4)
+	// c does not respond, so its extents are unknown
+	require.Equal(t, &dataPartitionExtent{ExtentID: 1, Size: 10, Crc: 1, Replicas: 2, Status: "unknown on c:1"}, extents[0])
+	require.Equal(t, "size differs; unknown on c:1", extents[2].Status)
+	// the size of the leader is shown
+	require.Equal(t, uint64(31), extents[2].Size)
+	require.Equal(t, "crc differs; unknown on c:1", extents[3].Status)
+
+	replicas["c:1"] = []*dataNodeExtentInfo{{FileID: 1, Size: 10, Crc: 1}, {FileID: 3, Size: 30, Crc: 3}}
+	extents = checkDataPartitionExtents(hosts, "", replicas)
+	require.Equal(t, extentStatusOK, extents[0].Status)
+	require.Equal(t, "missing on c:1", extents[1].Status)
+	require.Equal(t, "missing on c:1; crc differs", extents[3].Status)
+	require.Equal(t, uint64(30), extents[2].Size)
+
+	require.Contains(t, formatDataPartitionExtents(extents), "missing on c:1")
+
+	// an unknown crc does not differ from the known ones
+	replicas = map[string][]*dataNodeExtentInfo{
+		"a:1": {{FileID: 1, Size: 10, Crc: 0}, {FileID: 2, Size: 20, Crc: 0}},
+		"b:1": {{FileID: 1, Size: 10, Crc: 1}, {FileID: 2, Size: 20, Crc: 0}},
+	}
+	extents = checkDataPartitionExtents(hosts[:2], "", replicas)
+	require.Equal(t, extentStatusOK, extents[0].Status)
+	require.Equal(t, extentStatusOK, extents[1].Status)
+}
@@ -150,6 +150,7 @@ func formatSimpleVolView(svv *proto.SimpleVolView) string {
 	sb.WriteString(fmt.Sprintf("  DpCnt                           : %v\n", svv.DpCnt))
 	sb.WriteString(fmt.Sprintf("  DpReplicaNum                    : %v\n", svv.DpReplicaNum))
 	sb.WriteString(fmt.Sprintf("  Follower read                   : %v\n", formatEnabledDisabled(svv.FollowerRead)))
+	sb.WriteString(fmt.Sprintf("  Near read                       : %v\n", formatEnabledDisabled(svv.NearRead)))
 	sb.WriteString(fmt.Sprintf("  Inode count                     : %v\n", svv.InodeCount))
 	sb.WriteString(fmt.Sprintf("  Max metaPartition ID            : %v\n", svv.MaxMetaPartitionID))
 	sb.WriteString(fmt.Sprintf("  MpCnt                           : %v\n", svv.MpCnt))
@@ -963,3 +964,13 @@ func formatDataPartitionDecommissionProgress(info *proto.DecommissionDataPartiti
 	sb.WriteString(fmt.Sprintf("ErrorMessage:      %v\n", info.ErrorMessage))
 	return sb.String()
 }
+
+func formatDataPartitionExtents(extents []*dataPartitionExtent) string {
+	rows := table{
+		arow("EXTENT ID", "SIZE", "CRC", "REPLICAS", "STATUS"),
+	}
+	for _, e := range extents {
+		rows = rows.append(arow(e.ExtentID, e.Size, e.Crc, e.Replicas, e.Status))
+	}
+	return alignTable(rows...)
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cmdProtoUse         = "proto [COMMAND]"
+	cmdProtoShort       = "Protocol debugging tools"
+	cmdProtoDecodeShort = "Decode the packets of a raw capture file"
+)
+
+func newProtoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   cmdProtoUse,
+		Short: cmdProtoShort,
+		Args:  cobra.MinimumNArgs(0),
+	}
+	cmd.AddCommand(
+		newProtoDecodeCmd(),
+	)
+	return cmd
+}
+
+func newProtoDecodeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   CliOpDecode + " [CAPTURE FILE]",
+		Short: cmdProtoDecodeShort,
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				f   *os.File
+				err error
+			)
+			defer func() {
+				errout(err)
+			}()
+			if f, err = os.Open(args[0]); err != nil {
+				return
+			}
+			defer f.Close()
+			_, err = decodePackets(f, os.Stdout)
+		},
+	}
+	return cmd
+}
+
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countReader) Read(p []byte) (n int, err error) {
+	n, err = c.r.Read(p)
+	c.n += int64(n)
+	return
+}
+
+// decodePackets decodes packets from r one by one and writes them to w,
+// it stops at the first framing error and reports the offset of the broken packet.
+func decodePackets(r io.Reader, w io.Writer) (count int, err error) {
+	cr := &countReader{r: bufio.NewReader(r)}
+	for {
+		offset := cr.n
+		p := new(proto.Packet)
+		if err = p.ReadFromReader(cr); err != nil {
+			if err == io.EOF {
+				err = nil
+				fmt.Fprintf(w, "total packets: %v\n", count)
+				return
+			}
+			err = fmt.Errorf("decode packet %v at offset %v failed: %v", count, offset, err)
+			return
+		}
+		fmt.Fprintf(w, "[%v] offset(%v) %v\n", count, offset, p.DebugString())
+		count++
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+)
+
+func marshalCapturePacket(p *proto.Packet) []byte {
+	headSize := util.PacketHeaderSize
+	if p.ExtentType&proto.MultiVersionFlag > 0 {
+		headSize = util.PacketHeaderVerSize
+	}
+	header := make([]byte, headSize)
+	p.MarshalHeader(header)
+	buf := bytes.NewBuffer(header)
+	buf.Write(p.Arg[:p.ArgLen])
+	buf.Write(p.Data[:p.Size])
+	return buf.Bytes()
+}
+
+func TestProtoDecodeCapture(t *testing.T) {
+	write := proto.NewPacket()
+	write.Opcode = proto.OpWrite
+	write.PartitionID = 10
+	write.ExtentID = 1025
+	write.ReqID = 1
+	write.Data = []byte("hello")
+	write.Size = uint32(len(write.Data))
+
+	verWrite := proto.NewPacket()
+	verWrite.Opcode = proto.OpRandomWriteVer
+	verWrite.ExtentType = proto.NormalExtentType | proto.MultiVersionFlag
+	verWrite.PartitionID = 11
+	verWrite.ReqID = 2
+	verWrite.VerSeq = 99
+	verWrite.Arg = []byte("addr")
+	verWrite.ArgLen = uint32(len(verWrite.Arg))
+	verWrite.Data = []byte("world")
+	verWrite.Size = uint32(len(verWrite.Data))
+
+	capture := append(marshalCapturePacket(write), marshalCapturePacket(verWrite)...)
+	file := path.Join(t.TempDir(), "capture.bin")
+	if err := os.WriteFile(file, capture, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	out := new(bytes.Buffer)
+	count, err := decodePackets(f, out)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expect 2 packets, got %v", count)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("unexpected output:\n%v", out.String())
+	}
+	if !strings.HasPrefix(lines[0], "[0] offset(0) ") || !strings.Contains(lines[0], "Op(OpWrite)") ||
+		!strings.Contains(lines[0], "PartitionID(10)") || !strings.Contains(lines[0], "ExtentID(1025)") {
+		t.Fatalf("unexpected first packet: %v", lines[0])
+	}
+	expectOffset := util.PacketHeaderSize + len(write.Data)
+	if !strings.HasPrefix(lines[1], fmt.Sprintf("[1] offset(%v) ", expectOffset)) ||
+		!strings.Contains(lines[1], "Op(OpRandomWriteVer)") || !strings.Contains(lines[1], "VerSeq(99)") ||
+		!strings.Contains(lines[1], "ArgLen(4)") {
+		t.Fatalf("unexpected second packet: %v", lines[1])
+	}
+	if lines[2] != "total packets: 2" {
+		t.Fatalf("unexpected summary: %v", lines[2])
+	}
+
+	// truncated data of the last packet
+	out.Reset()
+	count, err = decodePackets(bytes.NewReader(capture[:len(capture)-2]), out)
+	if err == nil || count != 1 {
+		t.Fatalf("expect framing error after 1 packet, got count %v err %v", count, err)
+	}
+	if !strings.Contains(err.Error(), fmt.Sprintf("at offset %v", expectOffset)) {
+		t.Fatalf("error should report the broken packet offset: %v", err)
+	}
+
+	// bad magic
+	bad := append([]byte{}, capture...)
+	bad[0] = 0
+	if _, err = decodePackets(bytes.NewReader(bad), out); err == nil || !strings.Contains(err.Error(), "Bad Magic") {
+		t.Fatalf("expect bad magic error, got %v", err)
+	}
+
+	// a corrupted size is refused before the body is read
+	huge := proto.NewPacket()
+	huge.Opcode = proto.OpWrite
+	huge.Size = math.MaxUint32
+	header := make([]byte, util.PacketHeaderSize)
+	huge.MarshalHeader(header)
+	if _, err = decodePackets(bytes.NewReader(append(capture, header...)), out); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("expect size error, got %v", err)
+	}
+	huge.Size = util.BlockSize
+	huge.Data = make([]byte, huge.Size)
+	out.Reset()
+	if count, err = decodePackets(bytes.NewReader(marshalCapturePacket(huge)), out); err != nil || count != 1 {
+		t.Fatalf("a block sized packet should be decoded, count %v err %v", count, err)
+	}
+}
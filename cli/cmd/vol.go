@@ -47,6 +47,7 @@ func newVolCmd(client *master.MasterClient) *cobra.Command {
 		newVolInfoCmd(client),
 		newVolDeleteCmd(client),
 		newVolTransferCmd(client),
+		newVolRenameCmd(client),
 		newVolAddDPCmd(client),
 		newVolAddMPCmd(client),
 		newVolSetForbiddenCmd(client),
@@ -266,6 +267,7 @@ func newVolUpdateCmd(client *master.MasterClient) *cobra.Command {
 	var optZoneName string
 	var optCapacity uint64
 	var optFollowerRead string
+	var optNearRead string
 	var optEbsBlkSize int
 	var optCacheCap string
 	var optCacheAction string
@@ -353,6 +355,17 @@ func newVolUpdateCmd(client *master.MasterClient) *cobra.Command {
 				}
 				confirmString.WriteString(fmt.Sprintf("  Allow follower read : %v\n", formatEnabledDisabled(vv.FollowerRead)))
 			}
+			if optNearRead != "" {
+				isChange = true
+				var enable bool
+				if enable, err = strconv.ParseBool(optNearRead); err != nil {
+					return
+				}
+				confirmString.WriteString(fmt.Sprintf("  Near read           : %v -> %v\n", formatEnabledDisabled(vv.NearRead), formatEnabledDisabled(enable)))
+				vv.NearRead = enable
+			} else {
+				confirmString.WriteString(fmt.Sprintf("  Near read           : %v\n", formatEnabledDisabled(vv.NearRead)))
+			}
 			if optEbsBlkSize > 0 {
 				if vv.VolType == 0 {
 					err = fmt.Errorf("ebs-blk-size not support in hot vol\n")
@@ -607,6 +620,7 @@ func newVolUpdateCmd(client *master.MasterClient) *cobra.Command {
 	cmd.Flags().StringVar(&optZoneName, CliFlagZoneName, "", "Specify volume zone name")
 	cmd.Flags().Uint64Var(&optCapacity, CliFlagCapacity, 0, "Specify volume datanode capacity [Unit: GB]")
 	cmd.Flags().StringVar(&optFollowerRead, CliFlagEnableFollowerRead, "", "Enable read form replica follower (default false)")
+	cmd.Flags().StringVar(&optNearRead, CliFlagNearRead, "", "Enable read from nearest replica by default for clients (default false)")
 	cmd.Flags().IntVar(&optEbsBlkSize, CliFlagEbsBlkSize, 0, "Specify ebsBlk Size[Unit: byte]")
 	cmd.Flags().StringVar(&optCacheCap, CliFlagCacheCapacity, "", "Specify low volume capacity[Unit: GB]")
 	cmd.Flags().StringVar(&optCacheAction, CliFlagCacheAction, "", "Specify low volume cacheAction (default 0)")
@@ -851,6 +865,56 @@ func newVolTransferCmd(client *master.MasterClient) *cobra.Command {
 	return cmd
 }
 
+const (
+	cmdVolRenameUse   = "rename [VOLUME NAME] [NEW NAME]"
+	cmdVolRenameShort = "Rename a forbidden volume"
+)
+
+func newVolRenameCmd(client *master.MasterClient) *cobra.Command {
+	var optYes bool
+	cmd := &cobra.Command{
+		Use:   cmdVolRenameUse,
+		Short: cmdVolRenameShort,
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			volume := args[0]
+			newName := args[1]
+			defer func() {
+				errout(err)
+			}()
+			// ask user for confirm
+			if !optYes {
+				stdout("Rename volume [%v] to [%v], clients must remount with the new name (yes/no)[no]:", volume, newName)
+				var confirm string
+				_, _ = fmt.Scanln(&confirm)
+				if confirm != "yes" {
+					err = fmt.Errorf("Abort by user.\n")
+					return
+				}
+			}
+			var svv *proto.SimpleVolView
+			if svv, err = client.AdminAPI().GetVolumeSimpleInfo(volume); err != nil {
+				err = fmt.Errorf("Rename volume failed:\n%v\n", err)
+				return
+			}
+			if err = client.AdminAPI().RenameVolume(volume, newName, util.CalcAuthKey(svv.Owner)); err != nil {
+				err = fmt.Errorf("Rename volume failed:\n%v\n", err)
+				return
+			}
+			stdout("Volume has been renamed successfully.\n")
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return validVols(client, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.Flags().BoolVarP(&optYes, "yes", "y", false, "Answer yes for all questions")
+	return cmd
+}
+
 const (
 	cmdVolAddDPCmdUse   = "add-dp [VOLUME] [NUMBER]"
 	cmdVolAddDPCmdShort = "Create and add more data partition to a volume"
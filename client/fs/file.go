@@ -497,7 +497,10 @@ func (f *File) Flush(ctx context.Context, req *fuse.FlushRequest) (err error) {
 		metric.SetWithLabels(err, map[string]string{exporter.Vol: f.super.volname})
 	}()
 	if proto.IsHot(f.super.volType) {
-		err = f.super.ec.Flush(f.info.Inode)
+		// nothing to flush for the files only read
+		if dirty, _ := f.super.ec.IsDirty(ctx, f.info.Inode); dirty {
+			err = f.super.ec.Flush(f.info.Inode)
+		}
 	} else {
 		f.Lock()
 		err = f.fWriter.Flush(f.info.Inode, ctx)
@@ -531,7 +534,7 @@ func (f *File) Fsync(ctx context.Context, req *fuse.FsyncRequest) (err error) {
 	log.LogDebugf("TRACE Fsync enter: ino(%v)", f.info.Inode)
 	start := time.Now()
 	if proto.IsHot(f.super.volType) {
-		err = f.super.ec.Flush(f.info.Inode)
+		err = f.super.ec.Sync(f.info.Inode)
 	} else {
 		err = f.fWriter.Flush(f.info.Inode, ctx)
 	}
@@ -16,6 +16,7 @@ package fs
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -225,7 +226,9 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 		VerReadSeq:        opt.VerReadSeq,
 		OnAppendExtentKey: s.mw.AppendExtentKey,
 		OnSplitExtentKey:  s.mw.SplitExtentKey,
+		OnMergeExtentKeys: s.mw.MergeExtentKeys,
 		OnGetExtents:      s.mw.GetExtents,
+		OnGetExtentsByVer: s.mw.GetExtentsByVer,
 		OnTruncate:        s.mw.Truncate,
 		OnEvictIcache:     s.ic.Delete,
 		OnLoadBcache:      s.bc.Get,
@@ -234,6 +237,10 @@ func NewSuper(opt *proto.MountOptions) (s *Super, err error) {
 
 		DisableMetaCache:             DisableMetaCache,
 		MinWriteAbleDataPartitionCnt: opt.MinWriteAbleDataPartitionCnt,
+		FsyncCoalesceWindow:          time.Duration(opt.FsyncCoalesceWindow) * time.Millisecond,
+		HedgeReadDelay:               time.Duration(opt.HedgeReadDelay) * time.Millisecond,
+		HedgeReadMaxPercent:          opt.HedgeReadMaxPercent,
+		ReadPreference:               opt.ReadPreference,
 	}
 
 	s.ec, err = stream.NewExtentClient(extentConfig)
@@ -415,6 +422,53 @@ func replySucc(w http.ResponseWriter, r *http.Request, msg string) {
 	w.Write([]byte(msg))
 }
 
+// healthCheckTimeout is the time the meta node is given to reply to a health check.
+var healthCheckTimeout = 3 * time.Second
+
+type healthStatus struct {
+	Cluster           string `json:"cluster"`
+	Volume            string `json:"volume"`
+	Healthy           bool   `json:"healthy"`
+	Error             string `json:"error,omitempty"`
+	LastMasterContact string `json:"lastMasterContact"`
+}
+
+// checkMetaNode fetches the root inode from the meta node within the timeout.
+func (s *Super) checkMetaNode(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if _, err := s.mw.InodeGetWithContext(ctx, s.rootIno); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("get root inode(%v) timeout after %v", s.rootIno, timeout)
+		}
+		return err
+	}
+	return nil
+}
+
+// Healthz replies 200 if the meta node is reachable, otherwise 503, so that a liveness probe can restart a wedged mount.
+func (s *Super) Healthz(w http.ResponseWriter, r *http.Request) {
+	status := &healthStatus{
+		Cluster: s.cluster,
+		Volume:  s.volname,
+		Healthy: true,
+	}
+	if t := s.mw.LastMasterContact(); !t.IsZero() {
+		status.LastMasterContact = t.Format(time.RFC3339)
+	}
+	code := http.StatusOK
+	if err := s.checkMetaNode(r.Context(), healthCheckTimeout); err != nil {
+		log.LogWarnf("Healthz: volume(%v) meta node unreachable, err(%v)", s.volname, err)
+		status.Healthy = false
+		status.Error = err.Error()
+		code = http.StatusServiceUnavailable
+	}
+	data, _ := json.Marshal(status)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
 func (s *Super) SetSockAddr(addr string) {
 	s.sockaddr = addr
 }
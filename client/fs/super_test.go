@@ -0,0 +1,130 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package fs
+
+import (
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/meta"
+	"github.com/stretchr/testify/require"
+)
+
+// startFakeMetaNode serves the inode gets, or reads the requests without replying if wedged.
+func startFakeMetaNode(t *testing.T, wedged bool) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				for {
+					p := proto.NewPacket()
+					if err := p.ReadFromConnWithVer(conn, proto.NoReadDeadlineTime); err != nil {
+						return
+					}
+					if wedged {
+						continue
+					}
+					req := &proto.InodeGetRequest{}
+					if err := p.UnmarshalData(req); err != nil {
+						return
+					}
+					p.PacketOkWithData(&proto.InodeGetResponse{Info: &proto.InodeInfo{Inode: req.Inode, Mode: uint32(os.ModeDir | 0o755)}})
+					if err := p.WriteToConn(conn); err != nil {
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// newHealthzSuper returns a super of the volume whose only meta partition is served by metaAddr.
+func newHealthzSuper(t *testing.T, metaAddr string) *Super {
+	replies := map[string]interface{}{
+		proto.AdminGetIP:    &proto.ClusterInfo{Cluster: "test"},
+		proto.ClientVolStat: &proto.VolStatInfo{Name: "vol"},
+		proto.ClientVol: &proto.VolView{Name: "vol", MetaPartitions: []*proto.MetaPartitionView{{
+			PartitionID: 1, Start: 0, End: math.MaxUint64, Members: []string{metaAddr},
+			LeaderAddr: metaAddr, Status: proto.ReadWrite,
+		}}},
+	}
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := replies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(&proto.HTTPReply{Code: proto.ErrCodeSuccess, Msg: "success", Data: data})
+	}))
+	t.Cleanup(master.Close)
+
+	mw, err := meta.NewMetaWrapper(&meta.MetaConfig{Volume: "vol", Masters: []string{strings.TrimPrefix(master.URL, "http://")}})
+	require.NoError(t, err)
+	t.Cleanup(func() { mw.Close() })
+	return &Super{cluster: "test", volname: "vol", rootIno: proto.RootIno, mw: mw}
+}
+
+func TestHealthz(t *testing.T) {
+	if proto.Buffers == nil {
+		proto.InitBufferPool(int64(32768))
+	}
+	oldTimeout := healthCheckTimeout
+	healthCheckTimeout = 200 * time.Millisecond
+	defer func() { healthCheckTimeout = oldTimeout }()
+
+	healthz := func(s *Super) (int, *healthStatus) {
+		w := httptest.NewRecorder()
+		s.Healthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		status := &healthStatus{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), status))
+		return w.Code, status
+	}
+
+	code, status := healthz(newHealthzSuper(t, startFakeMetaNode(t, false)))
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, status.Healthy)
+	require.Equal(t, "vol", status.Volume)
+
+	// a meta node not replying and an unreachable one both fail the check within the timeout
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachable := ln.Addr().String()
+	ln.Close()
+	for _, addr := range []string{startFakeMetaNode(t, true), unreachable} {
+		s := newHealthzSuper(t, addr)
+		start := time.Now()
+		code, status = healthz(s)
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		require.False(t, status.Healthy)
+		require.Contains(t, status.Error, "timeout")
+		require.Less(t, time.Since(start), 2*time.Second)
+	}
+}
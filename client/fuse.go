@@ -82,6 +82,7 @@ const (
 	ControlCommandFreeOSMemory = "/debug/freeosmemory"
 	ControlCommandSuspend      = "/suspend"
 	ControlCommandResume       = "/resume"
+	ControlCommandHealthz      = "/healthz"
 	Role                       = "Client"
 
 	DefaultIP            = "127.0.0.1"
@@ -391,6 +392,9 @@ func main() {
 	registerInterceptedSignal(opt.MountPoint)
 	for retry := 0; retry < MasterRetrys; retry++ {
 		err = checkPermission(opt)
+		if err == nil {
+			err = checkCapacity(opt)
+		}
 		if err != nil {
 			time.Sleep(5 * time.Second * time.Duration(retry+1))
 		} else {
@@ -605,10 +609,12 @@ func mount(opt *proto.MountOptions) (fsConn *fuse.Conn, super *cfs.Super, err er
 	http.HandleFunc(ControlCommandSetRate, super.SetRate)
 	http.HandleFunc(ControlCommandGetRate, super.GetRate)
 	http.HandleFunc(log.SetLogLevelPath, log.SetLogLevel)
+	http.HandleFunc(log.GetLogLevelPath, log.GetLogLevel)
 	http.HandleFunc(ControlCommandFreeOSMemory, freeOSMemory)
 	http.HandleFunc(log.GetLogPath, log.GetLog)
 	http.HandleFunc(ControlCommandSuspend, super.SetSuspend)
 	http.HandleFunc(ControlCommandResume, super.SetResume)
+	http.HandleFunc(ControlCommandHealthz, super.Healthz)
 	// auditlog
 	http.HandleFunc(auditlog.EnableAuditLogReqPath, super.EnableAuditLog)
 	http.HandleFunc(auditlog.DisableAuditLogReqPath, auditlog.DisableAuditLog)
@@ -809,7 +815,13 @@ func parseMountOption(cfg *config.Config) (*proto.MountOptions, error) {
 	opt.BuffersTotalLimit = GlobalMountOptions[proto.BuffersTotalLimit].GetInt64()
 	opt.MetaSendTimeout = GlobalMountOptions[proto.MetaSendTimeout].GetInt64()
 	opt.MaxStreamerLimit = GlobalMountOptions[proto.MaxStreamerLimit].GetInt64()
+	opt.FsyncCoalesceWindow = GlobalMountOptions[proto.FsyncCoalesceWindow].GetInt64()
 	opt.EnableAudit = GlobalMountOptions[proto.EnableAudit].GetBool()
+	opt.CapacityWarnThreshold = GlobalMountOptions[proto.CapacityWarnThreshold].GetInt64()
+	opt.CapacityFullReadonly = GlobalMountOptions[proto.CapacityFullReadonly].GetBool()
+	opt.HedgeReadDelay = GlobalMountOptions[proto.HedgeReadDelay].GetInt64()
+	opt.HedgeReadMaxPercent = GlobalMountOptions[proto.HedgeReadMaxPercent].GetInt64()
+	opt.ReadPreference = GlobalMountOptions[proto.ReadPreference].GetString()
 	opt.RequestTimeout = GlobalMountOptions[proto.RequestTimeout].GetInt64()
 	opt.MinWriteAbleDataPartitionCnt = int(GlobalMountOptions[proto.MinWriteAbleDataPartitionCnt].GetInt64())
 	opt.FileSystemName = GlobalMountOptions[proto.FileSystemName].GetString()
@@ -819,6 +831,14 @@ func parseMountOption(cfg *config.Config) (*proto.MountOptions, error) {
 		return nil, errors.New(fmt.Sprintf("invalid config file: lack of mandatory fields, mountPoint(%v), volName(%v), owner(%v), masterAddr(%v)", opt.MountPoint, opt.Volname, opt.Owner, opt.Master))
 	}
 
+	if opt.CapacityWarnThreshold < 0 || opt.CapacityWarnThreshold > 100 {
+		return nil, errors.New(fmt.Sprintf("invalid fields, CapacityWarnThreshold(%v) must be in [0, 100]", opt.CapacityWarnThreshold))
+	}
+
+	if opt.HedgeReadMaxPercent <= 0 || opt.HedgeReadMaxPercent > 100 {
+		return nil, errors.New(fmt.Sprintf("invalid fields, HedgeReadMaxPercent(%v) must be in (0, 100]", opt.HedgeReadMaxPercent))
+	}
+
 	if opt.BuffersTotalLimit < 0 {
 		return nil, errors.New(fmt.Sprintf("invalid fields, BuffersTotalLimit(%v) must larger or equal than 0", opt.BuffersTotalLimit))
 	}
@@ -866,6 +886,35 @@ func checkPermission(opt *proto.MountOptions) (err error) {
 	return
 }
 
+// checkCapacity warns if the used ratio of the volume reaches opt.CapacityWarnThreshold,
+// and mounts as readonly if opt.CapacityFullReadonly is also set.
+func checkCapacity(opt *proto.MountOptions) (err error) {
+	if opt.CapacityWarnThreshold <= 0 || opt.Rdonly {
+		return
+	}
+	mc := master.NewMasterClientFromString(opt.Master, false)
+	var info *proto.VolStatInfo
+	if info, err = mc.ClientAPI().GetVolumeStat(opt.Volname); err != nil {
+		return
+	}
+	if info.TotalSize == 0 {
+		return
+	}
+	usedRatio := float64(info.UsedSize) * 100 / float64(info.TotalSize)
+	if usedRatio < float64(opt.CapacityWarnThreshold) {
+		return
+	}
+	msg := fmt.Sprintf("WARNING: vol(%v) is nearly full, used(%v) total(%v) ratio(%.2f%%) threshold(%v%%)",
+		opt.Volname, info.UsedSize, info.TotalSize, usedRatio, opt.CapacityWarnThreshold)
+	if opt.CapacityFullReadonly {
+		opt.Rdonly = true
+		msg += ", mount as readonly"
+	}
+	syslog.Println(msg)
+	log.LogWarn(msg)
+	return
+}
+
 func parseLogLevel(loglvl string) log.Level {
 	var level log.Level
 	switch strings.ToLower(loglvl) {
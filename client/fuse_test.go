@@ -0,0 +1,79 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	syslog "log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCapacity(t *testing.T) {
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, proto.ClientVolStat, r.URL.Path)
+		stat := &proto.VolStatInfo{Name: r.URL.Query().Get("name"), TotalSize: 100, UsedSize: 95}
+		data, _ := json.Marshal(&proto.HTTPReply{Code: proto.ErrCodeSuccess, Msg: "success", Data: stat})
+		w.Write(data)
+	}))
+	defer master.Close()
+
+	var output bytes.Buffer
+	syslog.SetOutput(&output)
+	defer syslog.SetOutput(os.Stderr)
+
+	newOpt := func(threshold int64, readonly bool) *proto.MountOptions {
+		return &proto.MountOptions{
+			Volname:               "vol",
+			Master:                strings.TrimPrefix(master.URL, "http://"),
+			CapacityWarnThreshold: threshold,
+			CapacityFullReadonly:  readonly,
+		}
+	}
+
+	// disabled
+	opt := newOpt(0, true)
+	require.NoError(t, checkCapacity(opt))
+	require.False(t, opt.Rdonly)
+	require.Empty(t, output.String())
+
+	// below the threshold
+	opt = newOpt(96, true)
+	require.NoError(t, checkCapacity(opt))
+	require.False(t, opt.Rdonly)
+	require.Empty(t, output.String())
+
+	// warn only
+	opt = newOpt(90, false)
+	require.NoError(t, checkCapacity(opt))
+	require.False(t, opt.Rdonly)
+	require.Contains(t, output.String(), "vol(vol) is nearly full")
+	require.NotContains(t, output.String(), "readonly")
+
+	// warn and mount as readonly
+	output.Reset()
+	opt = newOpt(90, true)
+	require.NoError(t, checkCapacity(opt))
+	require.True(t, opt.Rdonly)
+	require.Contains(t, output.String(), "vol(vol) is nearly full")
+	require.Contains(t, output.String(), "mount as readonly")
+}
@@ -69,7 +69,8 @@ const (
 
 // Apply the raft log operation. Currently we only have the random write operation.
 const (
-	MinTinyExtentsToRepair = 10 // minimum number of tiny extents to repair
+	MinTinyExtentsToRepair    = 10   // minimum number of tiny extents to repair
+	RepairSourceQuarantineSec = 3600 // seconds not to repair an extent from the source failed the crc verification
 )
 
 // Tiny extent has been put back to store
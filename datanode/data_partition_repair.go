@@ -174,15 +174,14 @@ func (dp *DataPartition) getLocalExtentInfo(extentType uint8, tinyExtents []uint
 		err = errors.Trace(err, "getLocalExtentInfo extent DataPartition(%v) GetAllWaterMark", dp.partitionID)
 		return
 	}
-	if len(localExtents) <= 0 {
-		extents = make([]*storage.ExtentInfo, 0)
-		return
-	}
 	extents = make([]*storage.ExtentInfo, 0, len(localExtents))
 	for _, et := range localExtents {
 		newEt := *et
 		extents = append(extents, &newEt)
 	}
+	if proto.IsNormalExtentType(extentType) {
+		dp.markCrcMismatchExtents(extents)
+	}
 	return
 }
 
@@ -253,7 +252,7 @@ func (dp *DataPartition) DoRepair(repairTasks []*DataPartitionRepairTask) {
 	for _, extentInfo := range repairTasks[0].ExtentsToBeRepaired {
 		log.LogDebugf("action[DoRepair] leader to repair len[%v], {%v}", len(repairTasks[0].ExtentsToBeRepaired), extentInfo)
 	RETRY:
-		err := dp.streamRepairExtent(extentInfo, repl.NewTinyExtentRepairReadPacket, repl.NewExtentRepairReadPacket, repl.NewNormalExtentWithHoleRepairReadPacket, repl.NewPacketEx)
+		err := dp.repairExtentFromReplicas(extentInfo, repl.NewTinyExtentRepairReadPacket, repl.NewExtentRepairReadPacket, repl.NewNormalExtentWithHoleRepairReadPacket, repl.NewPacketEx)
 		if err != nil {
 			if strings.Contains(err.Error(), storage.NoDiskReadRepairExtentTokenError.Error()) {
 				log.LogDebugf("action[DoRepair] retry dp(%v) extent(%v).", dp.partitionID, extentInfo.FileID)
@@ -327,7 +326,9 @@ func (dp *DataPartition) prepareRepairTasks(repairTasks []*DataPartitionRepairTa
 			if !ok {
 				extentInfoMap[extentID] = extentInfo
 			} else {
-				if extentInfo.TotalSize() > extentWithMaxSize.TotalSize() {
+				// prefer the replica passed the crc verification as the source among the ones of the same size
+				if extentInfo.TotalSize() > extentWithMaxSize.TotalSize() ||
+					(extentInfo.TotalSize() == extentWithMaxSize.TotalSize() && extentWithMaxSize.CrcMismatch && !extentInfo.CrcMismatch) {
 					extentInfoMap[extentID] = extentInfo
 				}
 			}
@@ -397,7 +398,8 @@ func (dp *DataPartition) buildExtentRepairTasks(repairTasks []*DataPartitionRepa
 			if dp.ExtentStore().IsDeletedNormalExtent(extentID) {
 				continue
 			}
-			if extentInfo.TotalSize() < maxFileInfo.TotalSize() {
+			// the extent failed the crc verification is repaired in full even if it has the max size
+			if extentInfo.TotalSize() < maxFileInfo.TotalSize() || extentInfo.CrcMismatch {
 				fixExtent := &storage.ExtentInfo{Source: maxFileInfo.Source, FileID: extentID, Size: maxFileInfo.Size, SnapshotDataOff: maxFileInfo.SnapshotDataOff}
 				repairTasks[index].ExtentsToBeRepaired = append(repairTasks[index].ExtentsToBeRepaired, fixExtent)
 				log.LogInfof("action[generatorFixExtentSizeTasks] fixExtent(%v_%v) on Index(%v) on(%v).",
@@ -514,6 +516,9 @@ func (dp *DataPartition) ExtentWithHoleRepairRead(request repl.PacketInterface,
 		if err != nil {
 			return
 		}
+		if err = dp.verifyBlockCrc(reply.GetExtentID(), offset, reply.GetData()[:currReadSize]); err != nil {
+			return
+		}
 		reply.SetCRC(crc)
 		reply.SetSize(currReadSize)
 		reply.SetResultCode(proto.OpOk)
@@ -581,6 +586,12 @@ func (dp *DataPartition) NormalExtentRepairRead(p repl.PacketInterface, connect
 			crc, err = store.Read(reply.GetExtentID(), offset, int64(currReadSize), reply.GetData(), isRepairRead)
 			reply.SetCRC(crc)
 		})
+		// the repair reads are always verified not to spread the corrupt data to the other replicas
+		if err == nil && isRepairRead {
+			err = dp.verifyBlockCrc(reply.GetExtentID(), offset, reply.GetData()[:currReadSize])
+		} else if err == nil {
+			err = dp.verifyReadCrc(reply.GetExtentID(), offset, reply.GetData()[:currReadSize])
+		}
 		if !shallDegrade && metrics != nil {
 			metrics.MetricIOBytes.AddWithLabels(int64(p.GetSize()), metricPartitionIOLabels)
 			partitionIOMetric.SetWithLabels(err, metricPartitionIOLabels)
@@ -636,7 +647,7 @@ func (dp *DataPartition) NotifyExtentRepair(members []*DataPartitionRepairTask)
 func (dp *DataPartition) doStreamExtentFixRepair(wg *sync.WaitGroup, remoteExtentInfo *storage.ExtentInfo) {
 	defer wg.Done()
 RETRY:
-	err := dp.streamRepairExtent(remoteExtentInfo, repl.NewTinyExtentRepairReadPacket, repl.NewExtentRepairReadPacket, repl.NewNormalExtentWithHoleRepairReadPacket, repl.NewPacketEx)
+	err := dp.repairExtentFromReplicas(remoteExtentInfo, repl.NewTinyExtentRepairReadPacket, repl.NewExtentRepairReadPacket, repl.NewNormalExtentWithHoleRepairReadPacket, repl.NewPacketEx)
 	if err != nil {
 		if strings.Contains(err.Error(), storage.NoDiskReadRepairExtentTokenError.Error()) {
 			log.LogWarnf("action[DoRepair] retry dp(%v) extent(%v).", dp.partitionID, remoteExtentInfo.FileID)
@@ -665,6 +676,135 @@ func (dp *DataPartition) applyRepairKey(extentID int) (m string) {
 	return fmt.Sprintf("ApplyRepairKey(%v_%v)", dp.partitionID, extentID)
 }
 
+// repairSourceKey identifies the extent of a replica quarantined as a repair source.
+type repairSourceKey struct {
+	extentID uint64
+	source   string
+}
+
+// repairSources returns the replicas to repair the extent from, the source picked by the leader first,
+// the local replica and the quarantined sources are skipped.
+func (dp *DataPartition) repairSources(remoteExtentInfo *storage.ExtentInfo) (sources []string) {
+	candidates := append([]string{remoteExtentInfo.Source}, dp.getReplicaCopy()...)
+	for _, addr := range candidates {
+		if addr == "" || strings.TrimSpace(strings.Split(addr, ":")[0]) == LocalIP ||
+			dp.isRepairSourceQuarantined(remoteExtentInfo.FileID, addr) {
+			continue
+		}
+		duplicated := false
+		for _, source := range sources {
+			if source == addr {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			sources = append(sources, addr)
+		}
+	}
+	return
+}
+
+func (dp *DataPartition) isRepairSourceQuarantined(extentID uint64, source string) bool {
+	value, ok := dp.repairQuarantine.Load(repairSourceKey{extentID: extentID, source: source})
+	if !ok {
+		return false
+	}
+	if time.Now().Unix()-value.(int64) > RepairSourceQuarantineSec {
+		dp.repairQuarantine.Delete(repairSourceKey{extentID: extentID, source: source})
+		return false
+	}
+	return true
+}
+
+func (dp *DataPartition) quarantineRepairSource(extentID uint64, source string) {
+	dp.repairQuarantine.Store(repairSourceKey{extentID: extentID, source: source}, time.Now().Unix())
+	log.LogErrorf("action[quarantineRepairSource] dp(%v) extent(%v) on source(%v) fails the crc verification, quarantined",
+		dp.partitionID, extentID, source)
+}
+
+// getRemoteExtentWatermark returns the watermark of the extent on the replica over the repair connection.
+func (dp *DataPartition) getRemoteExtentWatermark(target string, extentID uint64) (extentInfo *storage.ExtentInfo, err error) {
+	p := repl.NewPacketToGetAllWatermarks(dp.partitionID, proto.NormalExtentType)
+	if storage.IsTinyExtent(extentID) {
+		p.ExtentType = proto.TinyExtentType
+		if p.Data, err = json.Marshal([]uint64{extentID}); err != nil {
+			return
+		}
+		p.Size = uint32(len(p.Data))
+	}
+	conn, err := dp.getRepairConn(target)
+	if err != nil {
+		return nil, errors.Trace(err, "getRemoteExtentWatermark get conn from host(%v) error", target)
+	}
+	defer func() {
+		dp.putRepairConn(conn, dp.enableSmux() || err != nil)
+	}()
+	if err = p.WriteToConn(conn); err != nil {
+		return nil, errors.Trace(err, "getRemoteExtentWatermark write to host(%v) error", target)
+	}
+	reply := new(repl.Packet)
+	if err = reply.ReadFromConnWithVer(conn, proto.GetAllWatermarksDeadLineTime); err != nil {
+		return nil, errors.Trace(err, "getRemoteExtentWatermark read from host(%v) error", target)
+	}
+	if reply.ResultCode != proto.OpOk {
+		return nil, fmt.Errorf("getRemoteExtentWatermark host(%v) reply(%v)", target, string(reply.Data[:reply.Size]))
+	}
+	extents := make([]*storage.ExtentInfo, 0)
+	if err = json.Unmarshal(reply.Data[:reply.Size], &extents); err != nil {
+		return nil, errors.Trace(err, "getRemoteExtentWatermark unmarshal from host(%v) error", target)
+	}
+	for _, extent := range extents {
+		if extent.FileID == extentID {
+			return extent, nil
+		}
+	}
+	return nil, fmt.Errorf("getRemoteExtentWatermark extent %v not exist on host(%v)", extentID, target)
+}
+
+// isRepairSourceComplete checks the replica has the extent up to the watermark to repair to and has not
+// failed the crc verification on it, the repair from a shorter replica fails on the reply out of its data.
+func (dp *DataPartition) isRepairSourceComplete(remoteExtentInfo *storage.ExtentInfo, source string) bool {
+	extentInfo, err := dp.getRemoteExtentWatermark(source, remoteExtentInfo.FileID)
+	if err != nil {
+		log.LogWarnf("action[isRepairSourceComplete] dp(%v) extent(%v) skip source(%v), err(%v)",
+			dp.partitionID, remoteExtentInfo.FileID, source, err)
+		return false
+	}
+	if extentInfo.Size < remoteExtentInfo.Size || extentInfo.SnapshotDataOff < remoteExtentInfo.SnapshotDataOff ||
+		extentInfo.CrcMismatch {
+		log.LogWarnf("action[isRepairSourceComplete] dp(%v) skip source(%v) extent(%v), repair to(%v)",
+			dp.partitionID, source, extentInfo, remoteExtentInfo)
+		return false
+	}
+	return true
+}
+
+// repairExtentFromReplicas repairs the extent from the source picked by the leader. If the data of the source
+// fails the crc verification, the source is quarantined and the other replicas having the extent up to the
+// watermark are tried in turn. The source records the extent failed the verification by itself, and reports it
+// in the watermarks, so that the leader schedules a full repair of it on the source in the next round.
+func (dp *DataPartition) repairExtentFromReplicas(remoteExtentInfo *storage.ExtentInfo,
+	tinyPackFunc, normalPackFunc, normalWithHoleFunc repl.MakeExtentRepairReadPacket,
+	newPack repl.NewPacketFunc) (err error,
+) {
+	err = fmt.Errorf("dp %v extent %v has no trusted source to repair from", dp.partitionID, remoteExtentInfo)
+	for _, source := range dp.repairSources(remoteExtentInfo) {
+		// the source picked by the leader has the extent up to the watermark
+		if source != remoteExtentInfo.Source && !dp.isRepairSourceComplete(remoteExtentInfo, source) {
+			continue
+		}
+		extentInfo := *remoteExtentInfo
+		extentInfo.Source = source
+		err = dp.streamRepairExtent(&extentInfo, tinyPackFunc, normalPackFunc, normalWithHoleFunc, newPack)
+		if err == nil || !strings.Contains(err.Error(), storage.BlockCrcMismatchError.Error()) {
+			return
+		}
+		dp.quarantineRepairSource(remoteExtentInfo.FileID, source)
+	}
+	return
+}
+
 // The actual repair of an extent happens here.
 func (dp *DataPartition) streamRepairExtent(remoteExtentInfo *storage.ExtentInfo,
 	tinyPackFunc, normalPackFunc, normalWithHoleFunc repl.MakeExtentRepairReadPacket,
@@ -700,7 +840,9 @@ func (dp *DataPartition) streamRepairExtent(remoteExtentInfo *storage.ExtentInfo
 		return nil
 	}
 
-	if localExtentInfo.Size >= remoteExtentInfo.Size && localExtentInfo.SnapshotDataOff >= remoteExtentInfo.SnapshotDataOff {
+	_, crcMismatch := dp.crcMismatchExtents.Load(remoteExtentInfo.FileID)
+	crcMismatch = crcMismatch && !storage.IsTinyExtent(remoteExtentInfo.FileID)
+	if !crcMismatch && localExtentInfo.Size >= remoteExtentInfo.Size && localExtentInfo.SnapshotDataOff >= remoteExtentInfo.SnapshotDataOff {
 		log.LogDebugf("streamRepairExtent  dp %v local %v remote info %v", dp.partitionID, localExtentInfo, remoteExtentInfo)
 		return nil
 	}
@@ -751,7 +893,7 @@ func (dp *DataPartition) streamRepairExtent(remoteExtentInfo *storage.ExtentInfo
 			if reply.GetResultCode() != proto.OpOk {
 				if reply.GetResultCode() == proto.OpReadRepairExtentAgain {
 					log.LogDebugf("streamRepairExtent dp %v extent %v wait for token", dp.partitionID, remoteExtentInfo.FileID)
-					time.Sleep(time.Second * 5)
+					time.Sleep(repairReadAgainWait)
 					return storage.NoDiskReadRepairExtentTokenError
 				} else {
 					err = errors.Trace(fmt.Errorf("unknow result code"),
@@ -841,8 +983,32 @@ func (dp *DataPartition) streamRepairExtent(remoteExtentInfo *storage.ExtentInfo
 		return
 	}
 
-	// size difference between the local extent and the remote extent
 	var request repl.PacketInterface
+	if crcMismatch {
+		// overwrite the data failed the crc verification before appending the rest, the record is
+		// kept if the source is shorter than the local extent since the tail is not rewritten
+		rewriteSize := localExtentInfo.Size
+		if rewriteSize > remoteExtentInfo.Size {
+			rewriteSize = remoteExtentInfo.Size
+		}
+		if rewriteSize > 0 {
+			request = normalPackFunc(dp.partitionID, remoteExtentInfo.FileID, 0, int(rewriteSize))
+			if err = doWork(storage.RandomWriteType, 0, rewriteSize, request); err != nil {
+				log.LogErrorf("streamRepairExtent. rewrite local info %v, remote %v.err(%v)", localExtentInfo, remoteExtentInfo, err)
+				return
+			}
+		}
+		if rewriteSize == localExtentInfo.Size {
+			dp.crcMismatchExtents.Delete(remoteExtentInfo.FileID)
+			log.LogWarnf("streamRepairExtent dp %v extent %v failed the crc verification is rewritten from %v",
+				dp.partitionID, remoteExtentInfo.FileID, remoteExtentInfo.Source)
+		}
+		if localExtentInfo.Size >= remoteExtentInfo.Size && localExtentInfo.SnapshotDataOff >= remoteExtentInfo.SnapshotDataOff {
+			return
+		}
+	}
+
+	// size difference between the local extent and the remote extent
 	sizeDiff := remoteExtentInfo.Size - localExtentInfo.Size
 
 	if storage.IsTinyExtent(remoteExtentInfo.FileID) {
@@ -528,3 +528,369 @@ func TestExtentRepair(t *testing.T) {
 	data, crc = genDataAndGetCrc("snapshot", util.BlockSize)
 	testDoSnapshotRepair(t, normalId, data, crc, false)
 }
+
+func TestReadVerifyCrc(t *testing.T) {
+	proto.InitBufferPool(int64(32768))
+	worker := mockInitWorker(t, "verify")
+	dp := worker.dp
+	defer func() {
+		dp.extentStore.Close()
+		os.RemoveAll(filepath.Dir(dp.path))
+	}()
+
+	extentID := uint64(1025)
+	require.NoError(t, dp.extentStore.Create(extentID))
+	for i := 0; i < 2; i++ {
+		data, crc := genDataAndGetCrc(fmt.Sprintf("block%d", i), util.BlockSize)
+		_, err := dp.extentStore.Write(extentID, int64(i*util.BlockSize), util.BlockSize, data, crc, storage.AppendWriteType, true, false)
+		require.NoError(t, err)
+	}
+	// flip a byte of the second block on the disk
+	file, err := os.OpenFile(fmt.Sprintf("%v/%v", dp.path, extentID), os.O_RDWR, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteAt([]byte{0xff}, util.BlockSize+100)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	read := func(offset int64, size int, isRepairRead bool) (p *repl.Packet, err error) {
+		client, server := net.Pipe()
+		defer server.Close()
+		go func() {
+			buf := make([]byte, 4096)
+			for {
+				if _, err := client.Read(buf); err != nil {
+					return
+				}
+			}
+		}()
+		p = repl.NewExtentRepairReadPacket(dp.partitionID, extentID, int(offset), size).(*repl.Packet)
+		p.Opcode = proto.OpStreamRead
+		err = dp.NormalExtentRepairRead(p, server, isRepairRead, nil, repl.NewStreamReadResponsePacket)
+		return
+	}
+
+	// verification is disabled by default
+	_, err = read(0, 2*util.BlockSize, false)
+	require.NoError(t, err)
+	require.Empty(t, dp.CrcMismatchExtents())
+
+	// the repair reads are always verified
+	_, err = read(util.BlockSize, util.BlockSize, true)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), storage.BlockCrcMismatchError.Error())
+	require.Contains(t, dp.CrcMismatchExtents(), extentID)
+	dp.crcMismatchExtents.Delete(extentID)
+
+	dp.dataNode.readVerifyCrc = true
+	_, err = read(0, util.BlockSize, false)
+	require.NoError(t, err)
+	// the blocks partly read are not verified
+	_, err = read(util.BlockSize+4096, 4096, false)
+	require.NoError(t, err)
+	require.Empty(t, dp.CrcMismatchExtents())
+
+	p, err := read(0, 2*util.BlockSize, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), storage.BlockCrcMismatchError.Error())
+	p.PackErrorBody(ActionStreamRead, err.Error())
+	require.Equal(t, proto.OpTryOtherAddr, p.ResultCode)
+	mismatches := dp.CrcMismatchExtents()
+	require.Len(t, mismatches, 1)
+	require.Contains(t, mismatches, extentID)
+}
+
+func TestRepairExtentFromHealthyReplica(t *testing.T) {
+	proto.InitBufferPool(int64(32768))
+	extentID := uint64(1025)
+	const (
+		corruptAddr = "192.168.0.1:17310"
+		shortAddr   = "192.168.0.2:17310"
+		healthyAddr = "192.168.0.3:17310"
+	)
+	sources := make(map[string]*DataPartition)
+	var data []byte
+	for _, addr := range []string{corruptAddr, shortAddr, healthyAddr} {
+		worker := mockInitWorker(t, "source")
+		dp := worker.dp
+		defer func() {
+			dp.extentStore.Close()
+			os.RemoveAll(filepath.Dir(dp.path))
+		}()
+		require.NoError(t, dp.extentStore.Create(extentID))
+		data = data[:0]
+		blocks := 2
+		if addr == shortAddr {
+			blocks = 1
+		}
+		for i := 0; i < blocks; i++ {
+			block, crc := genDataAndGetCrc(fmt.Sprintf("block%d", i), util.BlockSize)
+			_, err := dp.extentStore.Write(extentID, int64(i*util.BlockSize), util.BlockSize, block, crc, storage.AppendWriteType, true, false)
+			require.NoError(t, err)
+			data = append(data, block...)
+		}
+		// age the extent past the repair interval to be listed in the watermarks
+		ei, err := dp.extentStore.Watermark(extentID)
+		require.NoError(t, err)
+		ei.ModifyTime -= 2 * storage.RepairInterval
+		sources[addr] = dp
+	}
+	// flip a byte of the second block on the disk of the source picked by the leader
+	file, err := os.OpenFile(fmt.Sprintf("%v/%v", sources[corruptAddr].path, extentID), os.O_RDWR, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteAt([]byte{0xff}, util.BlockSize+100)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	worker := mockInitWorker(t, "repairer")
+	dp := worker.dp
+	defer func() {
+		dp.extentStore.Close()
+		os.RemoveAll(filepath.Dir(dp.path))
+	}()
+	dp.replicas = []string{corruptAddr, shortAddr, healthyAddr}
+	serveRepairSources(t, dp, sources)
+	require.NoError(t, dp.extentStore.Create(extentID))
+
+	remote, err := sources[corruptAddr].extentStore.Watermark(extentID)
+	require.NoError(t, err)
+	remote.Source = corruptAddr
+	require.Equal(t, []string{corruptAddr, shortAddr, healthyAddr}, dp.repairSources(remote))
+	require.NoError(t, dp.repairExtentFromReplicas(remote, repl.NewTinyExtentRepairReadPacket, repl.NewExtentRepairReadPacket,
+		repl.NewNormalExtentWithHoleRepairReadPacket, repl.NewPacketEx))
+
+	// the repair falls through to the healthy replica
+	local, err := dp.extentStore.Watermark(extentID)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(data)), local.Size)
+	repaired := make([]byte, len(data))
+	_, err = dp.extentStore.Read(extentID, 0, int64(len(data)), repaired, false)
+	require.NoError(t, err)
+	require.Equal(t, data, repaired)
+
+	// the corrupt source is quarantined, and reports the extent to repair by itself, the short replica is
+	// skipped without being tried
+	require.Contains(t, sources[corruptAddr].CrcMismatchExtents(), extentID)
+	require.Empty(t, sources[healthyAddr].CrcMismatchExtents())
+	require.Equal(t, []string{shortAddr, healthyAddr}, dp.repairSources(remote))
+	watermark, err := dp.getRemoteExtentWatermark(corruptAddr, extentID)
+	require.NoError(t, err)
+	require.True(t, watermark.CrcMismatch)
+	require.False(t, dp.isRepairSourceComplete(remote, corruptAddr))
+	require.False(t, dp.isRepairSourceComplete(remote, shortAddr))
+	require.True(t, dp.isRepairSourceComplete(remote, healthyAddr))
+
+	// no source left to repair from once the healthy one is quarantined
+	dp.quarantineRepairSource(extentID, healthyAddr)
+	require.Error(t, dp.repairExtentFromReplicas(remote, repl.NewTinyExtentRepairReadPacket, repl.NewExtentRepairReadPacket,
+		repl.NewNormalExtentWithHoleRepairReadPacket, repl.NewPacketEx))
+}
+
+// serveRepairSources serves the repair reads and the watermarks of the sources keyed by address, and routes the repair
+// connections of the partition to them.
+func serveRepairSources(t *testing.T, dp *DataPartition, sources map[string]*DataPartition) {
+	listeners := make(map[string]string)
+	for addr, source := range sources {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { ln.Close() })
+		listeners[addr] = ln.Addr().String()
+		go func(ln net.Listener, source *DataPartition) {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				go func(conn net.Conn) {
+					defer conn.Close()
+					for {
+						p := repl.NewPacket()
+						if err := p.ReadFromConnWithVer(conn, proto.NoReadDeadlineTime); err != nil {
+							return
+						}
+						if p.Opcode == proto.OpGetAllWatermarks {
+							p.Object = source
+							source.dataNode.handlePacketToGetAllWatermarks(p)
+							p.WriteToConn(conn)
+							continue
+						}
+						if err := source.NormalExtentRepairRead(p, conn, true, nil, repl.NewStreamReadResponsePacket); err != nil {
+							p.PackErrorBody(ActionStreamRead, err.Error())
+							p.WriteToConn(conn)
+						}
+					}
+				}(conn)
+			}
+		}(ln, source)
+	}
+	dp.dataNode.getRepairConnFunc = func(target string) (net.Conn, error) {
+		return net.Dial("tcp", listeners[target])
+	}
+	dp.dataNode.putRepairConnFunc = func(conn net.Conn, force bool) {
+		conn.Close()
+	}
+}
+
+func TestRepairCrcMismatchExtent(t *testing.T) {
+	proto.InitBufferPool(int64(32768))
+	extentID := uint64(1025)
+	const (
+		repairerAddr = "192.168.0.1:17310"
+		healthyAddr  = "192.168.0.2:17310"
+	)
+	var data []byte
+	newReplica := func(role string) *DataPartition {
+		dp := mockInitWorker(t, role).dp
+		t.Cleanup(func() {
+			dp.extentStore.Close()
+			os.RemoveAll(filepath.Dir(dp.path))
+		})
+		require.NoError(t, dp.extentStore.Create(extentID))
+		data = data[:0]
+		for i := 0; i < 2; i++ {
+			block, crc := genDataAndGetCrc(fmt.Sprintf("block%d", i), util.BlockSize)
+			_, err := dp.extentStore.Write(extentID, int64(i*util.BlockSize), util.BlockSize, block, crc, storage.AppendWriteType, true, false)
+			require.NoError(t, err)
+			data = append(data, block...)
+		}
+		// age the extent past the repair interval to be listed in the watermarks
+		ei, err := dp.extentStore.Watermark(extentID)
+		require.NoError(t, err)
+		ei.ModifyTime -= 2 * storage.RepairInterval
+		return dp
+	}
+	healthy := newReplica("healthy")
+	dp := newReplica("repairer")
+	dp.replicas = []string{repairerAddr, healthyAddr}
+	// flip a byte of the second block on the disk, and fail a read on it
+	file, err := os.OpenFile(fmt.Sprintf("%v/%v", dp.path, extentID), os.O_RDWR, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteAt([]byte{0xff}, util.BlockSize+100)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	corrupt := make([]byte, len(data))
+	_, err = dp.extentStore.Read(extentID, 0, int64(len(data)), corrupt, false)
+	require.NoError(t, err)
+	require.Error(t, dp.verifyBlockCrc(extentID, 0, corrupt))
+	require.Contains(t, dp.CrcMismatchExtents(), extentID)
+
+	// the watermarks report the mismatch without touching the ones of the store
+	extents, _, err := dp.getLocalExtentInfo(proto.NormalExtentType, nil)
+	require.NoError(t, err)
+	require.Len(t, extents, 1)
+	require.True(t, extents[0].CrcMismatch)
+	local, err := dp.extentStore.Watermark(extentID)
+	require.NoError(t, err)
+	require.False(t, local.CrcMismatch)
+
+	// the extent of the max size is scheduled to repair from the replica passed the verification
+	healthyExtents, _, err := healthy.getLocalExtentInfo(proto.NormalExtentType, nil)
+	require.NoError(t, err)
+	extents[0].Source = repairerAddr
+	healthyExtents[0].Source = healthyAddr
+	repairTasks := []*DataPartitionRepairTask{
+		{addr: repairerAddr, extents: map[uint64]*storage.ExtentInfo{extentID: extents[0]}},
+		{addr: healthyAddr, extents: map[uint64]*storage.ExtentInfo{extentID: healthyExtents[0]}},
+	}
+	dp.prepareRepairTasks(repairTasks)
+	require.Len(t, repairTasks[0].ExtentsToBeRepaired, 1)
+	require.Equal(t, healthyAddr, repairTasks[0].ExtentsToBeRepaired[0].Source)
+	require.Empty(t, repairTasks[1].ExtentsToBeRepaired)
+
+	serveRepairSources(t, dp, map[string]*DataPartition{healthyAddr: healthy})
+	require.NoError(t, dp.repairExtentFromReplicas(repairTasks[0].ExtentsToBeRepaired[0], repl.NewTinyExtentRepairReadPacket,
+		repl.NewExtentRepairReadPacket, repl.NewNormalExtentWithHoleRepairReadPacket, repl.NewPacketEx))
+
+	// the extent is rewritten and the record is cleared
+	repaired := make([]byte, len(data))
+	_, err = dp.extentStore.Read(extentID, 0, int64(len(data)), repaired, false)
+	require.NoError(t, err)
+	require.Equal(t, data, repaired)
+	require.NoError(t, dp.verifyBlockCrc(extentID, 0, repaired))
+	require.Empty(t, dp.CrcMismatchExtents())
+	local, err = dp.extentStore.Watermark(extentID)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(data)), local.Size)
+
+	// the record of a deleted extent is dropped
+	dp.crcMismatchExtents.Store(uint64(1026), time.Now().Unix())
+	_, _, err = dp.getLocalExtentInfo(proto.NormalExtentType, nil)
+	require.NoError(t, err)
+	require.Empty(t, dp.CrcMismatchExtents())
+}
+
+func TestStreamRepairExtentBacksOffBusySource(t *testing.T) {
+	proto.InitBufferPool(int64(32768))
+	if extentRepairLimitRater == nil {
+		initRepairLimit()
+	}
+	old := repairReadAgainWait
+	repairReadAgainWait = 0
+	defer func() { repairReadAgainWait = old }()
+
+	extentID := uint64(1025)
+	const sourceAddr = "192.168.0.2:17310"
+	newReplica := func(role string) *DataPartition {
+		dp := mockInitWorker(t, role).dp
+		t.Cleanup(func() {
+			dp.extentStore.Close()
+			os.RemoveAll(filepath.Dir(dp.path))
+		})
+		require.NoError(t, dp.extentStore.Create(extentID))
+		return dp
+	}
+	source := newReplica("source")
+	data, crc := genDataAndGetCrc("busy", util.BlockSize)
+	_, err := source.extentStore.Write(extentID, 0, util.BlockSize, data, crc, storage.AppendWriteType, true, false)
+	require.NoError(t, err)
+	dp := newReplica("repairer")
+
+	// the source serves the repair reads by its handler with all the repair read slots of the disk taken
+	source.disk.repairReadLimiter = newRepairReadLimiter(1)
+	require.True(t, source.disk.repairReadLimiter.acquire(0))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				for {
+					p := repl.NewPacket()
+					if err := p.ReadFromConnWithVer(conn, proto.NoReadDeadlineTime); err != nil {
+						return
+					}
+					p.Object = source
+					source.dataNode.handleExtentRepairReadPacket(p, conn, true)
+				}
+			}(conn)
+		}
+	}()
+	dp.dataNode.getRepairConnFunc = func(target string) (net.Conn, error) {
+		return net.Dial("tcp", ln.Addr().String())
+	}
+	dp.dataNode.putRepairConnFunc = func(conn net.Conn, force bool) {
+		conn.Close()
+	}
+
+	remote := &storage.ExtentInfo{FileID: extentID, Size: util.BlockSize, Source: sourceAddr}
+	repair := func() error {
+		return dp.streamRepairExtent(remote, repl.NewTinyExtentRepairReadPacket, repl.NewExtentRepairReadPacket,
+			repl.NewNormalExtentWithHoleRepairReadPacket, repl.NewPacketEx)
+	}
+	// the busy source makes the repairer back off rather than fail
+	require.Equal(t, storage.NoDiskReadRepairExtentTokenError, repair())
+	local, err := dp.extentStore.Watermark(extentID)
+	require.NoError(t, err)
+	require.Zero(t, local.Size)
+
+	source.disk.repairReadLimiter.release()
+	require.NoError(t, repair())
+	repaired := make([]byte, len(data))
+	_, err = dp.extentStore.Read(extentID, 0, int64(len(data)), repaired, false)
+	require.NoError(t, err)
+	require.Equal(t, data, repaired)
+}
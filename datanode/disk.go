@@ -31,6 +31,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/storage"
 	"github.com/cubefs/cubefs/util/exporter"
 	"github.com/cubefs/cubefs/util/loadutil"
 	"github.com/cubefs/cubefs/util/log"
@@ -71,6 +72,8 @@ type Disk struct {
 	Status          int // disk status such as READONLY
 	ReservedSpace   uint64
 	DiskRdonlySpace uint64
+	// whether the unallocated space was below DiskRdonlySpace at the last stat
+	belowRdonlySpace bool
 
 	RejectWrite                               bool
 	partitionMap                              map[uint64]*DataPartition
@@ -89,6 +92,7 @@ type Disk struct {
 	extentRepairReadLimit       chan struct{}
 	enableExtentRepairReadLimit bool
 	extentRepairReadDp          uint64
+	repairReadLimiter           *repairReadLimiter
 }
 
 const (
@@ -147,6 +151,7 @@ func NewDisk(path string, reservedSpace, diskRdonlySpace uint64, maxErrCnt int,
 	d.extentRepairReadLimit = make(chan struct{}, MaxExtentRepairReadLimit)
 	d.extentRepairReadLimit <- struct{}{}
 	d.enableExtentRepairReadLimit = diskEnableReadRepairExtentLimit
+	d.repairReadLimiter = newRepairReadLimiter(space.dataNode.repairReadConcurrencyPerDisk)
 	return
 }
 
@@ -782,6 +787,20 @@ func (d *Disk) ReleaseReadExtentToken() {
 	}
 }
 
+// acquireRepairRead returns an error to make the requester try again if the disk is busy with the repair reads,
+// it is replied with OpReadRepairExtentAgain, which makes the repairer back off.
+func (d *Disk) acquireRepairRead() error {
+	if !d.repairReadLimiter.acquire(repairReadWaitTime) {
+		return fmt.Errorf("%v: disk(%v) reaches the repair read concurrency limit(%v)",
+			storage.NoDiskReadRepairExtentTokenError, d.Path, d.dataNode.repairReadConcurrencyPerDisk)
+	}
+	return nil
+}
+
+func (d *Disk) releaseRepairRead() {
+	d.repairReadLimiter.release()
+}
+
 func (d *Disk) SetExtentRepairReadLimitStatus(status bool) {
 	d.enableExtentRepairReadLimit = status
 }
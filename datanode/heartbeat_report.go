@@ -0,0 +1,95 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"sync"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const DefaultHeartbeatFullReportInterval = 10
+
+// partitionReporter turns the partition reports of a heartbeat into the delta against the last reports
+// when the node is under load, a full report is still sent every fullInterval heartbeats.
+type partitionReporter struct {
+	sync.Mutex
+	// the delta is reported if the cpu util or the partition count reaches the threshold, 0 means no threshold
+	cpuUtilThreshold      float64
+	partitionCntThreshold int
+	fullInterval          int
+
+	seq        uint64
+	sinceFull  int
+	lastReport map[uint64]proto.DataPartitionReport
+}
+
+func newPartitionReporter(cpuUtilThreshold float64, partitionCntThreshold, fullInterval int) *partitionReporter {
+	if fullInterval <= 0 {
+		fullInterval = DefaultHeartbeatFullReportInterval
+	}
+	return &partitionReporter{
+		cpuUtilThreshold:      cpuUtilThreshold,
+		partitionCntThreshold: partitionCntThreshold,
+		fullInterval:          fullInterval,
+	}
+}
+
+func (r *partitionReporter) underLoad(cpuUtil float64, partitionCnt int) bool {
+	return (r.cpuUtilThreshold > 0 && cpuUtil >= r.cpuUtilThreshold) ||
+		(r.partitionCntThreshold > 0 && partitionCnt >= r.partitionCntThreshold)
+}
+
+// report numbers the full partition reports of the response and replaces them with the delta if
+// the master has applied the last reports, i.e. baseSeq is the seq of them, and the node is under load.
+func (r *partitionReporter) report(response *proto.DataNodeHeartbeatResponse, baseSeq uint64, cpuUtil float64) {
+	r.Lock()
+	defer r.Unlock()
+
+	current := make(map[uint64]proto.DataPartitionReport, len(response.PartitionReports))
+	for _, vr := range response.PartitionReports {
+		current[vr.PartitionID] = *vr
+	}
+	delta := baseSeq != 0 && baseSeq == r.seq && r.sinceFull+1 < r.fullInterval &&
+		r.underLoad(cpuUtil, len(response.PartitionReports))
+	r.seq++
+	response.PartitionReportSeq = r.seq
+	if !delta {
+		r.sinceFull = 0
+		r.lastReport = current
+		return
+	}
+
+	changed := make([]*proto.DataPartitionReport, 0)
+	for _, vr := range response.PartitionReports {
+		if last, ok := r.lastReport[vr.PartitionID]; !ok || last != *vr {
+			changed = append(changed, vr)
+		}
+	}
+	deleted := make([]uint64, 0)
+	for id := range r.lastReport {
+		if _, ok := current[id]; !ok {
+			deleted = append(deleted, id)
+		}
+	}
+	log.LogDebugf("action[partitionReporter] report seq(%v) base(%v) changed(%v) deleted(%v) of %v partitions",
+		r.seq, baseSeq, len(changed), len(deleted), len(current))
+	response.PartitionReports = changed
+	response.DeletedPartitions = deleted
+	response.DeltaReportBaseSeq = baseSeq
+	r.sinceFull++
+	r.lastReport = current
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionReporterDelta(t *testing.T) {
+	const count = 2000
+	newResponse := func(changed map[uint64]bool, skip uint64) *proto.DataNodeHeartbeatResponse {
+		response := &proto.DataNodeHeartbeatResponse{}
+		for id := uint64(1); id <= count; id++ {
+			if id == skip {
+				continue
+			}
+			vr := &proto.DataPartitionReport{PartitionID: id, VolName: "vol", DiskPath: "/data0", Used: id}
+			if changed[id] {
+				vr.Used++
+			}
+			response.PartitionReports = append(response.PartitionReports, vr)
+		}
+		return response
+	}
+	reporter := newPartitionReporter(0, 1000, 3)
+
+	full := newResponse(nil, 0)
+	reporter.report(full, 0, 0)
+	require.EqualValues(t, 1, full.PartitionReportSeq)
+	require.Len(t, full.PartitionReports, count)
+	require.Zero(t, full.DeltaReportBaseSeq)
+
+	changed := map[uint64]bool{10: true, 20: true}
+	delta := newResponse(changed, 30)
+	reporter.report(delta, full.PartitionReportSeq, 0)
+	require.EqualValues(t, 2, delta.PartitionReportSeq)
+	require.EqualValues(t, 1, delta.DeltaReportBaseSeq)
+	require.Len(t, delta.PartitionReports, len(changed))
+	require.Equal(t, []uint64{30}, delta.DeletedPartitions)
+
+	fullData, err := json.Marshal(full)
+	require.NoError(t, err)
+	deltaData, err := json.Marshal(delta)
+	require.NoError(t, err)
+	require.Less(t, len(deltaData)*100, len(fullData))
+
+	// a stale base seq gets the full reports
+	stale := newResponse(nil, 0)
+	reporter.report(stale, full.PartitionReportSeq, 0)
+	require.Len(t, stale.PartitionReports, count)
+	require.Zero(t, stale.DeltaReportBaseSeq)
+
+	// the full reports are sent every fullInterval heartbeats
+	resp := newResponse(nil, 0)
+	reporter.report(resp, stale.PartitionReportSeq, 0)
+	require.NotZero(t, resp.DeltaReportBaseSeq)
+	resp2 := newResponse(nil, 0)
+	reporter.report(resp2, resp.PartitionReportSeq, 0)
+	require.NotZero(t, resp2.DeltaReportBaseSeq)
+	resp3 := newResponse(nil, 0)
+	reporter.report(resp3, resp2.PartitionReportSeq, 0)
+	require.Zero(t, resp3.DeltaReportBaseSeq)
+	require.Len(t, resp3.PartitionReports, count)
+
+	// not under load
+	idle := newPartitionReporter(90, 0, 3)
+	resp = newResponse(nil, 0)
+	idle.report(resp, 0, 0)
+	resp2 = newResponse(nil, 0)
+	idle.report(resp2, resp.PartitionReportSeq, 10)
+	require.Zero(t, resp2.DeltaReportBaseSeq)
+	require.Len(t, resp2.PartitionReports, count)
+}
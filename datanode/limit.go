@@ -3,16 +3,23 @@ package datanode
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"golang.org/x/time/rate"
 )
 
+// the time a repair read waits for a slot of the disk before it is told to try again
+const repairReadWaitTime = 100 * time.Millisecond
+
 var (
 	deleteLimiteRater      = rate.NewLimiter(rate.Inf, defaultMarkDeleteLimitBurst)
 	MaxExtentRepairLimit   = 20000
 	MinExtentRepairLimit   = 5
 	CurExtentRepairLimit   = MaxExtentRepairLimit
 	extentRepairLimitRater chan struct{}
+
+	// the time the repairer backs off once the source is busy with the repair reads
+	repairReadAgainWait = 5 * time.Second
 )
 
 func initRepairLimit() {
@@ -78,3 +85,43 @@ func setLimiter(limiter *rate.Limiter, limitValue uint64) {
 	}
 	limiter.SetLimit(l)
 }
+
+// repairReadLimiter bounds the concurrent repair reads of a disk, so that the recovery does not starve the client IO.
+// A nil limiter is unlimited.
+type repairReadLimiter struct {
+	sem chan struct{}
+}
+
+func newRepairReadLimiter(concurrency int) *repairReadLimiter {
+	if concurrency <= 0 {
+		return nil
+	}
+	return &repairReadLimiter{sem: make(chan struct{}, concurrency)}
+}
+
+// acquire takes a slot, waiting at most the wait time if all the slots are taken.
+func (l *repairReadLimiter) acquire(wait time.Duration) bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (l *repairReadLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.sem
+}
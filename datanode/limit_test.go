@@ -2,6 +2,8 @@ package datanode
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -121,3 +123,49 @@ func TestSetLimiter(t *testing.T) {
 	setLimiter(limiter, 0)
 	assert.Equal(t, rate.Inf, limiter.Limit())
 }
+
+func TestRepairReadLimiter(t *testing.T) {
+	unlimited := newRepairReadLimiter(0)
+	require.Nil(t, unlimited)
+	for i := 0; i < 10; i++ {
+		require.True(t, unlimited.acquire(0))
+	}
+	unlimited.release()
+
+	const concurrency = 3
+	limiter := newRepairReadLimiter(concurrency)
+	var (
+		wg      sync.WaitGroup
+		running int32
+		maxRun  int32
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !limiter.acquire(repairReadWaitTime) {
+			}
+			defer limiter.release()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&maxRun)
+				if n <= old || atomic.CompareAndSwapInt32(&maxRun, old, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+	require.LessOrEqual(t, atomic.LoadInt32(&maxRun), int32(concurrency))
+
+	for i := 0; i < concurrency; i++ {
+		require.True(t, limiter.acquire(0))
+	}
+	begin := time.Now()
+	require.False(t, limiter.acquire(10*time.Millisecond))
+	require.GreaterOrEqual(t, time.Since(begin), 10*time.Millisecond)
+	limiter.release()
+	require.True(t, limiter.acquire(0))
+}
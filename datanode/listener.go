@@ -0,0 +1,80 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"net"
+	"syscall"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	DefaultAcceptConcurrency = 1
+	MaxAcceptConcurrency     = 64
+	// the listen backlog is capped by net.core.somaxconn anyway
+	MaxListenBacklog = 65535
+)
+
+// listenWithBacklog listens on addr like net.Listen, and sets the backlog of the listen queue if it's positive,
+// otherwise the backlog is the system default, net.core.somaxconn.
+// Go always listens with the system default, there is no socket option for the backlog, so the socket listens
+// again with the backlog, which updates the backlog of a listening socket on linux.
+func listenWithBacklog(network, addr string, backlog int) (l net.Listener, err error) {
+	if l, err = net.Listen(network, addr); err != nil || backlog <= 0 {
+		return
+	}
+	tl, ok := l.(*net.TCPListener)
+	if !ok {
+		return
+	}
+	rc, err := tl.SyscallConn()
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	var listenErr error
+	if err = rc.Control(func(fd uintptr) {
+		listenErr = syscall.Listen(int(fd), backlog)
+	}); err == nil {
+		err = listenErr
+	}
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return
+}
+
+// acceptLoop accepts the connections of ln with concurrency goroutines and serves each of them in a new goroutine,
+// until ln is closed.
+func acceptLoop(ln net.Listener, concurrency int, serve func(conn net.Conn)) {
+	if concurrency <= 0 {
+		concurrency = DefaultAcceptConcurrency
+	}
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					log.LogErrorf("action[acceptLoop] failed to accept, err:%s", err.Error())
+					return
+				}
+				log.LogDebugf("action[acceptLoop] accept connection from %s.", conn.RemoteAddr().String())
+				go serve(conn)
+			}
+		}()
+	}
+}
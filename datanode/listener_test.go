@@ -0,0 +1,78 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptLoop(t *testing.T) {
+	for _, c := range []struct {
+		backlog     int
+		concurrency int
+	}{
+		{0, 0},
+		{1024, 1},
+		{4096, 8},
+	} {
+		ln, err := listenWithBacklog("tcp", "127.0.0.1:0", c.backlog)
+		require.NoError(t, err)
+		// echo one byte back
+		acceptLoop(ln, c.concurrency, func(conn net.Conn) {
+			defer conn.Close()
+			buf := make([]byte, 1)
+			if _, err := io.ReadFull(conn, buf); err == nil {
+				conn.Write(buf)
+			}
+		})
+
+		const conns = 500
+		var wg sync.WaitGroup
+		errs := make(chan error, conns)
+		for i := 0; i < conns; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				conn, err := net.DialTimeout("tcp", ln.Addr().String(), 10*time.Second)
+				if err != nil {
+					errs <- err
+					return
+				}
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(10 * time.Second))
+				buf := []byte{byte(i)}
+				if _, err = conn.Write(buf); err == nil {
+					_, err = io.ReadFull(conn, buf)
+				}
+				if err == nil && buf[0] != byte(i) {
+					err = io.ErrUnexpectedEOF
+				}
+				errs <- err
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err, "backlog %v concurrency %v", c.backlog, c.concurrency)
+		}
+		ln.Close()
+	}
+}
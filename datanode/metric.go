@@ -32,6 +32,9 @@ const (
 	MetricDpCount              = "dataPartitionCount"
 	MetricTotalDpSize          = "totalDpSize"
 	MetricCapacity             = "capacity"
+	MetricDiskBelowRdonlySpace = "diskBelowRdonlySpace"
+	MetricRdonlySpaceCrossing  = "rdonlySpaceCrossingCount"
+	MetricRaftApplyLag         = "dataPartitionRaftApplyLag"
 )
 
 type DataNodeMetrics struct {
@@ -44,6 +47,11 @@ type DataNodeMetrics struct {
 	MetricDpCount            *exporter.Gauge
 	MetricTotalDpSize        *exporter.Gauge
 	MetricCapacity           *exporter.GaugeVec
+	MetricDiskBelowRdonly    *exporter.GaugeVec
+	MetricRdonlyCrossing     *exporter.Counter
+	MetricRaftApplyLag       *exporter.GaugeVec
+
+	rdonlyCrossingReported uint64 // the crossings of the rdonly reserve added to MetricRdonlyCrossing
 }
 
 func (d *DataNode) registerMetrics() {
@@ -58,6 +66,9 @@ func (d *DataNode) registerMetrics() {
 	d.metrics.MetricDpCount = exporter.NewGauge(MetricDpCount)
 	d.metrics.MetricTotalDpSize = exporter.NewGauge(MetricTotalDpSize)
 	d.metrics.MetricCapacity = exporter.NewGaugeVec(MetricCapacity, "", []string{"type"})
+	d.metrics.MetricDiskBelowRdonly = exporter.NewGaugeVec(MetricDiskBelowRdonlySpace, "", []string{"disk"})
+	d.metrics.MetricRdonlyCrossing = exporter.NewCounter(MetricRdonlySpaceCrossing)
+	d.metrics.MetricRaftApplyLag = exporter.NewGaugeVec(MetricRaftApplyLag, "", []string{exporter.Vol, exporter.PartId})
 }
 
 func (d *DataNode) startMetrics() {
@@ -104,6 +115,8 @@ func (dm *DataNodeMetrics) doStat() {
 	dm.setDpCountMetrics()
 	dm.setTotalDpSizeMetrics()
 	dm.setCapacityMetrics()
+	dm.setRdonlySpaceMetrics()
+	dm.setRaftApplyLagMetrics()
 }
 
 func (dm *DataNodeMetrics) setLackDpCountMetrics() {
@@ -143,3 +156,28 @@ func (dm *DataNodeMetrics) setCapacityMetrics() {
 	dm.MetricCapacity.SetWithLabelValues(float64(used), "used")
 	dm.MetricCapacity.SetWithLabelValues(float64(available), "available")
 }
+
+func (dm *DataNodeMetrics) setRdonlySpaceMetrics() {
+	stats := dm.dataNode.space.stats
+	stats.Lock()
+	defer stats.Unlock()
+	for disk, below := range stats.DisksBelowRdonlySpace {
+		value := float64(0)
+		if below {
+			value = 1
+		}
+		dm.MetricDiskBelowRdonly.SetWithLabelValues(value, disk)
+	}
+	// the counter is added by the crossings since the last report
+	if cnt := stats.RdonlySpaceCrossingCnt; cnt > dm.rdonlyCrossingReported {
+		dm.MetricRdonlyCrossing.Add(int64(cnt - dm.rdonlyCrossingReported))
+		dm.rdonlyCrossingReported = cnt
+	}
+}
+
+func (dm *DataNodeMetrics) setRaftApplyLagMetrics() {
+	dm.dataNode.space.RangePartitions(func(dp *DataPartition) bool {
+		dm.MetricRaftApplyLag.SetWithLabelValues(float64(dp.raftApplyLag()), dp.volumeID, fmt.Sprintf("%d", dp.partitionID))
+		return true
+	})
+}
@@ -68,6 +68,7 @@ type DataPartitionMetadata struct {
 	StopRecover             bool
 	VerList                 []*proto.VolVersionInfo
 	ApplyID                 uint64
+	Readonly                bool
 }
 
 func (md *DataPartitionMetadata) Validate() (err error) {
@@ -135,9 +136,13 @@ type DataPartition struct {
 	volVersionInfoList         *proto.VolVersionInfoList
 	decommissionRepairProgress float64 // record repair progress for decommission datapartition
 	stopRecover                bool
+	readonly                   int32  // set to 1 by hand to reject writes while still serving reads
 	recoverErrCnt              uint64 // donot reset, if reach max err cnt, delete this dp
 
 	diskErrCnt uint64 // number of disk io errors while reading or writing
+
+	crcMismatchExtents sync.Map // extents failed the read crc verification to repair, extent id -> unix time
+	repairQuarantine   sync.Map // corrupt repair sources not to trust, repairSourceKey -> unix time
 }
 
 func (dp *DataPartition) IsForbidden() bool {
@@ -148,6 +153,27 @@ func (dp *DataPartition) SetForbidden(status bool) {
 	dp.config.Forbidden = status
 }
 
+func (dp *DataPartition) IsReadonly() bool {
+	return atomic.LoadInt32(&dp.readonly) == 1
+}
+
+// SetReadonly makes the partition reject writes while still serving reads, the flag is persisted
+// and kept across restarts until cleared.
+func (dp *DataPartition) SetReadonly(readonly bool) (err error) {
+	if readonly {
+		atomic.StoreInt32(&dp.readonly, 1)
+	} else {
+		atomic.StoreInt32(&dp.readonly, 0)
+	}
+	if readonly && dp.partitionStatus == proto.ReadWrite {
+		dp.partitionStatus = proto.ReadOnly
+	} else if !readonly && dp.partitionStatus == proto.ReadOnly {
+		dp.statusUpdate()
+	}
+	log.LogWarnf("action[SetReadonly] dp(%v) readonly(%v) status(%v)", dp.partitionID, readonly, dp.partitionStatus)
+	return dp.PersistMetadata()
+}
+
 func CreateDataPartition(dpCfg *dataPartitionCfg, disk *Disk, request *proto.CreateDataPartitionRequest) (dp *DataPartition, err error) {
 	if dp, err = newDataPartition(dpCfg, disk, true); err != nil {
 		return
@@ -254,6 +280,9 @@ func LoadDataPartition(partitionDir string, disk *Disk) (dp *DataPartition, err
 		return
 	}
 	dp.stopRecover = meta.StopRecover
+	if meta.Readonly {
+		dp.readonly = 1
+	}
 	dp.metaAppliedID = meta.ApplyID
 	dp.computeUsage()
 	dp.ForceSetDataPartitionToLoading()
@@ -632,6 +661,7 @@ func (dp *DataPartition) PersistMetadata() (err error) {
 		StopRecover:             dp.stopRecover,
 		VerList:                 dp.volVersionInfoList.VerList,
 		ApplyID:                 dp.appliedID,
+		Readonly:                dp.IsReadonly(),
 	}
 
 	if metaData, err = json.Marshal(md); err != nil {
@@ -690,6 +720,9 @@ func (dp *DataPartition) statusUpdate() {
 	if dp.isNormalType() && dp.extentStore.GetExtentCount() >= storage.MaxExtentCount {
 		status = proto.ReadOnly
 	}
+	if dp.IsReadonly() {
+		status = proto.ReadOnly
+	}
 	if dp.isNormalType() && dp.raftStatus == RaftStatusStopped {
 		// dp is still recovering
 		if dp.DataPartitionCreateType == proto.DecommissionedCreateDataPartition {
@@ -720,6 +753,68 @@ func (dp *DataPartition) ExtentStore() *storage.ExtentStore {
 	return dp.extentStore
 }
 
+// verifyReadCrc checks the data read for a client against the stored block crc if it is enabled.
+// On mismatch the extent is recorded to repair, and the error makes the client retry another replica.
+func (dp *DataPartition) verifyReadCrc(extentID uint64, offset int64, data []byte) (err error) {
+	if dp.dataNode == nil || !dp.dataNode.readVerifyCrc {
+		return
+	}
+	return dp.verifyBlockCrc(extentID, offset, data)
+}
+
+// verifyBlockCrc checks the data read against the stored block crc, the extent is recorded to repair on mismatch.
+func (dp *DataPartition) verifyBlockCrc(extentID uint64, offset int64, data []byte) (err error) {
+	if err = dp.ExtentStore().VerifyBlockCrc(extentID, offset, data); err == nil {
+		return
+	}
+	if !strings.Contains(err.Error(), storage.BlockCrcMismatchError.Error()) {
+		log.LogWarnf("verifyBlockCrc: dp(%v) extent(%v) offset(%v) size(%v) skip verifying, err(%v)",
+			dp.partitionID, extentID, offset, len(data), err)
+		return nil
+	}
+	dp.crcMismatchExtents.Store(extentID, time.Now().Unix())
+	log.LogErrorf("verifyBlockCrc: dp(%v) path(%v) extent(%v) offset(%v) size(%v) err(%v)",
+		dp.partitionID, dp.Path(), extentID, offset, len(data), err)
+	return
+}
+
+// CrcMismatchExtents returns the extents failed the read crc verification, extent id -> unix time.
+func (dp *DataPartition) CrcMismatchExtents() map[uint64]int64 {
+	extents := make(map[uint64]int64)
+	dp.crcMismatchExtents.Range(func(key, value interface{}) bool {
+		extents[key.(uint64)] = value.(int64)
+		return true
+	})
+	return extents
+}
+
+// markCrcMismatchExtents flags the extents failed the crc verification in the watermarks, so that the leader
+// schedules a full repair of them. The flagged watermarks are copied, the ones of the store are shared.
+// The records of the extents deleted in the meantime are dropped.
+func (dp *DataPartition) markCrcMismatchExtents(extents []*storage.ExtentInfo) {
+	store := dp.ExtentStore()
+	mismatches := make(map[uint64]bool)
+	dp.crcMismatchExtents.Range(func(key, value interface{}) bool {
+		extentID := key.(uint64)
+		if !store.HasExtent(extentID) || store.IsDeletedNormalExtent(extentID) {
+			dp.crcMismatchExtents.Delete(extentID)
+			return true
+		}
+		mismatches[extentID] = true
+		return true
+	})
+	if len(mismatches) == 0 {
+		return
+	}
+	for index, extent := range extents {
+		if mismatches[extent.FileID] {
+			flagged := *extent
+			flagged.CrcMismatch = true
+			extents[index] = &flagged
+		}
+	}
+}
+
 func (dp *DataPartition) checkIsDiskError(err error, rwFlag uint8) {
 	if err == nil {
 		return
@@ -805,12 +900,22 @@ func (dp *DataPartition) compareReplicas(v1, v2 []string) (equals bool) {
 	return false
 }
 
+// getPartitionFromMaster gets the partition from the master by the volume name, and by the partition id only if
+// that fails, e.g. the volume has been renamed and the partition still carries the old name.
+func (dp *DataPartition) getPartitionFromMaster() (partition *proto.DataPartitionInfo, err error) {
+	if partition, err = MasterClient.AdminAPI().GetDataPartition(dp.volumeID, dp.partitionID); err == nil {
+		return
+	}
+	log.LogWarnf("action[getPartitionFromMaster] partition(%v) vol(%v) err(%v), get it by id", dp.partitionID, dp.volumeID, err)
+	return MasterClient.AdminAPI().GetDataPartitionById(dp.partitionID)
+}
+
 // Fetch the replica information from the master.
 func (dp *DataPartition) fetchReplicasFromMaster() (isLeader bool, replicas []string, err error) {
 	var partition *proto.DataPartitionInfo
 	retry := 0
 	for {
-		if partition, err = MasterClient.AdminAPI().GetDataPartition(dp.volumeID, dp.partitionID); err != nil {
+		if partition, err = dp.getPartitionFromMaster(); err != nil {
 			retry++
 			if retry > 5 {
 				isLeader = false
@@ -1039,7 +1144,7 @@ func (dp *DataPartition) canRemoveSelf() (canRemove bool, err error) {
 	var partition *proto.DataPartitionInfo
 	retry := 0
 	for {
-		if partition, err = MasterClient.AdminAPI().GetDataPartition(dp.volumeID, dp.partitionID); err != nil {
+		if partition, err = dp.getPartitionFromMaster(); err != nil {
 			log.LogErrorf("action[canRemoveSelf] err[%v]", err)
 			retry++
 			if retry > 60 {
@@ -136,6 +136,20 @@ func (dp *DataPartition) raftStopped() bool {
 	return atomic.LoadInt32(&dp.raftStatus) == RaftStatusStopped
 }
 
+// raftApplyLag returns how many committed raft logs are not applied yet, a growing lag means the
+// partition accepts commits but falls behind on applying them.
+func (dp *DataPartition) raftApplyLag() uint64 {
+	if dp.raftStopped() || dp.raftPartition == nil {
+		return 0
+	}
+	committed := dp.raftPartition.CommittedIndex()
+	applied := dp.raftPartition.AppliedIndex()
+	if committed <= applied {
+		return 0
+	}
+	return committed - applied
+}
+
 func (dp *DataPartition) stopRaft() {
 	if atomic.CompareAndSwapInt32(&dp.raftStatus, RaftStatusRunning, RaftStatusStopped) {
 		// cache or preload partition not support raft and repair.
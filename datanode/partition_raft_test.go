@@ -0,0 +1,49 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"testing"
+
+	raftstoremock "github.com/cubefs/cubefs/util/mocktest/raftstore"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRaftApplyLag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	raftPartition := raftstoremock.NewMockPartition(ctrl)
+	dp := &DataPartition{partitionID: 1, volumeID: "vol", raftPartition: raftPartition}
+	// the lag of a stopped raft is not reported
+	require.Zero(t, dp.raftApplyLag())
+
+	dp.ForceSetRaftRunning()
+	for _, c := range []struct {
+		committed uint64
+		applied   uint64
+		lag       uint64
+	}{
+		{100, 100, 0},
+		{150, 100, 50},
+		// the applied index may be ahead of the committed one seen by a follower
+		{100, 120, 0},
+	} {
+		raftPartition.EXPECT().CommittedIndex().Return(c.committed)
+		raftPartition.EXPECT().AppliedIndex().Return(c.applied)
+		require.Equal(t, c.lag, dp.raftApplyLag())
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/repl"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/cubefs/cubefs/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionReadonly(t *testing.T) {
+	if proto.Buffers == nil {
+		proto.InitBufferPool(int64(32768))
+	}
+	worker := mockInitWorker(t, "readonly")
+	defer os.RemoveAll(path.Dir(worker.dp.Path()))
+	dp := worker.dp
+	dp.partitionSize = util.GB
+	s := dp.dataNode
+
+	extentID := uint64(1024)
+	data, crc := genDataAndGetCrc("readonly", util.BlockSize)
+	require.NoError(t, dp.ExtentStore().Create(extentID))
+	_, err := dp.ExtentStore().Write(extentID, 0, int64(len(data)), data, crc, storage.AppendWriteType, true, false)
+	require.NoError(t, err)
+
+	require.NoError(t, dp.SetReadonly(true))
+	require.True(t, dp.IsReadonly())
+	require.Equal(t, proto.ReadOnly, dp.Status())
+
+	// the flag is persisted
+	buf, err := os.ReadFile(path.Join(dp.Path(), DataPartitionMetadataFileName))
+	require.NoError(t, err)
+	md := &DataPartitionMetadata{}
+	require.NoError(t, json.Unmarshal(buf, md))
+	require.True(t, md.Readonly)
+
+	newPacket := func(op uint8) *repl.Packet {
+		p := repl.NewPacket()
+		p.Opcode = op
+		p.PartitionID = dp.partitionID
+		p.ExtentID = extentID
+		p.ExtentType = proto.NormalExtentType
+		p.Size = uint32(len(data))
+		p.Data = data
+		p.CRC = crc
+		p.Object = dp
+		return p
+	}
+	// writes are rejected
+	p := newPacket(proto.OpWrite)
+	s.handleWritePacket(p)
+	require.Equal(t, proto.OpNotPerm, p.ResultCode)
+	p = newPacket(proto.OpRandomWrite)
+	s.handleRandomWritePacket(p)
+	require.Equal(t, proto.OpNotPerm, p.ResultCode)
+	p = newPacket(proto.OpCreateExtent)
+	p.ExtentID++
+	s.handlePacketToCreateExtent(p)
+	require.Equal(t, proto.OpNotPerm, p.ResultCode)
+
+	// reads are still served
+	server, client := net.Pipe()
+	defer client.Close()
+	go func() {
+		defer server.Close()
+		p := newPacket(proto.OpStreamRead)
+		p.Data = nil
+		s.extentRepairReadPacket(p, server, false)
+	}()
+	reply := repl.NewPacket()
+	require.NoError(t, reply.ReadFromConnWithVer(client, proto.ReadDeadlineTime))
+	require.Equal(t, proto.OpOk, reply.ResultCode)
+	require.Equal(t, data, reply.Data[:reply.Size])
+
+	require.NoError(t, dp.SetReadonly(false))
+	require.False(t, dp.IsReadonly())
+	p = newPacket(proto.OpCreateExtent)
+	p.ExtentID++
+	s.handlePacketToCreateExtent(p)
+	require.Equal(t, proto.OpOk, p.ResultCode)
+}
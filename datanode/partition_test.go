@@ -0,0 +1,68 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	masterSDK "github.com/cubefs/cubefs/sdk/master"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPartitionFromRenamedVol(t *testing.T) {
+	// the master knows the volume by the new name only, like getDataPartition
+	var (
+		lock  sync.Mutex
+		names []string
+	)
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.FormValue("name")
+		lock.Lock()
+		names = append(names, name)
+		lock.Unlock()
+		reply := &proto.HTTPReply{Code: proto.ErrCodeSuccess}
+		if r.URL.Path != proto.AdminGetDataPartition || (name != "" && name != "newVol") {
+			reply.Code, reply.Msg = proto.ErrCodeDataPartitionNotExists, proto.ErrDataPartitionNotExists.Error()
+		} else {
+			reply.Data = &proto.DataPartitionInfo{PartitionID: 1, VolName: "newVol", Hosts: []string{LocalIP + ":17310"}}
+		}
+		json.NewEncoder(w).Encode(reply)
+	}))
+	defer master.Close()
+
+	old := MasterClient
+	defer func() { MasterClient = old }()
+	MasterClient = masterSDK.NewMasterCLientWithResolver([]string{strings.TrimPrefix(master.URL, "http://")}, false, 0)
+	require.NotNil(t, MasterClient)
+
+	dp := &DataPartition{partitionID: 1, volumeID: "oldVol"}
+	partition, err := dp.getPartitionFromMaster()
+	require.NoError(t, err)
+	require.Equal(t, "newVol", partition.VolName)
+	lock.Lock()
+	require.Equal(t, []string{"oldVol", ""}, names)
+	lock.Unlock()
+
+	isLeader, replicas, err := dp.fetchReplicasFromMaster()
+	require.NoError(t, err)
+	require.True(t, isLeader)
+	require.Equal(t, partition.Hosts, replicas)
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cubefs/cubefs/util"
+)
+
+const (
+	RepairConnModeSmux = "smux"
+	RepairConnModeTcp  = "tcp"
+)
+
+// RepairConnTargetStat is the usage of the repair connections to a target.
+type RepairConnTargetStat struct {
+	Active      int64 `json:"active"`
+	Gets        int64 `json:"gets"`
+	GetErrors   int64 `json:"getErrors"`
+	Puts        int64 `json:"puts"`
+	ForceCloses int64 `json:"forceCloses"`
+}
+
+// RepairConnStat is the health of the pool the repair connections come from.
+type RepairConnStat struct {
+	Mode     string                           `json:"mode"`
+	Targets  map[string]*RepairConnTargetStat `json:"targets"`
+	SmuxPool *util.SmuxConnPoolStat           `json:"smuxPool,omitempty"`
+	TcpPool  *util.ConnectPoolStat            `json:"tcpPool,omitempty"`
+}
+
+// repairConnCounter counts the repair connections taken from and returned to the pool by the dial target,
+// the target is recorded with the connection since its remote address may differ, e.g. the smux port.
+type repairConnCounter struct {
+	targets sync.Map // target -> *RepairConnTargetStat
+	conns   sync.Map // net.Conn -> target
+}
+
+func (c *repairConnCounter) target(addr string) *RepairConnTargetStat {
+	if stat, ok := c.targets.Load(addr); ok {
+		return stat.(*RepairConnTargetStat)
+	}
+	stat, _ := c.targets.LoadOrStore(addr, &RepairConnTargetStat{})
+	return stat.(*RepairConnTargetStat)
+}
+
+func (c *repairConnCounter) onGet(addr string, conn net.Conn, err error) {
+	stat := c.target(addr)
+	atomic.AddInt64(&stat.Gets, 1)
+	if err != nil {
+		atomic.AddInt64(&stat.GetErrors, 1)
+		return
+	}
+	c.conns.Store(conn, addr)
+	atomic.AddInt64(&stat.Active, 1)
+}
+
+// onPut counts a connection returned to the pool, it is force closed if the request on it failed.
+func (c *repairConnCounter) onPut(conn net.Conn, forceClose bool) {
+	addr, ok := c.conns.LoadAndDelete(conn)
+	if !ok {
+		addr = conn.RemoteAddr().String()
+	}
+	stat := c.target(addr.(string))
+	atomic.AddInt64(&stat.Puts, 1)
+	atomic.AddInt64(&stat.Active, -1)
+	if forceClose {
+		atomic.AddInt64(&stat.ForceCloses, 1)
+	}
+}
+
+func (c *repairConnCounter) snapshot() map[string]*RepairConnTargetStat {
+	targets := make(map[string]*RepairConnTargetStat)
+	c.targets.Range(func(key, value interface{}) bool {
+		stat := value.(*RepairConnTargetStat)
+		targets[key.(string)] = &RepairConnTargetStat{
+			Active:      atomic.LoadInt64(&stat.Active),
+			Gets:        atomic.LoadInt64(&stat.Gets),
+			GetErrors:   atomic.LoadInt64(&stat.GetErrors),
+			Puts:        atomic.LoadInt64(&stat.Puts),
+			ForceCloses: atomic.LoadInt64(&stat.ForceCloses),
+		}
+		return true
+	})
+	return targets
+}
+
+// getRepairConnStat returns the stats of the pool chosen by initConnPool.
+func (s *DataNode) getRepairConnStat() *RepairConnStat {
+	stat := &RepairConnStat{Targets: s.repairConnCounter.snapshot()}
+	if s.enableSmuxConnPool {
+		stat.Mode = RepairConnModeSmux
+		if s.smuxConnPool != nil {
+			stat.SmuxPool = s.smuxConnPool.GetStat()
+		}
+	} else {
+		stat.Mode = RepairConnModeTcp
+		stat.TcpPool = gConnPool.GetStat()
+	}
+	return stat
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/stretchr/testify/require"
+	"github.com/xtaci/smux"
+)
+
+// startRepairConnTarget listens on a local port holding the connections, and the streams of them if smux is on.
+func startRepairConnTarget(t *testing.T, enableSmux bool) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			if !enableSmux {
+				continue
+			}
+			go func() {
+				sess, err := smux.Server(conn, nil)
+				if err != nil {
+					return
+				}
+				for {
+					if _, err = sess.AcceptStream(); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// closedRepairConnTarget returns a local address nothing listens on.
+func closedRepairConnTarget(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func TestRepairConnStat(t *testing.T) {
+	for _, mode := range []string{RepairConnModeTcp, RepairConnModeSmux} {
+		t.Run(mode, func(t *testing.T) {
+			s := &DataNode{enableSmuxConnPool: mode == RepairConnModeSmux}
+			if s.enableSmuxConnPool {
+				s.smuxConnPoolConfig = util.DefaultSmuxConnPoolConfig()
+				s.smuxPortShift = 1
+			}
+			s.initConnPool()
+			defer s.closeSmuxConnPool()
+
+			// the stats are keyed by the target dialed, which differs from the remote address of the
+			// connections, the smux port is shifted and the tcp target is a host name
+			listenAddr := startRepairConnTarget(t, s.enableSmuxConnPool)
+			target := strings.Replace(listenAddr, "127.0.0.1", "localhost", 1)
+			if s.enableSmuxConnPool {
+				target = util.ShiftAddrPort(listenAddr, -s.smuxPortShift)
+			}
+			conn1, err := s.getRepairConnFunc(target)
+			require.NoError(t, err)
+			conn2, err := s.getRepairConnFunc(target)
+			require.NoError(t, err)
+			s.putRepairConnFunc(conn1, false)
+			s.putRepairConnFunc(conn2, true)
+			unreachable := closedRepairConnTarget(t)
+			_, err = s.getRepairConnFunc(unreachable)
+			require.Error(t, err)
+
+			rec := httptest.NewRecorder()
+			s.getRepairConnStatAPI(rec, httptest.NewRequest(http.MethodGet, "/repairConnStat", nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+			stat := &RepairConnStat{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &proto.HTTPReply{Data: stat}))
+			require.Equal(t, mode, stat.Mode)
+			require.Equal(t, &RepairConnTargetStat{Gets: 2, Puts: 2, ForceCloses: 1}, stat.Targets[target])
+			require.Equal(t, &RepairConnTargetStat{Gets: 1, GetErrors: 1}, stat.Targets[unreachable])
+			require.Len(t, stat.Targets, 2)
+			if mode == RepairConnModeSmux {
+				require.Nil(t, stat.TcpPool)
+				require.NotNil(t, stat.SmuxPool)
+				require.Contains(t, stat.SmuxPool.Pools, listenAddr)
+				require.Greater(t, stat.SmuxPool.Pools[listenAddr].TotalSessions, 0)
+			} else {
+				require.Nil(t, stat.SmuxPool)
+				require.NotNil(t, stat.TcpPool)
+				require.Contains(t, stat.TcpPool.Pools, target)
+				require.Greater(t, stat.TcpPool.Pools[target].IdleConns, 0)
+			}
+		})
+	}
+}
@@ -123,6 +123,27 @@ const (
 	ConfigKeyDiskUnavailablePartitionErrorCount = "diskUnavailablePartitionErrorCount"
 	// disk read extent limit
 	ConfigEnableDiskReadExtentLimit = "enableDiskReadRepairExtentLimit" // bool
+	// how to choose the disk to create a partition: weight, partitionCount, freeSpace or weighted
+	ConfigKeyDiskSelectPolicy = "diskSelectPolicy" // string
+	// the max concurrent repair reads of a disk, 0 means unlimited
+	ConfigKeyRepairReadConcurrencyPerDisk = "repairReadConcurrencyPerDisk" // int
+	// verify the data read by clients against the stored block crc, costs cpu
+	ConfigKeyReadVerifyCrc = "readVerifyCrc" // bool
+	// log the requests and responses of the repl layer as json
+	ConfigKeyReplJSONLog = "replJsonLog" // bool
+	// the backlog of the listen queue of the tcp service, 0 means the system default
+	ConfigKeyListenBacklog = "listenBacklog" // int
+	// the goroutines accepting the connections of the tcp service
+	ConfigKeyAcceptConcurrency = "acceptConcurrency" // int
+	// only report the changed partitions in the heartbeats if the cpu util in percent or the partition
+	// count reaches the threshold, 0 means no threshold
+	ConfigKeyHeartbeatDeltaCpuUtil      = "heartbeatDeltaCpuUtil"      // float
+	ConfigKeyHeartbeatDeltaPartitionCnt = "heartbeatDeltaPartitionCnt" // int
+	// still report all the partitions every that many heartbeats while reporting the changed ones
+	ConfigKeyHeartbeatFullReportInterval = "heartbeatFullReportInterval" // int
+	// serve the admin http handlers on a dedicated management address instead of the default http server
+	ConfigKeyMgmtBindIp = "mgmtBindIp" // string
+	ConfigKeyMgmtPort   = "mgmtPort"   // string
 )
 
 const cpuSampleDuration = 1 * time.Second
@@ -157,11 +178,22 @@ type DataNode struct {
 
 	getRepairConnFunc func(target string) (net.Conn, error)
 	putRepairConnFunc func(conn net.Conn, forceClose bool)
+	repairConnCounter repairConnCounter
 
 	metrics        *DataNodeMetrics
 	metricsDegrade int64
 	metricsCnt     uint64
-	volUpdating    sync.Map // map[string]*verOp2Phase
+	opStats        opStats
+
+	repairReadConcurrencyPerDisk int
+	readVerifyCrc                bool
+	listenBacklog                int
+	acceptConcurrency            int
+	partitionReporter            *partitionReporter
+	mgmtAddr                     string
+	mgmtListener                 net.Listener
+	mgmtServer                   *http.Server
+	volUpdating                  sync.Map // map[string]*verOp2Phase
 
 	control common.Control
 
@@ -267,7 +299,11 @@ func doStart(server common.Server, cfg *config.Config) (err error) {
 		return
 	}
 
-	go s.registerHandler()
+	if s.mgmtAddr == "" {
+		go s.registerHandler(http.DefaultServeMux)
+	} else if err = s.startMgmtService(); err != nil {
+		return
+	}
 
 	s.scheduleTask()
 
@@ -289,6 +325,7 @@ func doShutdown(server common.Server) {
 	s.space.Stop()
 	s.stopUpdateNodeInfo()
 	s.stopTCPService()
+	s.stopMgmtService()
 	s.stopRaftServer()
 	s.stopSmuxService()
 	s.closeSmuxConnPool()
@@ -346,9 +383,33 @@ func (s *DataNode) parseConfig(cfg *config.Config) (err error) {
 		s.zoneName = DefaultZoneName
 	}
 	s.metricsDegrade = cfg.GetInt64(CfgMetricsDegrade)
+	s.readVerifyCrc = cfg.GetBool(ConfigKeyReadVerifyCrc)
+	repl.SetJSONLog(cfg.GetBool(ConfigKeyReplJSONLog))
 
 	s.serviceIDKey = cfg.GetString(ConfigServiceIDKey)
 
+	s.listenBacklog = int(cfg.GetInt64(ConfigKeyListenBacklog))
+	if s.listenBacklog < 0 || s.listenBacklog > MaxListenBacklog {
+		log.LogWarnf("action[parseConfig] %v(%v) out of range, use the system default", ConfigKeyListenBacklog, s.listenBacklog)
+		s.listenBacklog = 0
+	}
+	s.acceptConcurrency = int(cfg.GetInt64(ConfigKeyAcceptConcurrency))
+	if s.acceptConcurrency <= 0 || s.acceptConcurrency > MaxAcceptConcurrency {
+		if s.acceptConcurrency != 0 {
+			log.LogWarnf("action[parseConfig] %v(%v) out of range, set as default(%v)",
+				ConfigKeyAcceptConcurrency, s.acceptConcurrency, DefaultAcceptConcurrency)
+		}
+		s.acceptConcurrency = DefaultAcceptConcurrency
+	}
+	if mgmtPort := cfg.GetString(ConfigKeyMgmtPort); mgmtPort != "" {
+		if !regexpPort.MatchString(mgmtPort) {
+			return fmt.Errorf("Err:%v must be a port number", ConfigKeyMgmtPort)
+		}
+		s.mgmtAddr = net.JoinHostPort(cfg.GetString(ConfigKeyMgmtBindIp), mgmtPort)
+	}
+	s.partitionReporter = newPartitionReporter(cfg.GetFloat(ConfigKeyHeartbeatDeltaCpuUtil),
+		int(cfg.GetInt64(ConfigKeyHeartbeatDeltaPartitionCnt)), int(cfg.GetInt64(ConfigKeyHeartbeatFullReportInterval)))
+
 	diskUnavailablePartitionErrorCount := cfg.GetInt64(ConfigKeyDiskUnavailablePartitionErrorCount)
 	if diskUnavailablePartitionErrorCount <= 0 || diskUnavailablePartitionErrorCount > 100 {
 		diskUnavailablePartitionErrorCount = DefaultDiskUnavailablePartitionErrorCount
@@ -394,6 +455,9 @@ func (s *DataNode) startSpaceManager(cfg *config.Config) (err error) {
 	s.space.SetRaftStore(s.raftStore)
 	s.space.SetNodeID(s.nodeID)
 	s.space.SetClusterID(s.clusterID)
+	if err = s.space.SetDiskSelectPolicy(cfg.GetString(ConfigKeyDiskSelectPolicy)); err != nil {
+		return
+	}
 	s.initQosLimit(cfg)
 
 	diskRdonlySpace := uint64(cfg.GetInt64(CfgDiskRdonlySpace))
@@ -401,6 +465,8 @@ func (s *DataNode) startSpaceManager(cfg *config.Config) (err error) {
 		diskRdonlySpace = DefaultDiskRetainMin
 	}
 	diskEnableReadRepairExtentLimit := cfg.GetBoolWithDefault(ConfigEnableDiskReadExtentLimit, false)
+	s.repairReadConcurrencyPerDisk = int(cfg.GetInt64(ConfigKeyRepairReadConcurrencyPerDisk))
+	log.LogInfof("startSpaceManager repairReadConcurrencyPerDisk %d", s.repairReadConcurrencyPerDisk)
 	log.LogInfof("startSpaceManager preReserveSpace %d", diskRdonlySpace)
 
 	paths := make([]string, 0)
@@ -630,31 +696,61 @@ func (s *DataNode) checkPartitionInMemoryMatchWithInDisk() (lackPartitions []uin
 	return
 }
 
-func (s *DataNode) registerHandler() {
-	http.HandleFunc("/disks", s.getDiskAPI)
-	http.HandleFunc("/partitions", s.getPartitionsAPI)
-	http.HandleFunc("/partition", s.getPartitionAPI)
-	http.HandleFunc("/extent", s.getExtentAPI)
-	http.HandleFunc("/block", s.getBlockCrcAPI)
-	http.HandleFunc("/stats", s.getStatAPI)
-	http.HandleFunc("/raftStatus", s.getRaftStatus)
-	http.HandleFunc("/setAutoRepairStatus", s.setAutoRepairStatus)
-	http.HandleFunc("/getTinyDeleted", s.getTinyDeleted)
-	http.HandleFunc("/getNormalDeleted", s.getNormalDeleted)
-	http.HandleFunc("/getSmuxPoolStat", s.getSmuxPoolStat())
-	http.HandleFunc("/setMetricsDegrade", s.setMetricsDegrade)
-	http.HandleFunc("/getMetricsDegrade", s.getMetricsDegrade)
-	http.HandleFunc("/qosEnable", s.setQosEnable())
-	http.HandleFunc("/genClusterVersionFile", s.genClusterVersionFile)
-	http.HandleFunc("/setDiskBad", s.setDiskBadAPI)
-	http.HandleFunc("/setDiskQos", s.setDiskQos)
-	http.HandleFunc("/getDiskQos", s.getDiskQos)
-	http.HandleFunc("/reloadDataPartition", s.reloadDataPartition)
-	http.HandleFunc("/setDiskExtentReadLimitStatus", s.setDiskExtentReadLimitStatus)
-	http.HandleFunc("/queryDiskExtentReadLimitStatus", s.queryDiskExtentReadLimitStatus)
-	// http.HandleFunc("/detachDataPartition", s.detachDataPartition)
-	// http.HandleFunc("/loadDataPartition", s.loadDataPartition)
-	http.HandleFunc("/releaseDiskExtentReadLimitToken", s.releaseDiskExtentReadLimitToken)
+func (s *DataNode) registerHandler(mux *http.ServeMux) {
+	mux.HandleFunc("/disks", s.getDiskAPI)
+	mux.HandleFunc("/partitions", s.getPartitionsAPI)
+	mux.HandleFunc("/partition", s.getPartitionAPI)
+	mux.HandleFunc("/extent", s.getExtentAPI)
+	mux.HandleFunc("/block", s.getBlockCrcAPI)
+	mux.HandleFunc("/stats", s.getStatAPI)
+	mux.HandleFunc("/raftStatus", s.getRaftStatus)
+	mux.HandleFunc("/setAutoRepairStatus", s.setAutoRepairStatus)
+	mux.HandleFunc("/getTinyDeleted", s.getTinyDeleted)
+	mux.HandleFunc("/getNormalDeleted", s.getNormalDeleted)
+	mux.HandleFunc("/tinyExtentStat", s.getTinyExtentStat)
+	mux.HandleFunc("/persistExtentIndex", s.persistExtentIndex)
+	mux.HandleFunc("/getSmuxPoolStat", s.getSmuxPoolStat())
+	mux.HandleFunc("/repairConnStat", s.getRepairConnStatAPI)
+	mux.HandleFunc("/setMetricsDegrade", s.setMetricsDegrade)
+	mux.HandleFunc("/getMetricsDegrade", s.getMetricsDegrade)
+	mux.HandleFunc("/qosEnable", s.setQosEnable())
+	mux.HandleFunc("/genClusterVersionFile", s.genClusterVersionFile)
+	mux.HandleFunc("/setDiskBad", s.setDiskBadAPI)
+	mux.HandleFunc("/setPartitionReadonly", s.setPartitionReadonly)
+	mux.HandleFunc("/setDiskQos", s.setDiskQos)
+	mux.HandleFunc("/getDiskQos", s.getDiskQos)
+	mux.HandleFunc("/reloadDataPartition", s.reloadDataPartition)
+	mux.HandleFunc("/setDiskExtentReadLimitStatus", s.setDiskExtentReadLimitStatus)
+	mux.HandleFunc("/queryDiskExtentReadLimitStatus", s.queryDiskExtentReadLimitStatus)
+	// mux.HandleFunc("/detachDataPartition", s.detachDataPartition)
+	// mux.HandleFunc("/loadDataPartition", s.loadDataPartition)
+	mux.HandleFunc("/releaseDiskExtentReadLimitToken", s.releaseDiskExtentReadLimitToken)
+}
+
+// startMgmtService serves the admin http handlers on the management address, so that they can be
+// firewalled away from the data plane.
+func (s *DataNode) startMgmtService() (err error) {
+	mux := http.NewServeMux()
+	s.registerHandler(mux)
+	if s.mgmtListener, err = net.Listen("tcp", s.mgmtAddr); err != nil {
+		log.LogErrorf("action[startMgmtService] failed to listen %v, err: %v", s.mgmtAddr, err)
+		return
+	}
+	s.mgmtServer = &http.Server{Handler: mux}
+	log.LogInfof("action[startMgmtService] serve admin http on %v", s.mgmtListener.Addr())
+	go func() {
+		if err := s.mgmtServer.Serve(s.mgmtListener); err != nil && err != http.ErrServerClosed {
+			log.LogErrorf("action[startMgmtService] serve admin http on %v, err: %v", s.mgmtAddr, err)
+		}
+	}()
+	return
+}
+
+func (s *DataNode) stopMgmtService() {
+	if s.mgmtServer != nil {
+		s.mgmtServer.Close()
+		log.LogDebugf("action[stopMgmtService] stop admin http service.")
+	}
 }
 
 func (s *DataNode) startTCPService() (err error) {
@@ -663,24 +759,15 @@ func (s *DataNode) startTCPService() (err error) {
 	if s.bindIp {
 		addr = fmt.Sprintf("%s:%v", LocalIP, s.port)
 	}
-	l, err := net.Listen(NetworkProtocol, addr)
-	log.LogDebugf("action[startTCPService] listen %v address(%v).", NetworkProtocol, addr)
+	l, err := listenWithBacklog(NetworkProtocol, addr, s.listenBacklog)
+	log.LogDebugf("action[startTCPService] listen %v address(%v) backlog(%v) acceptConcurrency(%v).",
+		NetworkProtocol, addr, s.listenBacklog, s.acceptConcurrency)
 	if err != nil {
 		log.LogError("failed to listen, err:", err)
 		return
 	}
 	s.tcpListener = l
-	go func(ln net.Listener) {
-		for {
-			conn, err := ln.Accept()
-			if err != nil {
-				log.LogErrorf("action[startTCPService] failed to accept, err:%s", err.Error())
-				break
-			}
-			log.LogDebugf("action[startTCPService] accept connection from %s.", conn.RemoteAddr().String())
-			go s.serveConn(conn)
-		}
-	}(l)
+	acceptLoop(l, s.acceptConcurrency, s.serveConn)
 	return
 }
 
@@ -842,19 +929,33 @@ func (s *DataNode) initConnPool() {
 		s.getRepairConnFunc = func(target string) (net.Conn, error) {
 			addr := util.ShiftAddrPort(target, s.smuxPortShift)
 			log.LogDebugf("[dataNode.getRepairConnFunc] get smux conn, addr(%v)", addr)
-			return s.smuxConnPool.GetConnect(addr)
+			conn, err := s.smuxConnPool.GetConnect(addr)
+			if err != nil {
+				s.repairConnCounter.onGet(target, nil, err)
+				return nil, err
+			}
+			s.repairConnCounter.onGet(target, conn, nil)
+			return conn, nil
 		}
 		s.putRepairConnFunc = func(conn net.Conn, forceClose bool) {
 			log.LogDebugf("[dataNode.putRepairConnFunc] put smux conn, addr(%v), forceClose(%v)", conn.RemoteAddr().String(), forceClose)
+			s.repairConnCounter.onPut(conn, forceClose)
 			s.smuxConnPool.PutConnect(conn.(*smux.Stream), forceClose)
 		}
 	} else {
 		s.getRepairConnFunc = func(target string) (conn net.Conn, err error) {
 			log.LogDebugf("[dataNode.getRepairConnFunc] get tcp conn, addr(%v)", target)
-			return gConnPool.GetConnect(target)
+			tcpConn, err := gConnPool.GetConnect(target)
+			if err != nil {
+				s.repairConnCounter.onGet(target, nil, err)
+				return nil, err
+			}
+			s.repairConnCounter.onGet(target, tcpConn, nil)
+			return tcpConn, nil
 		}
 		s.putRepairConnFunc = func(conn net.Conn, forceClose bool) {
 			log.LogDebugf("[dataNode.putRepairConnFunc] put tcp conn, addr(%v), forceClose(%v)", conn.RemoteAddr().String(), forceClose)
+			s.repairConnCounter.onPut(conn, forceClose)
 			gConnPool.PutConnect(conn.(*net.TCPConn), forceClose)
 		}
 	}
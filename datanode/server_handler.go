@@ -76,8 +76,20 @@ func (s *DataNode) getDiskAPI(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *DataNode) getStatAPI(w http.ResponseWriter, r *http.Request) {
-	response := &proto.DataNodeHeartbeatResponse{}
-	s.buildHeartBeatResponse(response)
+	var reset common.Bool
+	if err := parseArgs(r, reset.Key("reset").OmitEmpty()); err != nil {
+		s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	response := &struct {
+		*proto.DataNodeHeartbeatResponse
+		// the packets handled by opcode since the start or the last reset
+		OpStats map[string]*OpStatInfo `json:"opStats"`
+	}{
+		DataNodeHeartbeatResponse: &proto.DataNodeHeartbeatResponse{},
+	}
+	s.buildHeartBeatResponse(response.DataNodeHeartbeatResponse)
+	response.OpStats = s.opStats.snapshot(reset.V)
 
 	s.buildSuccessResp(w, response)
 }
@@ -98,8 +110,14 @@ func (s *DataNode) getRaftStatus(w http.ResponseWriter, r *http.Request) {
 		s.buildFailureResp(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	raftStatus := s.raftStore.RaftStatus(raftID.V)
-	s.buildSuccessResp(w, raftStatus)
+	status := &struct {
+		*raft.Status
+		ApplyLag uint64 `json:"applyLag"`
+	}{Status: s.raftStore.RaftStatus(raftID.V)}
+	if dp := s.space.Partition(raftID.V); dp != nil {
+		status.ApplyLag = dp.raftApplyLag()
+	}
+	s.buildSuccessResp(w, status)
 }
 
 func (s *DataNode) getPartitionsAPI(w http.ResponseWriter, r *http.Request) {
@@ -174,6 +192,8 @@ func (s *DataNode) getPartitionAPI(w http.ResponseWriter, r *http.Request) {
 		Replicas             []string              `json:"replicas"`
 		TinyDeleteRecordSize int64                 `json:"tinyDeleteRecordSize"`
 		RaftStatus           *raft.Status          `json:"raftStatus"`
+		CrcMismatchExtents   map[uint64]int64      `json:"crcMismatchExtents"`
+		Readonly             bool                  `json:"readonly"`
 	}{
 		VolName:              partition.volumeID,
 		ID:                   partition.partitionID,
@@ -186,6 +206,8 @@ func (s *DataNode) getPartitionAPI(w http.ResponseWriter, r *http.Request) {
 		Replicas:             partition.Replicas(),
 		TinyDeleteRecordSize: tinyDeleteRecordSize,
 		RaftStatus:           raftSt,
+		CrcMismatchExtents:   partition.CrcMismatchExtents(),
+		Readonly:             partition.IsReadonly(),
 	}
 
 	if partition.isNormalType() {
@@ -264,6 +286,59 @@ func (s *DataNode) getTinyDeleted(w http.ResponseWriter, r *http.Request) {
 	s.buildSuccessResp(w, extentInfo)
 }
 
+// getTinyExtentStat reports the used size and the holes of the tiny extents of the partition,
+// which tells whether the tiny extents need defragmentation.
+func (s *DataNode) getTinyExtentStat(w http.ResponseWriter, r *http.Request) {
+	var (
+		pid   common.Uint
+		err   error
+		stats []*storage.TinyExtentStat
+	)
+	if err = parseArgs(r, pid.ID()); err != nil {
+		s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	partition := s.space.Partition(pid.V)
+	if partition == nil {
+		s.buildFailureResp(w, http.StatusNotFound, "partition not exist")
+		return
+	}
+	if stats, err = partition.ExtentStore().GetTinyExtentStats(); err != nil {
+		s.buildFailureResp(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.buildSuccessResp(w, stats)
+}
+
+// persistExtentIndex persists the extent index of the partition now, which is also done by the backend task
+// periodically, and reports how the extents were restored on startup.
+func (s *DataNode) persistExtentIndex(w http.ResponseWriter, r *http.Request) {
+	var (
+		pid   common.Uint
+		err   error
+		count int
+	)
+	if err = parseArgs(r, pid.ID()); err != nil {
+		s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	partition := s.space.Partition(pid.V)
+	if partition == nil {
+		s.buildFailureResp(w, http.StatusNotFound, "partition not exist")
+		return
+	}
+	if count, err = partition.ExtentStore().PersistExtentIndex(); err != nil {
+		s.buildFailureResp(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	fromIndex, fromDisk := partition.ExtentStore().ExtentIndexLoadStat()
+	s.buildSuccessResp(w, map[string]interface{}{
+		"persisted":       count,
+		"loadedFromIndex": fromIndex,
+		"loadedFromDisk":  fromDisk,
+	})
+}
+
 func (s *DataNode) getNormalDeleted(w http.ResponseWriter, r *http.Request) {
 	var (
 		pid        common.Uint
@@ -378,6 +453,10 @@ func (s *DataNode) getSmuxPoolStat() func(http.ResponseWriter, *http.Request) {
 	}
 }
 
+func (s *DataNode) getRepairConnStatAPI(w http.ResponseWriter, r *http.Request) {
+	s.buildSuccessResp(w, s.getRepairConnStat())
+}
+
 func (s *DataNode) setMetricsDegrade(w http.ResponseWriter, r *http.Request) {
 	key := "level"
 	var level common.Int
@@ -478,6 +557,32 @@ func (s *DataNode) setDiskBadAPI(w http.ResponseWriter, r *http.Request) {
 	s.buildSuccessResp(w, "OK")
 }
 
+// setPartitionReadonly makes a partition reject writes while still serving reads, unlike setDiskBad
+// the other partitions on the disk are not affected.
+func (s *DataNode) setPartitionReadonly(w http.ResponseWriter, r *http.Request) {
+	var (
+		pid      common.Uint
+		readonly common.Bool
+	)
+	if err := parseArgs(r, pid.ID(), readonly.Key("readonly")); err != nil {
+		log.LogErrorf("[setPartitionReadonly] %v", err.Error())
+		s.buildFailureResp(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	partition := s.space.Partition(pid.V)
+	if partition == nil {
+		s.buildFailureResp(w, http.StatusNotFound, "partition not exist")
+		return
+	}
+	if err := partition.SetReadonly(readonly.V); err != nil {
+		err = fmt.Errorf("persist readonly flag of dp(%v) failed: %v", pid.V, err)
+		log.LogErrorf("[setPartitionReadonly] %v", err.Error())
+		s.buildFailureResp(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.buildSuccessResp(w, "OK")
+}
+
 func (s *DataNode) reloadDataPartition(w http.ResponseWriter, r *http.Request) {
 	if !s.checkAllDiskLoaded() {
 		s.buildFailureResp(w, http.StatusBadRequest, "please wait for disk loading")
@@ -0,0 +1,52 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMgmtService(t *testing.T) {
+	s := &DataNode{mgmtAddr: "127.0.0.1:0", metricsDegrade: 3}
+	require.NoError(t, s.startMgmtService())
+	defer s.stopMgmtService()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(fmt.Sprintf("http://%v%v", s.mgmtListener.Addr(), path))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(data)
+	}
+	code, body := get("/getMetricsDegrade")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "3\n", body)
+	code, body = get("/setMetricsDegrade?level=5")
+	require.Equal(t, http.StatusOK, code, body)
+	_, body = get("/getMetricsDegrade")
+	require.Equal(t, "5\n", body)
+
+	// the handlers are not on the default http server
+	rec := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/getMetricsDegrade", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -45,6 +46,38 @@ type SpaceManager struct {
 	diskUtils      map[string]*atomicutil.Float64
 	samplerDone    chan struct{}
 	allDisksLoaded bool
+	selectWeight   diskSelectWeight
+	stopOnce       sync.Once
+}
+
+const (
+	DiskSelectByWeight         = "weight"         // the allocated ratio of the disk, by default
+	DiskSelectByPartitionCount = "partitionCount" // the number of partitions on the disk
+	DiskSelectByFreeSpace      = "freeSpace"      // the available space of the disk
+	DiskSelectWeighted         = "weighted"       // the blend of the allocated ratio and the used ratio
+)
+
+// diskSelectWeight weighs a disk to create a partition, the disk with the least weight is chosen.
+type diskSelectWeight func(d *Disk) float64
+
+var diskSelectWeights = map[string]diskSelectWeight{
+	DiskSelectByWeight: func(d *Disk) float64 {
+		return d.getSelectWeight()
+	},
+	DiskSelectByPartitionCount: func(d *Disk) float64 {
+		return float64(d.PartitionCount())
+	},
+	DiskSelectByFreeSpace: func(d *Disk) float64 {
+		return -float64(d.Available)
+	},
+	DiskSelectWeighted: func(d *Disk) float64 {
+		if d.Total == 0 {
+			// the space of the disk is not known yet, never choose it
+			return math.MaxFloat64
+		}
+		usedRatio := 1 - float64(d.Available)/float64(d.Total)
+		return (d.getSelectWeight() + usedRatio) / 2
+	},
 }
 
 const diskSampleDuration = 1 * time.Second
@@ -64,47 +97,95 @@ func NewSpaceManager(dataNode *DataNode) *SpaceManager {
 	return space
 }
 
+// the timeout for Stop to wait for all the partitions to stop
+const defaultStopPartitionsTimeout = 5 * time.Minute
+
 func (manager *SpaceManager) Stop() {
+	manager.StopWithTimeout(defaultStopPartitionsTimeout)
+}
+
+type stopPartitionResult struct {
+	partitionID uint64
+	err         error
+}
+
+// stopPartition stops the raft and the store of the partition, a panic is returned as the error.
+func stopPartition(dp *DataPartition) (err error) {
 	defer func() {
-		recover()
+		if r := recover(); r != nil {
+			err = fmt.Errorf("stop partition panic: %v", r)
+		}
 	}()
-	close(manager.stopC)
-	// stop sampler
-	close(manager.samplerDone)
-	// Parallel stop data partitions.
-	const maxParallelism = 128
-	parallelism := int(math.Min(float64(maxParallelism), float64(len(manager.partitions))))
-	wg := sync.WaitGroup{}
-	partitionC := make(chan *DataPartition, parallelism)
-	wg.Add(1)
+	dp.stopRaft()
+	dp.Stop()
+	return
+}
 
-	// Close raft store.
+// StopWithTimeout stops the partitions by a bounded pool of workers and waits for them at most the timeout.
+// It returns the partitions which fail or do not stop in time, the rest are stopped anyway.
+func (manager *SpaceManager) StopWithTimeout(timeout time.Duration) (failed []uint64) {
+	manager.stopOnce.Do(func() {
+		close(manager.stopC)
+		// stop sampler
+		if manager.samplerDone != nil {
+			close(manager.samplerDone)
+		}
+	})
+
+	manager.partitionMutex.RLock()
+	partitions := make([]*DataPartition, 0, len(manager.partitions))
 	for _, partition := range manager.partitions {
-		partition.stopRaft()
+		partitions = append(partitions, partition)
+	}
+	manager.partitionMutex.RUnlock()
+	if len(partitions) == 0 {
+		return
 	}
 
-	go func(c chan<- *DataPartition) {
-		defer wg.Done()
-		for _, partition := range manager.partitions {
-			c <- partition
-		}
-		close(c)
-	}(partitionC)
-
+	// Parallel stop data partitions in the order of the ids, the timeout is applied to the whole pool, so the
+	// partitions queued behind the hanging ones are reported too. The channels are large enough that the workers
+	// go on stopping the rest and dropping the late results after the return.
+	const maxParallelism = 128
+	parallelism := int(math.Min(float64(maxParallelism), float64(len(partitions))))
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].partitionID < partitions[j].partitionID })
+	partitionC := make(chan *DataPartition, len(partitions))
+	resultC := make(chan stopPartitionResult, len(partitions))
+	for _, partition := range partitions {
+		partitionC <- partition
+	}
+	close(partitionC)
 	for i := 0; i < parallelism; i++ {
-		wg.Add(1)
-		go func(c <-chan *DataPartition) {
-			defer wg.Done()
-			var partition *DataPartition
-			for {
-				if partition = <-c; partition == nil {
-					return
-				}
-				partition.Stop()
+		go func() {
+			for partition := range partitionC {
+				resultC <- stopPartitionResult{partitionID: partition.partitionID, err: stopPartition(partition)}
+			}
+		}()
+	}
+
+	pending := make(map[uint64]struct{}, len(partitions))
+	for _, partition := range partitions {
+		pending[partition.partitionID] = struct{}{}
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for len(pending) > 0 {
+		select {
+		case result := <-resultC:
+			delete(pending, result.partitionID)
+			if result.err != nil {
+				log.LogErrorf("action[StopWithTimeout] stop partition(%v) failed: %v", result.partitionID, result.err)
+				failed = append(failed, result.partitionID)
+			}
+		case <-timer.C:
+			for id := range pending {
+				log.LogErrorf("action[StopWithTimeout] partition(%v) does not stop in %v", id, timeout)
+				failed = append(failed, id)
 			}
-		}(partitionC)
+			pending = nil
+		}
 	}
-	wg.Wait()
+	sort.Slice(failed, func(i, j int) bool { return failed[i] < failed[j] })
+	return
 }
 
 func (manager *SpaceManager) GetAllDiskPartitions() []*disk.PartitionStat {
@@ -180,6 +261,20 @@ func (manager *SpaceManager) SetClusterID(clusterID string) {
 	manager.clusterID = clusterID
 }
 
+// SetDiskSelectPolicy sets how to choose the disk to create a partition, empty for the default policy.
+func (manager *SpaceManager) SetDiskSelectPolicy(policy string) (err error) {
+	if policy == "" {
+		policy = DiskSelectByWeight
+	}
+	weight, ok := diskSelectWeights[policy]
+	if !ok {
+		return fmt.Errorf("unknown disk select policy(%v)", policy)
+	}
+	manager.selectWeight = weight
+	log.LogInfof("action[SetDiskSelectPolicy] disk select policy(%v)", policy)
+	return
+}
+
 func (manager *SpaceManager) GetClusterID() (clusterID string) {
 	return manager.clusterID
 }
@@ -293,14 +388,30 @@ func (manager *SpaceManager) updateMetrics() {
 		total, used, available                                 uint64
 		totalPartitionSize, remainingCapacityToCreatePartition uint64
 		maxCapacityToCreatePartition, partitionCnt             uint64
+		rdonlySpaceCrossingCnt                                 uint64
 	)
 	maxCapacityToCreatePartition = 0
+	disksBelowRdonlySpace := make(map[string]bool, len(manager.disks))
 	for _, d := range manager.disks {
 		if d.Status == proto.Unavailable {
 			log.LogInfof("disk is broken, not stat disk useage, diskpath %s", d.Path)
 			continue
 		}
 
+		below := d.Unallocated < d.DiskRdonlySpace
+		if below != d.belowRdonlySpace {
+			d.belowRdonlySpace = below
+			rdonlySpaceCrossingCnt++
+			if below {
+				log.LogWarnf("action[updateMetrics] disk(%v) unallocated(%v) drops below rdonly space(%v)",
+					d.Path, d.Unallocated, d.DiskRdonlySpace)
+			} else {
+				log.LogWarnf("action[updateMetrics] disk(%v) unallocated(%v) recovers above rdonly space(%v)",
+					d.Path, d.Unallocated, d.DiskRdonlySpace)
+			}
+		}
+		disksBelowRdonlySpace[d.Path] = below
+
 		total += d.Total
 		used += d.Used
 		available += d.Available
@@ -316,9 +427,38 @@ func (manager *SpaceManager) updateMetrics() {
 		"partitionCnt(%v) maxCapacityToCreatePartition(%v) ", total, used, available, totalPartitionSize, remainingCapacityToCreatePartition, partitionCnt, maxCapacityToCreatePartition)
 	manager.stats.updateMetrics(total, used, available, totalPartitionSize,
 		remainingCapacityToCreatePartition, maxCapacityToCreatePartition, partitionCnt)
+	manager.stats.updateMetricRdonlySpace(disksBelowRdonlySpace, rdonlySpaceCrossingCnt)
+}
+
+// diskSelectReason records why each disk is skipped when no disk is chosen to create a partition.
+type diskSelectReason struct {
+	total          int
+	decommissioned int
+	full           int
+	readOnly       int
+	unavailable    int
 }
 
-func (manager *SpaceManager) minPartitionCnt(decommissionedDisks []string) (d *Disk) {
+func (r *diskSelectReason) String() string {
+	switch {
+	case r.total == 0:
+		return "no disk"
+	case r.decommissioned == r.total:
+		return "all disks decommissioned"
+	case r.full == r.total:
+		return "all disks full"
+	case r.readOnly == r.total:
+		return "all disks read-only"
+	case r.unavailable == r.total:
+		return "all disks unavailable"
+	}
+	return fmt.Sprintf("no writable disk in %v disks: decommissioned(%v) full(%v) read-only(%v) unavailable(%v)",
+		r.total, r.decommissioned, r.full, r.readOnly, r.unavailable)
+}
+
+// minPartitionCnt returns the writable disk with the least allocated ratio,
+// or the reason why no disk is writable.
+func (manager *SpaceManager) minPartitionCnt(decommissionedDisks []string) (d *Disk, reason *diskSelectReason) {
 	manager.diskMutex.Lock()
 	defer manager.diskMutex.Unlock()
 	var (
@@ -329,16 +469,31 @@ func (manager *SpaceManager) minPartitionCnt(decommissionedDisks []string) (d *D
 	for _, disk := range decommissionedDisks {
 		decommissionedDiskMap[disk] = struct{}{}
 	}
+	selectWeight := manager.selectWeight
+	if selectWeight == nil {
+		selectWeight = diskSelectWeights[DiskSelectByWeight]
+	}
+	reason = &diskSelectReason{total: len(manager.disks)}
 	minWeight = math.MaxFloat64
 	for _, disk := range manager.disks {
 		if _, ok := decommissionedDiskMap[disk.Path]; ok {
 			log.LogInfof("action[minPartitionCnt] exclude decommissioned disk[%v]", disk.Path)
+			reason.decommissioned++
 			continue
 		}
 		if disk.Status != proto.ReadWrite {
+			switch {
+			case disk.Status == proto.Unavailable:
+				reason.unavailable++
+			case disk.Available == 0:
+				// the disk turns read-only once the free space is below the reserved space
+				reason.full++
+			default:
+				reason.readOnly++
+			}
 			continue
 		}
-		diskWeight := disk.getSelectWeight()
+		diskWeight := selectWeight(disk)
 		if diskWeight < minWeight {
 			minWeight = diskWeight
 			minWeightDisk = disk
@@ -351,7 +506,7 @@ func (manager *SpaceManager) minPartitionCnt(decommissionedDisks []string) (d *D
 		return
 	}
 	d = minWeightDisk
-	return d
+	return d, nil
 }
 
 func (manager *SpaceManager) statUpdateScheduler() {
@@ -416,9 +571,11 @@ func (manager *SpaceManager) CreatePartition(request *proto.CreateDataPartitionR
 		}
 		return
 	}
-	disk := manager.minPartitionCnt(request.DecommissionedDisks)
+	disk, reason := manager.minPartitionCnt(request.DecommissionedDisks)
 	if disk == nil {
-		return nil, ErrNoSpaceToCreatePartition
+		err = fmt.Errorf("%w: %v", ErrNoSpaceToCreatePartition, reason)
+		log.LogErrorf("action[CreatePartition] dp %v: %v", dpCfg.PartitionID, err)
+		return nil, err
 	}
 	if dp, err = CreateDataPartition(dpCfg, disk, request); err != nil {
 		return
@@ -427,6 +584,24 @@ func (manager *SpaceManager) CreatePartition(request *proto.CreateDataPartitionR
 	return
 }
 
+// VerifyCreatePartition runs the checks of CreatePartition without writing anything, it returns the disk
+// the partition would be created on, or the disk of the existing partition equal to the request.
+func (manager *SpaceManager) VerifyCreatePartition(request *proto.CreateDataPartitionRequest) (diskPath string, err error) {
+	manager.partitionMutex.Lock()
+	defer manager.partitionMutex.Unlock()
+	if dp := manager.partitions[request.PartitionId]; dp != nil {
+		if err = dp.IsEqualCreateDataPartitionRequest(request); err != nil {
+			return "", err
+		}
+		return dp.Disk().Path, nil
+	}
+	disk, reason := manager.minPartitionCnt(request.DecommissionedDisks)
+	if disk == nil {
+		return "", fmt.Errorf("%w: %v", ErrNoSpaceToCreatePartition, reason)
+	}
+	return disk.Path, nil
+}
+
 // DeletePartition deletes a partition based on the partition id.
 func (manager *SpaceManager) DeletePartition(dpID uint64) {
 	manager.partitionMutex.Lock()
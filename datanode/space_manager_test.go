@@ -0,0 +1,271 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package datanode
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/repl"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/cubefs/cubefs/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinPartitionCntReason(t *testing.T) {
+	newDisk := func(path string, status int, available uint64) *Disk {
+		return &Disk{Path: path, Status: status, Available: available, Total: 100}
+	}
+	newManager := func(disks ...*Disk) *SpaceManager {
+		manager := &SpaceManager{
+			disks:      make(map[string]*Disk),
+			partitions: make(map[uint64]*DataPartition),
+		}
+		for _, d := range disks {
+			manager.disks[d.Path] = d
+		}
+		return manager
+	}
+
+	cases := []struct {
+		name           string
+		disks          []*Disk
+		decommissioned []string
+		reason         string
+	}{
+		{"full", []*Disk{newDisk("/d1", proto.ReadOnly, 0), newDisk("/d2", proto.ReadOnly, 0)}, nil, "all disks full"},
+		{"read-only", []*Disk{newDisk("/d1", proto.ReadOnly, 10), newDisk("/d2", proto.ReadOnly, 20)}, nil, "all disks read-only"},
+		{"decommissioned", []*Disk{newDisk("/d1", proto.ReadWrite, 10), newDisk("/d2", proto.ReadWrite, 20)}, []string{"/d1", "/d2"}, "all disks decommissioned"},
+		{"unavailable", []*Disk{newDisk("/d1", proto.Unavailable, 10)}, nil, "all disks unavailable"},
+		{"none", nil, nil, "no disk"},
+		{
+			"mixed",
+			[]*Disk{newDisk("/d1", proto.ReadOnly, 0), newDisk("/d2", proto.ReadOnly, 10), newDisk("/d3", proto.ReadWrite, 10)},
+			[]string{"/d3"},
+			"no writable disk in 3 disks: decommissioned(1) full(1) read-only(1) unavailable(0)",
+		},
+	}
+	for _, c := range cases {
+		manager := newManager(c.disks...)
+		d, reason := manager.minPartitionCnt(c.decommissioned)
+		require.Nil(t, d, c.name)
+		require.Equal(t, c.reason, reason.String(), c.name)
+
+		_, err := manager.CreatePartition(&proto.CreateDataPartitionRequest{PartitionId: 1, DecommissionedDisks: c.decommissioned})
+		require.True(t, errors.Is(err, ErrNoSpaceToCreatePartition), c.name)
+		require.Contains(t, err.Error(), c.reason, c.name)
+	}
+
+	// the writable disk with the least allocated ratio is chosen
+	busy, idle := newDisk("/d1", proto.ReadWrite, 10), newDisk("/d2", proto.ReadWrite, 10)
+	busy.Allocated = 50
+	d, reason := newManager(busy, idle, newDisk("/d3", proto.ReadOnly, 0)).minPartitionCnt(nil)
+	require.Nil(t, reason)
+	require.Equal(t, idle, d)
+}
+
+func TestDiskSelectPolicy(t *testing.T) {
+	newDisk := func(path string, allocated, available uint64, partitions int) *Disk {
+		d := &Disk{Path: path, Status: proto.ReadWrite, Total: 100, Allocated: allocated, Available: available}
+		d.partitionMap = make(map[uint64]*DataPartition)
+		for i := 0; i < partitions; i++ {
+			d.partitionMap[uint64(i)] = nil
+		}
+		return d
+	}
+	manager := &SpaceManager{disks: make(map[string]*Disk)}
+	for _, d := range []*Disk{
+		newDisk("/least-allocated", 10, 20, 5),
+		newDisk("/least-partitions", 40, 90, 1),
+		newDisk("/most-free", 30, 95, 3),
+		newDisk("/balanced", 15, 85, 4),
+	} {
+		manager.disks[d.Path] = d
+	}
+
+	for policy, expect := range map[string]string{
+		"":                         "/least-allocated",
+		DiskSelectByWeight:         "/least-allocated",
+		DiskSelectByPartitionCount: "/least-partitions",
+		DiskSelectByFreeSpace:      "/most-free",
+		DiskSelectWeighted:         "/balanced",
+	} {
+		require.NoError(t, manager.SetDiskSelectPolicy(policy))
+		d, _ := manager.minPartitionCnt(nil)
+		require.Equal(t, expect, d.Path, "policy %v", policy)
+	}
+
+	// a disk whose space is not known yet is skipped by the weighted policy
+	unknown := newDisk("/unknown", 0, 50, 0)
+	unknown.Total = 0
+	manager.disks[unknown.Path] = unknown
+	require.NoError(t, manager.SetDiskSelectPolicy(DiskSelectWeighted))
+	d, _ := manager.minPartitionCnt(nil)
+	require.Equal(t, "/balanced", d.Path)
+	require.Error(t, manager.SetDiskSelectPolicy("random"))
+}
+
+func TestStopWithTimeout(t *testing.T) {
+	manager := &SpaceManager{
+		partitions: make(map[uint64]*DataPartition),
+		stopC:      make(chan bool),
+	}
+	newStoppable := func(id uint64) *DataPartition {
+		dir := t.TempDir()
+		store, err := storage.NewExtentStore(dir, id, util.GB, proto.PartitionTypeNormal, true)
+		require.NoError(t, err)
+		dp := &DataPartition{partitionID: id, path: dir, extentStore: store, stopC: make(chan bool)}
+		manager.partitions[id] = dp
+		return dp
+	}
+	isStopped := func(dp *DataPartition) bool {
+		select {
+		case <-dp.stopC:
+			return true
+		default:
+			return false
+		}
+	}
+	// partition 1 has a store to close
+	stopped := newStoppable(1)
+	// partition 2 is already stopped
+	manager.partitions[2] = &DataPartition{partitionID: 2}
+	manager.partitions[2].stopOnce.Do(func() {})
+	// partition 3 panics in stopping without the extent store
+	manager.partitions[3] = &DataPartition{partitionID: 3}
+	// more partitions than the workers hang in stopping
+	release := make(chan struct{})
+	released := false
+	defer func() {
+		if !released {
+			close(release)
+		}
+	}()
+	hanging := make([]uint64, 0)
+	for id := uint64(4); id < 260; id++ {
+		dp := &DataPartition{partitionID: id}
+		started := make(chan struct{})
+		go dp.stopOnce.Do(func() {
+			close(started)
+			<-release
+		})
+		<-started
+		manager.partitions[id] = dp
+		hanging = append(hanging, id)
+	}
+	// the partition queued behind the hanging ones waits for a worker
+	queued := newStoppable(1000)
+
+	start := time.Now()
+	failed := manager.StopWithTimeout(100 * time.Millisecond)
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.Equal(t, append(append([]uint64{3}, hanging...), queued.partitionID), failed)
+	require.True(t, isStopped(stopped), "partition 1 is not stopped")
+	require.False(t, isStopped(queued), "the workers are not bounded")
+
+	// stop again does not panic
+	require.Equal(t, append(hanging, queued.partitionID), manager.StopWithTimeout(100*time.Millisecond))
+
+	// the queued partition is stopped once the workers are released
+	close(release)
+	released = true
+	require.Eventually(t, func() bool { return isStopped(queued) }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestUpdateMetricsRdonlySpace(t *testing.T) {
+	d := &Disk{Path: "/d1", Status: proto.ReadWrite, DiskRdonlySpace: 10, Unallocated: 100}
+	manager := &SpaceManager{
+		disks: map[string]*Disk{d.Path: d},
+		stats: NewStats("zone"),
+	}
+
+	manager.updateMetrics()
+	require.Equal(t, map[string]bool{"/d1": false}, manager.stats.DisksBelowRdonlySpace)
+	require.Equal(t, uint64(0), manager.stats.RdonlySpaceCrossingCnt)
+
+	// crossing below the reserve is counted once
+	d.Unallocated = 5
+	manager.updateMetrics()
+	manager.updateMetrics()
+	require.Equal(t, map[string]bool{"/d1": true}, manager.stats.DisksBelowRdonlySpace)
+	require.Equal(t, uint64(1), manager.stats.RdonlySpaceCrossingCnt)
+
+	// and so is the recovery
+	d.Unallocated = 20
+	manager.updateMetrics()
+	require.Equal(t, map[string]bool{"/d1": false}, manager.stats.DisksBelowRdonlySpace)
+	require.Equal(t, uint64(2), manager.stats.RdonlySpaceCrossingCnt)
+}
+
+func TestCreatePartitionDryRun(t *testing.T) {
+	if proto.Buffers == nil {
+		proto.InitBufferPool(int64(32768))
+	}
+	dir := t.TempDir()
+	manager := &SpaceManager{
+		disks:      map[string]*Disk{dir: {Path: dir, Status: proto.ReadWrite, Total: 100, Available: 100}},
+		partitions: make(map[uint64]*DataPartition),
+	}
+	s := &DataNode{space: manager}
+
+	createPacket := func(opCode uint8, request *proto.CreateDataPartitionRequest) *repl.Packet {
+		task := proto.NewAdminTask(opCode, "127.0.0.1", request)
+		data, err := json.Marshal(task)
+		require.NoError(t, err)
+		p := repl.NewPacket()
+		p.Opcode = opCode
+		p.Data = data
+		p.Size = uint32(len(data))
+		return p
+	}
+	request := &proto.CreateDataPartitionRequest{PartitionId: 1, VolumeId: "vol", PartitionSize: 1024}
+	p := createPacket(proto.OpVerifyCreateDataPartition, request)
+	require.True(t, p.IsMasterCommand())
+	s.handlePacketToVerifyCreateDataPartition(p)
+	require.Equal(t, proto.OpOk, p.ResultCode, string(p.Data))
+	require.Equal(t, dir, string(p.Data[:p.Size]))
+
+	// nothing is created
+	require.Empty(t, manager.partitions)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	// the dry run fails as the creation would
+	manager.disks[dir].Status = proto.ReadOnly
+	p = createPacket(proto.OpVerifyCreateDataPartition, request)
+	s.handlePacketToVerifyCreateDataPartition(p)
+	require.NotEqual(t, proto.OpOk, p.ResultCode)
+	require.Contains(t, string(p.Data[:p.Size]), ErrNoSpaceToCreatePartition.Error())
+
+	// the existing partition is checked against the request
+	disk := manager.disks[dir]
+	manager.partitions[1] = &DataPartition{partitionID: 1, disk: disk, config: &dataPartitionCfg{VolName: "vol"}}
+	diskPath, err := manager.VerifyCreatePartition(request)
+	require.NoError(t, err)
+	require.Equal(t, dir, diskPath)
+	_, err = manager.VerifyCreatePartition(&proto.CreateDataPartitionRequest{PartitionId: 1, VolumeId: "other"})
+	require.Error(t, err)
+
+	// the task of the other opcode is rejected
+	p = createPacket(proto.OpCreateDataPartition, request)
+	s.handlePacketToVerifyCreateDataPartition(p)
+	require.NotEqual(t, proto.OpOk, p.ResultCode)
+	require.Contains(t, string(p.Data[:p.Size]), "unavali opcode")
+}
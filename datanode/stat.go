@@ -15,9 +15,12 @@
 package datanode
 
 import (
+	"math"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/cubefs/cubefs/proto"
 )
 
 // Stats defines various metrics that will be collected during the execution.
@@ -39,6 +42,11 @@ type Stats struct {
 	// the maximum capacity among all the disks that can be used to create partition
 	MaxCapacityToCreatePartition uint64
 
+	// the disks whose unallocated space is below the read-only reserve, no partition is created there
+	DisksBelowRdonlySpace map[string]bool
+	// the number of times the disks cross the read-only reserve in either direction
+	RdonlySpaceCrossingCnt uint64
+
 	sync.Mutex
 }
 
@@ -93,3 +101,52 @@ func (s *Stats) updateMetricLackPartitionsInDisk(lackPartitionsInDisk uint64) {
 
 	s.LackPartitionsInDisk = lackPartitionsInDisk
 }
+
+func (s *Stats) updateMetricRdonlySpace(disksBelowRdonlySpace map[string]bool, crossingCnt uint64) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.DisksBelowRdonlySpace = disksBelowRdonlySpace
+	s.RdonlySpaceCrossingCnt += crossingCnt
+}
+
+// OpStatInfo is the statistic of an opcode, LatencyNs sums the latency of the packets sampled by metricsDegrade.
+type OpStatInfo struct {
+	Count     uint64 `json:"count"`
+	Sampled   uint64 `json:"sampled"`
+	LatencyNs uint64 `json:"latencyNs"`
+}
+
+// opStats counts the packets by opcode.
+type opStats [math.MaxUint8 + 1]OpStatInfo
+
+func (st *opStats) add(op uint8, sampled bool, latency time.Duration) {
+	stat := &st[op]
+	atomic.AddUint64(&stat.Count, 1)
+	if sampled {
+		atomic.AddUint64(&stat.Sampled, 1)
+		atomic.AddUint64(&stat.LatencyNs, uint64(latency))
+	}
+}
+
+// snapshot returns the statistics of the opcodes ever seen by the name, and clears them if reset.
+func (st *opStats) snapshot(reset bool) map[string]*OpStatInfo {
+	load := atomic.LoadUint64
+	if reset {
+		load = func(addr *uint64) uint64 { return atomic.SwapUint64(addr, 0) }
+	}
+	stats := make(map[string]*OpStatInfo)
+	for op := range st {
+		stat := &st[op]
+		info := &OpStatInfo{
+			Count:     load(&stat.Count),
+			Sampled:   load(&stat.Sampled),
+			LatencyNs: load(&stat.LatencyNs),
+		}
+		if info.Count == 0 {
+			continue
+		}
+		stats[(&proto.Packet{Opcode: uint8(op)}).GetOpMsg()] = info
+	}
+	return stats
+}
@@ -2,6 +2,9 @@ package datanode
 
 import (
 	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
 
 	"github.com/stretchr/testify/require"
 )
@@ -46,3 +49,21 @@ func TestStats(t *testing.T) {
 	s.updateMetricLackPartitionsInDisk(lackPartitionsInDisk)
 	require.Equal(t, lackPartitionsInDisk, s.LackPartitionsInDisk, "updateMetricLackPartitionsInDisk() error")
 }
+
+func TestOpStats(t *testing.T) {
+	st := &opStats{}
+	st.add(proto.OpWrite, true, 2*time.Millisecond)
+	st.add(proto.OpWrite, false, time.Second)
+	st.add(proto.OpExtentRepairRead, true, time.Millisecond)
+
+	stats := st.snapshot(false)
+	require.Len(t, stats, 2)
+	require.Equal(t, &OpStatInfo{Count: 2, Sampled: 1, LatencyNs: uint64(2 * time.Millisecond)}, stats["OpWrite"])
+	require.Equal(t, &OpStatInfo{Count: 1, Sampled: 1, LatencyNs: uint64(time.Millisecond)}, stats["OpExtentRepairRead"])
+
+	// reset returns the statistics once and clears them
+	require.Equal(t, stats, st.snapshot(true))
+	require.Empty(t, st.snapshot(false))
+	st.add(proto.OpWrite, true, time.Millisecond)
+	require.Equal(t, &OpStatInfo{Count: 1, Sampled: 1, LatencyNs: uint64(time.Millisecond)}, st.snapshot(false)["OpWrite"])
+}
@@ -79,6 +79,13 @@ func isColdVolExtentDelErr(p *repl.Packet) bool {
 	return false
 }
 
+func operatePacketLog(p *repl.Packet, remote string, start int64, err error) string {
+	if repl.IsJSONLog() {
+		return p.LogMessage("OperatePacket", remote, start, err)
+	}
+	return fmt.Sprintf("action[OperatePacket] %v.", p.LogMessage(p.GetOpMsg(), remote, start, err))
+}
+
 func (s *DataNode) OperatePacket(p *repl.Packet, c net.Conn) (err error) {
 	var (
 		tpLabels map[string]string
@@ -97,16 +104,14 @@ func (s *DataNode) OperatePacket(p *repl.Packet, c net.Conn) (err error) {
 		p.Size = sz
 		if p.IsErrPacket() {
 			err = fmt.Errorf("op(%v) error(%v)", p.GetOpMsg(), string(p.Data[:resultSize]))
-			logContent := fmt.Sprintf("action[OperatePacket] %v.",
-				p.LogMessage(p.GetOpMsg(), c.RemoteAddr().String(), start, err))
+			logContent := operatePacketLog(p, c.RemoteAddr().String(), start, err)
 			if isColdVolExtentDelErr(p) {
 				log.LogInfof(logContent)
 			} else {
 				log.LogErrorf(logContent)
 			}
 		} else {
-			logContent := fmt.Sprintf("action[OperatePacket] %v.",
-				p.LogMessage(p.GetOpMsg(), c.RemoteAddr().String(), start, nil))
+			logContent := operatePacketLog(p, c.RemoteAddr().String(), start, nil)
 			switch p.Opcode {
 			case proto.OpStreamRead, proto.OpRead, proto.OpExtentRepairRead, proto.OpStreamFollowerRead:
 			case proto.OpReadTinyDeleteRecord:
@@ -122,6 +127,7 @@ func (s *DataNode) OperatePacket(p *repl.Packet, c net.Conn) (err error) {
 			}
 		}
 		p.Size = resultSize
+		s.opStats.add(p.Opcode, !shallDegrade, time.Duration(time.Now().UnixNano()-start))
 		if !shallDegrade {
 			tpObject.SetWithLabels(err, tpLabels)
 		}
@@ -156,6 +162,8 @@ func (s *DataNode) OperatePacket(p *repl.Packet, c net.Conn) (err error) {
 		s.handlePacketToGetAllWatermarks(p)
 	case proto.OpCreateDataPartition:
 		s.handlePacketToCreateDataPartition(p)
+	case proto.OpVerifyCreateDataPartition:
+		s.handlePacketToVerifyCreateDataPartition(p)
 	case proto.OpLoadDataPartition:
 		s.handlePacketToLoadDataPartition(p)
 	case proto.OpDeleteDataPartition:
@@ -202,6 +210,10 @@ func (s *DataNode) handlePacketToCreateExtent(p *repl.Packet) {
 		}
 	}()
 	partition := p.Object.(*DataPartition)
+	if partition.IsReadonly() {
+		err = storage.ReadonlyDataPartitionError
+		return
+	}
 	if partition.Available() <= 0 || !partition.disk.CanWrite() {
 		err = storage.NoSpaceError
 		return
@@ -222,25 +234,16 @@ func (s *DataNode) handlePacketToCreateExtent(p *repl.Packet) {
 	})
 }
 
-// Handle OpCreateDataPartition packet.
-func (s *DataNode) handlePacketToCreateDataPartition(p *repl.Packet) {
-	var (
-		err   error
-		bytes []byte
-		dp    *DataPartition
-	)
-	defer func() {
-		if err != nil {
-			p.PackErrorBody(ActionCreateDataPartition, err.Error())
-		}
-	}()
-	task := &proto.AdminTask{}
+// unmarshalCreateDataPartitionTask decodes the CreateDataPartitionRequest of the admin task with the opcode.
+func unmarshalCreateDataPartitionTask(p *repl.Packet, opCode uint8) (task *proto.AdminTask, request *proto.CreateDataPartitionRequest, err error) {
+	var bytes []byte
+	task = &proto.AdminTask{}
 	if err = json.Unmarshal(p.Data, task); err != nil {
 		err = fmt.Errorf("cannnot unmashal adminTask")
 		return
 	}
-	request := &proto.CreateDataPartitionRequest{}
-	if task.OpCode != proto.OpCreateDataPartition {
+	request = &proto.CreateDataPartitionRequest{}
+	if task.OpCode != opCode {
 		err = fmt.Errorf("from master Task(%v) failed,error unavali opcode(%v)", task.ToString(), task.OpCode)
 		return
 	}
@@ -256,6 +259,25 @@ func (s *DataNode) handlePacketToCreateDataPartition(p *repl.Packet) {
 		return
 	}
 	p.PartitionID = request.PartitionId
+	return
+}
+
+// Handle OpCreateDataPartition packet.
+func (s *DataNode) handlePacketToCreateDataPartition(p *repl.Packet) {
+	var (
+		err     error
+		dp      *DataPartition
+		task    *proto.AdminTask
+		request *proto.CreateDataPartitionRequest
+	)
+	defer func() {
+		if err != nil {
+			p.PackErrorBody(ActionCreateDataPartition, err.Error())
+		}
+	}()
+	if task, request, err = unmarshalCreateDataPartitionTask(p, proto.OpCreateDataPartition); err != nil {
+		return
+	}
 	if dp, err = s.space.CreatePartition(request); err != nil {
 		err = fmt.Errorf("from master Task(%v) cannot create Partition err(%v)", task.ToString(), err)
 		return
@@ -263,6 +285,31 @@ func (s *DataNode) handlePacketToCreateDataPartition(p *repl.Packet) {
 	p.PacketOkWithBody([]byte(dp.Disk().Path))
 }
 
+// Handle OpVerifyCreateDataPartition packet, the dry run of OpCreateDataPartition. It replies the disk the
+// partition would be created on and writes nothing. It has its own opcode so that the older data nodes
+// reject it rather than create the partition.
+func (s *DataNode) handlePacketToVerifyCreateDataPartition(p *repl.Packet) {
+	var (
+		err      error
+		diskPath string
+		task     *proto.AdminTask
+		request  *proto.CreateDataPartitionRequest
+	)
+	defer func() {
+		if err != nil {
+			p.PackErrorBody(ActionCreateDataPartition, err.Error())
+		}
+	}()
+	if task, request, err = unmarshalCreateDataPartitionTask(p, proto.OpVerifyCreateDataPartition); err != nil {
+		return
+	}
+	if diskPath, err = s.space.VerifyCreatePartition(request); err != nil {
+		err = fmt.Errorf("from master Task(%v) cannot create Partition err(%v)", task.ToString(), err)
+		return
+	}
+	p.PacketOkWithBody([]byte(diskPath))
+}
+
 func (s *DataNode) commitDelVersion(volumeID string, verSeq uint64) (err error) {
 	for _, partition := range s.space.partitions {
 		if partition.config.VolName != volumeID {
@@ -554,6 +601,9 @@ func (s *DataNode) handleHeartbeatPacket(p *repl.Packet) {
 			// set cpu util and io used in here
 			response.CpuUtil = s.cpuUtil.Load()
 			response.IoUtils = s.space.GetDiskUtils()
+			if s.partitionReporter != nil {
+				s.partitionReporter.report(response, request.PartitionReportSeq, response.CpuUtil)
+			}
 
 			if needUpdate {
 				log.LogWarnf("action[handleHeartbeatPacket] master change disk qos limit to [flowWrite %v, flowRead %v, iopsWrite %v, iopsRead %v]",
@@ -757,6 +807,10 @@ func (s *DataNode) handleWritePacket(p *repl.Packet) {
 		err = storage.ForbiddenDataPartitionError
 		return
 	}
+	if partition.IsReadonly() {
+		err = storage.ReadonlyDataPartitionError
+		return
+	}
 	shallDegrade := p.ShallDegrade()
 	if !shallDegrade {
 		metricPartitionIOLabels = GetIoMetricLabels(partition, "write")
@@ -880,6 +934,10 @@ func (s *DataNode) handleRandomWritePacket(p *repl.Packet) {
 		err = storage.ForbiddenDataPartitionError
 		return
 	}
+	if partition.IsReadonly() {
+		err = storage.ReadonlyDataPartitionError
+		return
+	}
 	log.LogDebugf("action[handleRandomWritePacket opcod %v seq %v dpid %v dpseq %v extid %v", p.Opcode, p.VerSeq, p.PartitionID, partition.verSeq, p.ExtentID)
 	// cache or preload partition not support raft and repair.
 	if !partition.isNormalType() {
@@ -952,6 +1010,12 @@ func (s *DataNode) handleExtentRepairReadPacket(p *repl.Packet, connect net.Conn
 	}
 	defer fininshDoExtentRepair()
 	partition := p.Object.(*DataPartition)
+	if err = partition.disk.acquireRepairRead(); err != nil {
+		p.PackErrorBody(ActionStreamRead, err.Error())
+		p.WriteToConn(connect)
+		return
+	}
+	defer partition.disk.releaseRepairRead()
 	if !partition.disk.RequireReadExtentToken(partition.partitionID) {
 		err = storage.NoDiskReadRepairExtentTokenError
 		log.LogWarn("check the source code cos i don't understand the unuesd error,", err)
@@ -970,6 +1034,13 @@ func (s *DataNode) handleExtentRepairReadPacket(p *repl.Packet, connect net.Conn
 }
 
 func (s *DataNode) handleTinyExtentRepairReadPacket(p *repl.Packet, connect net.Conn) {
+	partition := p.Object.(*DataPartition)
+	if err := partition.disk.acquireRepairRead(); err != nil {
+		p.PackErrorBody(ActionStreamReadTinyExtentRepair, err.Error())
+		p.WriteToConn(connect)
+		return
+	}
+	defer partition.disk.releaseRepairRead()
 	s.tinyExtentRepairRead(p, connect)
 }
 
@@ -1010,6 +1081,9 @@ func (s *DataNode) handlePacketToGetAllWatermarks(p *repl.Packet) {
 	store := partition.ExtentStore()
 	if proto.IsNormalExtentType(p.ExtentType) {
 		fInfoList, _, err = store.GetAllWatermarks(storage.NormalExtentFilter())
+		if err == nil {
+			partition.markCrcMismatchExtents(fInfoList)
+		}
 	} else {
 		extents := make([]uint64, 0)
 		err = json.Unmarshal(p.Data, &extents)
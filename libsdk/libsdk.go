@@ -1223,7 +1223,9 @@ func (c *client) start() (err error) {
 		FollowerRead:      c.followerRead,
 		OnAppendExtentKey: mw.AppendExtentKey,
 		OnSplitExtentKey:  mw.SplitExtentKey,
+		OnMergeExtentKeys: mw.MergeExtentKeys,
 		OnGetExtents:      mw.GetExtents,
+		OnGetExtentsByVer: mw.GetExtentsByVer,
 		OnTruncate:        mw.Truncate,
 		BcacheEnable:      c.enableBcache,
 		OnLoadBcache:      c.bc.Get,
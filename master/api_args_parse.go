@@ -381,6 +381,7 @@ type updateVolReq struct {
 	capacity                uint64
 	deleteLockTime          int64
 	followerRead            bool
+	nearRead                bool
 	authenticate            bool
 	enablePosixAcl          bool
 	enableTransaction       proto.TxOpMask
@@ -528,6 +529,10 @@ func parseVolUpdateReq(r *http.Request, vol *Vol, req *updateVolReq) (err error)
 		return
 	}
 
+	if req.nearRead, err = extractBoolWithDefault(r, nearReadKey, vol.NearRead); err != nil {
+		return
+	}
+
 	req.dpSelectorName = r.FormValue(dpSelectorNameKey)
 	req.dpSelectorParm = r.FormValue(dpSelectorParmKey)
 
@@ -650,6 +655,7 @@ type createVolReq struct {
 	capacity                             int
 	deleteLockTime                       int64
 	followerRead                         bool
+	nearRead                             bool
 	authenticate                         bool
 	crossZone                            bool
 	normalZonesFirst                     bool
@@ -808,6 +814,10 @@ func parseRequestToCreateVol(r *http.Request, req *createVolReq) (err error) {
 		return
 	}
 
+	if req.nearRead, err = extractBoolWithDefault(r, nearReadKey, false); err != nil {
+		return
+	}
+
 	var txMask proto.TxOpMask
 	if txMask, err = parseTxMask(r, proto.TxOpMaskOff); err != nil {
 		return
@@ -1370,6 +1380,25 @@ func parseQosInfo(r *http.Request) (info *proto.ClientReportLimitInfo, err error
 	return
 }
 
+func parseRequestToRenameVol(r *http.Request) (name, newName, authKey string, err error) {
+	if err = r.ParseForm(); err != nil {
+		return
+	}
+	if name, err = extractName(r); err != nil {
+		return
+	}
+	if newName = r.FormValue(volNewNameKey); newName == "" {
+		err = keyNotFound(volNewNameKey)
+		return
+	}
+	if !volNameRegexp.MatchString(newName) {
+		err = errors.New("newName can only be number and letters")
+		return
+	}
+	authKey, err = extractAuthKey(r)
+	return
+}
+
 func parseAndExtractName(r *http.Request) (name string, err error) {
 	if err = r.ParseForm(); err != nil {
 		return
@@ -194,12 +194,12 @@ func (m *Server) setMasterVolDeletionDelayTime(w http.ResponseWriter, r *http.Re
 
 // Turn on or off the automatic allocation of the data partitions.
 // If DisableAutoAllocate == off, then we WILL NOT automatically allocate new data partitions for the volume when:
-//  1. the used space is below the max capacity,
-//  2. and the number of r&w data partition is less than 20.
+//  1. the used space is below autoAllocUsedRatioCeiling of the max capacity,
+//  2. and the number of r&w data partition is less than autoAllocRwDpFloor (10 by default).
 //
 // If DisableAutoAllocate == on, then we WILL automatically allocate new data partitions for the volume when:
-//  1. the used space is below the max capacity,
-//  2. and the number of r&w data partition is less than 20.
+//  1. the used space is below autoAllocUsedRatioCeiling of the max capacity,
+//  2. and the number of r&w data partition is less than autoAllocRwDpFloor (10 by default).
 func (m *Server) setupAutoAllocation(w http.ResponseWriter, r *http.Request) {
 	var (
 		status bool
@@ -274,6 +274,32 @@ func (m *Server) forbidVolume(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(fmt.Sprintf("set volume forbidden to (%v) success", status)))
 }
 
+func (m *Server) renameVol(w http.ResponseWriter, r *http.Request) {
+	var (
+		name    string
+		newName string
+		authKey string
+		err     error
+		msg     string
+	)
+	metric := exporter.NewTPCnt(apiToMetricsName(proto.AdminRenameVol))
+	defer func() {
+		doStatAndMetric(proto.AdminRenameVol, metric, err, map[string]string{exporter.Vol: name})
+	}()
+
+	if name, newName, authKey, err = parseRequestToRenameVol(r); err != nil {
+		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
+		return
+	}
+	if err = m.cluster.renameVol(name, newName, authKey, m.user); err != nil {
+		sendErrReply(w, r, newErrHTTPReply(err))
+		return
+	}
+	msg = fmt.Sprintf("rename vol[%v] to [%v] successfully,from[%v]", name, newName, r.RemoteAddr)
+	log.LogWarn(msg)
+	sendOkReply(w, r, newSuccessHTTPReply(msg))
+}
+
 func (m *Server) setEnableAuditLogForVolume(w http.ResponseWriter, r *http.Request) {
 	var (
 		status bool
@@ -2193,6 +2219,24 @@ func (m *Server) markDeleteVol(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
+// checkReplicaNumChange checks that the data replica num of vol can be changed to replicaNum,
+// 0 or the current replica num means no change.
+func checkReplicaNumChange(vol *Vol, replicaNum int) error {
+	if replicaNum == 0 || replicaNum == int(vol.dpReplicaNum) {
+		return nil
+	}
+	if replicaNum != int(vol.dpReplicaNum)-1 {
+		return fmt.Errorf("replicaNum only need be reduced one replica one time")
+	}
+	if !proto.IsHot(vol.VolType) {
+		return fmt.Errorf("vol type(%v) replicaNum cann't be changed", vol.VolType)
+	}
+	if ok, dpArry := vol.isOkUpdateRepCnt(); !ok {
+		return fmt.Errorf("vol have dataPartitions[%v] with inconsistent dataPartitions cnt to volume's ", dpArry)
+	}
+	return nil
+}
+
 func (m *Server) checkReplicaNum(r *http.Request, vol *Vol, req *updateVolReq) (err error) {
 	var (
 		replicaNumInt64 int64
@@ -2210,19 +2254,8 @@ func (m *Server) checkReplicaNum(r *http.Request, vol *Vol, req *updateVolReq) (
 		return
 	}
 	req.replicaNum = replicaNum
-	if replicaNum != 0 && replicaNum != int(vol.dpReplicaNum) {
-		if replicaNum != int(vol.dpReplicaNum)-1 {
-			err = fmt.Errorf("replicaNum only need be reduced one replica one time")
-			return
-		}
-		if !proto.IsHot(vol.VolType) {
-			err = fmt.Errorf("vol type(%v) replicaNum cann't be changed", vol.VolType)
-			return
-		}
-		if ok, dpArry := vol.isOkUpdateRepCnt(); !ok {
-			err = fmt.Errorf("vol have dataPartitions[%v] with inconsistent dataPartitions cnt to volume's ", dpArry)
-			return
-		}
+	if err = checkReplicaNumChange(vol, replicaNum); err != nil {
+		return
 	}
 	if proto.IsHot(vol.VolType) {
 		if req.replicaNum == 0 ||
@@ -2284,6 +2317,7 @@ func (m *Server) updateVol(w http.ResponseWriter, r *http.Request) {
 	newArgs.capacity = req.capacity
 	newArgs.deleteLockTime = req.deleteLockTime
 	newArgs.followerRead = req.followerRead
+	newArgs.nearRead = req.nearRead
 	newArgs.authenticate = req.authenticate
 	newArgs.dpSelectorName = req.dpSelectorName
 	newArgs.dpSelectorParm = req.dpSelectorParm
@@ -2401,7 +2435,7 @@ func (m *Server) volShrink(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(msg))
 }
 
-func (m *Server) checkCreateReq(req *createVolReq) (err error) {
+func (c *Cluster) checkCreateReq(req *createVolReq) (err error) {
 	if !proto.IsHot(req.volType) && !proto.IsCold(req.volType) {
 		return fmt.Errorf("vol type %d is illegal", req.volType)
 	}
@@ -2489,8 +2523,8 @@ func (m *Server) checkCreateReq(req *createVolReq) (err error) {
 		return fmt.Errorf("low(%d) or high water(%d) can't be large than 90, low than 0", args.cacheLowWater, args.cacheHighWater)
 	}
 
-	if int(req.dpReplicaNum) > m.cluster.dataNodeCount() {
-		return fmt.Errorf("dp replicaNum %d can't be large than dataNodeCnt %d", req.dpReplicaNum, m.cluster.dataNodeCount())
+	if int(req.dpReplicaNum) > c.dataNodeCount() {
+		return fmt.Errorf("dp replicaNum %d can't be large than dataNodeCnt %d", req.dpReplicaNum, c.dataNodeCount())
 	}
 
 	req.coldArgs = args
@@ -2511,7 +2545,7 @@ func (m *Server) createVol(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = m.checkCreateReq(req); err != nil {
+	if err = m.cluster.checkCreateReq(req); err != nil {
 		sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: err.Error()})
 		return
 	}
@@ -2527,7 +2561,7 @@ func (m *Server) createVol(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = m.associateVolWithUser(req.owner, req.name); err != nil {
+	if err = m.user.associateVolWithUser(req.owner, req.name); err != nil {
 		sendErrReply(w, r, newErrHTTPReply(err))
 		return
 	}
@@ -2663,6 +2697,7 @@ func newSimpleView(vol *Vol) (view *proto.SimpleVolView) {
 		DpSelectorName:          vol.dpSelectorName,
 		DpSelectorParm:          vol.dpSelectorParm,
 		DpReadOnlyWhenVolFull:   vol.DpReadOnlyWhenVolFull,
+		NearRead:                vol.NearRead,
 		VolType:                 vol.VolType,
 		ObjBlockSize:            vol.EbsBlkSize,
 		CacheCapacity:           vol.CacheCapacity,
@@ -4379,6 +4414,7 @@ func (m *Server) getMetaNode(w http.ResponseWriter, r *http.Request) {
 		NodeSetID:                 metaNode.NodeSetID,
 		PersistenceMetaPartitions: metaNode.PersistenceMetaPartitions,
 		CpuUtil:                   metaNode.CpuUtil.Load(),
+		Version:                   metaNode.Version,
 	}
 	sendOkReply(w, r, newSuccessHTTPReply(metaNodeInfo))
 }
@@ -5394,11 +5430,11 @@ func genRespMessage(data []byte, req *proto.APIAccessReq, ts int64, key []byte)
 	return
 }
 
-func (m *Server) associateVolWithUser(userID, volName string) error {
+func (u *User) associateVolWithUser(userID, volName string) error {
 	var err error
 	var userInfo *proto.UserInfo
 
-	if userInfo, err = m.user.getUserInfo(userID); err != nil && err != proto.ErrUserNotExists {
+	if userInfo, err = u.getUserInfo(userID); err != nil && err != proto.ErrUserNotExists {
 		return err
 	}
 
@@ -5410,12 +5446,12 @@ func (m *Server) associateVolWithUser(userID, volName string) error {
 			Type:     proto.UserTypeNormal,
 		}
 
-		if userInfo, err = m.user.createKey(&param); err != nil {
+		if userInfo, err = u.createKey(&param); err != nil {
 			return err
 		}
 	}
 
-	if _, err = m.user.addOwnVol(userInfo.UserID, volName); err != nil {
+	if _, err = u.addOwnVol(userInfo.UserID, volName); err != nil {
 		return err
 	}
 
@@ -6234,6 +6270,46 @@ func (m *Server) queryBadDisks(w http.ResponseWriter, r *http.Request) {
 	sendOkReply(w, r, newSuccessHTTPReply(infos))
 }
 
+func (m *Server) queryBadDiskHistory(w http.ResponseWriter, r *http.Request) {
+	var (
+		err       error
+		nodeAddr  string
+		histories []proto.BadDiskHistory
+	)
+
+	metric := exporter.NewTPCnt("req_queryBadDiskHistory")
+	defer func() {
+		metric.Set(err)
+	}()
+
+	nodeAddr = r.FormValue(addrKey)
+	if len(nodeAddr) > 0 {
+		if !checkIp(nodeAddr) {
+			sendErrReply(w, r, &proto.HTTPReply{Code: proto.ErrCodeParamError, Msg: fmt.Errorf("addr not legal").Error()})
+			return
+		}
+	}
+
+	histories = make([]proto.BadDiskHistory, 0)
+	m.cluster.dataNodes.Range(func(addr, node interface{}) bool {
+		dataNode, ok := node.(*DataNode)
+		if !ok {
+			return true
+		}
+		if len(nodeAddr) > 0 && nodeAddr != dataNode.Addr {
+			return true
+		}
+		records := dataNode.getBadDiskHistory()
+		if len(records) == 0 {
+			return true
+		}
+		histories = append(histories, proto.BadDiskHistory{Address: dataNode.Addr, Records: records})
+		return true
+	})
+
+	sendOkReply(w, r, newSuccessHTTPReply(histories))
+}
+
 func (m *Server) queryDisks(w http.ResponseWriter, r *http.Request) {
 	var (
 		err      error
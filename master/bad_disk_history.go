@@ -0,0 +1,105 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+// the max bad disk records kept for a data node, the oldest one is dropped when full.
+const defaultBadDiskHistoryCap = 64
+
+// badDiskHistory is a ring buffer of the bad disk records of a data node.
+// A record begins when a disk is reported bad and ends when it is reported healthy again,
+// so a flapping disk leaves one record for each time it goes bad.
+type badDiskHistory struct {
+	records []*proto.BadDiskRecord
+	start   int
+	// the records of the disks that are still bad, key: disk path
+	open map[string]*proto.BadDiskRecord
+}
+
+func newBadDiskHistory(capacity int) *badDiskHistory {
+	return &badDiskHistory{
+		records: make([]*proto.BadDiskRecord, 0, capacity),
+		open:    make(map[string]*proto.BadDiskRecord),
+	}
+}
+
+func (h *badDiskHistory) add(record *proto.BadDiskRecord) {
+	if len(h.records) < cap(h.records) {
+		h.records = append(h.records, record)
+		return
+	}
+	evicted := h.records[h.start]
+	if h.open[evicted.DiskPath] == evicted {
+		delete(h.open, evicted.DiskPath)
+	}
+	h.records[h.start] = record
+	h.start = (h.start + 1) % len(h.records)
+}
+
+// update records the bad disks reported by a heartbeat at now.
+func (h *badDiskHistory) update(now time.Time, badDisks []string) {
+	bad := make(map[string]bool, len(badDisks))
+	for _, disk := range badDisks {
+		if bad[disk] {
+			continue
+		}
+		bad[disk] = true
+		if record, ok := h.open[disk]; ok {
+			record.LastSeen = now.Unix()
+			record.ErrorCount++
+			continue
+		}
+		record := &proto.BadDiskRecord{
+			DiskPath:   disk,
+			FirstSeen:  now.Unix(),
+			LastSeen:   now.Unix(),
+			ErrorCount: 1,
+		}
+		h.open[disk] = record
+		h.add(record)
+	}
+	for disk, record := range h.open {
+		if !bad[disk] {
+			record.RecoverTime = now.Unix()
+			delete(h.open, disk)
+		}
+	}
+}
+
+// list returns a copy of the records, the oldest first.
+func (h *badDiskHistory) list() (records []proto.BadDiskRecord) {
+	records = make([]proto.BadDiskRecord, 0, len(h.records))
+	for i := 0; i < len(h.records); i++ {
+		records = append(records, *h.records[(h.start+i)%len(h.records)])
+	}
+	return
+}
+
+// reportedBadDisks returns the bad disks in the heartbeat, the unavailable ones in DiskStats
+// and the ones in BadDisks kept for compatibility.
+func reportedBadDisks(resp *proto.DataNodeHeartbeatResponse) (badDisks []string) {
+	badDisks = append(badDisks, resp.BadDisks...)
+	for _, ds := range resp.DiskStats {
+		if ds.Status == proto.Unavailable {
+			badDisks = append(badDisks, ds.DiskPath)
+		}
+	}
+	return
+}
@@ -56,6 +56,7 @@ type Cluster struct {
 	dnMutex                      sync.RWMutex // data node mutex
 	nsMutex                      sync.RWMutex // nodeset mutex
 	badPartitionMutex            sync.RWMutex // BadDataPartitionIds and BadMetaPartitionIds operate mutex
+	events                       *clusterEventLog
 	leaderInfo                   *LeaderInfo
 	cfg                          *clusterConfig
 	metaReady                    bool
@@ -339,6 +340,7 @@ func newCluster(name string, leaderInfo *LeaderInfo, fsm *MetadataFsm, partition
 	c.t = newTopology()
 	c.BadDataPartitionIds = new(sync.Map)
 	c.BadMetaPartitionIds = new(sync.Map)
+	c.events = newClusterEventLog(defaultClusterEventLogCap)
 	c.dataNodeStatInfo = new(nodeStatInfo)
 	c.metaNodeStatInfo = new(nodeStatInfo)
 	c.FaultDomain = cfg.faultDomain
@@ -710,7 +712,11 @@ func (c *Cluster) checkDataNodeHeartbeat() {
 	tasks := make([]*proto.AdminTask, 0)
 	c.dataNodes.Range(func(addr, dataNode interface{}) bool {
 		node := dataNode.(*DataNode)
+		wasActive := node.isActive
 		node.checkLiveness()
+		if wasActive && !node.isActive {
+			c.events.add(EventNodeDown, node.Addr, "dataNode")
+		}
 		task := node.createHeartbeatTask(c.masterAddr(), c.diskQosEnable)
 		hbReq := task.Request.(*proto.HeartBeatRequest)
 		c.volMutex.RLock()
@@ -733,7 +739,11 @@ func (c *Cluster) checkMetaNodeHeartbeat() {
 
 	c.metaNodes.Range(func(addr, metaNode interface{}) bool {
 		node := metaNode.(*MetaNode)
+		wasActive := node.IsActive
 		node.checkHeartbeat()
+		if wasActive && !node.IsActive {
+			c.events.add(EventNodeDown, node.Addr, "metaNode")
+		}
 		task := node.createHeartbeatTask(c.masterAddr(), c.fileStatsEnable)
 		hbReq := task.Request.(*proto.HeartBeatRequest)
 
@@ -2055,6 +2065,7 @@ func (c *Cluster) migrateDataNode(srcAddr, targetAddr string, raftForce bool, li
 	}
 	srcNode.markDecommission(targetAddr, raftForce, limit)
 	c.syncUpdateDataNode(srcNode)
+	c.events.add(EventDecommission, srcAddr, fmt.Sprintf("dataNode to(%v)", targetAddr))
 	log.LogInfof("action[migrateDataNode] %v return now", srcAddr)
 	return
 }
@@ -2874,6 +2885,7 @@ func (c *Cluster) putBadMetaPartitions(addr string, partitionID uint64) {
 	}
 	newBadPartitionIDs = append(newBadPartitionIDs, partitionID)
 	c.BadMetaPartitionIds.Store(addr, newBadPartitionIDs)
+	c.events.add(EventPartitionBad, fmt.Sprintf("mp(%v)", partitionID), addr)
 }
 
 func (c *Cluster) getBadMetaPartitionsView() (bmpvs []badPartitionView) {
@@ -2908,6 +2920,7 @@ func (c *Cluster) putBadDataPartitionIDs(replica *DataReplica, addr string, part
 	}
 	newBadPartitionIDs = append(newBadPartitionIDs, partitionID)
 	c.BadDataPartitionIds.Store(key, newBadPartitionIDs)
+	c.events.add(EventPartitionBad, fmt.Sprintf("dp(%v)", partitionID), key)
 }
 
 func (c *Cluster) putBadDataPartitionIDsByDiskPath(disk, addr string, partitionID uint64) {
@@ -2927,6 +2940,7 @@ func (c *Cluster) putBadDataPartitionIDsByDiskPath(disk, addr string, partitionI
 	}
 	newBadPartitionIDs = append(newBadPartitionIDs, partitionID)
 	c.BadDataPartitionIds.Store(key, newBadPartitionIDs)
+	c.events.add(EventPartitionBad, fmt.Sprintf("dp(%v)", partitionID), key)
 }
 
 func in(target uint64, strArray []uint64) bool {
@@ -3076,6 +3090,7 @@ func (c *Cluster) migrateMetaNode(srcAddr, targetAddr string, limit int) (err er
 	c.deleteMetaNodeFromCache(metaNode)
 	msg = fmt.Sprintf("action[migrateMetaNode],clusterID[%v] migrate from node[%v] to node(%s) success", c.Name, srcAddr, targetAddr)
 	Warn(c.Name, msg)
+	c.events.add(EventDecommission, srcAddr, fmt.Sprintf("metaNode to(%v)", targetAddr))
 	return
 }
 
@@ -3310,6 +3325,7 @@ func (c *Cluster) createVol(req *createVolReq) (vol *Vol, err error) {
 	vol.updateViewCache(c)
 
 	log.LogInfof("action[createVol] vol[%v], readableAndWritableCnt[%v]", req.name, readWriteDataPartitions)
+	c.events.add(EventVolumeCreated, req.name, fmt.Sprintf("owner(%v)", req.owner))
 	return
 
 errHandler:
@@ -3373,6 +3389,7 @@ func (c *Cluster) doCreateVol(req *createVolReq) (vol *Vol, err error) {
 		FlowWlimit:   req.qosLimitArgs.flowWVal,
 
 		DpReadOnlyWhenVolFull: req.DpReadOnlyWhenVolFull,
+		NearRead:              req.nearRead,
 	}
 
 	log.LogInfof("[doCreateVol] volView, %v", vv)
@@ -4119,6 +4136,7 @@ func (c *Cluster) migrateDisk(nodeAddr, diskPath, dstPath string, raftForce bool
 	}
 	// add to the nodeset decommission list
 	c.addDecommissionDiskToNodeset(disk)
+	c.events.add(EventDecommission, nodeAddr, fmt.Sprintf("disk(%v)", diskPath))
 	log.LogInfof("action[addDecommissionDisk],clusterID[%v] dataNodeAddr:%v,diskPath[%v] raftForce [%v] "+
 		"limit [%v], diskDisable [%v], migrateType [%v] term [%v]",
 		c.Name, nodeAddr, diskPath, raftForce, limit, diskDisable, migrateType, disk.DecommissionTerm)
@@ -4342,9 +4360,8 @@ func (c *Cluster) getAllDecommissionDataPartitionByDisk(addr, disk string) (part
 }
 
 func (c *Cluster) listQuotaAll() (volsInfo []*proto.VolInfo) {
-	c.volMutex.RLock()
-	defer c.volMutex.RUnlock()
-	for _, vol := range c.vols {
+	// the volume locks are taken outside of volMutex, renameVol takes volMutex under the volume lock
+	for _, vol := range c.copyVols() {
 		if vol.quotaManager.HasQuota() {
 			stat := volStat(vol, false)
 			volInfo := proto.NewVolInfo(vol.Name, vol.Owner, vol.createTime, vol.status(), stat.TotalSize,
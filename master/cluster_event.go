@@ -0,0 +1,120 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// the max events kept in memory, the oldest one is dropped when full.
+	defaultClusterEventLogCap = 4096
+	defaultClusterEventLimit  = 100
+	maxClusterEventLimit      = 1000
+)
+
+const (
+	EventNodeUp             = "nodeUp"
+	EventNodeDown           = "nodeDown"
+	EventPartitionBad       = "partitionBad"
+	EventPartitionRecovered = "partitionRecovered"
+	EventVolumeCreated      = "volumeCreated"
+	EventDecommission       = "decommission"
+)
+
+type ClusterEvent struct {
+	Seq  uint64
+	Time int64
+	Type string
+	// the node address, the partition or the volume name
+	Target string
+	Detail string
+}
+
+// ClusterEventPage is a page of the events after a cursor.
+type ClusterEventPage struct {
+	Events []*ClusterEvent
+	// the cursor to get the next page
+	Next uint64
+	// true if some events after the cursor were dropped from the log, or the cursor is from a former leader
+	Missed bool
+}
+
+// clusterEventLog is a ring buffer of the cluster events, the events are only kept in the memory of the leader,
+// so they are lost on a leader change.
+type clusterEventLog struct {
+	sync.RWMutex
+	seq    uint64
+	events []*ClusterEvent
+	start  int
+}
+
+func newClusterEventLog(capacity int) *clusterEventLog {
+	return &clusterEventLog{events: make([]*ClusterEvent, 0, capacity)}
+}
+
+func (l *clusterEventLog) add(typ, target, detail string) {
+	if l == nil {
+		return
+	}
+	l.Lock()
+	defer l.Unlock()
+	l.seq++
+	event := &ClusterEvent{Seq: l.seq, Time: time.Now().Unix(), Type: typ, Target: target, Detail: detail}
+	if len(l.events) < cap(l.events) {
+		l.events = append(l.events, event)
+		return
+	}
+	l.events[l.start] = event
+	l.start = (l.start + 1) % len(l.events)
+}
+
+// since returns at most limit events whose seq is larger than seq and time is not before sinceTime, the oldest first.
+func (l *clusterEventLog) since(seq uint64, sinceTime int64, limit int) (page *ClusterEventPage) {
+	if limit <= 0 {
+		limit = defaultClusterEventLimit
+	}
+	if limit > maxClusterEventLimit {
+		limit = maxClusterEventLimit
+	}
+	l.RLock()
+	defer l.RUnlock()
+	page = &ClusterEventPage{Events: make([]*ClusterEvent, 0), Next: seq}
+	// the log lives in the memory of the leader and the seq restarts after a leader change, a cursor beyond
+	// the last seq is from the former leader, so read the log from the oldest event
+	if seq > l.seq {
+		page.Missed = true
+		seq, page.Next = 0, 0
+	}
+	if len(l.events) == 0 {
+		return
+	}
+	if oldest := l.events[l.start].Seq; oldest > seq+1 {
+		page.Missed = true
+	}
+	for i := 0; i < len(l.events) && len(page.Events) < limit; i++ {
+		event := l.events[(l.start+i)%len(l.events)]
+		if event.Seq <= seq {
+			continue
+		}
+		page.Next = event.Seq
+		if event.Time < sinceTime {
+			continue
+		}
+		page.Events = append(page.Events, event)
+	}
+	return
+}
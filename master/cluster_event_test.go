@@ -0,0 +1,124 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterEventLog(t *testing.T) {
+	l := newClusterEventLog(8)
+	page := l.since(0, 0, 0)
+	require.Empty(t, page.Events)
+	require.Equal(t, uint64(0), page.Next)
+
+	for i := 0; i < 5; i++ {
+		l.add(EventNodeDown, fmt.Sprintf("node%v", i), "dataNode")
+	}
+	// page through the events
+	page = l.since(0, 0, 2)
+	require.Len(t, page.Events, 2)
+	require.Equal(t, "node0", page.Events[0].Target)
+	require.Equal(t, uint64(2), page.Next)
+	require.False(t, page.Missed)
+	page = l.since(page.Next, 0, 10)
+	require.Len(t, page.Events, 3)
+	require.Equal(t, "node2", page.Events[0].Target)
+	require.Equal(t, uint64(5), page.Next)
+	page = l.since(page.Next, 0, 10)
+	require.Empty(t, page.Events)
+	require.Equal(t, uint64(5), page.Next)
+
+	// the events after the time only
+	require.Len(t, l.since(0, 1<<62, 10).Events, 0)
+	require.Equal(t, uint64(5), l.since(0, 1<<62, 10).Next)
+
+	// the oldest events are dropped when full
+	for i := 5; i < 12; i++ {
+		l.add(EventNodeUp, fmt.Sprintf("node%v", i), "dataNode")
+	}
+	page = l.since(2, 0, 100)
+	require.True(t, page.Missed)
+	require.Len(t, page.Events, 8)
+	require.Equal(t, uint64(5), page.Events[0].Seq)
+	require.Equal(t, uint64(12), page.Next)
+	require.False(t, l.since(4, 0, 100).Missed)
+}
+
+func TestClusterEventLogAfterLeaderChange(t *testing.T) {
+	// the new leader starts a new log
+	l := newClusterEventLog(8)
+	page := l.since(10, 0, 10)
+	require.True(t, page.Missed)
+	require.Empty(t, page.Events)
+	require.Equal(t, uint64(0), page.Next)
+
+	for i := 0; i < 3; i++ {
+		l.add(EventNodeDown, fmt.Sprintf("node%v", i), "dataNode")
+	}
+	// the cursor of the former leader is beyond the log, which is read from the oldest event
+	page = l.since(10, 0, 2)
+	require.True(t, page.Missed)
+	require.Len(t, page.Events, 2)
+	require.Equal(t, "node0", page.Events[0].Target)
+	require.Equal(t, uint64(2), page.Next)
+	page = l.since(page.Next, 0, 10)
+	require.False(t, page.Missed)
+	require.Len(t, page.Events, 1)
+	require.Equal(t, uint64(3), page.Next)
+}
+
+func TestGapiClusterEvents(t *testing.T) {
+	s := &ClusterService{user: server.user, cluster: server.cluster, conf: server.config, leaderInfo: server.leaderInfo}
+	query := s.Schema().Query.(*graphql.Object)
+	require.Contains(t, query.Fields, "clusterEvents")
+
+	args := func(since uint64) (a struct {
+		Since     uint64
+		SinceTime *int64
+		Limit     *int32
+	},
+	) {
+		a.Since = since
+		return
+	}
+	_, err := s.clusterEvents(gapiContext(proto.UserTypeNormal), args(0))
+	require.Error(t, err)
+
+	admin := gapiContext(proto.UserTypeAdmin)
+	page, err := s.clusterEvents(admin, args(0))
+	require.NoError(t, err)
+	cursor := page.Next
+	// follow the events from the cursor
+	for page.Next != cursor || len(page.Events) > 0 {
+		cursor = page.Next
+		page, err = s.clusterEvents(admin, args(cursor))
+		require.NoError(t, err)
+	}
+
+	server.cluster.events.add(EventPartitionBad, "dp(1)", "127.0.0.1:17310:/disk1")
+	server.cluster.events.add(EventPartitionRecovered, "dp(1)", "127.0.0.1:17310:/disk1")
+	page, err = s.clusterEvents(admin, args(cursor))
+	require.NoError(t, err)
+	require.Len(t, page.Events, 2)
+	require.Equal(t, EventPartitionBad, page.Events[0].Type)
+	require.Equal(t, EventPartitionRecovered, page.Events[1].Type)
+	require.Equal(t, page.Events[1].Seq, page.Next)
+}
@@ -831,6 +831,9 @@ func (c *Cluster) dealMetaNodeHeartbeatResp(nodeAddr string, resp *proto.MetaNod
 	// change cpu util and io used
 	metaNode.CpuUtil.Store(resp.CpuUtil)
 	metaNode.updateMetric(resp, c.cfg.MetaNodeThreshold)
+	if !metaNode.IsActive {
+		c.events.add(EventNodeUp, metaNode.Addr, "metaNode")
+	}
 	metaNode.setNodeActive()
 
 	if err = c.t.putMetaNode(metaNode); err != nil {
@@ -959,21 +962,11 @@ func (c *Cluster) handleResponseToLoadDataPartition(nodeAddr string, resp *proto
 	var (
 		dataNode *DataNode
 		dp       *DataPartition
-		vol      *Vol
 	)
 	if dataNode, err = c.dataNode(nodeAddr); err != nil {
 		return
 	}
-	if resp.VolName != "" {
-		vol, err = c.getVol(resp.VolName)
-		if err != nil {
-			return
-		}
-		dp, err = vol.getDataPartitionByID(resp.PartitionId)
-	} else {
-		dp, err = c.getDataPartitionByID(resp.PartitionId)
-	}
-	if err != nil {
+	if dp, err = c.getReportedDataPartition(resp.VolName, resp.PartitionId); err != nil {
 		return
 	}
 	dp.loadFile(dataNode, resp)
@@ -1014,6 +1007,9 @@ func (c *Cluster) handleDataNodeHeartbeatResp(nodeAddr string, resp *proto.DataN
 	dataNode.CpuUtil.Store(resp.CpuUtil)
 	dataNode.SetIoUtils(resp.IoUtils)
 
+	if !dataNode.isActive {
+		c.events.add(EventNodeUp, dataNode.Addr, "dataNode")
+	}
 	dataNode.updateNodeMetric(resp)
 	if err = c.t.putDataNode(dataNode); err != nil {
 		log.LogErrorf("action[handleDataNodeHeartbeatResp] dataNode[%v],zone[%v],node set[%v], err[%v]", dataNode.Addr, dataNode.ZoneName, dataNode.NodeSetID, err)
@@ -1068,27 +1064,45 @@ func (c *Cluster) adjustDataNode(dataNode *DataNode) {
 	err = c.t.putDataNode(dataNode)
 }
 
+// getReportedDataPartition finds the data partition reported by a data node,
+// the reported vol name may be stale after the vol is renamed, so fall back to the partition id.
+func (c *Cluster) getReportedDataPartition(volName string, partitionID uint64) (dp *DataPartition, err error) {
+	if volName != "" {
+		var vol *Vol
+		if vol, err = c.getVol(volName); err == nil {
+			if dp, err = vol.getDataPartitionByID(partitionID); err == nil {
+				return
+			}
+		}
+	}
+	return c.getDataPartitionByID(partitionID)
+}
+
+// getReportedMetaPartition finds the meta partition reported by a meta node and its vol,
+// the reported vol name may be stale after the vol is renamed, so fall back to the partition id.
+func (c *Cluster) getReportedMetaPartition(volName string, partitionID uint64) (vol *Vol, mp *MetaPartition, err error) {
+	if volName != "" {
+		if vol, err = c.getVol(volName); err == nil {
+			if mp, err = vol.metaPartition(partitionID); err == nil {
+				return
+			}
+		}
+	}
+	if mp, err = c.getMetaPartitionByID(partitionID); err != nil {
+		return
+	}
+	vol, err = c.getVol(mp.volName)
+	return
+}
+
 /*if node report data partition infos,so range data partition infos,then update data partition info*/
 func (c *Cluster) updateDataNode(dataNode *DataNode, dps []*proto.DataPartitionReport) {
 	for _, vr := range dps {
 		if vr == nil {
 			continue
 		}
-		if vr.VolName != "" {
-			vol, err := c.getVol(vr.VolName)
-			if err != nil {
-				continue
-			}
-			//if vol.Status == proto.VolStatusMarkDelete {
-			//	continue
-			//}
-			if dp, err := vol.getDataPartitionByID(vr.PartitionID); err == nil {
-				dp.updateMetric(vr, dataNode, c)
-			}
-		} else {
-			if dp, err := c.getDataPartitionByID(vr.PartitionID); err == nil {
-				dp.updateMetric(vr, dataNode, c)
-			}
+		if dp, err := c.getReportedDataPartition(vr.VolName, vr.PartitionID); err == nil {
+			dp.updateMetric(vr, dataNode, c)
 		}
 	}
 }
@@ -1103,27 +1117,8 @@ func (c *Cluster) updateMetaNode(metaNode *MetaNode, metaPartitions []*proto.Met
 			continue
 		}
 		var mp *MetaPartition
-		if mr.VolName != "" {
-
-			vol, err = c.getVol(mr.VolName)
-			if err != nil {
-				continue
-			}
-
-			//if vol.Status == proto.VolStatusMarkDelete {
-			//	continue
-			//}
-
-			mp, err = vol.metaPartition(mr.PartitionID)
-			if err != nil {
-				continue
-			}
-
-		} else {
-			mp, err = c.getMetaPartitionByID(mr.PartitionID)
-			if err != nil {
-				continue
-			}
+		if vol, mp, err = c.getReportedMetaPartition(mr.VolName, mr.PartitionID); err != nil {
+			continue
 		}
 
 		// send latest end to replica metanode, including updating the end after MaxMP split when the old MaxMP is unavailable
@@ -47,6 +47,8 @@ const (
 	cfgmetaPartitionInodeIdStep         = "metaPartitionInodeIdStep"
 	cfgMaxQuotaNumPerVol                = "maxQuotaNumPerVol"
 	disableAutoCreate                   = "disableAutoCreate"
+	cfgAutoAllocRwDpFloor               = "autoAllocRwDpFloor"        // allocate data partitions if the r&w ones are less than it
+	cfgAutoAllocUsedRatioCeiling        = "autoAllocUsedRatioCeiling" // and the used space is below the ratio of the capacity
 	cfgMonitorPushAddr                  = "monitorPushAddr"
 	intervalToScanS3Expiration          = "intervalToScanS3Expiration"
 
@@ -90,6 +92,7 @@ const (
 	defaultMaxDpCntLimit                               = 3000
 	defaultIntervalToScanS3Expiration                  = 12 * 3600
 	defaultMaxConcurrentLcNodes                        = 3
+	defaultAutoAllocUsedRatioCeiling           float64 = 1    // allocate until the used space reaches the capacity
 	metaPartitionInodeUsageThreshold           float64 = 0.75 // inode usage threshold on a meta partition
 	lowerLimitRWMetaPartition                          = 3    // lower limit of RW meta partition, equal defaultReplicaNum
 	// defaultIntervalToCheckDelVerTaskExpiration         = 3
@@ -137,6 +140,8 @@ type clusterConfig struct {
 	MetaPartitionInodeIdStep            uint64
 	MaxQuotaNumPerVol                   int
 	DisableAutoCreate                   bool
+	AutoAllocRwDpFloor                  int
+	AutoAllocUsedRatioCeiling           float64
 	MonitorPushAddr                     string
 	IntervalToScanS3Expiration          int64
 	MaxConcurrentLcNodes                uint64
@@ -173,6 +178,8 @@ func newClusterConfig() (cfg *clusterConfig) {
 	cfg.IntervalToScanS3Expiration = defaultIntervalToScanS3Expiration
 	cfg.MaxConcurrentLcNodes = defaultMaxConcurrentLcNodes
 	cfg.volDelayDeleteTimeHour = defaultVolDelayDeleteTimeHour
+	cfg.AutoAllocRwDpFloor = minNumOfRWDataPartitions
+	cfg.AutoAllocUsedRatioCeiling = defaultAutoAllocUsedRatioCeiling
 	return
 }
 
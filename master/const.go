@@ -63,8 +63,10 @@ const (
 	domainIdKey                = "domainId"
 	volOwnerKey                = "owner"
 	volAuthKey                 = "authKey"
+	volNewNameKey              = "newName"
 	replicaNumKey              = "replicaNum"
 	followerReadKey            = "followerRead"
+	nearReadKey                = "nearRead"
 	authenticateKey            = "authenticate"
 	akKey                      = "ak"
 	keywordsKey                = "keywords"
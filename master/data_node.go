@@ -16,6 +16,7 @@ package master
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -69,6 +70,8 @@ type DataNode struct {
 	ioUtils                   atomic.Value       `json:"-"`
 	DecommissionDiskList      []string
 	DecommissionDpTotal       int
+	badDiskHistory            *badDiskHistory
+	partitionReportSeq        uint64 // seq of the applied partition reports, 0 asks the data node for the full ones
 }
 
 func newDataNode(addr, zoneName, clusterID string) (dataNode *DataNode) {
@@ -82,6 +85,7 @@ func newDataNode(addr, zoneName, clusterID string) (dataNode *DataNode) {
 	dataNode.DpCntLimit = newDpCountLimiter(nil)
 	dataNode.CpuUtil.Store(0)
 	dataNode.SetIoUtils(make(map[string]float64))
+	dataNode.badDiskHistory = newBadDiskHistory(defaultBadDiskHistoryCap)
 	return
 }
 
@@ -148,11 +152,13 @@ func (dataNode *DataNode) updateNodeMetric(resp *proto.DataNodeHeartbeatResponse
 	}
 	dataNode.ZoneName = resp.ZoneName
 	dataNode.DataPartitionCount = resp.CreatedPartitionCnt
+	dataNode.mergePartitionReports(resp)
 	dataNode.DataPartitionReports = resp.PartitionReports
 	dataNode.TotalPartitionSize = resp.TotalPartitionSize
 
 	dataNode.BadDisks = resp.BadDisks
 	dataNode.DiskStats = resp.DiskStats
+	dataNode.badDiskHistory.update(time.Now(), reportedBadDisks(resp))
 
 	dataNode.StartTime = resp.StartTime
 	if dataNode.Total == 0 {
@@ -166,6 +172,47 @@ func (dataNode *DataNode) updateNodeMetric(resp *proto.DataNodeHeartbeatResponse
 		dataNode.Total, dataNode.Used, dataNode.AvailableSpace)
 }
 
+// mergePartitionReports applies the delta partition reports of the response to the last reports,
+// afterwards resp.PartitionReports holds all the partitions. The caller must hold the lock.
+func (dataNode *DataNode) mergePartitionReports(resp *proto.DataNodeHeartbeatResponse) {
+	if resp.DeltaReportBaseSeq == 0 {
+		atomic.StoreUint64(&dataNode.partitionReportSeq, resp.PartitionReportSeq)
+		return
+	}
+	if resp.DeltaReportBaseSeq != atomic.LoadUint64(&dataNode.partitionReportSeq) {
+		// some reports are missed, merge what we have and ask for the full ones in the next heartbeat
+		log.LogWarnf("action[mergePartitionReports] dataNode[%v] delta base seq[%v] mismatch applied seq[%v]",
+			dataNode.Addr, resp.DeltaReportBaseSeq, dataNode.partitionReportSeq)
+		atomic.StoreUint64(&dataNode.partitionReportSeq, 0)
+	} else {
+		atomic.StoreUint64(&dataNode.partitionReportSeq, resp.PartitionReportSeq)
+	}
+
+	reports := make(map[uint64]*proto.DataPartitionReport, len(dataNode.DataPartitionReports))
+	for _, vr := range dataNode.DataPartitionReports {
+		reports[vr.PartitionID] = vr
+	}
+	for _, vr := range resp.PartitionReports {
+		reports[vr.PartitionID] = vr
+	}
+	for _, id := range resp.DeletedPartitions {
+		delete(reports, id)
+	}
+	merged := make([]*proto.DataPartitionReport, 0, len(reports))
+	for _, vr := range reports {
+		merged = append(merged, vr)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].PartitionID < merged[j].PartitionID })
+	resp.PartitionReports = merged
+}
+
+// getBadDiskHistory returns the bad disk records of the data node, the oldest first.
+func (dataNode *DataNode) getBadDiskHistory() []proto.BadDiskRecord {
+	dataNode.RLock()
+	defer dataNode.RUnlock()
+	return dataNode.badDiskHistory.list()
+}
+
 func (dataNode *DataNode) canAlloc() bool {
 	dataNode.RLock()
 	defer dataNode.RUnlock()
@@ -257,6 +304,7 @@ func (dataNode *DataNode) createHeartbeatTask(masterAddr string, enableDiskQos b
 	request.QosFlowReadLimit = dataNode.QosFlowRLimit
 	request.QosFlowWriteLimit = dataNode.QosFlowWLimit
 	request.DecommissionDisks = dataNode.getDecommissionedDisks()
+	request.PartitionReportSeq = atomic.LoadUint64(&dataNode.partitionReportSeq)
 
 	task = proto.NewAdminTask(proto.OpDataNodeHeartbeat, dataNode.Addr, request)
 	return
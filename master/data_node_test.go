@@ -44,3 +44,111 @@ func decommissionDataNode(addr string, t *testing.T) {
 	reqURL := fmt.Sprintf("%v%v?addr=%v", hostAddr, proto.DecommissionDataNode, addr)
 	process(reqURL, t)
 }
+
+func TestDataNodeBadDiskHistory(t *testing.T) {
+	dataNode := newDataNode("127.0.0.1:9196", DefaultZoneName, "test")
+	heartbeat := func(badDisks ...string) {
+		resp := &proto.DataNodeHeartbeatResponse{}
+		for _, disk := range badDisks {
+			resp.DiskStats = append(resp.DiskStats, proto.DiskStat{DiskPath: disk, Status: proto.Unavailable})
+		}
+		resp.DiskStats = append(resp.DiskStats, proto.DiskStat{DiskPath: "/data0", Status: proto.ReadWrite})
+		dataNode.updateNodeMetric(resp)
+	}
+
+	// disk1 keeps bad for 3 heartbeats, disk2 flaps
+	heartbeat("/data1", "/data2")
+	heartbeat("/data1")
+	heartbeat("/data1", "/data2")
+	heartbeat()
+	heartbeat("/data2")
+
+	records := dataNode.getBadDiskHistory()
+	expects := []struct {
+		disk       string
+		errorCount uint64
+		recovered  bool
+	}{
+		{"/data1", 3, true},
+		{"/data2", 1, true},
+		{"/data2", 1, true},
+		{"/data2", 1, false},
+	}
+	if len(records) != len(expects) {
+		t.Fatalf("expect %v records, got %v", len(expects), records)
+	}
+	for i, e := range expects {
+		r := records[i]
+		if r.DiskPath != e.disk || r.ErrorCount != e.errorCount || (r.RecoverTime != 0) != e.recovered {
+			t.Errorf("record %v: expect %+v, got %+v", i, e, r)
+		}
+		if r.FirstSeen == 0 || r.LastSeen < r.FirstSeen || (e.recovered && r.RecoverTime < r.LastSeen) {
+			t.Errorf("record %v: bad timestamps %+v", i, r)
+		}
+	}
+
+	// the history is capped, the oldest records are dropped
+	history := newBadDiskHistory(4)
+	now := time.Unix(1700000000, 0)
+	for i := 0; i < 10; i++ {
+		history.update(now.Add(time.Duration(2*i)*time.Second), []string{"/data1"})
+		history.update(now.Add(time.Duration(2*i+1)*time.Second), nil)
+	}
+	history.update(now.Add(20*time.Second), []string{"/data1"})
+	history.update(now.Add(21*time.Second), []string{"/data1"})
+	records = history.list()
+	if len(records) != 4 {
+		t.Fatalf("expect 4 records, got %v", records)
+	}
+	for i, r := range records {
+		firstSeen := now.Unix() + int64(2*(i+7))
+		if r.FirstSeen != firstSeen {
+			t.Errorf("record %v: expect first seen %v, got %+v", i, firstSeen, r)
+		}
+	}
+	last := records[3]
+	if last.LastSeen != now.Unix()+21 || last.ErrorCount != 2 || last.RecoverTime != 0 {
+		t.Errorf("unexpected last record %+v", last)
+	}
+	if len(history.open) != 1 {
+		t.Errorf("expect 1 open record, got %v", history.open)
+	}
+}
+
+func TestDataNodeMergePartitionReports(t *testing.T) {
+	dataNode := newDataNode("127.0.0.1:9197", DefaultZoneName, "test")
+	heartbeat := func(seq, base uint64, deleted []uint64, reports ...*proto.DataPartitionReport) []uint64 {
+		resp := &proto.DataNodeHeartbeatResponse{
+			PartitionReports:   reports,
+			PartitionReportSeq: seq,
+			DeltaReportBaseSeq: base,
+			DeletedPartitions:  deleted,
+		}
+		dataNode.updateNodeMetric(resp)
+		ids := make([]uint64, 0)
+		for _, vr := range dataNode.DataPartitionReports {
+			ids = append(ids, vr.PartitionID)
+		}
+		return ids
+	}
+	report := func(id uint64) *proto.DataPartitionReport {
+		return &proto.DataPartitionReport{PartitionID: id}
+	}
+
+	ids := heartbeat(1, 0, nil, report(1), report(2), report(3))
+	if fmt.Sprint(ids) != "[1 2 3]" {
+		t.Fatalf("full report: got %v", ids)
+	}
+	ids = heartbeat(2, 1, []uint64{2}, report(4))
+	if fmt.Sprint(ids) != "[1 3 4]" {
+		t.Fatalf("delta report: got %v", ids)
+	}
+	if seq := dataNode.createHeartbeatTask("", false).Request.(*proto.HeartBeatRequest).PartitionReportSeq; seq != 2 {
+		t.Fatalf("expect request seq 2, got %v", seq)
+	}
+	// the delta based on a missed report makes the next heartbeat ask for the full reports
+	heartbeat(4, 3, nil, report(5))
+	if seq := dataNode.createHeartbeatTask("", false).Request.(*proto.HeartBeatRequest).PartitionReportSeq; seq != 0 {
+		t.Fatalf("expect request seq 0, got %v", seq)
+	}
+}
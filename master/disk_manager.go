@@ -16,6 +16,7 @@ package master
 
 import (
 	"fmt"
+	"sort"
 	"sync/atomic"
 	"time"
 
@@ -132,6 +133,7 @@ func (c *Cluster) checkDiskRecoveryProgress() {
 					partition.DecommissionErrorMessage = ""
 					partition.SetDecommissionStatus(DecommissionSuccess) // can be readonly or readwrite
 					Warn(c.Name, fmt.Sprintf("action[checkDiskRecoveryProgress]clusterID[%v],partitionID[%v] has recovered success", c.Name, partitionID))
+					c.events.add(EventPartitionRecovered, fmt.Sprintf("dp(%v)", partitionID), key.(string))
 				}
 				partition.RLock()
 				err = c.syncUpdateDataPartition(partition)
@@ -309,6 +311,51 @@ func (dd *DecommissionDisk) updateDecommissionStatus(c *Cluster, debug bool) (ui
 	return DecommissionRunning, progress
 }
 
+// DiskDecommissionProgress is the progress of a disk decommission task, whose id is the key of the disk.
+type DiskDecommissionProgress struct {
+	TaskID        string
+	Status        uint32
+	StatusMessage string
+	Progress      float64
+	Total         int
+	Completed     int
+	InFlight      []uint64
+	Failed        []uint64
+}
+
+// getProgress updates the decommission status of the disk and reports the partitions done, in flight and failed.
+func (dd *DecommissionDisk) getProgress(c *Cluster) *DiskDecommissionProgress {
+	status, progress := dd.updateDecommissionStatus(c, false)
+	progress, _ = FormatFloatFloor(progress, 4)
+	p := &DiskDecommissionProgress{
+		TaskID:        dd.GenerateKey(),
+		Status:        status,
+		StatusMessage: GetDecommissionStatusMessage(status),
+		Progress:      progress,
+		Total:         dd.DecommissionDpTotal,
+		InFlight:      make([]uint64, 0),
+		Failed:        make([]uint64, 0),
+	}
+	if status == DecommissionInitial || status == markDecommission || p.Total <= 0 {
+		// the partitions of the disk are not collected yet
+		return p
+	}
+	partitions := c.getAllDecommissionDataPartitionByDiskAndTerm(dd.SrcAddr, dd.DiskPath, dd.DecommissionTerm)
+	for _, dp := range partitions {
+		if dp.IsDecommissionFailed() && !dp.needRollback(c) {
+			p.Failed = append(p.Failed, dp.PartitionID)
+		} else {
+			p.InFlight = append(p.InFlight, dp.PartitionID)
+		}
+	}
+	sort.Slice(p.InFlight, func(i, j int) bool { return p.InFlight[i] < p.InFlight[j] })
+	sort.Slice(p.Failed, func(i, j int) bool { return p.Failed[i] < p.Failed[j] })
+	if p.Completed = p.Total - len(partitions); p.Completed < 0 {
+		p.Completed = 0
+	}
+	return p
+}
+
 func (dd *DecommissionDisk) GetDecommissionStatus() uint32 {
 	return atomic.LoadUint32(&dd.DecommissionStatus)
 }
@@ -1,19 +1,20 @@
 package master
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
 	"github.com/cubefs/cubefs/util/log"
 	"github.com/samsarahq/thunder/graphql"
 	"github.com/samsarahq/thunder/graphql/schemabuilder"
@@ -139,10 +140,18 @@ func (s *ClusterService) registerObject(schema *schemabuilder.Schema) {
 		return s.cluster.getVol(n.Name)
 	})
 
+	schema.Object("Disk", DataNodeDisk{})
+
 	object = schema.Object("DataNode", DataNode{})
 	object.FieldFunc("isActive", func(ctx context.Context, n *DataNode) bool {
 		return n.isActive
 	})
+	object.FieldFunc("disks", func(ctx context.Context, n *DataNode) ([]*DataNodeDisk, error) {
+		if _, _, err := permissions(ctx, ADMIN); err != nil {
+			return nil, err
+		}
+		return s.dataNodeDisks(n), nil
+	})
 
 	object = schema.Object("metaNode", MetaNode{})
 	object.FieldFunc("metaPartitionInfos", func(ctx context.Context, n *MetaNode) []*proto.MetaPartitionReport {
@@ -150,17 +159,72 @@ func (s *ClusterService) registerObject(schema *schemabuilder.Schema) {
 	})
 }
 
+// DataNodeDisk is the state of a disk of a data node, built from the disks and partitions reported by the node.
+type DataNodeDisk struct {
+	Path           string
+	Status         int
+	PartitionCount int
+	Bad            bool
+	Decommission   *DiskDecommissionProgress // nil if the disk is not being decommissioned
+}
+
+// dataNodeDisks returns the disks of the data node sorted by path.
+func (s *ClusterService) dataNodeDisks(n *DataNode) []*DataNodeDisk {
+	disks := make(map[string]*DataNodeDisk)
+	getDisk := func(path string) *DataNodeDisk {
+		disk, ok := disks[path]
+		if !ok {
+			disk = &DataNodeDisk{Path: path, Status: proto.ReadWrite}
+			disks[path] = disk
+		}
+		return disk
+	}
+
+	n.RLock()
+	for _, stat := range n.DiskStats {
+		disk := getDisk(stat.DiskPath)
+		disk.Status = stat.Status
+		disk.PartitionCount = stat.TotalPartitionCnt
+	}
+	// the nodes of old versions do not report the disk stats
+	if len(n.DiskStats) == 0 {
+		for _, report := range n.DataPartitionReports {
+			getDisk(report.DiskPath).PartitionCount++
+		}
+	}
+	for _, path := range n.BadDisks {
+		disk := getDisk(path)
+		disk.Bad = true
+		disk.Status = proto.Unavailable
+	}
+	n.RUnlock()
+
+	result := make([]*DataNodeDisk, 0, len(disks))
+	for _, disk := range disks {
+		key := (&DecommissionDisk{SrcAddr: n.Addr, DiskPath: disk.Path}).GenerateKey()
+		if value, ok := s.cluster.DecommissionDisks.Load(key); ok {
+			disk.Decommission = value.(*DecommissionDisk).getProgress(s.cluster)
+		}
+		result = append(result, disk)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
 func (s *ClusterService) registerQuery(schema *schemabuilder.Schema) {
 	query := schema.Query()
 	query.FieldFunc("clusterView", s.clusterView)
 	query.FieldFunc("dataNodeList", s.dataNodeList)
-	query.FieldFunc("dataNodeListTest", s.dataNodeListTest)
 	query.FieldFunc("dataNodeGet", s.dataNodeGet)
 	query.FieldFunc("metaNodeList", s.metaNodeList)
 	query.FieldFunc("metaNodeGet", s.metaNodeGet)
 	query.FieldFunc("masterList", s.masterList)
 	query.FieldFunc("getTopology", s.getTopology)
 	query.FieldFunc("alarmList", s.alarmList)
+	query.FieldFunc("clusterEvents", s.clusterEvents)
+	query.FieldFunc("decommissionDiskProgress", s.decommissionDiskProgress)
 }
 
 func (s *ClusterService) registerMutation(schema *schemabuilder.Schema) {
@@ -175,9 +239,13 @@ func (s *ClusterService) registerMutation(schema *schemabuilder.Schema) {
 	mutation.FieldFunc("decommissionMetaNode", s.decommissionMetaNode)
 	mutation.FieldFunc("decommissionDisk", s.decommissionDisk)
 	mutation.FieldFunc("decommissionDataNode", s.decommissionDataNode)
+	mutation.FieldFunc("createVolume", s.createVolume)
+	mutation.FieldFunc("deleteVolume", s.deleteVolume)
+	mutation.FieldFunc("updateVolume", s.updateVolume)
 }
 
 // Decommission a disk. This will decommission all the data partitions on this disk.
+// The decommission is persisted and resumed by the new leader, its progress can be queried with the returned task id.
 func (m *ClusterService) decommissionDisk(ctx context.Context, args struct {
 	OffLineAddr string
 	DiskPath    string
@@ -194,18 +262,28 @@ func (m *ClusterService) decommissionDisk(ctx context.Context, args struct {
 		return nil, err
 	}
 
-	var badPartitionIds []uint64
-	for _, bdp := range badPartitions {
-		badPartitionIds = append(badPartitionIds, bdp.PartitionID)
-	}
-	rstMsg := fmt.Sprintf("receive decommissionDisk node[%v] disk[%v], badPartitionIds[%v] has offline successfully",
-		node.Addr, args.DiskPath, badPartitionIds)
-	if err = m.cluster.decommissionDisk(node, false, args.DiskPath, badPartitions, true); err != nil {
+	if err = m.cluster.migrateDisk(node.Addr, args.DiskPath, "", false, 0, true, ManualDecommission); err != nil {
 		return nil, err
 	}
-	Warn(m.cluster.Name, rstMsg)
+	taskID := (&DecommissionDisk{SrcAddr: node.Addr, DiskPath: args.DiskPath}).GenerateKey()
+	Warn(m.cluster.Name, fmt.Sprintf("decommission disk [%v] submited!need check status later!", taskID))
 
-	return proto.Success("success"), nil
+	return proto.Success(taskID), nil
+}
+
+// Query the progress of a disk decommission by the task id returned from decommissionDisk.
+func (m *ClusterService) decommissionDiskProgress(ctx context.Context, args struct {
+	TaskID string
+},
+) (*DiskDecommissionProgress, error) {
+	if _, _, err := permissions(ctx, ADMIN); err != nil {
+		return nil, err
+	}
+	value, ok := m.cluster.DecommissionDisks.Load(args.TaskID)
+	if !ok {
+		return nil, fmt.Errorf("cannot find decommission task [%v], may be already offline", args.TaskID)
+	}
+	return value.(*DecommissionDisk).getProgress(m.cluster), nil
 }
 
 // Decommission a data node. This will decommission all the data partition on that node.
@@ -371,34 +449,6 @@ func (s *ClusterService) dataNodeList(ctx context.Context, args struct{}) ([]*Da
 	return all, nil
 }
 
-func (s *ClusterService) dataNodeListTest(ctx context.Context, args struct {
-	Num int64
-},
-) ([]*DataNode, error) {
-	if _, _, err := permissions(ctx, ADMIN); err != nil {
-		return nil, err
-	}
-	var all []*DataNode
-
-	for i := 0; i < int(args.Num); i++ {
-		all = append(all, &DataNode{
-			Total:          uint64(i),
-			Used:           1,
-			AvailableSpace: 1,
-			ID:             1,
-			ZoneName:       "123",
-			Addr:           "123123121231",
-			ReportTime:     time.Time{},
-			isActive:       false,
-			RWMutex:        sync.RWMutex{},
-			UsageRatio:     1,
-			SelectedTimes:  2,
-		})
-	}
-
-	return all, nil
-}
-
 func (s *ClusterService) metaNodeGet(ctx context.Context, args struct {
 	Addr string
 },
@@ -462,12 +512,12 @@ func (m *ClusterService) addRaftNode(ctx context.Context, args struct {
 
 // Turn on or off the automatic allocation of the data partitions.
 // If DisableAutoAllocate == off, then we WILL NOT automatically allocate new data partitions for the volume when:
-//  1. the used space is below the max capacity,
-//  2. and the number of r&w data partition is less than 20.
+//  1. the used space is below autoAllocUsedRatioCeiling of the max capacity,
+//  2. and the number of r&w data partition is less than autoAllocRwDpFloor (10 by default).
 //
 // If DisableAutoAllocate == on, then we WILL automatically allocate new data partitions for the volume when:
-//  1. the used space is below the max capacity,
-//  2. and the number of r&w data partition is less than 20.
+//  1. the used space is below autoAllocUsedRatioCeiling of the max capacity,
+//  2. and the number of r&w data partition is less than autoAllocRwDpFloor (10 by default).
 func (m *ClusterService) clusterFreeze(ctx context.Context, args struct {
 	Status bool
 },
@@ -482,6 +532,245 @@ func (m *ClusterService) clusterFreeze(ctx context.Context, args struct {
 	return proto.Success("success"), nil
 }
 
+type createVolumeArgs struct {
+	Name, Owner                                        string
+	Capacity                                           uint64
+	VolType, DpReplicaNum                              *int32
+	MpCount, DpCount, DpSize, DomainId                 *uint64
+	DeleteLockTime                                     *int64
+	ZoneName, Description, TxMask                      *string
+	FollowerRead, Authenticate, CrossZone, EnableQuota *bool
+	NormalZonesFirst, EnablePosixAcl, NearRead         *bool
+	DpReadOnlyWhenVolFull                              *bool
+}
+
+// Create a volume, the optional arguments take the same defaults as the REST API.
+func (m *ClusterService) createVolume(ctx context.Context, args createVolumeArgs) (*proto.GeneralResp, error) {
+	if _, _, err := permissions(ctx, ADMIN); err != nil {
+		return nil, err
+	}
+	if !volNameRegexp.MatchString(args.Name) {
+		return nil, fmt.Errorf("name can only be number and letters")
+	}
+	if !ownerRegexp.MatchString(args.Owner) {
+		return nil, fmt.Errorf("owner can only be number and letters")
+	}
+
+	req := &createVolReq{
+		name:                    args.Name,
+		owner:                   args.Owner,
+		capacity:                int(args.Capacity),
+		mpCount:                 defaultInitMetaPartitionCount,
+		dpCount:                 defaultInitDataPartitionCnt,
+		dpSize:                  120,
+		qosLimitArgs:            &qosArgs{},
+		txTimeout:               proto.DefaultTransactionTimeout,
+		txConflictRetryNum:      proto.DefaultTxConflictRetryNum,
+		txConflictRetryInterval: proto.DefaultTxConflictRetryInterval,
+		// the cache of a cold volume takes the defaults, checkCreateReq fills them in
+		coldArgs:          coldVolArgs{},
+		enableTransaction: proto.TxOpMaskOff,
+	}
+	if args.VolType != nil {
+		req.volType = int(*args.VolType)
+	}
+	if args.DpReplicaNum != nil {
+		if *args.DpReplicaNum < 0 || *args.DpReplicaNum > math.MaxUint8 {
+			return nil, fmt.Errorf("invalid arg dpReplicaNum: %v", *args.DpReplicaNum)
+		}
+		req.dpReplicaNum = uint8(*args.DpReplicaNum)
+	}
+	if args.MpCount != nil {
+		req.mpCount = int(*args.MpCount)
+	}
+	if args.DpCount != nil {
+		req.dpCount = int(*args.DpCount)
+	}
+	if args.DpSize != nil {
+		req.dpSize = int(*args.DpSize)
+	}
+	if args.ZoneName != nil {
+		req.zoneName = *args.ZoneName
+	}
+	if args.Description != nil {
+		req.description = *args.Description
+	}
+	if args.Authenticate != nil {
+		req.authenticate = *args.Authenticate
+	}
+	if args.CrossZone != nil {
+		req.crossZone = *args.CrossZone
+	}
+	if args.NormalZonesFirst != nil {
+		req.normalZonesFirst = *args.NormalZonesFirst
+	}
+	if args.EnableQuota != nil {
+		req.enableQuota = *args.EnableQuota
+	}
+	if args.DeleteLockTime != nil {
+		req.deleteLockTime = *args.DeleteLockTime
+	}
+	if args.DomainId != nil {
+		req.domainId = *args.DomainId
+	}
+	if args.EnablePosixAcl != nil {
+		req.enablePosixAcl = *args.EnablePosixAcl
+	}
+	if args.DpReadOnlyWhenVolFull != nil {
+		req.DpReadOnlyWhenVolFull = *args.DpReadOnlyWhenVolFull
+	}
+	if args.NearRead != nil {
+		req.nearRead = *args.NearRead
+	}
+	if args.TxMask != nil {
+		mask, err := proto.GetMaskFromString(*args.TxMask)
+		if err != nil {
+			return nil, err
+		}
+		req.enableTransaction = mask
+	}
+	if args.FollowerRead != nil {
+		if !*args.FollowerRead && proto.IsHot(req.volType) && (req.dpReplicaNum == 1 || req.dpReplicaNum == 2) {
+			return nil, fmt.Errorf("vol with 1 ro 2 replia should enable followerRead")
+		}
+		req.followerRead = *args.FollowerRead
+	}
+	if proto.IsHot(req.volType) && (req.dpReplicaNum == 1 || req.dpReplicaNum == 2) {
+		req.followerRead = true
+	}
+
+	if err := m.cluster.checkCreateReq(req); err != nil {
+		return nil, err
+	}
+	vol, err := m.cluster.createVol(req)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.user.associateVolWithUser(req.owner, req.name); err != nil {
+		return nil, err
+	}
+
+	msg := fmt.Sprintf("create vol[%v] successfully, has allocate [%v] data partitions", req.name, len(vol.dataPartitions.partitions))
+	log.LogWarn(msg)
+	return proto.Success(msg), nil
+}
+
+// Delete a volume directly, the delayed deletion is only supported by the REST API.
+func (m *ClusterService) deleteVolume(ctx context.Context, args struct {
+	Name, AuthKey string
+},
+) (*proto.GeneralResp, error) {
+	uid, _, err := permissions(ctx, ADMIN)
+	if err != nil {
+		return nil, err
+	}
+	if !volNameRegexp.MatchString(args.Name) {
+		return nil, fmt.Errorf("name can only be number and letters")
+	}
+	if args.AuthKey == "" {
+		return nil, keyNotFound(volAuthKey)
+	}
+	if !enableDirectDeleteVol {
+		return nil, fmt.Errorf("direct deletion of vol is disabled, delete vol[%v] by the REST API", args.Name)
+	}
+
+	if err = m.cluster.markDeleteVol(args.Name, args.AuthKey, false, true); err != nil {
+		return nil, err
+	}
+	if err = m.user.deleteVolPolicy(args.Name); err != nil {
+		return nil, err
+	}
+
+	msg := fmt.Sprintf("delete vol[%v] successfully,from[%v]", args.Name, uid)
+	log.LogWarn(msg)
+	return proto.Success(msg), nil
+}
+
+type updateVolumeArgs struct {
+	Name, AuthKey string
+	NewCapacity   *uint64
+	NewReplicaNum *int32
+}
+
+// Update the capacity(GB) and the data replica num of a volume, the replica num can only be reduced
+// one at a time as the REST API does.
+func (m *ClusterService) updateVolume(ctx context.Context, args updateVolumeArgs) (*proto.SimpleVolView, error) {
+	uid, _, err := permissions(ctx, ADMIN)
+	if err != nil {
+		return nil, err
+	}
+	if !volNameRegexp.MatchString(args.Name) {
+		return nil, fmt.Errorf("name can only be number and letters")
+	}
+	if args.NewCapacity == nil && args.NewReplicaNum == nil {
+		return nil, fmt.Errorf("at least one of newCapacity and newReplicaNum should be set")
+	}
+	vol, err := m.cluster.getVol(args.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	newArgs := getVolVarargs(vol)
+	if args.NewCapacity != nil {
+		if *args.NewCapacity == 0 {
+			return nil, fmt.Errorf("invalid arg newCapacity: %v", *args.NewCapacity)
+		}
+		if used := vol.totalUsedSpace(); *args.NewCapacity*util.GB < used {
+			return nil, fmt.Errorf("capacity[%v] is less than the used space[%v]", *args.NewCapacity, used/util.GB)
+		}
+		newArgs.capacity = *args.NewCapacity
+	}
+	if args.NewReplicaNum != nil {
+		replicaNum := *args.NewReplicaNum
+		if replicaNum <= 0 || replicaNum > int32(vol.dpReplicaNum) {
+			return nil, fmt.Errorf("invalid arg newReplicaNum: %v", replicaNum)
+		}
+		// only a change of the replica num of a hot volume is validated, the current one is kept as it is
+		if replicaNum != int32(vol.dpReplicaNum) {
+			if err = checkReplicaNumChange(vol, int(replicaNum)); err != nil {
+				return nil, err
+			}
+			if proto.IsHot(vol.VolType) && (replicaNum == 1 || replicaNum == 2) && !newArgs.followerRead {
+				return nil, fmt.Errorf("vol with 1 ro 2 replia should enable followerRead")
+			}
+			newArgs.dpReplicaNum = uint8(replicaNum)
+		}
+	}
+
+	if err = m.cluster.updateVol(args.Name, args.AuthKey, newArgs); err != nil {
+		return nil, err
+	}
+	log.LogWarnf("update vol[%v] capacity[%v] replicaNum[%v] successfully,from[%v]",
+		args.Name, newArgs.capacity, newArgs.dpReplicaNum, uid)
+
+	if vol, err = m.cluster.getVol(args.Name); err != nil {
+		return nil, err
+	}
+	return newSimpleView(vol), nil
+}
+
+// List the cluster events after the cursor Since, which is the Next of the last page or 0 for the first,
+// and optionally not before the unix time SinceTime. Poll it with the cursor to follow the events.
+func (m *ClusterService) clusterEvents(ctx context.Context, args struct {
+	Since     uint64
+	SinceTime *int64
+	Limit     *int32
+},
+) (*ClusterEventPage, error) {
+	if _, _, err := permissions(ctx, ADMIN); err != nil {
+		return nil, err
+	}
+	var sinceTime int64
+	if args.SinceTime != nil {
+		sinceTime = *args.SinceTime
+	}
+	var limit int
+	if args.Limit != nil {
+		limit = int(*args.Limit)
+	}
+	return m.cluster.events.since(args.Since, sinceTime, limit), nil
+}
+
 type WarnMessage struct {
 	Time     string `json:"time"`
 	Key      string `json:"key"`
@@ -499,14 +788,11 @@ func (m *ClusterService) alarmList(ctx context.Context, args struct {
 		return nil, err
 	}
 
-	size := int64(args.Size * 1000)
-
-	list := make([]*WarnMessage, 0, 100)
-
 	path := filepath.Join(log.LogDir, "master"+log.CriticalLogFileName)
 
 	stat, err := os.Stat(path)
 	if err != nil {
+		list := make([]*WarnMessage, 0, 1)
 		list = append(list, &WarnMessage{
 			Time:     time.Now().Format("2006-01-02 15:04:05"),
 			Key:      "not found",
@@ -523,71 +809,79 @@ func (m *ClusterService) alarmList(ctx context.Context, args struct {
 		return nil, fmt.Errorf("open file has err:[%s]", err.Error())
 	}
 
-	if stat.Size() > size {
-		if _, err := f.Seek(stat.Size()-size, 0); err != nil {
-			return nil, fmt.Errorf("seek file has err:[%s]", err.Error())
-		}
-	}
-
 	defer func() {
 		if err := f.Close(); err != nil {
 			log.LogErrorf("close alarm file has err:[%s]", err.Error())
 		}
 	}()
 
-	buf := bufio.NewReader(f)
-
-	all, err := io.ReadAll(buf)
+	lines, err := readLastLines(f, stat.Size(), int(args.Size))
 	if err != nil {
 		return nil, fmt.Errorf("read file:[%s] size:[%d] has err:[%s]", path, stat.Size(), err.Error())
 	}
 
-	for _, line := range strings.Split(string(all), "\n") {
+	list := make([]*WarnMessage, 0, len(lines))
+	for _, line := range lines {
+		list = append(list, parseWarnMessage(line))
+	}
+	return list, nil
+}
 
-		if len(line) == 0 {
-			break
+const alarmReadChunkSize = 64 * 1024
+
+// readLastLines reads at most count non-empty lines backwards from the end of the file, the latest line comes first.
+// The file is read in chunks, so the memory is proportional to the lines returned rather than the file size.
+func readLastLines(r io.ReaderAt, size int64, count int) ([]string, error) {
+	lines := make([]string, 0)
+	// the tail of the line whose head is in the preceding chunk
+	var partial []byte
+	for offset := size; offset > 0 && len(lines) < count; {
+		n := int64(alarmReadChunkSize)
+		if n > offset {
+			n = offset
 		}
-
-		split := strings.Split(string(line), " ")
-
-		var msg *WarnMessage
-
-		if len(split) < 7 {
-			value := string(line)
-			msg = &WarnMessage{
-				Time:     "unknow",
-				Key:      "parse msg has err",
-				Hostname: "parse msg has err",
-				Type:     "parse msg has err",
-				Value:    value,
-				Detail:   value,
-			}
-		} else {
-			value := strings.Join(split[6:], " ")
-			msg = &WarnMessage{
-				Time:     split[0] + " " + split[1],
-				Key:      split[4],
-				Hostname: split[5],
-				Type:     split[2],
-				Value:    value,
-				Detail:   value,
+		offset -= n
+		data := make([]byte, n, n+int64(len(partial)))
+		if _, err := r.ReadAt(data, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = append(data, partial...)
+		for i := bytes.LastIndexByte(data, '\n'); i >= 0 && len(lines) < count; i = bytes.LastIndexByte(data, '\n') {
+			if line := data[i+1:]; len(line) > 0 {
+				lines = append(lines, string(line))
 			}
+			data = data[:i]
 		}
-
-		list = append(list, msg)
+		partial = data
 	}
-
-	// reverse slice
-	l := len(list)
-	for i := 0; i < l/2; i++ {
-		list[i], list[l-i-1] = list[l-i-1], list[i]
+	if len(partial) > 0 && len(lines) < count {
+		lines = append(lines, string(partial))
 	}
+	return lines, nil
+}
 
-	if len(list) > int(args.Size) {
-		list = list[:args.Size]
+// parseWarnMessage parses a line of the critical log written by the alarms.
+func parseWarnMessage(line string) *WarnMessage {
+	split := strings.Split(line, " ")
+	if len(split) < 7 {
+		return &WarnMessage{
+			Time:     "unknow",
+			Key:      "parse msg has err",
+			Hostname: "parse msg has err",
+			Type:     "parse msg has err",
+			Value:    line,
+			Detail:   line,
+		}
+	}
+	value := strings.Join(split[6:], " ")
+	return &WarnMessage{
+		Time:     split[0] + " " + split[1],
+		Key:      split[4],
+		Hostname: split[5],
+		Type:     split[2],
+		Value:    value,
+		Detail:   value,
 	}
-
-	return list, nil
 }
 
 func (m *ClusterService) makeClusterView() *proto.ClusterView {
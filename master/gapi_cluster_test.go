@@ -0,0 +1,374 @@
+package master
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/samsarahq/thunder/graphql"
+	"github.com/stretchr/testify/require"
+)
+
+func gapiContext(userType proto.UserType) context.Context {
+	return context.WithValue(context.Background(), proto.UserInfoKey, &proto.UserInfo{UserID: "gapiUser", UserType: userType})
+}
+
+func TestGapiCreateAndDeleteVolume(t *testing.T) {
+	s := &ClusterService{user: server.user, cluster: server.cluster, conf: server.config, leaderInfo: server.leaderInfo}
+	require.NotNil(t, s.Schema())
+
+	volName := "gapiVol"
+	zoneName := testZone2
+	dpReplicaNum := int32(3)
+	createArgs := func(name string, capacity uint64) (args createVolumeArgs) {
+		args.Name, args.Owner, args.Capacity = name, testOwner, capacity
+		args.ZoneName, args.DpReplicaNum = &zoneName, &dpReplicaNum
+		return
+	}
+	admin := gapiContext(proto.UserTypeAdmin)
+
+	// only the admin can create volumes
+	_, err := s.createVolume(gapiContext(proto.UserTypeNormal), createArgs(volName, 100))
+	require.Error(t, err)
+	// the arguments are validated as the REST API does
+	_, err = s.createVolume(admin, createArgs("gapi-vol?", 100))
+	require.Error(t, err)
+	_, err = s.createVolume(admin, createArgs(volName, 0))
+	require.Error(t, err)
+	_, err = server.cluster.getVol(volName)
+	require.Error(t, err)
+
+	_, err = s.createVolume(admin, createArgs(volName, 100))
+	require.NoError(t, err)
+	vol, err := server.cluster.getVol(volName)
+	require.NoError(t, err)
+	require.Equal(t, testOwner, vol.Owner)
+	require.Equal(t, uint8(3), vol.dpReplicaNum)
+	require.Equal(t, defaultInitMetaPartitionCount, len(vol.MetaPartitions))
+	userInfo, err := server.user.getUserInfo(testOwner)
+	require.NoError(t, err)
+	require.Contains(t, userInfo.Policy.OwnVols, volName)
+
+	deleteArgs := struct{ Name, AuthKey string }{Name: volName, AuthKey: buildAuthKey(testOwner)}
+	_, err = s.deleteVolume(gapiContext(proto.UserTypeNormal), deleteArgs)
+	require.Error(t, err)
+	_, err = s.deleteVolume(admin, struct{ Name, AuthKey string }{Name: volName})
+	require.Error(t, err)
+	_, err = s.deleteVolume(admin, deleteArgs)
+	require.NoError(t, err)
+	vol, err = server.cluster.getVol(volName)
+	require.NoError(t, err)
+	require.Equal(t, proto.VolStatusMarkDelete, vol.Status)
+}
+
+func TestGapiCreateVolumeArgs(t *testing.T) {
+	s := &ClusterService{user: server.user, cluster: server.cluster, conf: server.config, leaderInfo: server.leaderInfo}
+	admin := gapiContext(proto.UserTypeAdmin)
+	zoneName := testZone2
+	boolp := func(v bool) *bool { return &v }
+	deleteLockTime, txMask, volType := int64(2), "create", int32(proto.VolumeTypeCold)
+
+	// the cache of a cold volume takes the defaults
+	coldName := "gapiColdVol"
+	_, err := s.createVolume(admin, createVolumeArgs{Name: coldName, Owner: testOwner, Capacity: 100, ZoneName: &zoneName, VolType: &volType})
+	require.NoError(t, err)
+	defer server.cluster.markDeleteVol(coldName, buildAuthKey(testOwner), false, true)
+	vol, err := server.cluster.getVol(coldName)
+	require.NoError(t, err)
+	require.True(t, proto.IsCold(vol.VolType))
+	require.Equal(t, uint64(defaultEbsBlkSize), uint64(vol.EbsBlkSize))
+	require.Equal(t, defaultCacheTtl, vol.CacheTTL)
+
+	volName := "gapiArgsVol"
+	args := createVolumeArgs{
+		Name: volName, Owner: testOwner, Capacity: 100, ZoneName: &zoneName,
+		DeleteLockTime: &deleteLockTime, TxMask: &txMask,
+		EnablePosixAcl: boolp(true), NearRead: boolp(true), DpReadOnlyWhenVolFull: boolp(true),
+	}
+	invalidMask := "nope"
+	args.TxMask = &invalidMask
+	_, err = s.createVolume(admin, args)
+	require.Error(t, err)
+	args.TxMask = &txMask
+	_, err = s.createVolume(admin, args)
+	require.NoError(t, err)
+	defer server.cluster.markDeleteVol(volName, buildAuthKey(testOwner), false, true)
+	vol, err = server.cluster.getVol(volName)
+	require.NoError(t, err)
+	require.Equal(t, deleteLockTime, vol.DeleteLockTime)
+	require.True(t, vol.enablePosixAcl)
+	require.True(t, vol.NearRead)
+	require.True(t, vol.DpReadOnlyWhenVolFull)
+	require.Equal(t, proto.TxOpMaskCreate, vol.enableTransaction)
+}
+
+func TestGapiUpdateVolume(t *testing.T) {
+	s := &ClusterService{user: server.user, cluster: server.cluster, conf: server.config, leaderInfo: server.leaderInfo}
+	admin := gapiContext(proto.UserTypeAdmin)
+
+	volName := "gapiUpdateVol"
+	zoneName := testZone2
+	createArgs := createVolumeArgs{Name: volName, Owner: testOwner, Capacity: 100, ZoneName: &zoneName}
+	_, err := s.createVolume(admin, createArgs)
+	require.NoError(t, err)
+	defer server.cluster.markDeleteVol(volName, buildAuthKey(testOwner), false, true)
+
+	updateArgs := func(capacity *uint64, replicaNum *int32) updateVolumeArgs {
+		return updateVolumeArgs{Name: volName, AuthKey: buildAuthKey(testOwner), NewCapacity: capacity, NewReplicaNum: replicaNum}
+	}
+	u64 := func(v uint64) *uint64 { return &v }
+	i32 := func(v int32) *int32 { return &v }
+
+	// only the admin can update volumes
+	_, err = s.updateVolume(gapiContext(proto.UserTypeNormal), updateArgs(u64(200), nil))
+	require.Error(t, err)
+	// nothing to update
+	_, err = s.updateVolume(admin, updateArgs(nil, nil))
+	require.Error(t, err)
+	_, err = s.updateVolume(admin, updateArgs(u64(0), nil))
+	require.Error(t, err)
+	// the replica num can only be reduced one at a time
+	_, err = s.updateVolume(admin, updateArgs(nil, i32(4)))
+	require.Error(t, err)
+	_, err = s.updateVolume(admin, updateArgs(nil, i32(1)))
+	require.Error(t, err)
+	_, err = s.updateVolume(admin, updateArgs(nil, i32(0)))
+	require.Error(t, err)
+	// a volume with 2 replicas needs follower read
+	_, err = s.updateVolume(admin, updateArgs(nil, i32(2)))
+	require.Error(t, err)
+
+	vol, err := server.cluster.getVol(volName)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), vol.Capacity)
+	require.Equal(t, uint8(3), vol.dpReplicaNum)
+
+	// shrinking below the used space is rejected
+	dps := vol.cloneDataPartitionMap()
+	require.NotEmpty(t, dps)
+	for _, dp := range dps {
+		dp.used = 60 * util.GB
+		break
+	}
+	_, err = s.updateVolume(admin, updateArgs(u64(50), nil))
+	require.Error(t, err)
+
+	view, err := s.updateVolume(admin, updateArgs(u64(200), nil))
+	require.NoError(t, err)
+	require.Equal(t, volName, view.Name)
+	require.Equal(t, uint64(200), view.Capacity)
+	require.Equal(t, uint8(3), view.DpReplicaNum)
+
+	// the current replica num is not validated again, even if follower read is disabled afterwards
+	vol.dpReplicaNum = 2
+	vol.FollowerRead = false
+	view, err = s.updateVolume(admin, updateArgs(u64(300), i32(2)))
+	require.NoError(t, err)
+	require.Equal(t, uint64(300), view.Capacity)
+	require.Equal(t, uint8(2), view.DpReplicaNum)
+	require.False(t, view.FollowerRead)
+}
+
+func TestGapiClusterSchema(t *testing.T) {
+	s := &ClusterService{user: server.user, cluster: server.cluster, conf: server.config, leaderInfo: server.leaderInfo}
+	query := s.Schema().Query.(*graphql.Object)
+	require.Contains(t, query.Fields, "dataNodeList")
+	// the fake data nodes are not exposed
+	require.NotContains(t, query.Fields, "dataNodeListTest")
+	mutation := s.Schema().Mutation.(*graphql.Object)
+	require.Contains(t, mutation.Fields, "updateVolume")
+}
+
+func TestReadLastLines(t *testing.T) {
+	const lines = 50000
+	path := filepath.Join(t.TempDir(), "master_critical.log")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	for i := 0; i < lines; i++ {
+		_, err = fmt.Fprintf(f, "2024/01/02 15:04:05.000000 [Critical] alarm.go:48: cubefs_master_alarm 192.168.0.1 detail of alarm %06d %s\n",
+			i, strings.Repeat("x", 40))
+		require.NoError(t, err)
+	}
+	// a line spans several chunks and the empty lines are skipped
+	long := strings.Repeat("y", 3*alarmReadChunkSize)
+	_, err = fmt.Fprintf(f, "%s\n\n2024/01/02 15:04:06.000000 [Critical] alarm.go:48: key host latest\n\n", long)
+	require.NoError(t, err)
+	stat, err := f.Stat()
+	require.NoError(t, err)
+	require.Greater(t, stat.Size(), int64(5<<20))
+
+	result, err := readLastLines(f, stat.Size(), 3)
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	require.Equal(t, "2024/01/02 15:04:06.000000 [Critical] alarm.go:48: key host latest", result[0])
+	require.Equal(t, long, result[1])
+	require.True(t, strings.Contains(result[2], fmt.Sprintf("alarm %06d", lines-1)))
+
+	msg := parseWarnMessage(result[0])
+	require.Equal(t, &WarnMessage{
+		Time:     "2024/01/02 15:04:06.000000",
+		Key:      "key",
+		Hostname: "host",
+		Type:     "[Critical]",
+		Value:    "latest",
+		Detail:   "latest",
+	}, msg)
+	require.Equal(t, "parse msg has err", parseWarnMessage(result[1]).Key)
+
+	result, err = readLastLines(f, stat.Size(), 2*lines)
+	require.NoError(t, err)
+	require.Len(t, result, lines+2)
+	require.True(t, strings.Contains(result[len(result)-1], "alarm 000000"))
+
+	result, err = readLastLines(f, stat.Size(), 0)
+	require.NoError(t, err)
+	require.Len(t, result, 0)
+}
+
+func TestGapiDecommissionDiskProgress(t *testing.T) {
+	s := &ClusterService{user: server.user, cluster: server.cluster, conf: server.config, leaderInfo: server.leaderInfo}
+	c := server.cluster
+	admin := gapiContext(proto.UserTypeAdmin)
+
+	_, err := s.decommissionDisk(admin, struct{ OffLineAddr, DiskPath string }{"127.0.0.1:1", "/cfs"})
+	require.Error(t, err)
+
+	// a task of 4 partitions, one is done, two are in flight and one failed
+	dd := &DecommissionDisk{
+		SrcAddr:             "127.0.0.1:19999",
+		DiskPath:            "/gapiDecommission",
+		DecommissionStatus:  DecommissionRunning,
+		DecommissionDpTotal: 4,
+		DecommissionTerm:    uint64(time.Now().Unix()),
+	}
+	// the partitions of a dedicated volume are not touched by the other decommission tests
+	volName := "gapiDecommissionVol"
+	createVol(map[string]interface{}{nameKey: volName}, t)
+	defer delVol(volName, t)
+	vol, err := c.getVol(volName)
+	require.NoError(t, err)
+	dps := vol.dataPartitions.clonePartitions()
+	require.GreaterOrEqual(t, len(dps), 3)
+	dps = dps[:3]
+	for i, dp := range dps {
+		dp.DecommissionSrcAddr, dp.DecommissionSrcDiskPath, dp.DecommissionTerm = dd.SrcAddr, dd.DiskPath, dd.DecommissionTerm
+		dp.SetDecommissionStatus(DecommissionRunning)
+		if i == 2 {
+			dp.SetDecommissionStatus(DecommissionFail)
+		}
+	}
+	defer func() {
+		for _, dp := range dps {
+			dp.DecommissionSrcAddr, dp.DecommissionSrcDiskPath, dp.DecommissionTerm = "", "", 0
+			dp.SetDecommissionStatus(DecommissionInitial)
+		}
+		c.syncDeleteDecommissionDisk(dd)
+		c.DecommissionDisks.Delete(dd.GenerateKey())
+	}()
+	require.NoError(t, c.syncAddDecommissionDisk(dd))
+	c.DecommissionDisks.Store(dd.GenerateKey(), dd)
+
+	check := func() {
+		_, err := s.decommissionDiskProgress(gapiContext(proto.UserTypeNormal), struct{ TaskID string }{dd.GenerateKey()})
+		require.Error(t, err)
+		p, err := s.decommissionDiskProgress(admin, struct{ TaskID string }{dd.GenerateKey()})
+		require.NoError(t, err)
+		require.Equal(t, dd.GenerateKey(), p.TaskID)
+		require.Equal(t, uint32(DecommissionRunning), p.Status)
+		require.Equal(t, 4, p.Total)
+		require.Equal(t, 1, p.Completed)
+		require.Equal(t, 0.25, p.Progress)
+		require.ElementsMatch(t, []uint64{dps[0].PartitionID, dps[1].PartitionID}, p.InFlight)
+		require.Equal(t, []uint64{dps[2].PartitionID}, p.Failed)
+	}
+	check()
+
+	// the task is reloaded from the store by a new leader
+	c.DecommissionDisks.Delete(dd.GenerateKey())
+	_, err = s.decommissionDiskProgress(admin, struct{ TaskID string }{dd.GenerateKey()})
+	require.Error(t, err)
+	require.NoError(t, c.loadDecommissionDiskList())
+	value, ok := c.DecommissionDisks.Load(dd.GenerateKey())
+	require.True(t, ok)
+	dd = value.(*DecommissionDisk)
+	check()
+}
+
+func TestGapiDataNodeDisks(t *testing.T) {
+	s := &ClusterService{user: server.user, cluster: server.cluster, conf: server.config, leaderInfo: server.leaderInfo}
+	c := server.cluster
+	schema := s.Schema()
+	nodeType := schema.Query.(*graphql.Object).Fields["dataNodeGet"].Type
+
+	node := newDataNode("127.0.0.1:19998", testZone1, c.Name)
+	node.DiskStats = []proto.DiskStat{
+		{DiskPath: "/data1", Status: proto.ReadWrite, TotalPartitionCnt: 3},
+		{DiskPath: "/data0", Status: proto.ReadOnly, TotalPartitionCnt: 2},
+	}
+	node.BadDisks = []string{"/data2"}
+	dd := &DecommissionDisk{
+		SrcAddr:             node.Addr,
+		DiskPath:            "/data1",
+		DecommissionStatus:  DecommissionRunning,
+		DecommissionDpTotal: 3,
+		DecommissionTerm:    uint64(time.Now().Unix()),
+	}
+	c.DecommissionDisks.Store(dd.GenerateKey(), dd)
+	defer c.DecommissionDisks.Delete(dd.GenerateKey())
+
+	query, err := graphql.Parse(`{ addr disks { path status partitionCount bad decommission { taskID total } } }`, nil)
+	require.NoError(t, err)
+	ctx := gapiContext(proto.UserTypeAdmin)
+	require.NoError(t, graphql.PrepareQuery(ctx, nodeType, query.SelectionSet))
+	executor := graphql.NewExecutor(graphql.NewImmediateGoroutineScheduler())
+	out, err := executor.Execute(ctx, nodeType, node, query)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(out)
+	require.NoError(t, err)
+	result := &struct {
+		Addr  string
+		Disks []struct {
+			Path           string
+			Status         int
+			PartitionCount int
+			Bad            bool
+			Decommission   *struct {
+				TaskID string
+				Total  int
+			}
+		}
+	}{}
+	require.NoError(t, json.Unmarshal(data, result))
+	require.Equal(t, node.Addr, result.Addr)
+	require.Len(t, result.Disks, 3)
+	disk := result.Disks[0]
+	require.Equal(t, "/data0", disk.Path)
+	require.Equal(t, proto.ReadOnly, disk.Status)
+	require.Equal(t, 2, disk.PartitionCount)
+	require.False(t, disk.Bad)
+	require.Nil(t, disk.Decommission)
+	disk = result.Disks[1]
+	require.Equal(t, "/data1", disk.Path)
+	require.Equal(t, 3, disk.PartitionCount)
+	require.NotNil(t, disk.Decommission)
+	require.Equal(t, dd.GenerateKey(), disk.Decommission.TaskID)
+	require.Equal(t, 3, disk.Decommission.Total)
+	disk = result.Disks[2]
+	require.Equal(t, "/data2", disk.Path)
+	require.True(t, disk.Bad)
+	require.Equal(t, proto.Unavailable, disk.Status)
+
+	// the disks are not exposed to the normal users
+	ctx = gapiContext(proto.UserTypeNormal)
+	_, err = executor.Execute(ctx, nodeType, node, query)
+	require.Error(t, err)
+}
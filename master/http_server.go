@@ -375,6 +375,9 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.AdminVolExpand).
 		HandlerFunc(m.volExpand)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.AdminRenameVol).
+		HandlerFunc(m.renameVol)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.ClientVol).
 		HandlerFunc(m.getVol)
@@ -625,6 +628,9 @@ func (m *Server) registerAPIRoutes(router *mux.Router) {
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.QueryBadDisks).
 		HandlerFunc(m.queryBadDisks)
+	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
+		Path(proto.QueryBadDiskHistory).
+		HandlerFunc(m.queryBadDiskHistory)
 	router.NewRoute().Methods(http.MethodGet, http.MethodPost).
 		Path(proto.QueryDisks).
 		HandlerFunc(m.queryDisks)
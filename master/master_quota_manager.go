@@ -266,6 +266,8 @@ func (mqMgr *MasterQuotaManager) getQuotaHbInfos() (infos []*proto.QuotaHeartBea
 		info.QuotaId = quotaId
 		info.LimitedInfo.LimitedFiles = quotaInfo.LimitedInfo.LimitedFiles
 		info.LimitedInfo.LimitedBytes = quotaInfo.LimitedInfo.LimitedBytes
+		info.MaxFiles = quotaInfo.MaxFiles
+		info.MaxBytes = quotaInfo.MaxBytes
 		info.Enable = mqMgr.vol.enableQuota
 		infos = append(infos, info)
 		log.LogDebugf("getQuotaHbInfos info %v", info)
@@ -48,6 +48,7 @@ type MetaNode struct {
 	RdOnly                    bool
 	MigrateLock               sync.RWMutex
 	CpuUtil                   atomicutil.Float64 `json:"-"`
+	Version                   string
 }
 
 func newMetaNode(addr, zoneName, clusterID string) (node *MetaNode) {
@@ -122,6 +123,7 @@ func (metaNode *MetaNode) updateMetric(resp *proto.MetaNodeHeartbeatResponse, th
 		metaNode.MaxMemAvailWeight = uint64(left)
 	}
 	metaNode.ZoneName = resp.ZoneName
+	metaNode.Version = resp.Version
 	metaNode.Threshold = threshold
 }
 
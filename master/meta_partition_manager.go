@@ -220,6 +220,7 @@ func (c *Cluster) checkMetaPartitionRecoveryProgress() {
 				partition.RUnlock()
 				Warn(c.Name, fmt.Sprintf("checkMetaPartitionRecoveryProgress clusterID[%v],vol[%v] partitionID[%v] has recovered success",
 					c.Name, partition.volName, partitionID))
+				c.events.add(EventPartitionRecovered, fmt.Sprintf("mp(%v)", partitionID), key.(string))
 			} else {
 				newBadMpIds = append(newBadMpIds, partitionID)
 			}
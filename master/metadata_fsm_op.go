@@ -249,6 +249,7 @@ type volValue struct {
 	Capacity              uint64
 	Owner                 string
 	FollowerRead          bool
+	NearRead              bool
 	Authenticate          bool
 	DpReadOnlyWhenVolFull bool
 
@@ -357,6 +358,7 @@ func newVolValue(vol *Vol) (vv *volValue) {
 		ClientHitTriggerCnt: vol.qosManager.ClientHitTriggerCnt,
 
 		DpReadOnlyWhenVolFull: vol.DpReadOnlyWhenVolFull,
+		NearRead:              vol.NearRead,
 		Forbidden:             vol.Forbidden,
 		EnableAuditLog:        vol.EnableAuditLog,
 		AuthKey:               vol.authKey,
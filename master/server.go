@@ -238,6 +238,15 @@ func (m *Server) checkConfig(cfg *config.Config) (err error) {
 	m.config.DisableAutoCreate = cfg.GetBoolWithDefault(disableAutoCreate, false)
 	syslog.Printf("get disableAutoCreate cfg %v", m.config.DisableAutoCreate)
 
+	if floor := cfg.GetInt64(cfgAutoAllocRwDpFloor); floor > 0 {
+		m.config.AutoAllocRwDpFloor = int(floor)
+	}
+	if ceiling := cfg.GetFloat(cfgAutoAllocUsedRatioCeiling); ceiling > 0 && ceiling <= 1 {
+		m.config.AutoAllocUsedRatioCeiling = ceiling
+	}
+	syslog.Printf("get autoAllocRwDpFloor cfg %v, autoAllocUsedRatioCeiling cfg %v",
+		m.config.AutoAllocRwDpFloor, m.config.AutoAllocUsedRatioCeiling)
+
 	m.config.faultDomain = cfg.GetBoolWithDefault(faultDomain, false)
 	m.config.heartbeatPort = cfg.GetInt64(heartbeatPortKey)
 	m.config.replicaPort = cfg.GetInt64(replicaPortKey)
@@ -36,6 +36,7 @@ type VolVarargs struct {
 	capacity                uint64 // GB
 	deleteLockTime          int64  // h
 	followerRead            bool
+	nearRead                bool
 	authenticate            bool
 	dpSelectorName          string
 	dpSelectorParm          string
@@ -104,6 +105,7 @@ type Vol struct {
 	domainId                uint64
 	qosManager              *QosCtrlManager
 	DpReadOnlyWhenVolFull   bool
+	NearRead                bool
 	aclMgr                  AclManager
 	uidSpaceManager         *UidSpaceManager
 	volLock                 sync.RWMutex
@@ -182,6 +184,7 @@ func newVol(vv volValue) (vol *Vol) {
 	}
 	vol.qosManager.volUpdateMagnify(magnifyQosVal)
 	vol.DpReadOnlyWhenVolFull = vv.DpReadOnlyWhenVolFull
+	vol.NearRead = vv.NearRead
 	vol.mpsLock = newMpsLockManager(vol)
 	vol.EnableAuditLog = true
 	vol.preloadCapacity = math.MaxUint64 // mark as special value to trigger calculate
@@ -983,9 +986,9 @@ func (vol *Vol) autoCreateDataPartitions(c *Cluster) {
 			return
 		}
 
-		if vol.dataPartitions.readableAndWritableCnt < minNumOfRWDataPartitions {
-			c.batchCreateDataPartition(vol, minNumOfRWDataPartitions, false)
-			log.LogWarnf("autoCreateDataPartitions: readWrite less than 10, alloc new 10 partitions, vol %s", vol.Name)
+		if floor := c.cfg.AutoAllocRwDpFloor; vol.dataPartitions.readableAndWritableCnt < floor {
+			c.batchCreateDataPartition(vol, floor, false)
+			log.LogWarnf("autoCreateDataPartitions: readWrite less than %v, alloc new %v partitions, vol %s", floor, floor, vol.Name)
 		}
 
 		return
@@ -1015,20 +1018,36 @@ func (vol *Vol) autoCreateDataPartitions(c *Cluster) {
 		return
 	}
 
-	if (vol.Capacity > 200000 && vol.dataPartitions.readableAndWritableCnt < 200) || vol.dataPartitions.readableAndWritableCnt < minNumOfRWDataPartitions {
+	if vol.shouldAutoAllocate(c.cfg) {
 		vol.dataPartitions.lastAutoCreateTime = time.Now()
-		count := vol.calculateExpansionNum()
+		count := vol.calculateExpansionNum(c.cfg.AutoAllocRwDpFloor)
 		log.LogInfof("action[autoCreateDataPartitions] vol[%v] count[%v]", vol.Name, count)
 		c.batchCreateDataPartition(vol, count, false)
 	}
 }
 
+// shouldAutoAllocate tells whether to allocate new data partitions for the volume automatically, i.e.
+//  1. the used space is below the ceiling ratio of the capacity,
+//  2. and the number of r&w data partitions is less than the floor, or 200 for the volumes over 200000GB.
+func (vol *Vol) shouldAutoAllocate(cfg *clusterConfig) bool {
+	rwCnt := vol.dataPartitions.readableAndWritableCnt
+	capacity := vol.capacity()
+	if rwCnt >= cfg.AutoAllocRwDpFloor && !(capacity > 200000 && rwCnt < 200) {
+		return false
+	}
+	if cfg.AutoAllocUsedRatioCeiling < 1 &&
+		float64(vol.totalUsedSpace()) >= cfg.AutoAllocUsedRatioCeiling*float64(capacity*util.GB) {
+		return false
+	}
+	return true
+}
+
 // Calculate the expansion number (the number of data partitions to be allocated to the given volume)
-func (vol *Vol) calculateExpansionNum() (count int) {
+func (vol *Vol) calculateExpansionNum(minCount int) (count int) {
 	c := float64(vol.Capacity) * volExpansionRatio * float64(util.GB) / float64(util.DefaultDataPartitionSize)
 	switch {
-	case c < minNumOfRWDataPartitions:
-		count = minNumOfRWDataPartitions
+	case c < float64(minCount):
+		count = minCount
 	case c > maxNumberOfDataPartitionsForExpansion:
 		count = maxNumberOfDataPartitionsForExpansion
 	default:
@@ -1519,6 +1538,7 @@ func setVolFromArgs(args *VolVarargs, vol *Vol) {
 	vol.Capacity = args.capacity
 	vol.DeleteLockTime = args.deleteLockTime
 	vol.FollowerRead = args.followerRead
+	vol.NearRead = args.nearRead
 	vol.authenticate = args.authenticate
 	vol.enablePosixAcl = args.enablePosixAcl
 	vol.DpReadOnlyWhenVolFull = args.dpReadOnlyWhenVolFull
@@ -1568,6 +1588,7 @@ func getVolVarargs(vol *Vol) *VolVarargs {
 		capacity:                vol.Capacity,
 		deleteLockTime:          vol.DeleteLockTime,
 		followerRead:            vol.FollowerRead,
+		nearRead:                vol.NearRead,
 		authenticate:            vol.authenticate,
 		dpSelectorName:          vol.dpSelectorName,
 		dpSelectorParm:          vol.dpSelectorParm,
@@ -0,0 +1,317 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// renameVol renames the volume to newName.
+// The volume must be forbidden first so that no client is working on it. The volume, its data and meta partitions,
+// quotas, uid space limits, lifecycle configuration and user policies are committed by a single raft proposal,
+// the memory is updated only after the proposal is applied.
+// Data nodes and meta nodes keep reporting partitions with the old name, these reports are matched by partition id,
+// and their lookups of the partitions by the old name fall back to the partition id.
+func (c *Cluster) renameVol(name, newName, authKey string, u *User) (err error) {
+	var (
+		vol   *Vol
+		users []*proto.UserInfo
+		cmds  int
+	)
+
+	c.createVolMutex.Lock()
+	defer c.createVolMutex.Unlock()
+
+	if vol, err = c.getVol(name); err != nil {
+		err = proto.ErrVolNotExists
+		goto errHandler
+	}
+	if vol.status() == proto.VolStatusMarkDelete {
+		err = proto.ErrVolNotExists
+		goto errHandler
+	}
+	if !matchKey(vol.Owner, authKey) {
+		err = proto.ErrVolAuthKeyNotMatch
+		goto errHandler
+	}
+	if !vol.Forbidden {
+		err = fmt.Errorf("vol must be forbidden before rename")
+		goto errHandler
+	}
+	if name == newName {
+		err = fmt.Errorf("new name is the same as the old one")
+		goto errHandler
+	}
+	if _, err = c.getVol(newName); err == nil {
+		err = proto.ErrDuplicateVol
+		goto errHandler
+	}
+
+	u.volUserMutex.Lock()
+	defer u.volUserMutex.Unlock()
+
+	if users, err = u.getUserInfosOfVol(name); err != nil {
+		goto errHandler
+	}
+	if cmds, err = c.commitRenameVol(vol, newName, users); err != nil {
+		goto errHandler
+	}
+	u.renameVol(name, newName, users)
+	// the view cache is set under the volume lock, so it is updated after the lock is released
+	vol.updateViewCache(c)
+	log.LogWarnf("action[renameVol] vol[%v] is renamed to [%v], cmds[%v]", name, newName, cmds)
+	return
+
+errHandler:
+	err = fmt.Errorf("action[renameVol], clusterID[%v] name:%v, newName:%v, err:%v ", c.Name, name, newName, err.Error())
+	log.LogError(errors.Stack(err))
+	Warn(c.Name, err.Error())
+	return
+}
+
+// commitRenameVol persists the rename by raft and applies it in memory, both under the volume lock, so that
+// a concurrent updateVol can not persist the old name over the rename.
+func (c *Cluster) commitRenameVol(vol *Vol, newName string, users []*proto.UserInfo) (cmds int, err error) {
+	vol.volLock.Lock()
+	defer vol.volLock.Unlock()
+
+	cmdMap, err := c.buildRenameVolRaftCmds(vol, newName, users)
+	if err != nil {
+		return
+	}
+	if err = c.syncBatchCommitCmd(cmdMap); err != nil {
+		return 0, proto.ErrPersistenceByRaft
+	}
+	c.doRenameVol(vol, newName)
+	return len(cmdMap), nil
+}
+
+// buildRenameVolRaftCmds builds the raft commands that persist everything referring to the volume by name,
+// the values are copies so that nothing in memory changes before the commands are applied.
+func (c *Cluster) buildRenameVolRaftCmds(vol *Vol, newName string, users []*proto.UserInfo) (cmdMap map[string]*RaftCmd, err error) {
+	cmdMap = make(map[string]*RaftCmd)
+	putCmd := func(opType uint32, key string, value interface{}) (err error) {
+		metadata := &RaftCmd{Op: opType, K: key}
+		if metadata.V, err = json.Marshal(value); err != nil {
+			return errors.New(err.Error())
+		}
+		cmdMap[metadata.K] = metadata
+		return
+	}
+
+	vv := newVolValue(vol)
+	vv.Name = newName
+	if err = putCmd(opSyncUpdateVol, volPrefix+strconv.FormatUint(vol.ID, 10), vv); err != nil {
+		return
+	}
+
+	for _, dp := range vol.cloneDataPartitionMap() {
+		dp.RLock()
+		dpv := newDataPartitionValue(dp)
+		dp.RUnlock()
+		dpv.VolName = newName
+		key := dataPartitionPrefix + strconv.FormatUint(dp.VolID, 10) + keySeparator + strconv.FormatUint(dp.PartitionID, 10)
+		if err = putCmd(opSyncUpdateDataPartition, key, dpv); err != nil {
+			return
+		}
+	}
+
+	for _, mp := range vol.cloneMetaPartitionMap() {
+		mp.RLock()
+		mpv := newMetaPartitionValue(mp)
+		mp.RUnlock()
+		mpv.VolName = newName
+		key := metaPartitionPrefix + strconv.FormatUint(mp.volID, 10) + keySeparator + strconv.FormatUint(mp.PartitionID, 10)
+		if err = putCmd(opSyncUpdateMetaPartition, key, mpv); err != nil {
+			return
+		}
+	}
+
+	if vol.quotaManager != nil {
+		vol.quotaManager.RLock()
+		for _, quotaInfo := range vol.quotaManager.IdQuotaInfoMap {
+			info := *quotaInfo
+			info.VolName = newName
+			key := quotaPrefix + strconv.FormatUint(vol.ID, 10) + keySeparator + strconv.FormatUint(uint64(info.QuotaId), 10)
+			if err = putCmd(opSyncSetQuota, key, &info); err != nil {
+				vol.quotaManager.RUnlock()
+				return
+			}
+		}
+		vol.quotaManager.RUnlock()
+	}
+
+	if vol.uidSpaceManager != nil {
+		var uidFsm UidSpaceFsm
+		vol.uidSpaceManager.RLock()
+		for _, uidInfo := range vol.uidSpaceManager.uidInfo {
+			info := *uidInfo
+			info.VolName = newName
+			uidFsm.UidSpaceArr = append(uidFsm.UidSpaceArr, &info)
+		}
+		vol.uidSpaceManager.RUnlock()
+		if len(uidFsm.UidSpaceArr) > 0 {
+			if err = putCmd(opSyncUid, UidPrefix+strconv.FormatUint(vol.ID, 10), &uidFsm); err != nil {
+				return
+			}
+		}
+	}
+
+	if lcConf := c.lcMgr.GetS3BucketLifecycle(vol.Name); lcConf != nil {
+		if err = putCmd(opSyncDeleteLcConf, lcConfPrefix+vol.Name, lcConf); err != nil {
+			return
+		}
+		newConf := &proto.LcConfiguration{VolName: newName, Rules: lcConf.Rules}
+		if err = putCmd(opSyncAddLcConf, lcConfPrefix+newName, newConf); err != nil {
+			return
+		}
+	}
+
+	if len(users) == 0 {
+		return
+	}
+	userIDs := make([]string, 0, len(users))
+	for _, userInfo := range users {
+		var newInfo *proto.UserInfo
+		if newInfo, err = copyUserInfo(userInfo); err != nil {
+			return
+		}
+		newInfo.Policy.RenameVol(vol.Name, newName)
+		if err = putCmd(opSyncUpdateUserInfo, userPrefix+newInfo.UserID, newInfo); err != nil {
+			return
+		}
+		userIDs = append(userIDs, userInfo.UserID)
+	}
+	if err = putCmd(opSyncDeleteVolUser, volUserPrefix+vol.Name, &proto.VolUser{Vol: vol.Name}); err != nil {
+		return
+	}
+	err = putCmd(opSyncAddVolUser, volUserPrefix+newName, &proto.VolUser{Vol: newName, UserIDs: userIDs})
+	return
+}
+
+// doRenameVol updates the volume and everything referring to it by name in memory.
+func (c *Cluster) doRenameVol(vol *Vol, newName string) {
+	oldName := vol.Name
+
+	c.volMutex.Lock()
+	delete(c.vols, oldName)
+	vol.Name = newName
+	c.vols[newName] = vol
+	c.volMutex.Unlock()
+	c.volStatInfo.Delete(oldName)
+
+	vol.dataPartitions.Lock()
+	vol.dataPartitions.volName = newName
+	for _, dp := range vol.dataPartitions.partitionMap {
+		dp.Lock()
+		dp.VolName = newName
+		dp.Unlock()
+	}
+	vol.dataPartitions.Unlock()
+
+	vol.mpsLock.Lock()
+	for _, mp := range vol.MetaPartitions {
+		mp.Lock()
+		mp.volName = newName
+		mp.Unlock()
+	}
+	vol.mpsLock.UnLock()
+
+	if vol.quotaManager != nil {
+		vol.quotaManager.Lock()
+		for _, quotaInfo := range vol.quotaManager.IdQuotaInfoMap {
+			quotaInfo.VolName = newName
+		}
+		vol.quotaManager.Unlock()
+	}
+
+	if vol.uidSpaceManager != nil {
+		vol.uidSpaceManager.Lock()
+		vol.uidSpaceManager.volName = newName
+		for _, uidInfo := range vol.uidSpaceManager.uidInfo {
+			uidInfo.VolName = newName
+		}
+		vol.uidSpaceManager.Unlock()
+	}
+
+	if lcConf := c.lcMgr.GetS3BucketLifecycle(oldName); lcConf != nil {
+		c.lcMgr.DelS3BucketLifecycle(oldName)
+		_ = c.lcMgr.SetS3BucketLifecycle(&proto.LcConfiguration{VolName: newName, Rules: lcConf.Rules})
+	}
+}
+
+// getUserInfosOfVol returns the users who own or are authorized to the volume, deleted users are skipped.
+func (u *User) getUserInfosOfVol(volName string) (users []*proto.UserInfo, err error) {
+	var (
+		userIDs  []string
+		userInfo *proto.UserInfo
+	)
+	if userIDs, err = u.getUsersOfVol(volName); err != nil {
+		if err == proto.ErrHaveNoPolicy {
+			err = nil
+		}
+		return
+	}
+	for _, userID := range userIDs {
+		if userInfo, err = u.getUserInfo(userID); err != nil {
+			if err == proto.ErrUserNotExists {
+				log.LogWarnf("action[getUserInfosOfVol], userID: %v does not exist", userID)
+				err = nil
+				continue
+			}
+			return
+		}
+		users = append(users, userInfo)
+	}
+	return
+}
+
+// renameVol moves the policies and the volume index of the users to newName in memory.
+func (u *User) renameVol(volName, newName string, users []*proto.UserInfo) {
+	if len(users) == 0 {
+		u.volUser.Delete(volName)
+		return
+	}
+	userIDs := make([]string, 0, len(users))
+	for _, userInfo := range users {
+		userInfo.Mu.Lock()
+		userInfo.Policy.RenameVol(volName, newName)
+		userInfo.Mu.Unlock()
+		userIDs = append(userIDs, userInfo.UserID)
+	}
+	u.volUser.Delete(volName)
+	u.volUser.Store(newName, &proto.VolUser{Vol: newName, UserIDs: userIDs})
+}
+
+func copyUserInfo(userInfo *proto.UserInfo) (newInfo *proto.UserInfo, err error) {
+	var data []byte
+	userInfo.Mu.RLock()
+	data, err = json.Marshal(userInfo)
+	userInfo.Mu.RUnlock()
+	if err != nil {
+		return
+	}
+	newInfo = proto.NewUserInfo()
+	if err = json.Unmarshal(data, newInfo); err != nil {
+		return
+	}
+	return
+}
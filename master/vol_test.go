@@ -3,6 +3,7 @@ package master
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -175,6 +176,191 @@ func TestCreateColdVol(t *testing.T) {
 	delVol(volName, t)
 }
 
+func TestVolReadPolicyDefault(t *testing.T) {
+	volName := "readPolicyVol"
+	req := map[string]interface{}{}
+	req[nameKey] = volName
+	req[followerReadKey] = true
+	checkCreateVolParam(nearReadKey, req, "tt", true, t)
+	createVol(req, t)
+
+	view := getSimpleVol(volName, true, t)
+	assert.True(t, view.FollowerRead)
+	assert.True(t, view.NearRead)
+
+	updateReq := map[string]interface{}{
+		nameKey:    volName,
+		volAuthKey: buildAuthKey(testOwner),
+	}
+	checkParam(nearReadKey, proto.AdminUpdateVol, updateReq, "tt", false, t)
+	setUpdateVolParm(nearReadKey, updateReq, false, t)
+	view = getSimpleVol(volName, true, t)
+	assert.True(t, view.FollowerRead)
+	assert.False(t, view.NearRead)
+
+	// the default survives reloading vol from raft store value
+	vol, err := server.cluster.getVol(volName)
+	assert.Nil(t, err)
+	vol.NearRead = true
+	assert.True(t, newVolFromVolValue(newVolValue(vol)).NearRead)
+	vol.NearRead = false
+
+	delVol(volName, t)
+}
+
+func TestRenameVol(t *testing.T) {
+	oldName := "renameVolSrc"
+	newName := "renameVolDst"
+	userID := "renameUser"
+	createVol(map[string]interface{}{nameKey: oldName}, t)
+	vol, err := server.cluster.getVol(oldName)
+	assert.Nil(t, err)
+
+	_, err = server.user.createKey(&proto.UserCreateParam{ID: userID, Type: proto.UserTypeNormal})
+	assert.Nil(t, err)
+	_, err = server.user.updatePolicy(&proto.UserPermUpdateParam{UserID: userID, Volume: oldName, Policy: []string{proto.BuiltinPermissionReadOnly.String()}})
+	assert.Nil(t, err)
+	assert.Nil(t, server.cluster.SetBucketLifecycle(&proto.LcConfiguration{VolName: oldName}))
+
+	req := map[string]interface{}{
+		nameKey:       oldName,
+		volNewNameKey: newName,
+		volAuthKey:    buildAuthKey(testOwner),
+	}
+	// the vol must be forbidden first
+	processWithFatalV2(proto.AdminRenameVol, false, req, t)
+	process(fmt.Sprintf("%v%v?name=%v&%v=true", hostAddr, proto.AdminVolForbidden, oldName, forbiddenKey), t)
+	checkParam(volNewNameKey, proto.AdminRenameVol, req, commonVolName, newName, t)
+	checkParam(volNewNameKey, proto.AdminRenameVol, req, "a", newName, t)
+	checkParam(volAuthKey, proto.AdminRenameVol, req, "wrongKey", buildAuthKey(testOwner), t)
+	processWithFatalV2(proto.AdminRenameVol, true, req, t)
+
+	// memory
+	_, err = server.cluster.getVol(oldName)
+	assert.Equal(t, proto.ErrVolNotExists, err)
+	renamed, err := server.cluster.getVol(newName)
+	assert.Nil(t, err)
+	assert.True(t, renamed == vol)
+	assert.Equal(t, newName, vol.Name)
+	assert.Equal(t, newName, vol.dataPartitions.volName)
+	assert.Equal(t, newName, vol.uidSpaceManager.volName)
+	for _, dp := range vol.cloneDataPartitionMap() {
+		assert.Equal(t, newName, dp.VolName)
+	}
+	for _, mp := range vol.cloneMetaPartitionMap() {
+		assert.Equal(t, newName, mp.volName)
+	}
+	assert.Nil(t, server.cluster.GetBucketLifecycle(oldName))
+	assert.NotNil(t, server.cluster.GetBucketLifecycle(newName))
+	for _, id := range []string{testOwner, userID} {
+		userInfo, err := server.user.getUserInfo(id)
+		assert.Nil(t, err)
+		assert.False(t, userInfo.Policy.IsOwn(oldName))
+		_, exist := userInfo.Policy.AuthorizedVols[oldName]
+		assert.False(t, exist)
+	}
+	owner, _ := server.user.getUserInfo(testOwner)
+	assert.True(t, owner.Policy.IsOwn(newName))
+	user, _ := server.user.getUserInfo(userID)
+	assert.True(t, user.Policy.IsAuthorized(newName, "", proto.OSSGetObjectAction))
+	_, err = server.user.getUsersOfVol(oldName)
+	assert.Equal(t, proto.ErrHaveNoPolicy, err)
+	userIDs, err := server.user.getUsersOfVol(newName)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{testOwner, userID}, userIDs)
+
+	// raft store
+	store := server.cluster.fsm.store
+	result, err := store.SeekForPrefix([]byte(volPrefix + strconv.FormatUint(vol.ID, 10)))
+	assert.Nil(t, err)
+	for _, value := range result {
+		vv := &volValue{}
+		assert.Nil(t, json.Unmarshal(value, vv))
+		assert.Equal(t, newName, vv.Name)
+	}
+	result, err = store.SeekForPrefix([]byte(dataPartitionPrefix + strconv.FormatUint(vol.ID, 10) + keySeparator))
+	assert.Nil(t, err)
+	assert.Equal(t, len(vol.cloneDataPartitionMap()), len(result))
+	for _, value := range result {
+		dpv := &dataPartitionValue{}
+		assert.Nil(t, json.Unmarshal(value, dpv))
+		assert.Equal(t, newName, dpv.VolName)
+	}
+	result, err = store.SeekForPrefix([]byte(metaPartitionPrefix + strconv.FormatUint(vol.ID, 10) + keySeparator))
+	assert.Nil(t, err)
+	assert.Equal(t, len(vol.cloneMetaPartitionMap()), len(result))
+	for _, value := range result {
+		mpv := &metaPartitionValue{}
+		assert.Nil(t, json.Unmarshal(value, mpv))
+		assert.Equal(t, newName, mpv.VolName)
+	}
+	for _, prefix := range []string{volUserPrefix, lcConfPrefix} {
+		result, err = store.SeekForPrefix([]byte(prefix + oldName))
+		assert.Nil(t, err)
+		assert.Empty(t, result)
+		result, err = store.SeekForPrefix([]byte(prefix + newName))
+		assert.Nil(t, err)
+		assert.Len(t, result, 1)
+	}
+	for _, id := range []string{testOwner, userID} {
+		result, err = store.SeekForPrefix([]byte(userPrefix + id))
+		assert.Nil(t, err)
+		for _, value := range result {
+			assert.NotContains(t, string(value), `"`+oldName+`"`)
+		}
+	}
+
+	// partitions reported with the old name are still matched
+	for _, dp := range vol.cloneDataPartitionMap() {
+		found, err := server.cluster.getReportedDataPartition(oldName, dp.PartitionID)
+		assert.Nil(t, err)
+		assert.True(t, found == dp)
+	}
+	for _, mp := range vol.cloneMetaPartitionMap() {
+		foundVol, found, err := server.cluster.getReportedMetaPartition(oldName, mp.PartitionID)
+		assert.Nil(t, err)
+		assert.True(t, foundVol == vol && found == mp)
+	}
+
+	// the old name is free again
+	req[nameKey] = newName
+	req[volNewNameKey] = oldName
+	processWithFatalV2(proto.AdminRenameVol, true, req, t)
+	_, err = server.cluster.getVol(oldName)
+	assert.Nil(t, err)
+	process(fmt.Sprintf("%v%v?name=%v&%v=false", hostAddr, proto.AdminVolForbidden, oldName, forbiddenKey), t)
+	delVol(oldName, t)
+}
+
+func TestRenameVolHoldsVolLock(t *testing.T) {
+	oldName := "renameVolLockSrc"
+	newName := "renameVolLockDst"
+	createVol(map[string]interface{}{nameKey: oldName}, t)
+	process(fmt.Sprintf("%v%v?name=%v&%v=true", hostAddr, proto.AdminVolForbidden, oldName, forbiddenKey), t)
+	vol, err := server.cluster.getVol(oldName)
+	assert.Nil(t, err)
+
+	// an update holding the volume lock finishes before the rename persists anything
+	vol.volLock.Lock()
+	done := make(chan error, 1)
+	go func() {
+		done <- server.cluster.renameVol(oldName, newName, buildAuthKey(testOwner), server.user)
+	}()
+	select {
+	case err = <-done:
+		t.Fatalf("rename finished while the volume is locked, err %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	assert.Equal(t, oldName, vol.Name)
+	vol.volLock.Unlock()
+	assert.Nil(t, <-done)
+	assert.Equal(t, newName, vol.Name)
+	assert.NotEmpty(t, vol.getViewCache())
+
+	process(fmt.Sprintf("%v%v?name=%v&%v=false", hostAddr, proto.AdminVolForbidden, newName, forbiddenKey), t)
+	delVol(newName, t)
+}
+
 func checkCreateVolParam(key string, req map[string]interface{}, wrong, correct interface{}, t *testing.T) {
 	checkParam(key, proto.AdminCreateVol, req, wrong, correct, t)
 }
@@ -416,3 +602,44 @@ func TestConcurrentReadWriteDataPartitionMap(t *testing.T) {
 		vol.updateViewCache(server.cluster)
 	}
 }
+
+func TestVolShouldAutoAllocate(t *testing.T) {
+	name := "TestVolShouldAutoAllocate"
+	vol := newVol(volValue{
+		ID:                1,
+		Name:              name,
+		Owner:             name,
+		DataPartitionSize: util.DefaultDataPartitionSize,
+		Capacity:          100,
+		DpReplicaNum:      defaultReplicaNum,
+		ReplicaNum:        defaultReplicaNum,
+	})
+	dp := newDataPartition(1, 3, name, 1, 0, 0)
+	dp.used = 50 * util.GB
+	vol.dataPartitions.put(dp)
+
+	cfg := newClusterConfig()
+	assert.Equal(t, minNumOfRWDataPartitions, cfg.AutoAllocRwDpFloor)
+	cfg.AutoAllocRwDpFloor = 50
+	for _, c := range []struct {
+		rwCnt  int
+		expect bool
+	}{
+		{0, true},
+		{49, true},
+		{50, false},
+		{51, false},
+	} {
+		vol.dataPartitions.readableAndWritableCnt = c.rwCnt
+		assert.Equal(t, c.expect, vol.shouldAutoAllocate(cfg), "rw count %v", c.rwCnt)
+	}
+
+	// half of the capacity is used
+	vol.dataPartitions.readableAndWritableCnt = 10
+	cfg.AutoAllocUsedRatioCeiling = 0.6
+	assert.True(t, vol.shouldAutoAllocate(cfg))
+	cfg.AutoAllocUsedRatioCeiling = 0.5
+	assert.False(t, vol.shouldAutoAllocate(cfg))
+
+	assert.Equal(t, 50, vol.calculateExpansionNum(cfg.AutoAllocRwDpFloor))
+}
@@ -23,6 +23,10 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/cubefs/cubefs/cmd/common"
 	"github.com/cubefs/cubefs/proto"
@@ -75,8 +79,24 @@ func (m *MetaNode) registerAPIHandler() (err error) {
 	http.HandleFunc("/genClusterVersionFile", m.genClusterVersionFileHandler)
 	http.HandleFunc("/getInodeSnapshot", m.getInodeSnapshotHandler)
 	http.HandleFunc("/getDentrySnapshot", m.getDentrySnapshotHandler)
+	// get the snapshot versions of a dentry
+	http.HandleFunc("/getDentryVersions", m.getDentryVersionsHandler)
 	// get tx information
 	http.HandleFunc("/getTx", m.getTxHandler)
+	// get the shape of the inode and dentry trees
+	http.HandleFunc("/treeStats", m.getTreeStatsHandler)
+	// get the access recency and frequency of directories and volumes
+	http.HandleFunc("/getAccessStats", m.getAccessStatsHandler)
+	http.HandleFunc("/getVolAccessStats", m.getVolAccessStatsHandler)
+	// resolve the paths of an inode, disabled by default since it scans the dentry tree
+	http.HandleFunc("/resolveInodePath", m.resolveInodePathHandler)
+	http.HandleFunc("/checkConsistency", m.checkConsistencyHandler)
+	http.HandleFunc("/setSlowOpThreshold", m.setSlowOpThresholdHandler)
+	http.HandleFunc("/getSlowOpStat", m.getSlowOpStatHandler)
+	http.HandleFunc("/getInodeAllocStat", m.getInodeAllocStatHandler)
+	http.HandleFunc("/setHeavyOpLimit", m.setHeavyOpLimitHandler)
+	http.HandleFunc("/getHeavyOpLimit", m.getHeavyOpLimitHandler)
+	http.HandleFunc("/evictInodeCache", m.evictInodeCacheHandler)
 	return
 }
 
@@ -147,6 +167,259 @@ func (m *MetaNode) getPartitionByIDHandler(w http.ResponseWriter, r *http.Reques
 	resp.Msg = http.StatusText(http.StatusOK)
 }
 
+func (m *MetaNode) getTreeStatsHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getTreeStatsHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	if err := parseArgs(r, pid.PID()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Data = map[string]interface{}{
+		"inode":  mp.GetInodeTree().Stats(treeStatsMaxNodes),
+		"dentry": mp.GetDentryTree().Stats(treeStatsMaxNodes),
+	}
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
+func (m *MetaNode) getAccessStatsHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getAccessStatsHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	var limit common.Int
+	if err := parseArgs(r, pid.PID(), limit.Key("limit").OmitEmpty()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Data = mp.GetAccessStats(int(limit.V))
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
+func (m *MetaNode) getVolAccessStatsHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getVolAccessStatsHandler] response %s", err)
+		}
+	}()
+	var name common.String
+	if err := parseArgs(r, name.Key("name")); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	stats := &VolAccessStats{VolName: name.V}
+	m.metadataManager.Range(true, func(_ uint64, mp MetaPartition) bool {
+		if mp.GetVolName() == name.V {
+			stats.merge(mp.GetAccessStats(-1))
+		}
+		return true
+	})
+	if stats.Partitions == 0 {
+		resp.Code = http.StatusNotFound
+		resp.Msg = fmt.Sprintf("no meta partition of vol %v", name.V)
+		return
+	}
+	resp.Data = stats
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
+// setSlowOpThresholdHandler sets the slow op threshold in milliseconds of a partition,
+// or of all the loaded partitions if pid is omitted.
+func (m *MetaNode) setSlowOpThresholdHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[setSlowOpThresholdHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	var threshold common.Int
+	if err := parseArgs(r, pid.PID().OmitEmpty(), threshold.Key("threshold")); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if threshold.V < 0 {
+		resp.Msg = fmt.Sprintf("invalid threshold %v", threshold.V)
+		return
+	}
+	stats := make([]*SlowOpStat, 0)
+	if pid.V != 0 {
+		mp, err := m.metadataManager.GetPartition(pid.V)
+		if err != nil {
+			resp.Code = http.StatusNotFound
+			resp.Msg = err.Error()
+			return
+		}
+		mp.SetSlowOpThreshold(time.Duration(threshold.V) * time.Millisecond)
+		stats = append(stats, mp.GetSlowOpStat())
+	} else {
+		m.metadataManager.Range(true, func(_ uint64, mp MetaPartition) bool {
+			mp.SetSlowOpThreshold(time.Duration(threshold.V) * time.Millisecond)
+			stats = append(stats, mp.GetSlowOpStat())
+			return true
+		})
+	}
+	log.LogInfof("[setSlowOpThresholdHandler] set slow op threshold of %v partitions to %vms", len(stats), threshold.V)
+	resp.Data = stats
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
+func (m *MetaNode) getSlowOpStatHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getSlowOpStatHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	if err := parseArgs(r, pid.PID()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Data = mp.GetSlowOpStat()
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
+// setHeavyOpLimitHandler sets the limit in ops per second of an op class for each partition, 0 is unlimited.
+func (m *MetaNode) setHeavyOpLimitHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[setHeavyOpLimitHandler] response %s", err)
+		}
+	}()
+	var op common.String
+	var limit common.Int
+	if err := parseArgs(r, op.Key("op"), limit.Key("limit")); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	if err := updateHeavyOpLimit(op.V, limit.V); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	log.LogInfof("[setHeavyOpLimitHandler] set the limit of op class %v to %v", op.V, limit.V)
+	resp.Data = HeavyOpLimits()
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
+func (m *MetaNode) getHeavyOpLimitHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
+	resp.Data = HeavyOpLimits()
+	data, _ := resp.Marshal()
+	if _, err := w.Write(data); err != nil {
+		log.LogErrorf("[getHeavyOpLimitHandler] response %s", err)
+	}
+}
+
+// evictInodeCacheHandler evicts the data cached of the inodes of a cold volume partition, of the listed
+// inodes, or of the inodes not accessed within ttl seconds, or both.
+func (m *MetaNode) evictInodeCacheHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[evictInodeCacheHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	var inodeList common.String
+	var ttl common.Int
+	if err := parseArgs(r, pid.PID(), inodeList.Key("inodes").OmitEmpty(), ttl.Key("ttl").OmitEmpty()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	inodes := make([]uint64, 0)
+	for _, field := range strings.Split(inodeList.V, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		ino, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			resp.Msg = fmt.Sprintf("invalid inode %v", field)
+			return
+		}
+		inodes = append(inodes, ino)
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	evicted, err := mp.EvictInodeCache(inodes, time.Duration(ttl.V)*time.Second)
+	resp.Data = map[string]int{"evicted": evicted}
+	if err != nil {
+		resp.Code = http.StatusInternalServerError
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
+func (m *MetaNode) getInodeAllocStatHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getInodeAllocStatHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	if err := parseArgs(r, pid.PID()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Data = mp.GetInodeAllocStat()
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
 func (m *MetaNode) getLeaderPartitionsHandler(w http.ResponseWriter, r *http.Request) {
 	resp := NewAPIResponse(http.StatusOK, http.StatusText(http.StatusOK))
 	mps := m.metadataManager.GetLeaderPartitions()
@@ -476,6 +749,104 @@ func (m *MetaNode) getDentryHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (m *MetaNode) getDentryVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[getDentryVersionsHandler] response %s", err)
+		}
+	}()
+	var pid, pIno common.Uint
+	var name common.String
+	if err := parseArgs(r, pid.PID(), pIno.ParentIno(), name.Key("name")); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	versions, ok := mp.GetDentryVersions(pIno.V, name.V)
+	if !ok {
+		resp.Code = http.StatusNotFound
+		resp.Msg = fmt.Sprintf("dentry parentIno(%v) name(%v) not found", pIno.V, name.V)
+		return
+	}
+	resp.Data = versions
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
+func (m *MetaNode) resolveInodePathHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[resolveInodePathHandler] response %s", err)
+		}
+	}()
+	if !m.enableInodePathResolve {
+		resp.Code = http.StatusForbidden
+		resp.Msg = fmt.Sprintf("resolving inode path is disabled, set %v to enable it", cfgEnableInodePathResolve)
+		return
+	}
+	var pid, ino common.Uint
+	var limit common.Int
+	if err := parseArgs(r, pid.PID(), ino.Ino(), limit.Key("limit").OmitEmpty()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	resp.Data = mp.ResolveInodePaths(ino.V, int(limit.V))
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
+// checkConsistencyHandler checks the dentries against the inodes of a partition, only one check runs at a time
+// since it scans the whole partition.
+func (m *MetaNode) checkConsistencyHandler(w http.ResponseWriter, r *http.Request) {
+	resp := NewAPIResponse(http.StatusBadRequest, "")
+	defer func() {
+		data, _ := resp.Marshal()
+		if _, err := w.Write(data); err != nil {
+			log.LogErrorf("[checkConsistencyHandler] response %s", err)
+		}
+	}()
+	var pid common.Uint
+	if err := parseArgs(r, pid.PID()); err != nil {
+		resp.Msg = err.Error()
+		return
+	}
+	mp, err := m.metadataManager.GetPartition(pid.V)
+	if err != nil {
+		resp.Code = http.StatusNotFound
+		resp.Msg = err.Error()
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&m.consistencyChecking, 0, 1) {
+		resp.Code = http.StatusTooManyRequests
+		resp.Msg = "another consistency check is running"
+		return
+	}
+	defer atomic.StoreInt32(&m.consistencyChecking, 0)
+	report := mp.checkConsistency(r.Context())
+	if len(report.Anomalies) > 0 {
+		log.LogWarnf("[checkConsistencyHandler] mp(%v) found %v anomalies, truncated(%v)",
+			pid.V, len(report.Anomalies), report.Truncated)
+	}
+	resp.Data = report
+	resp.Code = http.StatusOK
+	resp.Msg = http.StatusText(http.StatusOK)
+}
+
 func (m *MetaNode) getTxHandler(w http.ResponseWriter, r *http.Request) {
 	resp := NewAPIResponse(http.StatusBadRequest, "")
 	defer func() {
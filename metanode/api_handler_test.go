@@ -1,15 +1,21 @@
 package metanode
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path"
 	"testing"
+	"time"
 
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/btree"
 	"github.com/stretchr/testify/require"
 )
 
@@ -159,3 +165,404 @@ func TestWithWrongMetaPartitionID(t *testing.T) {
 	data := httpReqHandle(url, t)
 	require.Contains(t, string(data), "unknown meta partition")
 }
+
+func TestGetTreeStats(t *testing.T) {
+	testPath := "/tmp/testMetaNodeApiHandler/"
+	os.RemoveAll(testPath)
+	defer os.RemoveAll(testPath)
+
+	mp := createMetaPartition(testPath, t)
+	require.NotNil(t, mp)
+	for ino := uint64(2); ino <= 1000; ino++ {
+		mp.inodeTree.ReplaceOrInsert(NewInode(ino, 0), true)
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%v%v?pid=%v",
+		PROF_PORT, "/treeStats", METAPARTITION_ID)
+	resp := &struct {
+		Code int
+		Data map[string]btree.Stats
+	}{}
+	require.NoError(t, json.Unmarshal(httpReqHandle(url, t), resp))
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	inodeStats := resp.Data["inode"]
+	require.Equal(t, 1000, inodeStats.Items)
+	require.Equal(t, defaultBTreeDegree, inodeStats.Degree)
+	require.Equal(t, 2, inodeStats.Height)
+	require.True(t, inodeStats.Nodes > 1)
+	require.True(t, inodeStats.FillFactor > 0 && inodeStats.FillFactor <= 1)
+	require.Equal(t, 1, resp.Data["dentry"].Items)
+	require.Equal(t, 1, resp.Data["dentry"].Height)
+}
+
+func TestGetAccessStats(t *testing.T) {
+	testPath := "/tmp/testMetaNodeApiHandler/"
+	os.RemoveAll(testPath)
+	defer os.RemoveAll(testPath)
+
+	mp := createMetaPartition(testPath, t)
+	require.NotNil(t, mp)
+	mp.accessStats = newAccessStats(defaultAccessStatsWindow, 1, defaultAccessStatsMaxDirs)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, mp.Lookup(&LookupReq{PartitionID: METAPARTITION_ID, ParentID: 0, Name: "/"}, &Packet{}))
+	}
+	require.NoError(t, mp.ReadDir(&ReadDirReq{PartitionID: METAPARTITION_ID, ParentID: 1}, &Packet{}))
+
+	url := fmt.Sprintf("http://127.0.0.1:%v%v?pid=%v",
+		PROF_PORT, "/getAccessStats", METAPARTITION_ID)
+	resp := &struct {
+		Code int
+		Data *AccessStatsReport
+	}{}
+	require.NoError(t, json.Unmarshal(httpReqHandle(url, t), resp))
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, uint64(6), resp.Data.Accesses)
+	require.Len(t, resp.Data.Dirs, 2)
+	require.Equal(t, uint64(1), resp.Data.Dirs[0].Inode)
+	require.Equal(t, uint64(1), resp.Data.Dirs[0].Accesses)
+	require.Equal(t, uint64(0), resp.Data.Dirs[1].Inode)
+	require.Equal(t, uint64(5), resp.Data.Dirs[1].Accesses)
+
+	url = fmt.Sprintf("http://127.0.0.1:%v%v?name=%v",
+		PROF_PORT, "/getVolAccessStats", mp.config.VolName)
+	volResp := &struct {
+		Code int
+		Data *VolAccessStats
+	}{}
+	require.NoError(t, json.Unmarshal(httpReqHandle(url, t), volResp))
+	require.Equal(t, http.StatusOK, volResp.Code)
+	require.Equal(t, 1, volResp.Data.Partitions)
+	require.Equal(t, uint64(6), volResp.Data.Accesses)
+	require.Equal(t, resp.Data.LastAccess, volResp.Data.LastAccess)
+}
+
+func TestGetDentryVersions(t *testing.T) {
+	testPath := "/tmp/testMetaNodeApiHandler/"
+	os.RemoveAll(testPath)
+	defer os.RemoveAll(testPath)
+
+	mp := createMetaPartition(testPath, t)
+	require.NotNil(t, mp)
+	// a dentry recreated with another inode after a snapshot
+	d := &Dentry{ParentId: 1, Name: "versioned", Inode: 9, Type: FileModeType, multiSnap: NewDentrySnap(3)}
+	d.addVersion(5)
+	d.Inode = 10
+	mp.dentryTree.ReplaceOrInsert(d, true)
+	mp.dentryTree.ReplaceOrInsert(&Dentry{ParentId: 1, Name: "plain", Inode: 11, Type: FileModeType}, true)
+
+	getVersions := func(name string) (code int, versions []proto.DetryInfo) {
+		url := fmt.Sprintf("http://127.0.0.1:%v%v?pid=%v&parentIno=1&name=%v",
+			PROF_PORT, "/getDentryVersions", METAPARTITION_ID, name)
+		resp := &struct {
+			Code int
+			Data []proto.DetryInfo
+		}{}
+		require.NoError(t, json.Unmarshal(httpReqHandle(url, t), resp))
+		return resp.Code, resp.Data
+	}
+
+	code, versions := getVersions("versioned")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []proto.DetryInfo{
+		{Inode: 10, Mode: FileModeType, VerSeq: 5},
+		{Inode: 9, Mode: FileModeType, VerSeq: 3},
+	}, versions)
+
+	// the dentry without snapshot
+	code, versions = getVersions("plain")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []proto.DetryInfo{{Inode: 11, Mode: FileModeType}}, versions)
+
+	code, _ = getVersions("missing")
+	require.Equal(t, http.StatusNotFound, code)
+}
+
+func TestResolveInodePath(t *testing.T) {
+	testPath := "/tmp/testMetaNodeApiHandler/"
+	os.RemoveAll(testPath)
+	defer os.RemoveAll(testPath)
+
+	mp := createMetaPartition(testPath, t)
+	require.NotNil(t, mp)
+	// /a/b/f, hard linked by /link, and x whose parent 50 is on another partition
+	for _, d := range []*Dentry{
+		{ParentId: 1, Name: "a", Inode: 2, Type: uint32(os.ModeDir)},
+		{ParentId: 2, Name: "b", Inode: 3, Type: uint32(os.ModeDir)},
+		{ParentId: 3, Name: "f", Inode: 4, Type: FileModeType},
+		{ParentId: 1, Name: "link", Inode: 4, Type: FileModeType},
+		{ParentId: 50, Name: "x", Inode: 6, Type: FileModeType},
+	} {
+		mp.dentryTree.ReplaceOrInsert(d, true)
+	}
+
+	resolve := func(ino uint64, limit int) (code int, result *InodePaths) {
+		url := fmt.Sprintf("http://127.0.0.1:%v%v?pid=%v&ino=%v&limit=%v",
+			PROF_PORT, "/resolveInodePath", METAPARTITION_ID, ino, limit)
+		resp := &struct {
+			Code int
+			Data *InodePaths
+		}{}
+		require.NoError(t, json.Unmarshal(httpReqHandle(url, t), resp))
+		return resp.Code, resp.Data
+	}
+
+	// disabled by default
+	code, _ := resolve(4, 0)
+	require.Equal(t, http.StatusForbidden, code)
+
+	server.enableInodePathResolve = true
+	defer func() { server.enableInodePathResolve = false }()
+
+	code, result := resolve(4, 0)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []string{"/a/b/f", "/link"}, result.Paths)
+	require.Empty(t, result.PartialPaths)
+	require.False(t, result.Truncated)
+	// one scan for each level: 4, 3 and 2
+	dentries := mp.GetDentryTreeLen()
+	require.Equal(t, 3*dentries, result.Scanned)
+
+	_, result = resolve(6, 0)
+	require.Empty(t, result.Paths)
+	require.Equal(t, []string{"ino(50)/x"}, result.PartialPaths)
+
+	_, result = resolve(1, 0)
+	require.Equal(t, []string{"/"}, result.Paths)
+	require.Equal(t, 0, result.Scanned)
+
+	// the scan is bounded by the limit
+	_, result = resolve(4, dentries+1)
+	require.True(t, result.Truncated)
+	require.Equal(t, dentries+1, result.Scanned)
+	require.Equal(t, []string{"/link"}, result.Paths)
+	require.Equal(t, []string{"ino(3)/f"}, result.PartialPaths)
+}
+
+func TestCheckConsistency(t *testing.T) {
+	testPath := "/tmp/testMetaNodeApiHandler/"
+	os.RemoveAll(testPath)
+	defer os.RemoveAll(testPath)
+
+	mp := createMetaPartition(testPath, t)
+	require.NotNil(t, mp)
+	dir := func(ino uint64, nlink uint32) *Inode {
+		i := NewInode(ino, uint32(os.ModeDir))
+		i.NLink = nlink
+		return i
+	}
+	deleted := NewInode(13, FileModeType)
+	deleted.SetDeleteMark()
+	// 12 is missing, 14 is linked twice with nlink 1, 20 has 2 children but nlink 2
+	for _, ino := range []*Inode{dir(10, 6), NewInode(11, FileModeType), deleted, NewInode(14, FileModeType), dir(20, 2)} {
+		mp.inodeTree.ReplaceOrInsert(ino, true)
+	}
+	for _, d := range []*Dentry{
+		{ParentId: 10, Name: "f", Inode: 11, Type: FileModeType},
+		{ParentId: 10, Name: "g", Inode: 12, Type: FileModeType},
+		{ParentId: 10, Name: "l1", Inode: 14, Type: FileModeType},
+		{ParentId: 10, Name: "remote", Inode: 500, Type: FileModeType},
+		{ParentId: 20, Name: "h", Inode: 13, Type: FileModeType},
+		{ParentId: 20, Name: "l2", Inode: 14, Type: FileModeType},
+	} {
+		mp.dentryTree.ReplaceOrInsert(d, true)
+	}
+
+	check := func() (code int, report *ConsistencyReport) {
+		url := fmt.Sprintf("http://127.0.0.1:%v%v?pid=%v", PROF_PORT, "/checkConsistency", METAPARTITION_ID)
+		resp := &struct {
+			Code int
+			Data *ConsistencyReport
+		}{}
+		require.NoError(t, json.Unmarshal(httpReqHandle(url, t), resp))
+		return resp.Code, resp.Data
+	}
+
+	code, report := check()
+	require.Equal(t, http.StatusOK, code)
+	require.False(t, report.Canceled)
+	require.False(t, report.Truncated)
+	require.Equal(t, mp.GetDentryTreeLen(), report.Dentries)
+	require.ElementsMatch(t, []*ConsistencyAnomaly{
+		{Type: AnomalyDanglingDentry, ParentId: 10, Name: "g", Inode: 12},
+		{Type: AnomalyDeletedInode, ParentId: 20, Name: "h", Inode: 13},
+		{Type: AnomalyNLinkMismatch, Inode: 14, NLink: 1, Expect: 2},
+		{Type: AnomalyNLinkMismatch, Inode: 20, NLink: 2, Expect: 4},
+	}, report.Anomalies)
+
+	// only one check runs at a time
+	server.consistencyChecking = 1
+	code, _ = check()
+	require.Equal(t, http.StatusTooManyRequests, code)
+	server.consistencyChecking = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.True(t, mp.checkConsistency(ctx).Canceled)
+}
+
+func TestSlowOpTrace(t *testing.T) {
+	testPath := "/tmp/testMetaNodeApiHandler/"
+	os.RemoveAll(testPath)
+	defer os.RemoveAll(testPath)
+
+	mp := createMetaPartition(testPath, t)
+	require.NotNil(t, mp)
+	metaM := server.metadataManager.(*metadataManager)
+
+	stat := func(path string) (code int, data json.RawMessage) {
+		url := fmt.Sprintf("http://127.0.0.1:%v%v", PROF_PORT, path)
+		resp := &struct {
+			Code int
+			Data json.RawMessage
+		}{}
+		require.NoError(t, json.Unmarshal(httpReqHandle(url, t), resp))
+		return resp.Code, resp.Data
+	}
+	code, _ := stat(fmt.Sprintf("/setSlowOpThreshold?pid=%v&threshold=-1", METAPARTITION_ID))
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = stat(fmt.Sprintf("/setSlowOpThreshold?pid=%v&threshold=20", INVALID_METAPARTITION_ID))
+	require.Equal(t, http.StatusNotFound, code)
+	code, _ = stat("/setSlowOpThreshold?threshold=20")
+	require.Equal(t, http.StatusOK, code)
+
+	handle := func(delay time.Duration) bool {
+		p := &Packet{}
+		p.Opcode = proto.OpMetaLookup
+		p.PartitionID = METAPARTITION_ID
+		args := []byte(`{"pid":1,"ino":1,"name":"f"}`)
+		start := time.Now()
+		// the artificially slow handler
+		time.Sleep(delay)
+		return metaM.traceSlowOp(p, args, "127.0.0.1", time.Since(start))
+	}
+	require.False(t, handle(0))
+	require.True(t, handle(50*time.Millisecond))
+
+	code, data := stat(fmt.Sprintf("/getSlowOpStat?pid=%v", METAPARTITION_ID))
+	require.Equal(t, http.StatusOK, code)
+	s := &SlowOpStat{}
+	require.NoError(t, json.Unmarshal(data, s))
+	require.Equal(t, &SlowOpStat{PartitionID: METAPARTITION_ID, ThresholdMs: 20, Count: 1}, s)
+
+	// zero disables the tracing
+	code, _ = stat(fmt.Sprintf("/setSlowOpThreshold?pid=%v&threshold=0", METAPARTITION_ID))
+	require.Equal(t, http.StatusOK, code)
+	require.False(t, handle(50*time.Millisecond))
+	require.Equal(t, uint64(1), mp.GetSlowOpStat().Count)
+}
+
+func TestHeavyOpLimit(t *testing.T) {
+	testPath := "/tmp/testMetaNodeApiHandler/"
+	os.RemoveAll(testPath)
+	defer os.RemoveAll(testPath)
+	defer updateHeavyOpLimit(heavyOpReadDir, 0)
+
+	mp := createMetaPartition(testPath, t)
+	require.NotNil(t, mp)
+	metaM := server.metadataManager.(*metadataManager)
+
+	set := func(op string, limit int) int {
+		url := fmt.Sprintf("http://127.0.0.1:%v/setHeavyOpLimit?op=%v&limit=%v", PROF_PORT, op, limit)
+		resp := &struct{ Code int }{}
+		require.NoError(t, json.Unmarshal(httpReqHandle(url, t), resp))
+		return resp.Code
+	}
+	require.Equal(t, http.StatusBadRequest, set("lookup", 5))
+	require.Equal(t, http.StatusBadRequest, set(heavyOpReadDir, -1))
+	require.Equal(t, http.StatusOK, set(heavyOpReadDir, 5))
+	require.Equal(t, map[string]int64{heavyOpReadDir: 5, heavyOpListXAttr: 0, heavyOpBatchInodeGet: 0}, HeavyOpLimits())
+
+	conn, peer := net.Pipe()
+	defer conn.Close()
+	go io.Copy(io.Discard, peer)
+	flood := func(opcode uint8) (throttled int) {
+		for i := 0; i < 100; i++ {
+			p := &Packet{}
+			p.Opcode = opcode
+			p.PartitionID = METAPARTITION_ID
+			if metaM.throttleHeavyOp(conn, p) {
+				require.Equal(t, proto.OpAgain, p.ResultCode)
+				throttled++
+			}
+		}
+		return
+	}
+	// the burst passes, then the flood is throttled
+	throttled := flood(proto.OpMetaReadDirLimit)
+	require.Greater(t, throttled, 80)
+	require.LessOrEqual(t, throttled, 95)
+	// the other classes and the light ops are not limited
+	require.Zero(t, flood(proto.OpMetaListXAttr))
+	require.Zero(t, flood(proto.OpMetaLookup))
+
+	require.Equal(t, http.StatusOK, set(heavyOpReadDir, 0))
+	require.Zero(t, flood(proto.OpMetaReadDir))
+}
+
+func TestInodeAllocStat(t *testing.T) {
+	testPath := "/tmp/testMetaNodeApiHandler/"
+	os.RemoveAll(testPath)
+	defer os.RemoveAll(testPath)
+
+	mp := createMetaPartition(testPath, t)
+	require.NotNil(t, mp)
+
+	// 40 of the ids [0, 100] are allocated, 10 of them in the last 10 seconds
+	now := time.Now()
+	mp.config.Cursor = 30
+	mp.sampleInodeAlloc(now.Add(-10 * time.Second))
+	mp.config.Cursor = 40
+	mp.sampleInodeAlloc(now)
+
+	url := fmt.Sprintf("http://127.0.0.1:%v/getInodeAllocStat?pid=%v", PROF_PORT, METAPARTITION_ID)
+	resp := &struct {
+		Code int
+		Data *InodeAllocStat
+	}{}
+	require.NoError(t, json.Unmarshal(httpReqHandle(url, t), resp))
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, &InodeAllocStat{
+		PartitionID:    METAPARTITION_ID,
+		Start:          0,
+		End:            100,
+		Cursor:         40,
+		Allocated:      40,
+		Free:           60,
+		LargestFreeRun: 60,
+		AllocRate:      1,
+		ExhaustInSec:   60,
+	}, resp.Data)
+
+	// no allocation since the last sample
+	mp.sampleInodeAlloc(now.Add(10 * time.Second))
+	stat := mp.GetInodeAllocStat()
+	require.Zero(t, stat.AllocRate)
+	require.EqualValues(t, -1, stat.ExhaustInSec)
+
+	url = fmt.Sprintf("http://127.0.0.1:%v/getInodeAllocStat?pid=%v", PROF_PORT, INVALID_METAPARTITION_ID)
+	require.NoError(t, json.Unmarshal(httpReqHandle(url, t), resp))
+	require.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestUnknownOpReply(t *testing.T) {
+	if proto.Buffers == nil {
+		proto.InitBufferPool(int64(32768))
+	}
+	m := &metadataManager{partitions: make(map[uint64]MetaPartition)}
+	conn, peer := net.Pipe()
+	defer conn.Close()
+	defer peer.Close()
+
+	p := &Packet{}
+	p.Magic = proto.ProtoMagic
+	p.Opcode = 0xFE
+	p.ReqID = 10
+	p.PartitionID = METAPARTITION_ID
+	go m.HandleMetadataOperation(conn, p, "127.0.0.1:1")
+	reply := proto.NewPacket()
+	require.NoError(t, reply.ReadFromConnWithVer(peer, proto.ReadDeadlineTime))
+	require.Equal(t, int64(10), reply.ReqID)
+	require.Equal(t, proto.OpArgMismatchErr, reply.ResultCode)
+	require.Contains(t, string(reply.Data), proto.ErrVersionMismatch.Error())
+}
@@ -95,6 +95,13 @@ func (b *BTree) Execute(fn func(tree *btree.BTree) interface{}) interface{} {
 	return fn(b.tree)
 }
 
+// Stats returns the shape of the btree, see btree.Stats for maxNodes.
+func (b *BTree) Stats(maxNodes int) btree.Stats {
+	b.RLock()
+	defer b.RUnlock()
+	return b.tree.Stats(maxNodes)
+}
+
 // ReplaceOrInsert is the wrapper of google's btree ReplaceOrInsert.
 func (b *BTree) ReplaceOrInsert(key BtreeItem, replace bool) (item BtreeItem, ok bool) {
 	b.Lock()
@@ -133,6 +140,13 @@ func (b *BTree) AscendRange(greaterOrEqual, lessThan BtreeItem, iterator func(i
 	b.RUnlock()
 }
 
+// DescendRange is the wrapper of the google's btree DescendRange.
+func (b *BTree) DescendRange(lessOrEqual, greaterThan BtreeItem, iterator func(i BtreeItem) bool) {
+	b.RLock()
+	b.tree.DescendRange(lessOrEqual, greaterThan, iterator)
+	b.RUnlock()
+}
+
 // AscendGreaterOrEqual is the wrapper of the google's btree AscendGreaterOrEqual
 func (b *BTree) AscendGreaterOrEqual(pivot BtreeItem, iterator func(i BtreeItem) bool) {
 	b.RLock()
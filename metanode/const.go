@@ -70,6 +70,10 @@ type (
 	LookupReq = proto.LookupRequest
 	// Client -> MetaNode lookup
 	LookupResp = proto.LookupResponse
+	// Client -> MetaNode batch lookup
+	BatchLookupReq = proto.BatchLookupRequest
+	// MetaNode -> Client batch lookup
+	BatchLookupResp = proto.BatchLookupResponse
 	// Client -> MetaNode
 	InodeGetReq = proto.InodeGetRequest
 	// Tool -> MetaNode
@@ -187,6 +191,8 @@ const (
 	opFSMStoreTickV1  = 72
 
 	opFSMVerListSnapShot = 73
+
+	opFSMExtentsMerge = 74
 )
 
 var (
@@ -220,6 +226,12 @@ const (
 	cfgRetainLogs                = "retainLogs"                // string, raft RetainLogs
 	cfgRaftSyncSnapFormatVersion = "raftSyncSnapFormatVersion" // int, format version of snapshot that raft leader sent to follower
 	cfgServiceIDKey              = "serviceIDKey"
+	cfgQuotaSoftThreshold        = "quotaSoftThreshold"     // int, percentage of the quota limits to report near limit
+	cfgMaxDentryNameLen          = "maxDentryNameLen"       // int, max bytes of a dentry name
+	cfgEnableInodePathResolve    = "enableInodePathResolve" // bool, enable the api to resolve the paths of an inode
+	cfgReadDirOpLimit            = "readDirOpLimit"         // int, readdir ops per second of a partition, 0 is unlimited
+	cfgListXAttrOpLimit          = "listXAttrOpLimit"       // int, listxattr ops per second of a partition, 0 is unlimited
+	cfgBatchInodeGetOpLimit      = "batchInodeGetOpLimit"   // int, batch inode get ops per second of a partition, 0 is unlimited
 
 	metaNodeDeleteBatchCountKey = "batchCount"
 	configNameResolveInterval   = "nameResolveInterval" // int
@@ -235,6 +247,9 @@ const (
 	defaultQuotaSwitch           = true
 	DefaultNameResolveInterval   = 1 // minutes
 	DefaultRaftNumOfLogsToRetain = 20000 * 2
+
+	// max nodes visited per level by the treeStats API, larger levels are sampled
+	treeStatsMaxNodes = 4096
 )
 
 const (
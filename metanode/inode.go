@@ -1631,6 +1631,37 @@ func (i *Inode) AppendExtentWithCheck(param *AppendExtParam) (delExtents []proto
 	return
 }
 
+// MergeExtents replaces the adjacent extent keys with the merged one. The inodes with snapshot versions are
+// refused, for a split key the refs of the replaced keys but the one kept by the merged key are released.
+func (i *Inode) MergeExtents(mpId uint64, merged proto.ExtentKey, keys []proto.ExtentKey) (status uint8) {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.getLayerLen() > 0 {
+		log.LogWarnf("action[MergeExtents] mpId[%v] inode[%v] has %v snapshot versions", mpId, i.Inode, i.getLayerLen())
+		return proto.OpNotPerm
+	}
+
+	checkRef := func(ek *proto.ExtentKey, cnt int) bool {
+		if i.multiSnap == nil || i.multiSnap.ekRefMap == nil {
+			return false
+		}
+		val, ok := i.multiSnap.ekRefMap.Load(ek.PartitionId<<32 | ek.ExtentId)
+		return ok && val.(uint32) >= uint32(cnt)
+	}
+	replaced, status := i.Extents.Merge(merged, keys, checkRef)
+	if status != proto.OpOk {
+		return
+	}
+	if replaced[0].IsSplit() {
+		for idx := 1; idx < len(replaced); idx++ {
+			i.DecSplitEk(mpId, &replaced[idx])
+		}
+	}
+	log.LogDebugf("action[MergeExtents] mpId[%v] inode[%v] keys %v merged into %v", mpId, i.Inode, replaced, merged)
+	return
+}
+
 func (i *Inode) ExtentsTruncate(length uint64, ct int64, doOnLastKey func(*proto.ExtentKey), insertRefMap func(ek *proto.ExtentKey)) (delExtents []proto.ExtentKey) {
 	delExtents = i.Extents.Truncate(length, doOnLastKey, insertRefMap)
 	i.Size = length
@@ -53,6 +53,7 @@ type MetadataManager interface {
 	HandleMetadataOperation(conn net.Conn, p *Packet, remoteAddr string) error
 	GetPartition(id uint64) (MetaPartition, error)
 	GetLeaderPartitions() map[uint64]MetaPartition
+	Range(needLock bool, f func(i uint64, p MetaPartition) bool)
 	checkVolVerList() (err error)
 }
 
@@ -119,6 +120,7 @@ func (m *metadataManager) getPacketLabels(p *Packet) (labels map[string]string)
 // HandleMetadataOperation handles the metadata operations.
 func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remoteAddr string) (err error) {
 	start := time.Now()
+	args := p.Data
 	if log.EnableInfo() {
 		log.LogInfof("HandleMetadataOperation input info op (%s), data %s, remote %s", p.String(), string(p.Data), remoteAddr)
 	}
@@ -127,6 +129,7 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 	labels := m.getPacketLabels(p)
 	defer func() {
 		metric.SetWithLabels(err, labels)
+		m.traceSlowOp(p, args, remoteAddr, time.Since(start))
 		if err != nil {
 			log.LogWarnf("HandleMetadataOperation output (%s), remote %s, err %s", p.String(), remoteAddr, err.Error())
 			return
@@ -138,6 +141,10 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		}
 	}()
 
+	if m.throttleHeavyOp(conn, p) {
+		return
+	}
+
 	switch p.Opcode {
 	case proto.OpMetaCreateInode:
 		err = m.opCreateInode(conn, p, remoteAddr)
@@ -179,6 +186,8 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		err = m.opMetaExtentsAdd(conn, p, remoteAddr)
 	case proto.OpMetaExtentAddWithCheck:
 		err = m.opMetaExtentAddWithCheck(conn, p, remoteAddr)
+	case proto.OpMetaExtentsMerge:
+		err = m.opMetaExtentsMerge(conn, p, remoteAddr)
 	case proto.OpMetaExtentsList:
 		err = m.opMetaExtentsList(conn, p, remoteAddr)
 	case proto.OpMetaObjExtentsList:
@@ -189,6 +198,8 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		err = m.opMetaExtentsTruncate(conn, p, remoteAddr)
 	case proto.OpMetaLookup:
 		err = m.opMetaLookup(conn, p, remoteAddr)
+	case proto.OpMetaBatchLookup:
+		err = m.opMetaBatchLookup(conn, p, remoteAddr)
 	case proto.OpDeleteMetaPartition:
 		err = m.opDeleteMetaPartition(conn, p, remoteAddr)
 	case proto.OpUpdateMetaPartition:
@@ -228,6 +239,8 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 		err = m.opMetaBatchGetXAttr(conn, p, remoteAddr)
 	case proto.OpMetaRemoveXAttr:
 		err = m.opMetaRemoveXAttr(conn, p, remoteAddr)
+	case proto.OpMetaBatchRemoveXAttr:
+		err = m.opMetaBatchRemoveXAttr(conn, p, remoteAddr)
 	case proto.OpMetaListXAttr:
 		err = m.opMetaListXAttr(conn, p, remoteAddr)
 	case proto.OpMetaUpdateXAttr:
@@ -289,6 +302,9 @@ func (m *metadataManager) HandleMetadataOperation(conn net.Conn, p *Packet, remo
 	default:
 		err = fmt.Errorf("%s unknown Opcode: %d, reqId: %d", remoteAddr,
 			p.Opcode, p.GetReqID())
+		// tell the client the op is not supported, e.g. by an old meta node during a rolling upgrade
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(fmt.Sprintf("%v: unknown opcode(%v)", proto.ErrVersionMismatch, p.Opcode)))
+		m.respondToClientWithVer(conn, p)
 	}
 	if err != nil {
 		err = errors.NewErrorf("%s [%s] req: %d - %s", remoteAddr, p.GetOpMsg(),
@@ -146,6 +146,7 @@ func (m *metadataManager) opMasterHeartbeat(conn net.Conn, p *Packet, remoteAddr
 			return true
 		})
 		resp.ZoneName = m.zoneName
+		resp.Version = proto.Version
 		resp.Status = proto.TaskSucceeds
 	end:
 		adminTask.Request = nil
@@ -929,7 +930,7 @@ func (m *metadataManager) opReadDir(conn net.Conn, p *Packet, remoteAddr string)
 // Handle OpReadDirLimit
 func (m *metadataManager) opReadDirLimit(conn net.Conn, p *Packet, remoteAddr string) (err error) {
 	req := &proto.ReadDirLimitRequest{}
-	if err = json.Unmarshal(p.Data, req); err != nil {
+	if err = p.UnmarshalData(req); err != nil {
 		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
 		m.respondToClient(conn, p)
 		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
@@ -1131,6 +1132,39 @@ func (m *metadataManager) opMetaLookup(conn net.Conn, p *Packet, remoteAddr stri
 	return
 }
 
+func (m *metadataManager) opMetaBatchLookup(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &proto.BatchLookupRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClient(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+
+	if mp.IsForbidden() {
+		err = storage.ForbiddenMetaPartitionError
+		p.PacketErrorWithBody(proto.OpForbidErr, []byte(err.Error()))
+		m.respondToClient(conn, p)
+		return
+	}
+
+	if !mp.IsFollowerRead() && !m.serveProxy(conn, mp, p) {
+		return
+	}
+	err = mp.BatchLookup(req, p)
+	m.respondToClient(conn, p)
+	log.LogDebugf("%s [opMetaBatchLookup] req: %d - %v, resp: %v, body: %s",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg(), p.Data)
+	return
+}
+
 func (m *metadataManager) opMetaExtentsAdd(conn net.Conn, p *Packet, remoteAddr string) (err error) {
 	req := &proto.AppendExtentKeyRequest{}
 	if err = json.Unmarshal(p.Data, req); err != nil {
@@ -1204,6 +1238,38 @@ func (m *metadataManager) opMetaExtentAddWithCheck(conn net.Conn, p *Packet, rem
 	return
 }
 
+func (m *metadataManager) opMetaExtentsMerge(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &proto.MergeExtentKeysRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionID)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+
+	if err = mp.ExtentsMerge(req, p); err != nil {
+		log.LogErrorf("%s [opMetaExtentsMerge] ExtentsMerge: %s", remoteAddr, err.Error())
+	}
+	m.updatePackRspSeq(mp, p)
+	if err = m.respondToClientWithVer(conn, p); err != nil {
+		log.LogErrorf("%s [opMetaExtentsMerge] ExtentsMerge: %s, "+
+			"response to client: %s", remoteAddr, err.Error(), p.GetResultMsg())
+	}
+	log.LogDebugf("%s [opMetaExtentsMerge] req: %d - %v, resp: %v, body: %s",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg(), p.Data)
+	return
+}
+
 func (m *metadataManager) opMetaExtentsList(conn net.Conn, p *Packet, remoteAddr string) (err error) {
 	req := &proto.GetExtentsRequest{}
 	if err = json.Unmarshal(p.Data, req); err != nil {
@@ -1652,7 +1718,7 @@ func (m *metadataManager) opRemoveMetaPartitionRaftMember(conn net.Conn, p *Pack
 
 func (m *metadataManager) opMetaBatchInodeGet(conn net.Conn, p *Packet, remoteAddr string) (err error) {
 	req := &proto.BatchInodeGetRequest{}
-	if err = json.Unmarshal(p.Data, req); err != nil {
+	if err = p.UnmarshalData(req); err != nil {
 		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
 		m.respondToClient(conn, p)
 		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
@@ -1945,6 +2011,37 @@ func (m *metadataManager) opMetaRemoveXAttr(conn net.Conn, p *Packet, remoteAddr
 	return
 }
 
+func (m *metadataManager) opMetaBatchRemoveXAttr(conn net.Conn, p *Packet, remoteAddr string) (err error) {
+	req := &proto.BatchRemoveXAttrRequest{}
+	if err = json.Unmarshal(p.Data, req); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	mp, err := m.getPartition(req.PartitionId)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, ([]byte)(err.Error()))
+		m.respondToClientWithVer(conn, p)
+		err = errors.NewErrorf("[%v] req: %v, resp: %v", p.GetOpMsgWithReqAndResult(), req, err.Error())
+		return
+	}
+	if !m.serveProxy(conn, mp, p) {
+		return
+	}
+	if err = m.checkMultiVersionStatus(mp, p); err != nil {
+		err = errors.NewErrorf("[%v],req[%v],err[%v]", p.GetOpMsgWithReqAndResult(), req, string(p.Data))
+		m.respondToClientWithVer(conn, p)
+		return
+	}
+	err = mp.BatchRemoveXAttr(req, p)
+	m.updatePackRspSeq(mp, p)
+	_ = m.respondToClientWithVer(conn, p)
+	log.LogDebugf("%s [opMetaBatchRemoveXAttr] req: %d - %v, resp: %v, body: %s",
+		remoteAddr, p.GetReqID(), req, p.GetResultMsg(), p.Data)
+	return
+}
+
 func (m *metadataManager) opMetaListXAttr(conn net.Conn, p *Packet, remoteAddr string) (err error) {
 	req := &proto.ListXAttrRequest{}
 	if err = json.Unmarshal(p.Data, req); err != nil {
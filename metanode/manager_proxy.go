@@ -48,10 +48,12 @@ func (m *metadataManager) IsForbiddenOp(mp MetaPartition, reqOp uint8) bool {
 		proto.OpMetaSetXAttr,
 		proto.OpMetaBatchSetXAttr,
 		proto.OpMetaRemoveXAttr,
+		proto.OpMetaBatchRemoveXAttr,
 		// extent
 		proto.OpMetaTruncate,
 		proto.OpMetaExtentsAdd,
 		proto.OpMetaExtentAddWithCheck,
+		proto.OpMetaExtentsMerge,
 		proto.OpMetaObjExtentAdd,
 		proto.OpMetaBatchObjExtentsAdd,
 		proto.OpMetaBatchExtentsAdd,
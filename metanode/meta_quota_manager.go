@@ -17,7 +17,11 @@ package metanode
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/util/log"
@@ -29,11 +33,28 @@ type MetaQuotaManager struct {
 	statisticRebuildTemp *sync.Map // key quotaId, value proto.QuotaUsedInfo
 	statisticRebuildBase *sync.Map // key quotaId, value proto.QuotaUsedInfo
 	limitedMap           *sync.Map
+	hardLimitMap         *sync.Map // key quotaId, value *quotaHardLimit
 	rbuilding            bool
 	volName              string
 	rwlock               sync.RWMutex
 	mpID                 uint64
 	enable               bool
+	statisticRestored    bool // statisticBase is restored from the snapshot, no need to count it on load
+}
+
+type quotaHardLimit struct {
+	maxFiles  uint64
+	maxBytes  uint64
+	nearLimit int32 // 1 if the usage reaches the soft threshold, updated atomically under the read lock
+}
+
+// reachSoftThreshold checks whether the used files or bytes reach the threshold percentage of the limits.
+func (limit *quotaHardLimit) reachSoftThreshold(usedInfo proto.QuotaUsedInfo, threshold uint64) bool {
+	ratio := float64(threshold) / 100
+	if limit.maxFiles > 0 && float64(usedInfo.UsedFiles) >= float64(limit.maxFiles)*ratio {
+		return true
+	}
+	return limit.maxBytes > 0 && float64(usedInfo.UsedBytes) >= float64(limit.maxBytes)*ratio
 }
 
 type MetaQuotaInode struct {
@@ -53,6 +74,7 @@ func NewQuotaManager(volName string, mpId uint64) (mqMgr *MetaQuotaManager) {
 		statisticRebuildTemp: new(sync.Map),
 		statisticRebuildBase: new(sync.Map),
 		limitedMap:           new(sync.Map),
+		hardLimitMap:         new(sync.Map),
 		volName:              volName,
 		mpID:                 mpId,
 	}
@@ -173,6 +195,12 @@ func (mqMgr *MetaQuotaManager) setQuotaHbInfo(infos []*proto.QuotaHeartBeatInfo)
 		}
 		mqMgr.enable = info.Enable
 		mqMgr.limitedMap.Store(info.QuotaId, info.LimitedInfo)
+		if value, ok := mqMgr.hardLimitMap.Load(info.QuotaId); ok {
+			limit := value.(*quotaHardLimit)
+			limit.maxFiles, limit.maxBytes = info.MaxFiles, info.MaxBytes
+		} else {
+			mqMgr.hardLimitMap.Store(info.QuotaId, &quotaHardLimit{maxFiles: info.MaxFiles, maxBytes: info.MaxBytes})
+		}
 		log.LogDebugf("mp[%v] quotaId [%v] limitedInfo [%v]", mqMgr.mpID, info.QuotaId, info.LimitedInfo)
 	}
 	mqMgr.limitedMap.Range(func(key, value interface{}) bool {
@@ -191,6 +219,7 @@ func (mqMgr *MetaQuotaManager) setQuotaHbInfo(infos []*proto.QuotaHeartBeatInfo)
 
 		if !found {
 			mqMgr.limitedMap.Delete(quotaId)
+			mqMgr.hardLimitMap.Delete(quotaId)
 		}
 		return true
 	})
@@ -227,8 +256,9 @@ func (mqMgr *MetaQuotaManager) getQuotaReportInfos() (infos []*proto.QuotaReport
 		}
 		usedInfo = value.(proto.QuotaUsedInfo)
 		reportInfo := &proto.QuotaReportInfo{
-			QuotaId:  quotaId,
-			UsedInfo: usedInfo,
+			QuotaId:   quotaId,
+			UsedInfo:  usedInfo,
+			NearLimit: mqMgr.checkNearLimit(quotaId, usedInfo),
 		}
 		infos = append(infos, reportInfo)
 		log.LogDebugf("[getQuotaReportInfos] statisticBase mp[%v] key [%v] usedInfo [%v]", mqMgr.mpID, key.(uint32), usedInfo)
@@ -237,6 +267,114 @@ func (mqMgr *MetaQuotaManager) getQuotaReportInfos() (infos []*proto.QuotaReport
 	return
 }
 
+// checkNearLimit reports whether the quota usage reaches the soft threshold of the limits,
+// and warns once the usage goes beyond it. The caller must hold the read lock at least.
+func (mqMgr *MetaQuotaManager) checkNearLimit(quotaId uint32, usedInfo proto.QuotaUsedInfo) bool {
+	value, ok := mqMgr.hardLimitMap.Load(quotaId)
+	if !ok {
+		return false
+	}
+	limit := value.(*quotaHardLimit)
+	threshold := QuotaSoftThreshold()
+	nearLimit := limit.reachSoftThreshold(usedInfo, threshold)
+	var flag int32
+	if nearLimit {
+		flag = 1
+	}
+	if atomic.SwapInt32(&limit.nearLimit, flag) == 0 && nearLimit {
+		log.LogWarnf("[checkNearLimit] mp[%v] vol[%v] quotaId [%v] usedInfo [%v] reaches %v%% of maxFiles [%v] or maxBytes [%v]",
+			mqMgr.mpID, mqMgr.volName, quotaId, usedInfo, threshold, limit.maxFiles, limit.maxBytes)
+	}
+	return nearLimit
+}
+
+// snapshotStatistic returns the used info of all the quotas, which is consistent with the apply index
+// if it is called by the fsm.
+func (mqMgr *MetaQuotaManager) snapshotStatistic() map[uint32]proto.QuotaUsedInfo {
+	mqMgr.rwlock.RLock()
+	defer mqMgr.rwlock.RUnlock()
+	statistic := make(map[uint32]proto.QuotaUsedInfo)
+	mqMgr.statisticBase.Range(func(key, value interface{}) bool {
+		statistic[key.(uint32)] = value.(proto.QuotaUsedInfo)
+		return true
+	})
+	mqMgr.statisticTemp.Range(func(key, value interface{}) bool {
+		usedInfo := statistic[key.(uint32)]
+		tempInfo := value.(proto.QuotaUsedInfo)
+		usedInfo.Add(&tempInfo)
+		statistic[key.(uint32)] = usedInfo
+		return true
+	})
+	return statistic
+}
+
+func (mqMgr *MetaQuotaManager) restoreStatistic(statistic map[uint32]proto.QuotaUsedInfo) {
+	mqMgr.rwlock.Lock()
+	defer mqMgr.rwlock.Unlock()
+	mqMgr.statisticBase = new(sync.Map)
+	mqMgr.statisticTemp = new(sync.Map)
+	for quotaId, usedInfo := range statistic {
+		mqMgr.statisticBase.Store(quotaId, usedInfo)
+		log.LogInfof("restoreStatistic mp[%v] quotaId [%v] usedInfo [%v]", mqMgr.mpID, quotaId, usedInfo)
+	}
+	mqMgr.statisticRestored = true
+}
+
+func (mqMgr *MetaQuotaManager) isStatisticRestored() bool {
+	mqMgr.rwlock.RLock()
+	defer mqMgr.rwlock.RUnlock()
+	return mqMgr.statisticRestored
+}
+
+// quota statistic format, all in big endian:
+// count(uint32) | [quotaId(uint32) usedFiles(int64) usedBytes(int64)] * count, sorted by quotaId | crc32(uint32)
+const quotaStatisticItemLen = 4 + 8 + 8
+
+func marshalQuotaStatistic(statistic map[uint32]proto.QuotaUsedInfo) []byte {
+	quotaIds := make([]uint32, 0, len(statistic))
+	for quotaId := range statistic {
+		quotaIds = append(quotaIds, quotaId)
+	}
+	sort.Slice(quotaIds, func(i, j int) bool { return quotaIds[i] < quotaIds[j] })
+
+	data := make([]byte, 4, 4+len(quotaIds)*quotaStatisticItemLen+4)
+	binary.BigEndian.PutUint32(data, uint32(len(quotaIds)))
+	item := make([]byte, quotaStatisticItemLen)
+	for _, quotaId := range quotaIds {
+		usedInfo := statistic[quotaId]
+		binary.BigEndian.PutUint32(item[0:4], quotaId)
+		binary.BigEndian.PutUint64(item[4:12], uint64(usedInfo.UsedFiles))
+		binary.BigEndian.PutUint64(item[12:20], uint64(usedInfo.UsedBytes))
+		data = append(data, item...)
+	}
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(data))
+	return append(data, crc...)
+}
+
+func unmarshalQuotaStatistic(raw []byte) (statistic map[uint32]proto.QuotaUsedInfo, err error) {
+	if len(raw) < 8 {
+		return nil, fmt.Errorf("quota statistic length %v is too short", len(raw))
+	}
+	data := raw[:len(raw)-4]
+	if crc := binary.BigEndian.Uint32(raw[len(raw)-4:]); crc != crc32.ChecksumIEEE(data) {
+		return nil, ErrSnapshotCrcMismatch
+	}
+	count := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if len(data) != int(count)*quotaStatisticItemLen {
+		return nil, fmt.Errorf("quota statistic count %v mismatches length %v", count, len(data))
+	}
+	statistic = make(map[uint32]proto.QuotaUsedInfo, count)
+	for ; len(data) > 0; data = data[quotaStatisticItemLen:] {
+		statistic[binary.BigEndian.Uint32(data[0:4])] = proto.QuotaUsedInfo{
+			UsedFiles: int64(binary.BigEndian.Uint64(data[4:12])),
+			UsedBytes: int64(binary.BigEndian.Uint64(data[12:20])),
+		}
+	}
+	return
+}
+
 func (mqMgr *MetaQuotaManager) statisticRebuildStart() bool {
 	mqMgr.rwlock.Lock()
 	defer mqMgr.rwlock.Unlock()
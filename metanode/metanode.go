@@ -17,6 +17,7 @@ package metanode
 import (
 	"fmt"
 	syslog "log"
+	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -73,6 +74,8 @@ type MetaNode struct {
 	clusterUuid               string
 	clusterUuidEnable         bool
 	serviceIDKey              string
+	enableInodePathResolve    bool
+	consistencyChecking       int32
 
 	control common.Control
 }
@@ -215,6 +218,33 @@ func (m *MetaNode) parseConfig(cfg *config.Config) (err error) {
 	}
 
 	m.serviceIDKey = cfg.GetString(cfgServiceIDKey)
+	m.enableInodePathResolve = cfg.GetBool(cfgEnableInodePathResolve)
+
+	if cfg.HasKey(cfgQuotaSoftThreshold) {
+		threshold := cfg.GetInt64(cfgQuotaSoftThreshold)
+		if threshold <= 0 || threshold > 100 {
+			return fmt.Errorf("cfgQuotaSoftThreshold is not legal, should be between 1-100, now %v", threshold)
+		}
+		updateQuotaSoftThreshold(uint64(threshold))
+	}
+
+	if cfg.HasKey(cfgMaxDentryNameLen) {
+		nameLen := cfg.GetInt64(cfgMaxDentryNameLen)
+		if nameLen <= 0 || nameLen > math.MaxUint32 {
+			return fmt.Errorf("cfgMaxDentryNameLen is not legal, should be positive, now %v", nameLen)
+		}
+		updateMaxDentryNameLen(uint32(nameLen))
+	}
+
+	for key, class := range map[string]string{
+		cfgReadDirOpLimit:       heavyOpReadDir,
+		cfgListXAttrOpLimit:     heavyOpListXAttr,
+		cfgBatchInodeGetOpLimit: heavyOpBatchInodeGet,
+	} {
+		if err := updateHeavyOpLimit(class, cfg.GetInt64(key)); err != nil {
+			return fmt.Errorf("%v is not legal: %v", key, err)
+		}
+	}
 
 	total, _, err := util.GetMemInfo()
 	if err != nil {
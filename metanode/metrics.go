@@ -29,6 +29,8 @@ const (
 	MetricMetaPartitionInodeCount  = "mpInodeCount"
 	MetricMetaPartitionDentryCount = "mpDentryCount"
 	MetricConnectionCount          = "connectionCnt"
+	MetricMetaPartitionInodeIdFree = "mpInodeIdFree"
+	MetricMetaPartitionInodeIdDue  = "mpInodeIdExhaustSec"
 )
 
 type MetaNodeMetrics struct {
@@ -36,6 +38,8 @@ type MetaNodeMetrics struct {
 	MetricMetaFailedPartition      *exporter.Gauge
 	MetricMetaPartitionInodeCount  *exporter.Gauge
 	MetricMetaPartitionDentryCount *exporter.Gauge
+	MetricMetaPartitionInodeIdFree *exporter.Gauge
+	MetricMetaPartitionInodeIdDue  *exporter.Gauge
 
 	metricStopCh chan struct{}
 }
@@ -48,6 +52,8 @@ func (m *MetaNode) startStat() {
 		MetricMetaFailedPartition:      exporter.NewGauge(MetricMetaFailedPartition),
 		MetricMetaPartitionInodeCount:  exporter.NewGauge(MetricMetaPartitionInodeCount),
 		MetricMetaPartitionDentryCount: exporter.NewGauge(MetricMetaPartitionDentryCount),
+		MetricMetaPartitionInodeIdFree: exporter.NewGauge(MetricMetaPartitionInodeIdFree),
+		MetricMetaPartitionInodeIdDue:  exporter.NewGauge(MetricMetaPartitionInodeIdDue),
 	}
 
 	go m.collectPartitionMetrics()
@@ -60,6 +66,11 @@ func (m *MetaNode) upatePartitionMetrics(mp *metaPartition) {
 	}
 	m.metrics.MetricMetaPartitionInodeCount.SetWithLabels(float64(mp.GetInodeTreeLen()), labels)
 	m.metrics.MetricMetaPartitionDentryCount.SetWithLabels(float64(mp.GetDentryTreeLen()), labels)
+
+	mp.sampleInodeAlloc(time.Now())
+	stat := mp.GetInodeAllocStat()
+	m.metrics.MetricMetaPartitionInodeIdFree.SetWithLabels(float64(stat.Free), labels)
+	m.metrics.MetricMetaPartitionInodeIdDue.SetWithLabels(float64(stat.ExhaustInSec), labels)
 }
 
 func (m *MetaNode) collectPartitionMetrics() {
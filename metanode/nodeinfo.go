@@ -9,8 +9,10 @@ import (
 )
 
 const (
-	UpdateNodeInfoTicket     = 1 * time.Minute
-	DefaultDeleteBatchCounts = 128
+	UpdateNodeInfoTicket      = 1 * time.Minute
+	DefaultDeleteBatchCounts  = 128
+	DefaultQuotaSoftThreshold = 90
+	DefaultMaxDentryNameLen   = 255 // NAME_MAX of POSIX
 )
 
 type NodeInfo struct {
@@ -22,6 +24,8 @@ var (
 	nodeInfoStopC              = make(chan struct{})
 	deleteWorkerSleepMs uint64 = 0
 	dirChildrenNumLimit uint32 = proto.DefaultDirChildrenNumLimit
+	quotaSoftThreshold  uint64 = DefaultQuotaSoftThreshold
+	maxDentryNameLen    uint32 = DefaultMaxDentryNameLen
 )
 
 func DeleteBatchCount() uint64 {
@@ -36,6 +40,22 @@ func updateDeleteBatchCount(val uint64) {
 	atomic.StoreUint64(&nodeInfo.deleteBatchCount, val)
 }
 
+func QuotaSoftThreshold() uint64 {
+	return atomic.LoadUint64(&quotaSoftThreshold)
+}
+
+func updateQuotaSoftThreshold(val uint64) {
+	atomic.StoreUint64(&quotaSoftThreshold, val)
+}
+
+func MaxDentryNameLen() uint32 {
+	return atomic.LoadUint32(&maxDentryNameLen)
+}
+
+func updateMaxDentryNameLen(val uint32) {
+	atomic.StoreUint32(&maxDentryNameLen, val)
+}
+
 func updateDeleteWorkerSleepMs(val uint64) {
 	atomic.StoreUint64(&deleteWorkerSleepMs, val)
 }
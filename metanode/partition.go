@@ -16,6 +16,7 @@ package metanode
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -154,6 +155,7 @@ type OpExtend interface {
 	GetAllXAttr(req *proto.GetAllXAttrRequest, p *Packet) (err error)
 	BatchGetXAttr(req *proto.BatchGetXAttrRequest, p *Packet) (err error)
 	RemoveXAttr(req *proto.RemoveXAttrRequest, p *Packet) (err error)
+	BatchRemoveXAttr(req *proto.BatchRemoveXAttrRequest, p *Packet) (err error)
 	ListXAttr(req *proto.ListXAttrRequest, p *Packet) (err error)
 	UpdateXAttr(req *proto.UpdateXAttrRequest, p *Packet) (err error)
 }
@@ -168,8 +170,13 @@ type OpDentry interface {
 	ReadDirLimit(req *ReadDirLimitReq, p *Packet) (err error)
 	ReadDirOnly(req *ReadDirOnlyReq, p *Packet) (err error)
 	Lookup(req *LookupReq, p *Packet) (err error)
+	BatchLookup(req *BatchLookupReq, p *Packet) (err error)
 	GetDentryTree() *BTree
 	GetDentryTreeLen() int
+	GetAccessStats(limit int) *AccessStatsReport
+	ResolveInodePaths(ino uint64, limit int) *InodePaths
+	checkConsistency(ctx context.Context) *ConsistencyReport
+	GetDentryVersions(parentID uint64, name string) (versions []proto.DetryInfo, ok bool)
 	TxCreateDentry(req *proto.TxCreateDentryRequest, p *Packet, remoteAddr string) (err error)
 	TxDeleteDentry(req *proto.TxDeleteDentryRequest, p *Packet, remoteAddr string) (err error)
 	TxUpdateDentry(req *proto.TxUpdateDentryRequest, p *Packet, remoteAddr string) (err error)
@@ -191,6 +198,7 @@ type OpTransaction interface {
 type OpExtent interface {
 	ExtentAppend(req *proto.AppendExtentKeyRequest, p *Packet) (err error)
 	ExtentAppendWithCheck(req *proto.AppendExtentKeyWithCheckRequest, p *Packet) (err error)
+	ExtentsMerge(req *proto.MergeExtentKeysRequest, p *Packet) (err error)
 	BatchObjExtentAppend(req *proto.AppendObjExtentKeysRequest, p *Packet) (err error)
 	ExtentsList(req *proto.GetExtentsRequest, p *Packet) (err error)
 	ObjExtentsList(req *proto.GetExtentsRequest, p *Packet) (err error)
@@ -276,6 +284,10 @@ type MetaPartition interface {
 	SetForbidden(status bool)
 	IsEnableAuditLog() bool
 	SetEnableAuditLog(status bool)
+	SetSlowOpThreshold(threshold time.Duration)
+	GetSlowOpStat() *SlowOpStat
+	GetInodeAllocStat() *InodeAllocStat
+	EvictInodeCache(inodes []uint64, ttl time.Duration) (evicted int, err error)
 }
 
 type UidManager struct {
@@ -510,6 +522,11 @@ type metaPartition struct {
 	multiVersionList       *proto.VolVersionInfoList
 	verUpdateChan          chan []byte
 	enableAuditLog         bool
+	accessStats            *accessStats
+	slowOpThreshold        int64 // time.Duration, accessed atomically
+	slowOpCount            uint64
+	inodeAllocSampler      inodeAllocSampler
+	heavyOpLimiter         heavyOpLimiter
 }
 
 func (mp *metaPartition) IsForbidden() bool {
@@ -875,6 +892,7 @@ func NewMetaPartition(conf *MetaPartitionConfig, manager *metadataManager) MetaP
 			TemporaryVerMap: make(map[uint64]*proto.VolVersionInfo),
 		},
 		enableAuditLog: true,
+		accessStats:    newAccessStats(defaultAccessStatsWindow, defaultAccessStatsSampleRate, defaultAccessStatsMaxDirs),
 	}
 	mp.txProcessor = NewTransactionProcessor(mp)
 	return mp
@@ -1076,6 +1094,10 @@ func (mp *metaPartition) LoadSnapshot(snapshotPath string) (err error) {
 		}
 	}
 
+	// the quota statistic is derived data, count it by the extends if it fails to restore
+	if err = mp.loadQuotaStatistic(snapshotPath); err != nil {
+		log.LogWarnf("action[LoadSnapshot] partition(%v) %v", mp.config.PartitionId, err)
+	}
 	if err = mp.loadExtend(snapshotPath, crcs[2]); err != nil {
 		return
 	}
@@ -1172,6 +1194,9 @@ func (mp *metaPartition) store(sm *storeMsg) (err error) {
 	if err = mp.storeUniqID(tmpDir, sm); err != nil {
 		return
 	}
+	if err = mp.storeQuotaStatistic(tmpDir, sm); err != nil {
+		return
+	}
 
 	// write crc to file
 	if err = os.WriteFile(path.Join(tmpDir, SnapshotSign), crcBuffer.Bytes(), 0o775); err != nil {
@@ -0,0 +1,211 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultAccessStatsWindow     = time.Hour
+	defaultAccessStatsSampleRate = 16
+	// directories beyond the limit are not tracked until the stale ones are evicted
+	defaultAccessStatsMaxDirs = 100000
+	accessStatsEvictInterval  = 60 // seconds
+)
+
+// DirAccessStat is the access recency and frequency of a directory.
+type DirAccessStat struct {
+	Inode      uint64 `json:"ino"`
+	LastAccess int64  `json:"lastAccess"`
+	// Accesses is the estimated number of accesses within the last window.
+	Accesses uint64 `json:"accesses"`
+}
+
+// AccessStatsReport is the access pattern of a meta partition.
+type AccessStatsReport struct {
+	PartitionID uint64           `json:"partitionID"`
+	VolName     string           `json:"volName"`
+	Window      int64            `json:"window"`
+	SampleRate  uint64           `json:"sampleRate"`
+	LastAccess  int64            `json:"lastAccess"`
+	Accesses    uint64           `json:"accesses"`
+	TrackedDirs int              `json:"trackedDirs"`
+	Dirs        []*DirAccessStat `json:"dirs"`
+}
+
+type dirAccess struct {
+	lastAccess  int64
+	windowStart int64
+	curCount    uint64
+	prevCount   uint64
+}
+
+// rotate moves the counter to the window of now.
+func (d *dirAccess) rotate(now, window int64) {
+	if now-d.windowStart < window {
+		return
+	}
+	if now-d.windowStart < 2*window {
+		d.prevCount = d.curCount
+	} else {
+		d.prevCount = 0
+	}
+	d.curCount = 0
+	d.windowStart = now - (now-d.windowStart)%window
+}
+
+// estimate weights the count of the previous window by its part still in the sliding window.
+func (d *dirAccess) estimate(now, window int64) uint64 {
+	d.rotate(now, window)
+	elapsed := now - d.windowStart
+	return d.curCount + uint64(float64(d.prevCount)*float64(window-elapsed)/float64(window))
+}
+
+// accessStats tracks the accesses of the directories of a meta partition.
+// Only one of every sampleRate accesses is recorded and counted as sampleRate accesses,
+// so the counts are approximate and the tracking stays cheap on the read path.
+type accessStats struct {
+	sync.Mutex
+	window     int64 // seconds
+	sampleRate uint64
+	maxDirs    int
+	counter    uint64
+	lastAccess int64
+	lastEvict  int64
+	dirs       map[uint64]*dirAccess
+}
+
+func newAccessStats(window time.Duration, sampleRate uint64, maxDirs int) *accessStats {
+	if sampleRate == 0 {
+		sampleRate = 1
+	}
+	return &accessStats{
+		window:     int64(window / time.Second),
+		sampleRate: sampleRate,
+		maxDirs:    maxDirs,
+		dirs:       make(map[uint64]*dirAccess),
+	}
+}
+
+func (s *accessStats) record(dirIno uint64) {
+	if s == nil {
+		return
+	}
+	if atomic.AddUint64(&s.counter, 1)%s.sampleRate != 0 {
+		return
+	}
+	s.recordAt(dirIno, time.Now().Unix())
+}
+
+func (s *accessStats) recordAt(dirIno uint64, now int64) {
+	s.Lock()
+	defer s.Unlock()
+	s.lastAccess = now
+	d, ok := s.dirs[dirIno]
+	if !ok {
+		if len(s.dirs) >= s.maxDirs && !s.evict(now) {
+			return
+		}
+		d = &dirAccess{windowStart: now}
+		s.dirs[dirIno] = d
+	}
+	d.rotate(now, s.window)
+	d.lastAccess = now
+	d.curCount += s.sampleRate
+}
+
+// evict drops the directories not accessed for two windows, their counts are already zero.
+func (s *accessStats) evict(now int64) bool {
+	if now-s.lastEvict < accessStatsEvictInterval {
+		return false
+	}
+	s.lastEvict = now
+	for ino, d := range s.dirs {
+		if now-d.lastAccess >= 2*s.window {
+			delete(s.dirs, ino)
+		}
+	}
+	return len(s.dirs) < s.maxDirs
+}
+
+// report returns the stats of the partition, the directories are sorted from the coldest.
+// A positive limit returns the coldest limit directories only, a negative one returns the summary only.
+func (s *accessStats) report(limit int) (r *AccessStatsReport) {
+	return s.reportAt(limit, time.Now().Unix())
+}
+
+func (s *accessStats) reportAt(limit int, now int64) (r *AccessStatsReport) {
+	s.Lock()
+	defer s.Unlock()
+	r = &AccessStatsReport{
+		Window:      s.window,
+		SampleRate:  s.sampleRate,
+		LastAccess:  s.lastAccess,
+		TrackedDirs: len(s.dirs),
+		Dirs:        make([]*DirAccessStat, 0, len(s.dirs)),
+	}
+	for ino, d := range s.dirs {
+		stat := &DirAccessStat{
+			Inode:      ino,
+			LastAccess: d.lastAccess,
+			Accesses:   d.estimate(now, s.window),
+		}
+		r.Accesses += stat.Accesses
+		if limit >= 0 {
+			r.Dirs = append(r.Dirs, stat)
+		}
+	}
+	sort.Slice(r.Dirs, func(i, j int) bool {
+		if r.Dirs[i].Accesses != r.Dirs[j].Accesses {
+			return r.Dirs[i].Accesses < r.Dirs[j].Accesses
+		}
+		return r.Dirs[i].LastAccess < r.Dirs[j].LastAccess
+	})
+	if limit > 0 && len(r.Dirs) > limit {
+		r.Dirs = r.Dirs[:limit]
+	}
+	return
+}
+
+// VolAccessStats is the access pattern of a volume on a meta node.
+type VolAccessStats struct {
+	VolName    string `json:"volName"`
+	Partitions int    `json:"partitions"`
+	LastAccess int64  `json:"lastAccess"`
+	Accesses   uint64 `json:"accesses"`
+}
+
+func (v *VolAccessStats) merge(r *AccessStatsReport) {
+	v.Partitions++
+	v.Accesses += r.Accesses
+	if r.LastAccess > v.LastAccess {
+		v.LastAccess = r.LastAccess
+	}
+}
+
+// GetAccessStats returns the access pattern of the directories in the partition.
+func (mp *metaPartition) GetAccessStats(limit int) *AccessStatsReport {
+	if mp.accessStats == nil {
+		return &AccessStatsReport{PartitionID: mp.config.PartitionId, VolName: mp.config.VolName}
+	}
+	r := mp.accessStats.report(limit)
+	r.PartitionID = mp.config.PartitionId
+	r.VolName = mp.config.VolName
+	return r
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessStats(t *testing.T) {
+	const (
+		window  = 100
+		hotDir  = 2
+		warmDir = 3
+		coldDir = 4
+	)
+	s := newAccessStats(window*time.Second, 1, 3)
+	now := int64(1000)
+
+	s.recordAt(coldDir, now)
+	for i := 0; i < 10; i++ {
+		s.recordAt(warmDir, now+10)
+	}
+	for i := 0; i < 100; i++ {
+		s.recordAt(hotDir, now+50)
+	}
+
+	r := s.reportAt(0, now+50)
+	require.Equal(t, 3, r.TrackedDirs)
+	require.Equal(t, now+50, r.LastAccess)
+	require.Equal(t, uint64(111), r.Accesses)
+	require.Equal(t, []uint64{coldDir, warmDir, hotDir}, []uint64{r.Dirs[0].Inode, r.Dirs[1].Inode, r.Dirs[2].Inode})
+	require.Equal(t, uint64(1), r.Dirs[0].Accesses)
+	require.Equal(t, now, r.Dirs[0].LastAccess)
+	require.Equal(t, uint64(100), r.Dirs[2].Accesses)
+	require.Equal(t, now+50, r.Dirs[2].LastAccess)
+
+	// the coldest dirs only
+	r = s.reportAt(1, now+50)
+	require.Len(t, r.Dirs, 1)
+	require.Equal(t, uint64(coldDir), r.Dirs[0].Inode)
+	// the summary only
+	r = s.reportAt(-1, now+50)
+	require.Len(t, r.Dirs, 0)
+	require.Equal(t, uint64(111), r.Accesses)
+
+	// the accesses of the previous window fade out as the window slides
+	r = s.reportAt(0, now+175)
+	require.Equal(t, uint64(coldDir), r.Dirs[0].Inode)
+	require.Equal(t, uint64(0), r.Dirs[0].Accesses)
+	require.Equal(t, uint64(75), r.Dirs[2].Accesses)
+	r = s.reportAt(0, now+400)
+	require.Equal(t, uint64(0), r.Accesses)
+	require.Equal(t, now+50, r.LastAccess)
+
+	// a new dir is tracked only after the stale ones are evicted
+	s = newAccessStats(window*time.Second, 1, 2)
+	s.recordAt(coldDir, now)
+	s.recordAt(hotDir, now+50)
+	s.recordAt(warmDir, now+60)
+	require.Equal(t, 2, s.reportAt(0, now+60).TrackedDirs)
+	s.recordAt(warmDir, now+220)
+	r = s.reportAt(0, now+220)
+	require.Equal(t, 2, r.TrackedDirs)
+	require.Equal(t, uint64(hotDir), r.Dirs[0].Inode)
+	require.Equal(t, uint64(warmDir), r.Dirs[1].Inode)
+
+	// sampled accesses are counted by the sample rate
+	s = newAccessStats(window*time.Second, 4, 3)
+	for i := 0; i < 100; i++ {
+		s.record(hotDir)
+	}
+	r = s.report(0)
+	require.Equal(t, uint64(4), r.SampleRate)
+	require.Equal(t, uint64(100), r.Accesses)
+}
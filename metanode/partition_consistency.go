@@ -0,0 +1,127 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"context"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+const (
+	// the max anomalies kept in a consistency report
+	maxConsistencyAnomalies = 1000
+
+	AnomalyDanglingDentry = "danglingDentry" // the inode of the dentry doesn't exist
+	AnomalyDeletedInode   = "deletedInode"   // the inode of the dentry is marked deleted
+	AnomalyNLinkMismatch  = "nlinkMismatch"  // the nlink of the inode doesn't match its dentries
+)
+
+type ConsistencyAnomaly struct {
+	Type     string
+	ParentId uint64 `json:",omitempty"`
+	Name     string `json:",omitempty"`
+	Inode    uint64
+	NLink    uint32 `json:",omitempty"`
+	// the nlink expected from the dentries
+	Expect uint32 `json:",omitempty"`
+}
+
+// ConsistencyReport is the result of checking the dentries against the inodes of a partition.
+type ConsistencyReport struct {
+	PartitionID uint64
+	Dentries    int
+	Inodes      int
+	Anomalies   []*ConsistencyAnomaly
+	// true if there are more anomalies than maxConsistencyAnomalies
+	Truncated bool
+	// true if the check is canceled before finished
+	Canceled bool
+}
+
+func (r *ConsistencyReport) add(a *ConsistencyAnomaly) {
+	if len(r.Anomalies) >= maxConsistencyAnomalies {
+		r.Truncated = true
+		return
+	}
+	r.Anomalies = append(r.Anomalies, a)
+}
+
+// checkConsistency scans the dentry tree and checks that the inodes referenced exist and are not deleted,
+// and that the nlink of the inodes matches the dentries.
+// Only the inodes in the range of the partition are checked, the others are checked by their own partition.
+// The nlink of a directory is 2 plus its children, which are all in this partition, so it's checked exactly.
+// A file may be linked by the dentries of other partitions, so only a nlink less than its dentries here is reported.
+// The trees are cloned without stopping the writes, the files modified during the check may be reported falsely.
+func (mp *metaPartition) checkConsistency(ctx context.Context) (report *ConsistencyReport) {
+	report = &ConsistencyReport{PartitionID: mp.config.PartitionId}
+	dentryTree := mp.GetDentryTree().GetTree()
+	inodeTree := mp.GetInodeTree().GetTree()
+	report.Dentries = dentryTree.Len()
+	report.Inodes = inodeTree.Len()
+
+	inRange := func(ino uint64) bool {
+		return ino >= mp.config.Start && ino <= mp.config.End
+	}
+	// the dentries referencing the inodes and the children of the directories
+	refs := make(map[uint64]uint32)
+	children := make(map[uint64]uint32)
+	dentryTree.Ascend(func(i BtreeItem) bool {
+		if ctx.Err() != nil {
+			report.Canceled = true
+			return false
+		}
+		d := i.(*Dentry)
+		if d.isDeleted() {
+			return true
+		}
+		children[d.ParentId]++
+		if !inRange(d.Inode) {
+			return true
+		}
+		refs[d.Inode]++
+		item := inodeTree.Get(NewInode(d.Inode, 0))
+		if item == nil {
+			report.add(&ConsistencyAnomaly{Type: AnomalyDanglingDentry, ParentId: d.ParentId, Name: d.Name, Inode: d.Inode})
+		} else if item.(*Inode).ShouldDelete() {
+			report.add(&ConsistencyAnomaly{Type: AnomalyDeletedInode, ParentId: d.ParentId, Name: d.Name, Inode: d.Inode})
+		}
+		return true
+	})
+	if report.Canceled {
+		return
+	}
+
+	inodeTree.Ascend(func(i BtreeItem) bool {
+		if ctx.Err() != nil {
+			report.Canceled = true
+			return false
+		}
+		ino := i.(*Inode)
+		if ino.ShouldDelete() {
+			return true
+		}
+		nlink := ino.GetNLink()
+		if proto.IsDir(ino.Type) {
+			if expect := 2 + children[ino.Inode]; nlink != expect {
+				report.add(&ConsistencyAnomaly{Type: AnomalyNLinkMismatch, Inode: ino.Inode, NLink: nlink, Expect: expect})
+			}
+		} else if nlink < refs[ino.Inode] {
+			report.add(&ConsistencyAnomaly{Type: AnomalyNLinkMismatch, Inode: ino.Inode, NLink: nlink, Expect: refs[ino.Inode]})
+		}
+		return true
+	})
+	return
+}
@@ -68,6 +68,16 @@ func (mp *metaPartition) startFreeList() (err error) {
 func (mp *metaPartition) updateVolView(convert func(view *proto.DataPartitionsView) *DataPartitionsView) (err error) {
 	volName := mp.config.VolName
 	dataView, err := masterClient.ClientAPI().EncodingGzip().GetDataPartitions(volName)
+	if err != nil {
+		// the volume may have been renamed, the partition keeps the old name, so look the name up by the partition id
+		var partition *proto.MetaPartitionInfo
+		if partition, _ = masterClient.ClientAPI().GetMetaPartition(mp.config.PartitionId); partition != nil &&
+			partition.VolName != "" && partition.VolName != volName {
+			log.LogWarnf("updateVolWorker: partition(%v) vol(%v) is renamed to (%v)", mp.config.PartitionId, volName, partition.VolName)
+			volName = partition.VolName
+			dataView, err = masterClient.ClientAPI().EncodingGzip().GetDataPartitions(volName)
+		}
+	}
 	if err != nil {
 		err = fmt.Errorf("updateVolWorker: get data partitions view fail: volume(%v) err(%v)",
 			volName, err)
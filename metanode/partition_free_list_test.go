@@ -15,6 +15,9 @@
 package metanode
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"strings"
@@ -22,6 +25,7 @@ import (
 	"testing"
 
 	"github.com/cubefs/cubefs/proto"
+	masterSDK "github.com/cubefs/cubefs/sdk/master"
 	"github.com/cubefs/cubefs/util"
 	"github.com/cubefs/cubefs/util/fileutil"
 	"github.com/stretchr/testify/require"
@@ -68,3 +72,42 @@ func TestPersistInodesFreeList(t *testing.T) {
 	}
 	require.Greater(t, cnt, 1)
 }
+
+func TestUpdateVolViewOfRenamedVol(t *testing.T) {
+	// the master knows the volume by the new name only
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reply := &proto.HTTPReply{Code: proto.ErrCodeSuccess}
+		name := r.FormValue("name")
+		switch {
+		case r.URL.Path == proto.ClientMetaPartition:
+			reply.Data = &proto.MetaPartitionInfo{PartitionID: 10001, VolName: "newVol"}
+		case name != "newVol":
+			reply.Code, reply.Msg = proto.ErrCodeVolNotExists, proto.ErrVolNotExists.Error()
+		case r.URL.Path == proto.ClientDataPartitions:
+			reply.Data = &proto.DataPartitionsView{DataPartitions: []*proto.DataPartitionResponse{
+				{PartitionID: 1, Hosts: []string{"127.0.0.1:17310"}},
+			}}
+		case r.URL.Path == proto.AdminGetVol:
+			reply.Data = &proto.SimpleVolView{Name: name, DeleteLockTime: 10}
+		}
+		json.NewEncoder(w).Encode(reply)
+	}))
+	defer master.Close()
+
+	old := masterClient
+	defer func() { masterClient = old }()
+	masterClient = masterSDK.NewMasterCLientWithResolver([]string{strings.TrimPrefix(master.URL, "http://")}, false, 0)
+	require.NotNil(t, masterClient)
+
+	mp := &metaPartition{config: &MetaPartitionConfig{PartitionId: 10001, VolName: "oldVol"}, vol: NewVol()}
+	err := mp.updateVolView(func(view *proto.DataPartitionsView) *DataPartitionsView {
+		newView := &DataPartitionsView{}
+		for _, dp := range view.DataPartitions {
+			newView.DataPartitions = append(newView.DataPartitions, &DataPartition{PartitionID: dp.PartitionID, Hosts: dp.Hosts})
+		}
+		return newView
+	})
+	require.NoError(t, err)
+	require.NotNil(t, mp.vol.GetPartition(1))
+	require.EqualValues(t, 10, mp.vol.volDeleteLockTime)
+}
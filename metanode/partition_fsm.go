@@ -220,6 +220,12 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			return
 		}
 		resp = mp.fsmAppendExtentsWithCheck(ino, true)
+	case opFSMExtentsMerge:
+		ino := NewInode(0, 0)
+		if err = ino.Unmarshal(msg.V); err != nil {
+			return
+		}
+		resp = mp.fsmMergeExtents(ino)
 	case opFSMObjExtentsAdd:
 		ino := NewInode(0, 0)
 		if err = ino.Unmarshal(msg.V); err != nil {
@@ -267,6 +273,7 @@ func (mp *metaPartition) Apply(command []byte, index uint64) (resp interface{},
 			uidRebuild:     uidRebuild,
 			uniqChecker:    uniqChecker,
 			multiVerList:   mp.GetAllVerList(),
+			quotaStatistic: mp.mqMgr.snapshotStatistic(),
 		}
 		log.LogDebugf("opFSMStoreTick: quotaRebuild [%v] uidRebuild [%v]", quotaRebuild, uidRebuild)
 		mp.storeChan <- msg
@@ -155,6 +155,35 @@ func (mp *metaPartition) getDentryList(dentry *Dentry) (denList []proto.DetryInf
 	return
 }
 
+// GetDentryVersions returns the current version of the dentry followed by its snapshot versions,
+// ok is false if the dentry does not exist.
+func (mp *metaPartition) GetDentryVersions(parentID uint64, name string) (versions []proto.DetryInfo, ok bool) {
+	item := mp.dentryTree.Get(&Dentry{ParentId: parentID, Name: name})
+	if item == nil {
+		return nil, false
+	}
+	d := item.(*Dentry)
+	versions = make([]proto.DetryInfo, 0, d.getSnapListLen()+1)
+	versions = append(versions, proto.DetryInfo{
+		Inode:  d.Inode,
+		Mode:   d.Type,
+		IsDel:  d.isDeleted(),
+		VerSeq: d.getVerSeq(),
+	})
+	if d.getSnapListLen() == 0 {
+		return versions, true
+	}
+	for _, den := range d.multiSnap.dentryList {
+		versions = append(versions, proto.DetryInfo{
+			Inode:  den.Inode,
+			Mode:   den.Type,
+			IsDel:  den.isDeleted(),
+			VerSeq: den.getVerSeq(),
+		})
+	}
+	return versions, true
+}
+
 // Query a dentry from the dentry tree with specified dentry info.
 func (mp *metaPartition) getDentry(dentry *Dentry) (*Dentry, uint8) {
 	item := mp.dentryTree.Get(dentry)
@@ -170,6 +199,22 @@ func (mp *metaPartition) getDentry(dentry *Dentry) (*Dentry, uint8) {
 	return den, proto.OpNotExistErr
 }
 
+// getDentryBatch looks up the names of the parent with the version seq, the names not found are skipped.
+func (mp *metaPartition) getDentryBatch(parentID uint64, names []string, verSeq uint64) (dentries []*Dentry) {
+	dentries = make([]*Dentry, 0, len(names))
+	for _, name := range names {
+		dentry := &Dentry{
+			ParentId: parentID,
+			Name:     name,
+		}
+		dentry.setVerSeq(verSeq)
+		if den, status := mp.getDentry(dentry); status == proto.OpOk {
+			dentries = append(dentries, den)
+		}
+	}
+	return
+}
+
 func (mp *metaPartition) fsmTxDeleteDentry(txDentry *TxDentry) (resp *DentryResponse) {
 	resp = NewDentryResponse()
 	resp.Status = proto.OpOk
@@ -438,24 +483,37 @@ func (mp *metaPartition) readDir(req *ReadDirReq) (resp *ReadDirResp) {
 	return
 }
 
+// getDentryAttr returns the basic attributes of the inode, nil if it is not found in the partition.
+func (mp *metaPartition) getDentryAttr(inode uint64, verSeq uint64) *proto.DentryAttr {
+	ino := NewInode(inode, 0)
+	ino.setVer(verSeq)
+	i := mp.getInodeByVer(ino)
+	if i == nil || i.ShouldDelete() {
+		return nil
+	}
+	i.RLock()
+	defer i.RUnlock()
+	return &proto.DentryAttr{
+		Size:       i.Size,
+		ModifyTime: i.ModifyTime,
+		Mode:       i.Type,
+		Nlink:      i.NLink,
+	}
+}
+
 // Read dentry from btree by limit count
 // if req.Marker == "" and req.Limit == 0, it becomes readDir
 // else if req.Marker != "" and req.Limit == 0, return dentries from pid:name to pid+1
 // else if req.Marker == "" and req.Limit != 0, return dentries from pid with limit count
 // else if req.Marker != "" and req.Limit != 0, return dentries from pid:marker to pid:xxxx with limit count
+// if req.Reverse is set, the dentries are returned in descending order, and the marker is the upper bound
 func (mp *metaPartition) readDirLimit(req *ReadDirLimitReq) (resp *ReadDirLimitResp) {
 	log.LogDebugf("action[readDirLimit] mp[%v] req %v", mp.config.PartitionId, req)
 	resp = &ReadDirLimitResp{}
-	startDentry := &Dentry{
-		ParentId: req.ParentID,
-	}
-	if len(req.Marker) > 0 {
-		startDentry.Name = req.Marker
-	}
-	endDentry := &Dentry{
-		ParentId: req.ParentID + 1,
-	}
-	mp.dentryTree.AscendRange(startDentry, endDentry, func(i BtreeItem) bool {
+	collect := func(i BtreeItem) bool {
+		if i.(*Dentry).ParentId != req.ParentID {
+			return true
+		}
 		if !proto.IsDir(i.(*Dentry).Type) && (req.VerOpt&uint8(proto.FlagsSnapshotDel) > 0) {
 			if req.VerOpt&uint8(proto.FlagsSnapshotDelDir) > 0 {
 				return true
@@ -473,12 +531,41 @@ func (mp *metaPartition) readDirLimit(req *ReadDirLimitReq) (resp *ReadDirLimitR
 			Type:  d.Type,
 			Name:  d.Name,
 		})
+		if req.WithAttrs {
+			resp.Attrs = append(resp.Attrs, mp.getDentryAttr(d.Inode, req.VerSeq))
+		}
 		// Limit == 0 means no limit.
 		if req.Limit > 0 && uint64(len(resp.Children)) >= req.Limit {
 			return false
 		}
 		return true
-	})
+	}
+
+	if req.Reverse {
+		// the names are never empty, so the dentries of the parent are all in (ParentID+"", ParentID+1+""),
+		// and the marker is the inclusive upper bound.
+		upperDentry := &Dentry{
+			ParentId: req.ParentID + 1,
+		}
+		if len(req.Marker) > 0 {
+			upperDentry = &Dentry{ParentId: req.ParentID, Name: req.Marker}
+		}
+		lowerDentry := &Dentry{
+			ParentId: req.ParentID,
+		}
+		mp.dentryTree.DescendRange(upperDentry, lowerDentry, collect)
+	} else {
+		startDentry := &Dentry{
+			ParentId: req.ParentID,
+		}
+		if len(req.Marker) > 0 {
+			startDentry.Name = req.Marker
+		}
+		endDentry := &Dentry{
+			ParentId: req.ParentID + 1,
+		}
+		mp.dentryTree.AscendRange(startDentry, endDentry, collect)
+	}
 	log.LogDebugf("action[readDirLimit] mp[%v] resp %v", mp.config.PartitionId, resp)
 	return
 }
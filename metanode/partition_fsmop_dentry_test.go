@@ -0,0 +1,198 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func readDirLimitPages(parentID uint64, limit uint64, verOpt uint8, reverse bool) (names []string) {
+	marker := ""
+	for {
+		pageLimit := limit
+		if marker != "" && limit > 0 {
+			pageLimit++
+		}
+		resp := mp.readDirLimit(&ReadDirLimitReq{
+			PartitionID: partitionId,
+			ParentID:    parentID,
+			Marker:      marker,
+			Limit:       pageLimit,
+			VerSeq:      mp.verSeq,
+			VerOpt:      verOpt,
+			Reverse:     reverse,
+		})
+		children := resp.Children
+		// the marker is returned again as the first child of the next page
+		if marker != "" && len(children) > 0 && children[0].Name == marker {
+			children = children[1:]
+		}
+		if len(children) == 0 {
+			return
+		}
+		for _, child := range children {
+			names = append(names, child.Name)
+		}
+		marker = children[len(children)-1].Name
+	}
+}
+
+func TestReadDirLimitReverse(t *testing.T) {
+	const count = 100
+	initMp(t)
+	parent := testCreateInode(t, DirModeType)
+	// dentries of the neighbour must not leak into the pages of the parent
+	neighbour := testCreateInode(t, DirModeType)
+	require.Equal(t, parent.Inode+1, neighbour.Inode)
+	testCreateDentry(t, neighbour.Inode, testCreateInode(t, FileModeType).Inode, "0", FileModeType)
+
+	for i := 0; i < count; i++ {
+		mode := FileModeType
+		if i%3 == 0 {
+			mode = DirModeType
+		}
+		ino := testCreateInode(t, mode)
+		testCreateDentry(t, parent.Inode, ino.Inode, fmt.Sprintf("name_%03d", i), mode)
+	}
+
+	reversed := func(names []string) []string {
+		r := make([]string, len(names))
+		for i, name := range names {
+			r[len(names)-1-i] = name
+		}
+		return r
+	}
+
+	for _, limit := range []uint64{0, 1, 7, count} {
+		forward := readDirLimitPages(parent.Inode, limit, 0, false)
+		require.Len(t, forward, count, "limit %v", limit)
+		require.Equal(t, forward, reversed(readDirLimitPages(parent.Inode, limit, 0, true)), "limit %v", limit)
+	}
+
+	// the snapshot filtering keeps the directories only in both directions
+	verOpt := uint8(proto.FlagsSnapshotDel | proto.FlagsSnapshotDelDir)
+	forward := readDirLimitPages(parent.Inode, 5, verOpt, false)
+	require.Len(t, forward, (count+2)/3)
+	require.Equal(t, forward, reversed(readDirLimitPages(parent.Inode, 5, verOpt, true)))
+
+	// the marker is the inclusive upper bound in reverse
+	resp := mp.readDirLimit(&ReadDirLimitReq{ParentID: parent.Inode, Marker: "name_050", Limit: 3, VerSeq: mp.verSeq, Reverse: true})
+	require.Len(t, resp.Children, 3)
+	require.Equal(t, []string{"name_050", "name_049", "name_048"},
+		[]string{resp.Children[0].Name, resp.Children[1].Name, resp.Children[2].Name})
+}
+
+func TestFsmCreateDentryIdempotent(t *testing.T) {
+	initMp(t)
+	parent := testCreateInode(t, DirModeType)
+	ino := testCreateInode(t, FileModeType)
+	parentNLink := func() uint32 {
+		return mp.inodeTree.Get(NewInode(parent.Inode, 0)).(*Inode).GetNLink()
+	}
+	newDentry := func(inode uint64) *Dentry {
+		return &Dentry{ParentId: parent.Inode, Name: "retry", Inode: inode, Type: FileModeType}
+	}
+
+	require.Equal(t, proto.OpOk, mp.fsmCreateDentry(newDentry(ino.Inode), false))
+	nlink := parentNLink()
+	// the retried create of the same dentry succeeds without linking the parent again
+	require.Equal(t, proto.OpOk, mp.fsmCreateDentry(newDentry(ino.Inode), false))
+	require.Equal(t, nlink, parentNLink())
+	// a different inode with the same name still conflicts
+	require.Equal(t, proto.OpExistErr, mp.fsmCreateDentry(newDentry(ino.Inode+1), false))
+}
+
+func TestBatchLookup(t *testing.T) {
+	const count = 20
+	initMp(t)
+	parent := testCreateInode(t, DirModeType)
+	names := make([]string, 0, 2*count)
+	for i := 0; i < count; i++ {
+		mode := FileModeType
+		if i%2 == 0 {
+			mode = DirModeType
+		}
+		ino := testCreateInode(t, mode)
+		testCreateDentry(t, parent.Inode, ino.Inode, fmt.Sprintf("name_%02d", i), mode)
+		names = append(names, fmt.Sprintf("name_%02d", i), fmt.Sprintf("missing_%02d", i))
+	}
+
+	p := &Packet{}
+	require.NoError(t, mp.BatchLookup(&BatchLookupReq{PartitionID: partitionId, ParentID: parent.Inode, Names: names, VerSeq: mp.verSeq}, p))
+	require.Equal(t, proto.OpOk, p.ResultCode)
+	resp := &BatchLookupResp{}
+	require.NoError(t, json.Unmarshal(p.Data, resp))
+	require.Len(t, resp.Children, count)
+
+	// the batch results are the same as the lookups one by one
+	i := 0
+	for _, name := range names {
+		dentry := &Dentry{ParentId: parent.Inode, Name: name}
+		dentry.setVerSeq(mp.verSeq)
+		d, status := mp.getDentry(dentry)
+		if status != proto.OpOk {
+			continue
+		}
+		require.Equal(t, proto.Dentry{Name: d.Name, Inode: d.Inode, Type: d.Type}, resp.Children[i])
+		i++
+	}
+	require.Equal(t, count, i)
+}
+
+func TestCreateDentryName(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	leader, submits := mockPartitionRaftForXAttrTest(mockCtrl)
+	parent, child := uint64(100), uint64(101)
+	create := func(name string) uint8 {
+		p := &Packet{}
+		leader.CreateDentry(&CreateDentryReq{ParentID: parent, Name: name, Inode: child, Mode: FileModeType}, p, "")
+		return p.ResultCode
+	}
+
+	// 85 multibyte characters of 3 bytes reach the limit exactly
+	boundary := strings.Repeat("字", DefaultMaxDentryNameLen/3)
+	require.Len(t, boundary, DefaultMaxDentryNameLen)
+	require.NoError(t, checkDentryName(boundary))
+	invalid := []string{boundary + "a", strings.Repeat("a", DefaultMaxDentryNameLen+1), "", "a/b", "/", "a\x00b"}
+	for _, name := range invalid {
+		require.Error(t, checkDentryName(name), name)
+		require.Equal(t, proto.OpArgMismatchErr, create(name), name)
+		p := &Packet{}
+		leader.TxCreateDentry(&proto.TxCreateDentryRequest{ParentID: parent, Name: name, Inode: child, TxInfo: proto.NewTransactionInfo(0, proto.TxTypeCreate)}, p, "")
+		require.Equal(t, proto.OpArgMismatchErr, p.ResultCode, name)
+	}
+	// the invalid names are rejected before the proposal
+	require.Zero(t, *submits)
+
+	// the limit is configurable
+	updateMaxDentryNameLen(4)
+	defer updateMaxDentryNameLen(DefaultMaxDentryNameLen)
+	require.Error(t, checkDentryName("abcde"))
+	require.NoError(t, checkDentryName("abcd"))
+
+	// the apply does not depend on the local limit, the replicas apply the same entries alike
+	initMp(t)
+	dir := testCreateInode(t, DirModeType)
+	ino := testCreateInode(t, FileModeType)
+	require.Equal(t, proto.OpOk, mp.fsmCreateDentry(&Dentry{ParentId: dir.Inode, Name: "abcde", Inode: ino.Inode, Type: FileModeType}, false))
+}
@@ -538,6 +538,28 @@ func (mp *metaPartition) fsmAppendExtentsWithCheck(ino *Inode, isSplit bool) (st
 	return
 }
 
+// fsmMergeExtents replaces the adjacent extent keys of the inode with the merged one, the first key of ino.
+// The size and the used space of the inode are unchanged, and no extent is deleted.
+func (mp *metaPartition) fsmMergeExtents(ino *Inode) (status uint8) {
+	item := mp.inodeTree.CopyGet(ino)
+	if item == nil {
+		return proto.OpNotExistErr
+	}
+	fsmIno := item.(*Inode)
+	if fsmIno.ShouldDelete() {
+		return proto.OpNotExistErr
+	}
+
+	eks := ino.Extents.CopyExtents()
+	if len(eks) < 3 {
+		return proto.OpArgMismatchErr
+	}
+	status = fsmIno.MergeExtents(mp.config.PartitionId, eks[0], eks[1:])
+	log.LogInfof("fsmMergeExtents mp[%v] inode[%v] merged ek(%v) keys(%v) status(%v)",
+		mp.config.PartitionId, fsmIno.Inode, eks[0], eks[1:], status)
+	return
+}
+
 func (mp *metaPartition) fsmAppendObjExtents(ino *Inode) (status uint8) {
 	status = proto.OpOk
 	item := mp.inodeTree.CopyGet(ino)
@@ -0,0 +1,90 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// InodeAllocStat is the usage of the inode id range of a meta partition.
+// The ids are handed out in order by the cursor and never reused, so the free ids are always
+// the single run above the cursor and LargestFreeRun equals Free.
+type InodeAllocStat struct {
+	PartitionID    uint64  `json:"pid"`
+	Start          uint64  `json:"start"`
+	End            uint64  `json:"end"`
+	Cursor         uint64  `json:"cursor"`
+	Allocated      uint64  `json:"allocated"`
+	Free           uint64  `json:"free"`
+	LargestFreeRun uint64  `json:"largestFreeRun"`
+	AllocRate      float64 `json:"allocRate"`    // ids per second between the last two samples
+	ExhaustInSec   int64   `json:"exhaustInSec"` // -1 if no allocation is observed
+}
+
+// inodeAllocSampler keeps the last sample of the cursor to estimate the allocation rate.
+type inodeAllocSampler struct {
+	sync.Mutex
+	at     time.Time
+	cursor uint64
+	rate   float64
+}
+
+// sampleInodeAlloc samples the cursor and updates the allocation rate since the last sample.
+func (mp *metaPartition) sampleInodeAlloc(now time.Time) {
+	cursor := atomic.LoadUint64(&mp.config.Cursor)
+	s := &mp.inodeAllocSampler
+	s.Lock()
+	defer s.Unlock()
+	if !s.at.IsZero() && now.After(s.at) {
+		s.rate = 0
+		if cursor > s.cursor {
+			s.rate = float64(cursor-s.cursor) / now.Sub(s.at).Seconds()
+		}
+	}
+	s.at = now
+	s.cursor = cursor
+}
+
+// GetInodeAllocStat returns the allocated and free inode ids and when the free ones run out at the recent rate.
+func (mp *metaPartition) GetInodeAllocStat() *InodeAllocStat {
+	stat := &InodeAllocStat{
+		PartitionID:  mp.config.PartitionId,
+		Start:        mp.config.Start,
+		End:          mp.config.End,
+		Cursor:       atomic.LoadUint64(&mp.config.Cursor),
+		ExhaustInSec: -1,
+	}
+	if stat.Cursor > stat.Start {
+		stat.Allocated = stat.Cursor - stat.Start
+	}
+	if stat.End > stat.Cursor {
+		stat.Free = stat.End - stat.Cursor
+	}
+	stat.LargestFreeRun = stat.Free
+
+	mp.inodeAllocSampler.Lock()
+	stat.AllocRate = mp.inodeAllocSampler.rate
+	mp.inodeAllocSampler.Unlock()
+	if stat.AllocRate > 0 {
+		stat.ExhaustInSec = math.MaxInt64
+		if sec := float64(stat.Free) / stat.AllocRate; sec < math.MaxInt64 {
+			stat.ExhaustInSec = int64(sec)
+		}
+	}
+	return stat
+}
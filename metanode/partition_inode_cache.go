@@ -0,0 +1,83 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// EvictInodeCache evicts the data cached of the listed inodes, if ttl is positive only the inodes not accessed
+// within ttl are evicted, and all the inodes are candidates if none is listed. It returns the number of the
+// inodes evicted, the eviction stops at the first error.
+func (mp *metaPartition) EvictInodeCache(inodes []uint64, ttl time.Duration) (evicted int, err error) {
+	if !proto.IsCold(mp.volType) {
+		return 0, fmt.Errorf("vol %v is not a cold volume, the extents are not cache", mp.config.VolName)
+	}
+	if len(inodes) == 0 && ttl <= 0 {
+		return 0, fmt.Errorf("neither inodes nor ttl is specified")
+	}
+	if _, ok := mp.IsLeader(); !ok {
+		return 0, fmt.Errorf("mp %v is not the leader", mp.config.PartitionId)
+	}
+
+	expire := time.Now().Add(-ttl).Unix()
+	isCandidate := func(ino *Inode) bool {
+		if ino.ShouldDelete() || ino.Extents.Len() == 0 {
+			return false
+		}
+		return ttl <= 0 || ino.AccessTime < expire
+	}
+	candidates := make([]uint64, 0)
+	if len(inodes) > 0 {
+		for _, id := range inodes {
+			item := mp.inodeTree.Get(NewInode(id, 0))
+			if item != nil && isCandidate(item.(*Inode)) {
+				candidates = append(candidates, id)
+			}
+		}
+	} else {
+		mp.inodeTree.Ascend(func(i BtreeItem) bool {
+			if ino := i.(*Inode); isCandidate(ino) {
+				candidates = append(candidates, ino.Inode)
+			}
+			return true
+		})
+	}
+
+	for _, id := range candidates {
+		if len(mp.extDelCh) > defaultDelExtentsCnt-100 {
+			err = fmt.Errorf("extent del chan full")
+			break
+		}
+		var val []byte
+		if val, err = NewInode(id, 0).Marshal(); err != nil {
+			break
+		}
+		var resp interface{}
+		if resp, err = mp.submit(opFSMClearInodeCache, val); err != nil {
+			break
+		}
+		if resp.(uint8) == proto.OpOk {
+			evicted++
+		}
+	}
+	log.LogInfof("EvictInodeCache: mp(%v) inodes(%v) ttl(%v) candidates(%v) evicted(%v) err(%v)",
+		mp.config.PartitionId, len(inodes), ttl, len(candidates), evicted, err)
+	return
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvictInodeCache(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp, _ := mockPartitionRaftForXAttrTest(mockCtrl)
+	mp.config.NodeId = 1
+
+	now := time.Now()
+	stale, fresh := now.Add(-2*time.Hour).Unix(), now.Unix()
+	cached := func(id uint64) bool {
+		return mp.inodeTree.Get(NewInode(id, 0)).(*Inode).Extents.Len() > 0
+	}
+	// inode id -> access time, whether the data is cached
+	for id, ino := range map[uint64]struct {
+		accessTime int64
+		cached     bool
+	}{
+		10: {stale, true},
+		11: {fresh, true},
+		12: {stale, false},
+		13: {stale, true},
+	} {
+		inode := NewInode(id, proto.Mode(0o644))
+		inode.AccessTime = ino.accessTime
+		if ino.cached {
+			inode.Extents.eks = []proto.ExtentKey{{FileOffset: 0, PartitionId: 1, ExtentId: id, Size: 4096}}
+		}
+		mp.inodeTree.ReplaceOrInsert(inode, true)
+	}
+
+	// the extents of a hot volume are the data rather than cache
+	_, err := mp.EvictInodeCache(nil, time.Hour)
+	require.Error(t, err)
+	mp.volType = proto.VolumeTypeCold
+	// evicting everything needs to be explicit
+	_, err = mp.EvictInodeCache(nil, 0)
+	require.Error(t, err)
+
+	// only the stale inodes having data cached are evicted
+	evicted, err := mp.EvictInodeCache(nil, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 2, evicted)
+	require.False(t, cached(10))
+	require.True(t, cached(11))
+	require.False(t, cached(13))
+
+	// the listed inodes are evicted regardless of the access time, the missing ones are skipped
+	evicted, err = mp.EvictInodeCache([]uint64{11, 12, 999}, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, evicted)
+	require.False(t, cached(11))
+
+	// the followers do not evict
+	mp.config.NodeId = 2
+	_, err = mp.EvictInodeCache([]uint64{11}, 0)
+	require.Error(t, err)
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+// the default max dentries scanned to resolve the paths of an inode
+const defaultResolvePathScanLimit = 1 << 20
+
+// InodePaths is the result of resolving the paths of an inode.
+type InodePaths struct {
+	Inode uint64
+	// the paths from the root, more than one if the inode has hard links
+	Paths []string
+	// the paths whose ancestors are not in the partition, beginning with the inode of the farthest ancestor found,
+	// e.g. "ino(1024)/a/b", the rest of the path is on the partition of that inode's parent.
+	PartialPaths []string
+	// the count of the dentries scanned, the cost of resolving
+	Scanned int
+	// true if the scan stopped at the limit before all the paths were resolved
+	Truncated bool
+}
+
+// ResolveInodePaths finds the paths of the inode by walking up its parent dentries.
+// There is no reverse index from an inode to its dentries, so every level scans the whole dentry tree,
+// which costs O(depth * dentries). The scan stops once limit dentries are scanned.
+func (mp *metaPartition) ResolveInodePaths(ino uint64, limit int) (result *InodePaths) {
+	result = &InodePaths{Inode: ino}
+	if limit <= 0 {
+		limit = defaultResolvePathScanLimit
+	}
+	tree := mp.GetDentryTree().GetTree()
+
+	// parents of the inodes visited, key: child inode
+	parents := make(map[uint64][]*Dentry)
+	visited := map[uint64]bool{ino: true}
+	frontier := map[uint64]bool{ino: true}
+	if ino == proto.RootIno {
+		frontier = nil
+	}
+	for len(frontier) > 0 && !result.Truncated {
+		tree.Ascend(func(i BtreeItem) bool {
+			if result.Scanned >= limit {
+				result.Truncated = true
+				return false
+			}
+			result.Scanned++
+			d := i.(*Dentry)
+			if frontier[d.Inode] && !d.isDeleted() {
+				parents[d.Inode] = append(parents[d.Inode], d)
+			}
+			return true
+		})
+		next := make(map[uint64]bool)
+		for child := range frontier {
+			for _, d := range parents[child] {
+				if d.ParentId != proto.RootIno && !visited[d.ParentId] {
+					visited[d.ParentId] = true
+					next[d.ParentId] = true
+				}
+			}
+		}
+		frontier = next
+	}
+
+	var walk func(ino uint64, suffix string, depth int)
+	walk = func(ino uint64, suffix string, depth int) {
+		if ino == proto.RootIno {
+			result.Paths = append(result.Paths, path.Join("/", suffix))
+			return
+		}
+		ds := parents[ino]
+		// a directory can't be an ancestor of itself, the depth guards against a corrupted tree
+		if len(ds) == 0 || depth > len(visited) {
+			result.PartialPaths = append(result.PartialPaths, path.Join(fmt.Sprintf("ino(%v)", ino), suffix))
+			return
+		}
+		for _, d := range ds {
+			walk(d.ParentId, path.Join(d.Name, suffix), depth+1)
+		}
+	}
+	walk(ino, "", 0)
+	sort.Strings(result.Paths)
+	sort.Strings(result.PartialPaths)
+	return
+}
@@ -17,6 +17,7 @@ package metanode
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -26,6 +27,21 @@ import (
 	"github.com/cubefs/cubefs/util/log"
 )
 
+// checkDentryName rejects the names which are empty, longer than MaxDentryNameLen bytes, or contain NUL or '/'.
+// The limit is node local, so it is checked before the proposal rather than in the apply.
+func checkDentryName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty name")
+	}
+	if maxLen := MaxDentryNameLen(); uint32(len(name)) > maxLen {
+		return fmt.Errorf("name length %v exceeds %v bytes", len(name), maxLen)
+	}
+	if strings.ContainsAny(name, "\x00/") {
+		return fmt.Errorf("name contains NUL or '/'")
+	}
+	return nil
+}
+
 func (mp *metaPartition) TxCreateDentry(req *proto.TxCreateDentryRequest, p *Packet, remoteAddr string) (err error) {
 	start := time.Now()
 	if mp.IsEnableAuditLog() {
@@ -38,6 +54,10 @@ func (mp *metaPartition) TxCreateDentry(req *proto.TxCreateDentryRequest, p *Pac
 		p.PacketErrorWithBody(proto.OpExistErr, []byte(err.Error()))
 		return
 	}
+	if err = checkDentryName(req.Name); err != nil {
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+		return
+	}
 
 	for _, quotaId := range req.QuotaIds {
 		status := mp.mqMgr.IsOverQuota(false, true, quotaId)
@@ -95,6 +115,10 @@ func (mp *metaPartition) CreateDentry(req *CreateDentryReq, p *Packet, remoteAdd
 		p.PacketErrorWithBody(proto.OpExistErr, []byte(err.Error()))
 		return
 	}
+	if err = checkDentryName(req.Name); err != nil {
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+		return
+	}
 
 	item := mp.inodeTree.CopyGet(NewInode(req.ParentID, 0))
 	if item == nil {
@@ -504,6 +528,7 @@ func (mp *metaPartition) UpdateDentry(req *UpdateDentryReq, p *Packet, remoteAdd
 }
 
 func (mp *metaPartition) ReadDirOnly(req *ReadDirOnlyReq, p *Packet) (err error) {
+	mp.accessStats.record(req.ParentID)
 	resp := mp.readDirOnly(req)
 	reply, err := json.Marshal(resp)
 	if err != nil {
@@ -516,6 +541,7 @@ func (mp *metaPartition) ReadDirOnly(req *ReadDirOnlyReq, p *Packet) (err error)
 
 // ReadDir reads the directory based on the given request.
 func (mp *metaPartition) ReadDir(req *ReadDirReq, p *Packet) (err error) {
+	mp.accessStats.record(req.ParentID)
 	resp := mp.readDir(req)
 	reply, err := json.Marshal(resp)
 	if err != nil {
@@ -528,18 +554,18 @@ func (mp *metaPartition) ReadDir(req *ReadDirReq, p *Packet) (err error) {
 
 func (mp *metaPartition) ReadDirLimit(req *ReadDirLimitReq, p *Packet) (err error) {
 	log.LogInfof("action[ReadDirLimit] read seq [%v], request[%v]", req.VerSeq, req)
+	mp.accessStats.record(req.ParentID)
 	resp := mp.readDirLimit(req)
-	reply, err := json.Marshal(resp)
-	if err != nil {
+	if err = p.PacketOkWithData(resp); err != nil {
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		return
 	}
-	p.PacketOkWithBody(reply)
 	return
 }
 
 // Lookup looks up the given dentry from the request.
 func (mp *metaPartition) Lookup(req *LookupReq, p *Packet) (err error) {
+	mp.accessStats.record(req.ParentID)
 	dentry := &Dentry{
 		ParentId: req.ParentID,
 		Name:     req.Name,
@@ -580,6 +606,28 @@ func (mp *metaPartition) Lookup(req *LookupReq, p *Packet) (err error) {
 	return
 }
 
+// BatchLookup looks up the names of a parent in one request.
+func (mp *metaPartition) BatchLookup(req *BatchLookupReq, p *Packet) (err error) {
+	mp.accessStats.record(req.ParentID)
+	resp := &BatchLookupResp{
+		Children: make([]proto.Dentry, 0, len(req.Names)),
+	}
+	for _, d := range mp.getDentryBatch(req.ParentID, req.Names, req.VerSeq) {
+		resp.Children = append(resp.Children, proto.Dentry{
+			Inode: d.Inode,
+			Type:  d.Type,
+			Name:  d.Name,
+		})
+	}
+	reply, err := json.Marshal(resp)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkWithBody(reply)
+	return
+}
+
 // GetDentryTree returns the dentry tree stored in the meta partition.
 func (mp *metaPartition) GetDentryTree() *BTree {
 	return mp.dentryTree.GetTree()
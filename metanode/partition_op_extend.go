@@ -216,6 +216,20 @@ func (mp *metaPartition) RemoveXAttr(req *proto.RemoveXAttrRequest, p *Packet) (
 	return
 }
 
+// BatchRemoveXAttr removes the keys of the inode in a single raft proposal.
+func (mp *metaPartition) BatchRemoveXAttr(req *proto.BatchRemoveXAttrRequest, p *Packet) (err error) {
+	extend := NewExtend(req.Inode)
+	for _, key := range req.Keys {
+		extend.Put([]byte(key), nil, req.VerSeq)
+	}
+	if _, err = mp.putExtend(opFSMRemoveXAttr, extend); err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	p.PacketOkReply()
+	return
+}
+
 func (mp *metaPartition) ListXAttr(req *proto.ListXAttrRequest, p *Packet) (err error) {
 	response := &proto.ListXAttrResponse{
 		VolName:     req.VolName,
@@ -0,0 +1,109 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	raftstoremock "github.com/cubefs/cubefs/util/mocktest/raftstore"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// mockPartitionRaftForXAttrTest returns a partition applying the proposals at once and the number of them.
+func mockPartitionRaftForXAttrTest(ctrl *gomock.Controller) (*metaPartition, *int) {
+	partition := NewMetaPartitionForQuotaTest()
+	raft := raftstoremock.NewMockPartition(ctrl)
+	submits := 0
+	raft.EXPECT().Submit(gomock.Any()).DoAndReturn(func(cmd []byte) (resp interface{}, err error) {
+		submits++
+		return partition.Apply(cmd, uint64(submits))
+	}).AnyTimes()
+	raft.EXPECT().LeaderTerm().Return(uint64(1), uint64(1)).AnyTimes()
+	partition.raftPartition = raft
+	return partition, &submits
+}
+
+func TestBatchXAttr(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp, submits := mockPartitionRaftForXAttrTest(mockCtrl)
+
+	getAll := func(ino uint64) map[string]string {
+		p := &Packet{}
+		require.NoError(t, mp.GetAllXAttr(&proto.GetAllXAttrRequest{Inode: ino}, p))
+		require.Equal(t, proto.OpOk, p.ResultCode)
+		resp := &proto.GetAllXAttrResponse{}
+		require.NoError(t, json.Unmarshal(p.Data, resp))
+		return resp.Attrs
+	}
+
+	const perKeyIno, batchIno = 10, 11
+	attrs := make(map[string]string)
+	keys := make([]string, 0)
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("user.k%d", i)
+		attrs[key] = fmt.Sprintf("v%d", i)
+		keys = append(keys, key)
+	}
+
+	// per key set takes a proposal for each key while the batch takes only one
+	for key, val := range attrs {
+		p := &Packet{}
+		require.NoError(t, mp.SetXAttr(&proto.SetXAttrRequest{Inode: perKeyIno, Key: key, Value: val}, p))
+		require.Equal(t, proto.OpOk, p.ResultCode)
+	}
+	require.Equal(t, len(attrs), *submits)
+	*submits = 0
+	p := &Packet{}
+	require.NoError(t, mp.BatchSetXAttr(&proto.BatchSetXAttrRequest{Inode: batchIno, Attrs: attrs}, p))
+	require.Equal(t, proto.OpOk, p.ResultCode)
+	require.Equal(t, 1, *submits)
+
+	require.Equal(t, attrs, getAll(perKeyIno))
+	require.Equal(t, getAll(perKeyIno), getAll(batchIno))
+	for key, val := range attrs {
+		p = &Packet{}
+		require.NoError(t, mp.GetXAttr(&proto.GetXAttrRequest{Inode: batchIno, Key: key}, p))
+		resp := &proto.GetXAttrResponse{}
+		require.NoError(t, json.Unmarshal(p.Data, resp))
+		require.Equal(t, val, resp.Value)
+	}
+
+	// same for removal
+	removed := keys[:5]
+	*submits = 0
+	for _, key := range removed {
+		p = &Packet{}
+		require.NoError(t, mp.RemoveXAttr(&proto.RemoveXAttrRequest{Inode: perKeyIno, Key: key}, p))
+		require.Equal(t, proto.OpOk, p.ResultCode)
+	}
+	require.Equal(t, len(removed), *submits)
+	*submits = 0
+	p = &Packet{}
+	require.NoError(t, mp.BatchRemoveXAttr(&proto.BatchRemoveXAttrRequest{Inode: batchIno, Keys: removed}, p))
+	require.Equal(t, proto.OpOk, p.ResultCode)
+	require.Equal(t, 1, *submits)
+
+	left := getAll(batchIno)
+	require.Len(t, left, len(keys)-len(removed))
+	for _, key := range removed {
+		require.NotContains(t, left, key)
+	}
+	require.Equal(t, getAll(perKeyIno), left)
+}
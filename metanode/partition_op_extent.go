@@ -166,6 +166,37 @@ func (mp *metaPartition) ExtentAppendWithCheck(req *proto.AppendExtentKeyWithChe
 	return
 }
 
+// ExtentsMerge replaces the adjacent extent keys of an inode with the merged one.
+// Format: the merged extent key followed by the keys it replaces.
+func (mp *metaPartition) ExtentsMerge(req *proto.MergeExtentKeysRequest, p *Packet) (err error) {
+	if !proto.IsHot(mp.volType) {
+		err = fmt.Errorf("only support hot vol")
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	if len(req.Extents) < 2 {
+		err = fmt.Errorf("merge %v extent keys", len(req.Extents))
+		p.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(err.Error()))
+		return
+	}
+	ino := NewInode(req.Inode, 0)
+	ino.Extents.eks = make([]proto.ExtentKey, 0, len(req.Extents)+1)
+	ino.Extents.eks = append(ino.Extents.eks, req.Extent)
+	ino.Extents.eks = append(ino.Extents.eks, req.Extents...)
+	val, err := ino.Marshal()
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
+		return
+	}
+	resp, err := mp.submit(opFSMExtentsMerge, val)
+	if err != nil {
+		p.PacketErrorWithBody(proto.OpAgain, []byte(err.Error()))
+		return
+	}
+	p.PacketErrorWithBody(resp.(uint8), nil)
+	return
+}
+
 func (mp *metaPartition) SetTxInfo(info []*proto.TxInfo) {
 	for _, txInfo := range info {
 		if txInfo.Volume != mp.config.VolName {
@@ -0,0 +1,94 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtentsMerge(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mp, _ := mockPartitionRaftForXAttrTest(mockCtrl)
+	mp.uidManager = NewUidMgr(mp.config.VolName, mp.config.PartitionId)
+
+	const (
+		inode    = 100
+		extentID = 1025
+		ekSize   = 1000
+		appends  = 4
+	)
+	require.Equal(t, proto.OpOk, mp.fsmCreateInode(NewInode(inode, 0)))
+	ino := mp.inodeTree.Get(NewInode(inode, 0)).(*Inode)
+
+	// many small appends to the same extent take split keys, then one to another extent
+	for i := 0; i < appends; i++ {
+		ek := proto.ExtentKey{FileOffset: uint64(i * ekSize), PartitionId: 1, ExtentId: extentID, ExtentOffset: uint64(i * ekSize), Size: ekSize}
+		p := &Packet{}
+		require.NoError(t, mp.ExtentAppendWithCheck(&proto.AppendExtentKeyWithCheckRequest{Inode: inode, Extent: ek}, p))
+		require.Equal(t, proto.OpOk, p.ResultCode)
+	}
+	p := &Packet{}
+	ek := proto.ExtentKey{FileOffset: appends * ekSize, PartitionId: 1, ExtentId: extentID + 1, Size: ekSize}
+	require.NoError(t, mp.ExtentAppendWithCheck(&proto.AppendExtentKeyWithCheckRequest{Inode: inode, Extent: ek}, p))
+	require.Equal(t, proto.OpOk, p.ResultCode)
+
+	eks := ino.Extents.CopyExtents()
+	require.Len(t, eks, appends+1)
+	for _, ek := range eks[:appends] {
+		require.True(t, ek.IsSplit())
+	}
+	for len(mp.extDelCh) > 0 {
+		require.Empty(t, <-mp.extDelCh)
+	}
+
+	merge := func(merged proto.ExtentKey, keys []proto.ExtentKey) uint8 {
+		p := &Packet{}
+		mp.ExtentsMerge(&proto.MergeExtentKeysRequest{Inode: inode, Extent: merged, Extents: keys}, p)
+		return p.ResultCode
+	}
+	merged := eks[0]
+	merged.SnapInfo = nil
+	merged.Size = appends * ekSize
+
+	// the merged key must span the keys, which must be adjacent ones of the same extent
+	short := merged
+	short.Size -= ekSize
+	require.Equal(t, proto.OpArgMismatchErr, merge(short, eks[:appends]))
+	require.Equal(t, proto.OpArgMismatchErr, merge(merged, eks[appends-1:]))
+	require.Equal(t, eks, ino.Extents.CopyExtents())
+
+	require.Equal(t, proto.OpOk, merge(merged, eks[:appends]))
+	merged.SetSplit(true)
+	require.Equal(t, []proto.ExtentKey{merged, eks[appends]}, ino.Extents.CopyExtents())
+	require.Equal(t, uint64((appends+1)*ekSize), ino.Size)
+	// nothing is deleted, and the merged key holds the only ref of the extent
+	require.Len(t, mp.extDelCh, 0)
+	ref, ok := ino.multiSnap.ekRefMap.Load(uint64(1)<<32 | extentID)
+	require.True(t, ok)
+	require.Equal(t, uint32(1), ref)
+
+	// the keys replaced already conflict
+	require.Equal(t, proto.OpConflictExtentsErr, merge(merged, eks[:appends]))
+
+	// deleting the merged key deletes the whole extent
+	del := []proto.ExtentKey{merged}
+	ino.DecSplitExts(mp.config.PartitionId, del)
+	require.False(t, del[0].IsSplit())
+}
@@ -568,12 +568,10 @@ func (mp *metaPartition) InodeGetBatch(req *InodeGetReqBatch, p *Packet) (err er
 			}
 		}
 	}
-	data, err := json.Marshal(resp)
-	if err != nil {
+	if err = p.PacketOkWithData(resp); err != nil {
 		p.PacketErrorWithBody(proto.OpErr, []byte(err.Error()))
 		return
 	}
-	p.PacketOkWithBody(data)
 	return
 }
 
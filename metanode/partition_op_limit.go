@@ -0,0 +1,144 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+	"golang.org/x/time/rate"
+)
+
+// the classes of the heavy read ops which are rate limited per partition
+const (
+	heavyOpReadDir       = "readDir"
+	heavyOpListXAttr     = "listXAttr"
+	heavyOpBatchInodeGet = "batchInodeGet"
+
+	heavyOpThrottledMetricName = "heavyOpThrottled"
+)
+
+var (
+	heavyOpClasses = []string{heavyOpReadDir, heavyOpListXAttr, heavyOpBatchInodeGet}
+
+	// the limits in ops per second of each partition by op class, unlimited if absent
+	heavyOpLimitsLock sync.RWMutex
+	heavyOpLimits     = make(map[string]int64)
+)
+
+// heavyOpClass returns the class of the op, empty if the op is not limited.
+func heavyOpClass(opcode uint8) string {
+	switch opcode {
+	case proto.OpMetaReadDir, proto.OpMetaReadDirOnly, proto.OpMetaReadDirLimit:
+		return heavyOpReadDir
+	case proto.OpMetaListXAttr:
+		return heavyOpListXAttr
+	case proto.OpMetaBatchInodeGet:
+		return heavyOpBatchInodeGet
+	}
+	return ""
+}
+
+// HeavyOpLimits returns the limits of all the op classes, zero means unlimited.
+func HeavyOpLimits() map[string]int64 {
+	heavyOpLimitsLock.RLock()
+	defer heavyOpLimitsLock.RUnlock()
+	limits := make(map[string]int64, len(heavyOpClasses))
+	for _, class := range heavyOpClasses {
+		limits[class] = heavyOpLimits[class]
+	}
+	return limits
+}
+
+func heavyOpLimit(class string) int64 {
+	heavyOpLimitsLock.RLock()
+	defer heavyOpLimitsLock.RUnlock()
+	return heavyOpLimits[class]
+}
+
+// updateHeavyOpLimit sets the limit in ops per second of the op class for each partition, zero turns it off.
+func updateHeavyOpLimit(class string, limit int64) error {
+	if limit < 0 {
+		return fmt.Errorf("invalid limit %v of op class %v", limit, class)
+	}
+	for _, c := range heavyOpClasses {
+		if c != class {
+			continue
+		}
+		heavyOpLimitsLock.Lock()
+		if limit == 0 {
+			delete(heavyOpLimits, class)
+		} else {
+			heavyOpLimits[class] = limit
+		}
+		heavyOpLimitsLock.Unlock()
+		return nil
+	}
+	return fmt.Errorf("unknown op class %v, expect one of %v", class, heavyOpClasses)
+}
+
+// heavyOpLimiter holds the token buckets of the op classes of a partition.
+type heavyOpLimiter struct {
+	sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// allow takes a token of the op class, the bucket follows the current limit of the class.
+func (l *heavyOpLimiter) allow(class string) bool {
+	limit := heavyOpLimit(class)
+	l.Lock()
+	defer l.Unlock()
+	if limit <= 0 {
+		delete(l.limiters, class)
+		return true
+	}
+	lim, ok := l.limiters[class]
+	if !ok || lim.Limit() != rate.Limit(limit) {
+		if l.limiters == nil {
+			l.limiters = make(map[string]*rate.Limiter)
+		}
+		lim = rate.NewLimiter(rate.Limit(limit), int(limit))
+		l.limiters[class] = lim
+	}
+	return lim.Allow()
+}
+
+// throttleHeavyOp replies OpAgain to the client if the heavy op exceeds the limit of its partition.
+func (m *metadataManager) throttleHeavyOp(conn net.Conn, p *Packet) bool {
+	class := heavyOpClass(p.Opcode)
+	if class == "" {
+		return false
+	}
+	partition, err := m.getPartition(p.PartitionID)
+	if err != nil {
+		return false
+	}
+	mp, ok := partition.(*metaPartition)
+	if !ok || mp.heavyOpLimiter.allow(class) {
+		return false
+	}
+	exporter.NewCounter(heavyOpThrottledMetricName).AddWithLabels(1, map[string]string{
+		exporter.Vol: mp.config.VolName,
+		exporter.Op:  class,
+	})
+	log.LogDebugf("throttleHeavyOp: mp(%v) op(%v) exceeds the limit %v", mp.config.PartitionId, p.GetOpMsg(), heavyOpLimit(class))
+	p.PacketErrorWithBody(proto.OpAgain, []byte(fmt.Sprintf("%v ops of partition %v are throttled", class, mp.config.PartitionId)))
+	m.respondToClient(conn, p)
+	return true
+}
@@ -15,6 +15,7 @@
 package metanode
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/cubefs/cubefs/proto"
@@ -127,6 +128,102 @@ func TestGetQuotaReportInfos(t *testing.T) {
 	require.Equal(t, info, infos[0])
 }
 
+func TestGetQuotaReportInfosNearLimit(t *testing.T) {
+	partition := NewMetaPartitionForQuotaTest()
+	var quotaId uint32 = 1
+	partition.mqMgr.setQuotaHbInfo([]*proto.QuotaHeartBeatInfo{{
+		VolName:  VolNameForTest,
+		QuotaId:  quotaId,
+		Enable:   true,
+		MaxFiles: 100,
+		MaxBytes: 1000,
+	}})
+	partition.mqMgr.statisticBase.Store(quotaId, proto.QuotaUsedInfo{UsedFiles: 10, UsedBytes: 800})
+	infos := partition.mqMgr.getQuotaReportInfos()
+	require.Len(t, infos, 1)
+	require.False(t, infos[0].NearLimit)
+
+	// the used bytes reach 90% of the max bytes
+	partition.mqMgr.updateUsedInfo(100, 0, quotaId)
+	infos = partition.mqMgr.getQuotaReportInfos()
+	require.True(t, infos[0].NearLimit)
+	require.Equal(t, proto.QuotaUsedInfo{UsedFiles: 10, UsedBytes: 900}, infos[0].UsedInfo)
+
+	partition.mqMgr.updateUsedInfo(-500, 0, quotaId)
+	require.False(t, partition.mqMgr.getQuotaReportInfos()[0].NearLimit)
+
+	// the used files reach the threshold as well
+	partition.mqMgr.updateUsedInfo(0, 85, quotaId)
+	require.True(t, partition.mqMgr.getQuotaReportInfos()[0].NearLimit)
+
+	threshold := QuotaSoftThreshold()
+	defer updateQuotaSoftThreshold(threshold)
+	updateQuotaSoftThreshold(100)
+	require.False(t, partition.mqMgr.getQuotaReportInfos()[0].NearLimit)
+
+	// the reports may run concurrently under the read lock
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			partition.mqMgr.getQuotaReportInfos()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestQuotaStatisticMarshal(t *testing.T) {
+	statistic := map[uint32]proto.QuotaUsedInfo{
+		1:   {UsedFiles: 3, UsedBytes: 300},
+		2:   {UsedFiles: 0, UsedBytes: -1},
+		100: {UsedFiles: 1 << 40, UsedBytes: 1 << 62},
+	}
+	data := marshalQuotaStatistic(statistic)
+	// the format is stable regardless of the map order
+	require.Equal(t, data, marshalQuotaStatistic(statistic))
+	result, err := unmarshalQuotaStatistic(data)
+	require.NoError(t, err)
+	require.Equal(t, statistic, result)
+
+	result, err = unmarshalQuotaStatistic(marshalQuotaStatistic(nil))
+	require.NoError(t, err)
+	require.Len(t, result, 0)
+
+	data[5]++
+	_, err = unmarshalQuotaStatistic(data)
+	require.Equal(t, ErrSnapshotCrcMismatch, err)
+	_, err = unmarshalQuotaStatistic(data[:4])
+	require.Error(t, err)
+}
+
+func TestQuotaStatisticStoreAndLoad(t *testing.T) {
+	rootDir := t.TempDir()
+	partition := NewMetaPartitionForQuotaTest()
+	var quotaId uint32 = 1
+	partition.mqMgr.statisticBase.Store(quotaId, proto.QuotaUsedInfo{UsedFiles: 3, UsedBytes: 300})
+	partition.mqMgr.updateUsedInfo(100, 1, quotaId)
+	partition.mqMgr.updateUsedInfo(50, 1, quotaId+1)
+	statistic := partition.mqMgr.snapshotStatistic()
+	require.Equal(t, map[uint32]proto.QuotaUsedInfo{
+		quotaId:     {UsedFiles: 4, UsedBytes: 400},
+		quotaId + 1: {UsedFiles: 1, UsedBytes: 50},
+	}, statistic)
+	require.NoError(t, partition.storeQuotaStatistic(rootDir, &storeMsg{quotaStatistic: statistic}))
+
+	restored := NewMetaPartitionForQuotaTest()
+	require.NoError(t, restored.loadQuotaStatistic(rootDir))
+	require.True(t, restored.mqMgr.isStatisticRestored())
+	require.Equal(t, statistic, restored.mqMgr.snapshotStatistic())
+
+	// the statistic is counted on load without the file
+	emptyDir := t.TempDir()
+	require.NoError(t, partition.storeQuotaStatistic(emptyDir, &storeMsg{}))
+	restored = NewMetaPartitionForQuotaTest()
+	require.NoError(t, restored.loadQuotaStatistic(emptyDir))
+	require.False(t, restored.mqMgr.isStatisticRestored())
+}
+
 func NewMetaPartitionForQuotaTest() *metaPartition {
 	mpC := &MetaPartitionConfig{
 		PartitionId: PartitionIdForTest,
@@ -0,0 +1,86 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metanode
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/exporter"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	slowOpMetricName = "slowOp"
+	// request arguments beyond the limit are truncated in the slow op log
+	slowOpMaxArgsLen = 256
+)
+
+// SlowOpStat is the slow op tracing state of a meta partition.
+type SlowOpStat struct {
+	PartitionID uint64 `json:"pid"`
+	ThresholdMs int64  `json:"thresholdMs"`
+	Count       uint64 `json:"count"`
+}
+
+// SetSlowOpThreshold sets the processing time beyond which an op is traced as slow, zero disables the tracing.
+func (mp *metaPartition) SetSlowOpThreshold(threshold time.Duration) {
+	atomic.StoreInt64(&mp.slowOpThreshold, int64(threshold))
+}
+
+// GetSlowOpStat returns the slow op threshold and the number of slow ops traced so far.
+func (mp *metaPartition) GetSlowOpStat() *SlowOpStat {
+	return &SlowOpStat{
+		PartitionID: mp.config.PartitionId,
+		ThresholdMs: time.Duration(atomic.LoadInt64(&mp.slowOpThreshold)).Milliseconds(),
+		Count:       atomic.LoadUint64(&mp.slowOpCount),
+	}
+}
+
+// traceSlowOp counts and logs the op if it took longer than the slow op threshold of the partition.
+func (mp *metaPartition) traceSlowOp(p *Packet, args []byte, remoteAddr string, cost time.Duration) bool {
+	threshold := time.Duration(atomic.LoadInt64(&mp.slowOpThreshold))
+	if threshold <= 0 || cost <= threshold {
+		return false
+	}
+	atomic.AddUint64(&mp.slowOpCount, 1)
+	exporter.NewCounter(slowOpMetricName).AddWithLabels(1, map[string]string{
+		exporter.Op:  p.GetOpMsg(),
+		exporter.Vol: mp.config.VolName,
+	})
+	if len(args) > slowOpMaxArgsLen {
+		args = args[:slowOpMaxArgsLen]
+	}
+	log.LogWarnf("slow op: (%s), args %s, remote %s, cost %v, threshold %v",
+		p.String(), string(args), remoteAddr, cost, threshold)
+	return true
+}
+
+// traceSlowOp traces the op on its partition, the ops not bound to a loaded partition are skipped.
+func (m *metadataManager) traceSlowOp(p *Packet, args []byte, remoteAddr string, cost time.Duration) bool {
+	if p.Opcode == proto.OpMetaNodeHeartbeat || p.Opcode == proto.OpCreateMetaPartition {
+		return false
+	}
+	partition, err := m.getPartition(p.PartitionID)
+	if err != nil {
+		return false
+	}
+	mp, ok := partition.(*metaPartition)
+	if !ok {
+		return false
+	}
+	return mp.traceSlowOp(p, args, remoteAddr, cost)
+}
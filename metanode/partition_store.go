@@ -52,6 +52,7 @@ const (
 	uniqIDFile              = "uniqID"
 	uniqCheckerFile         = "uniqChecker"
 	verdataFile             = "multiVer"
+	quotaStatisticFile      = "quotaStatistic"
 	StaleMetadataSuffix     = ".old"
 	StaleMetadataTimeFormat = "20060102150405.000000000"
 )
@@ -281,6 +282,7 @@ func (mp *metaPartition) loadExtend(rootDir string, crc uint32) (err error) {
 	if _, err = crcCheck.Write(varintTmp[:n]); err != nil {
 		return
 	}
+	statisticRestored := mp.mqMgr.isStatisticRestored()
 	for i := uint64(0); i < numExtends; i++ {
 		// read length
 		var numBytes uint64
@@ -297,12 +299,14 @@ func (mp *metaPartition) loadExtend(rootDir string, crc uint32) (err error) {
 		// log.LogDebugf("loadExtend: new extend from bytes: partitionID (%v) volume(%v) inode[%v]",
 		//	mp.config.PartitionId, mp.config.VolName, extend.inode)
 		_ = mp.fsmSetXAttr(extend)
+		if !statisticRestored {
+			mp.statisticExtendByLoad(extend)
+		}
 
 		if _, err = crcCheck.Write(mem[offset : offset+int(numBytes)]); err != nil {
 			return
 		}
 		offset += int(numBytes)
-		mp.statisticExtendByLoad(extend)
 	}
 
 	log.LogInfof("loadExtend: load complete: partitionID(%v) volume(%v) numExtends(%v) filename(%v)",
@@ -314,6 +318,30 @@ func (mp *metaPartition) loadExtend(rootDir string, crc uint32) (err error) {
 	return nil
 }
 
+// loadQuotaStatistic restores the quota statistic stored with the snapshot, so that the quota
+// usage is not counted by the extends on load. It is skipped if the file does not exist.
+func (mp *metaPartition) loadQuotaStatistic(rootDir string) (err error) {
+	filename := path.Join(rootDir, quotaStatisticFile)
+	if _, err = os.Stat(filename); err != nil {
+		err = nil
+		return
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		err = errors.NewErrorf("[loadQuotaStatistic] ReadFile: %s", err.Error())
+		return
+	}
+	statistic, err := unmarshalQuotaStatistic(data)
+	if err != nil {
+		err = errors.NewErrorf("[loadQuotaStatistic] unmarshal: %s", err.Error())
+		return
+	}
+	mp.mqMgr.restoreStatistic(statistic)
+	log.LogInfof("loadQuotaStatistic: load complete: partitionID(%v) volume(%v) quotas(%v) filename(%v)",
+		mp.config.PartitionId, mp.config.VolName, len(statistic), filename)
+	return
+}
+
 func (mp *metaPartition) loadMultipart(rootDir string, crc uint32) (err error) {
 	filename := path.Join(rootDir, multipartFile)
 	if _, err = os.Stat(filename); err != nil {
@@ -1300,6 +1328,29 @@ func (mp *metaPartition) storeUniqID(rootDir string, sm *storeMsg) (err error) {
 	return
 }
 
+func (mp *metaPartition) storeQuotaStatistic(rootDir string, sm *storeMsg) (err error) {
+	// the statistic is unknown if the snapshot is not taken by the fsm, count it on load then
+	if sm.quotaStatistic == nil {
+		return
+	}
+	filename := path.Join(rootDir, quotaStatisticFile)
+	fp, err := os.OpenFile(filename, os.O_RDWR|os.O_APPEND|os.O_TRUNC|os.
+		O_CREATE, 0o755)
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = fp.Sync()
+		fp.Close()
+	}()
+	if _, err = fp.Write(marshalQuotaStatistic(sm.quotaStatistic)); err != nil {
+		return
+	}
+	log.LogInfof("storeQuotaStatistic: store complete: partitionID(%v) volume(%v) quotas(%v)",
+		mp.config.PartitionId, mp.config.VolName, len(sm.quotaStatistic))
+	return
+}
+
 func (mp *metaPartition) storeUniqChecker(rootDir string, sm *storeMsg) (crc uint32, err error) {
 	filename := path.Join(rootDir, uniqCheckerFile)
 	fp, err := os.OpenFile(filename, os.O_RDWR|os.O_TRUNC|os.O_APPEND|os.
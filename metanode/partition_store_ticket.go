@@ -42,6 +42,7 @@ type storeMsg struct {
 	uniqId         uint64
 	uniqChecker    *uniqChecker
 	multiVerList   []*proto.VolVersionInfo
+	quotaStatistic map[uint32]proto.QuotaUsedInfo
 }
 
 func (mp *metaPartition) startSchedule(curIndex uint64) {
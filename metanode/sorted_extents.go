@@ -451,6 +451,69 @@ func (se *SortedExtents) AppendWithCheck(inodeID uint64, ek proto.ExtentKey, add
 	return
 }
 
+// Merge replaces the keys, the adjacent ones of the same normal extent with the same seq, with the merged key
+// spanning them. Nothing is deleted since the merged key covers the same data of the extent. checkRef is called
+// with the first key and the number of keys if they are split ones, the merge is refused unless it returns true.
+func (se *SortedExtents) Merge(merged proto.ExtentKey, keys []proto.ExtentKey, checkRef func(ek *proto.ExtentKey, cnt int) bool) (replaced []proto.ExtentKey, status uint8) {
+	if len(keys) < 2 {
+		return nil, proto.OpArgMismatchErr
+	}
+
+	se.Lock()
+	defer se.Unlock()
+
+	startIndex := -1
+	for idx, key := range se.eks {
+		if key.FileOffset == keys[0].FileOffset {
+			startIndex = idx
+			break
+		}
+	}
+	if startIndex < 0 || startIndex+len(keys) > len(se.eks) {
+		log.LogWarnf("action[Merge] keys [%v] not found in eks [%v]", keys, se.eks)
+		return nil, proto.OpConflictExtentsErr
+	}
+
+	first := &se.eks[startIndex]
+	size := uint64(0)
+	for idx := range keys {
+		key := &se.eks[startIndex+idx]
+		if key.FileOffset != keys[idx].FileOffset || key.PartitionId != keys[idx].PartitionId ||
+			key.ExtentId != keys[idx].ExtentId || key.ExtentOffset != keys[idx].ExtentOffset || key.Size != keys[idx].Size {
+			log.LogWarnf("action[Merge] key [%v] is changed to [%v]", keys[idx], key)
+			return nil, proto.OpConflictExtentsErr
+		}
+		if idx > 0 && (!se.eks[startIndex+idx-1].IsSequenceWithSameSeq(key) || key.IsSplit() != first.IsSplit()) {
+			log.LogWarnf("action[Merge] key [%v] is not adjacent to [%v]", key, se.eks[startIndex+idx-1])
+			return nil, proto.OpArgMismatchErr
+		}
+		size += uint64(key.Size)
+	}
+	if storage.IsTinyExtent(first.ExtentId) || merged.PartitionId != first.PartitionId || merged.ExtentId != first.ExtentId ||
+		merged.FileOffset != first.FileOffset || merged.ExtentOffset != first.ExtentOffset || uint64(merged.Size) != size {
+		log.LogWarnf("action[Merge] merged key [%v] doesn't span keys [%v]", merged, keys)
+		return nil, proto.OpArgMismatchErr
+	}
+	if first.IsSplit() && (checkRef == nil || !checkRef(first, len(keys))) {
+		log.LogWarnf("action[Merge] refs of split keys [%v] mismatch", keys)
+		return nil, proto.OpArgMismatchErr
+	}
+
+	replaced = make([]proto.ExtentKey, len(keys))
+	copy(replaced, se.eks[startIndex:startIndex+len(keys)])
+
+	// keep the seq and the split flag of the replaced keys
+	ek := *first
+	if first.SnapInfo != nil {
+		snapInfo := *first.SnapInfo
+		ek.SnapInfo = &snapInfo
+	}
+	ek.Size = merged.Size
+	ek.CRC = 0
+	se.instertWithDiscard(ek, startIndex, startIndex+len(keys))
+	return replaced, proto.OpOk
+}
+
 func (se *SortedExtents) Truncate(offset uint64, doOnLastKey func(*proto.ExtentKey), insertRefMap func(ek *proto.ExtentKey)) (deleteExtents []proto.ExtentKey) {
 	var endIndex int
 
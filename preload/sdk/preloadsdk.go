@@ -98,6 +98,7 @@ func NewClient(config PreloadConfig) *PreLoadClient {
 			mainMux := http.NewServeMux()
 			mux := http.NewServeMux()
 			http.HandleFunc(log.SetLogLevelPath, log.SetLogLevel)
+			http.HandleFunc(log.GetLogLevelPath, log.GetLogLevel)
 			mux.Handle("/debug/pprof", http.HandlerFunc(pprof.Index))
 			mux.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
 			mux.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
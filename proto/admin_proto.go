@@ -49,6 +49,7 @@ const (
 	AdminUpdateVol                            = "/vol/update"
 	AdminVolShrink                            = "/vol/shrink"
 	AdminVolExpand                            = "/vol/expand"
+	AdminRenameVol                            = "/vol/rename"
 	AdminVolForbidden                         = "/vol/forbidden"
 	AdminVolEnableAuditLog                    = "/vol/auditlog"
 	AdminCreateVol                            = "/admin/createVol"
@@ -159,6 +160,7 @@ const (
 	PauseDecommissionDisk              = "/disk/pauseDecommission"
 	QueryDecommissionDiskDecoFailedDps = "/disk/queryDecommissionFailedDps"
 	QueryBadDisks                      = "/disk/queryBadDisks"
+	QueryBadDiskHistory                = "/disk/queryBadDiskHistory"
 	QueryDisks                         = "/disk/queryDisks"
 	QueryDiskDetail                    = "/disk/detail"
 	RestoreStoppedAutoDecommissionDisk = "/disk/restoreStoppedAutoDecommissionDisk"
@@ -261,6 +263,7 @@ var GApiInfo map[string]string = map[string]string{
 	"adminupdatevol":                     AdminUpdateVol,
 	"adminvolshrink":                     AdminVolShrink,
 	"adminvolexpand":                     AdminVolExpand,
+	"adminrenamevol":                     AdminRenameVol,
 	"admincreatevol":                     AdminCreateVol,
 	"admingetvol":                        AdminGetVol,
 	"adminclusterfreeze":                 AdminClusterFreeze,
@@ -691,6 +694,9 @@ type HeartBeatRequest struct {
 	ForbiddenVols     []string
 	DisableAuditVols  []string
 	DecommissionDisks []string // NOTE: for datanode
+	// PartitionReportSeq is the seq of the last data partition reports applied by the master, the data node
+	// may only report the partitions changed since then, 0 asks for the full reports.
+	PartitionReportSeq uint64 `json:"partitionReportSeq,omitempty"`
 }
 
 // DataPartitionReport defines the partition report.
@@ -748,6 +754,11 @@ type DataNodeHeartbeatResponse struct {
 	DiskStats           []DiskStat         // key: disk path
 	CpuUtil             float64            `json:"cpuUtil"`
 	IoUtils             map[string]float64 `json:"ioUtil"`
+	// PartitionReportSeq identifies the PartitionReports. If DeltaReportBaseSeq is not 0, PartitionReports
+	// only has the partitions changed since the reports of that seq and DeletedPartitions the ones gone.
+	PartitionReportSeq uint64   `json:"partitionReportSeq,omitempty"`
+	DeltaReportBaseSeq uint64   `json:"deltaReportBaseSeq,omitempty"`
+	DeletedPartitions  []uint64 `json:"deletedPartitions,omitempty"`
 }
 
 // MetaPartitionReport defines the meta partition report.
@@ -779,6 +790,7 @@ type MetaNodeHeartbeatResponse struct {
 	Status               uint8
 	Result               string
 	CpuUtil              float64 `json:"cpuUtil"`
+	Version              string  `json:"version"`
 }
 
 // LcNodeHeartbeatResponse defines the response to the lc node heartbeat.
@@ -1088,6 +1100,7 @@ type SimpleVolView struct {
 	DpSelectorParm          string
 	DefaultZonePrior        bool
 	DpReadOnlyWhenVolFull   bool
+	NearRead                bool
 
 	VolType          int
 	ObjBlockSize     int
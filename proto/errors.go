@@ -100,6 +100,8 @@ var (
 	ErrNodeSetNotExists                        = errors.New("node set not exists")
 	ErrCompressFailed                          = errors.New("compress data failed")
 	ErrDecompressFailed                        = errors.New("decompress data failed")
+	// replied with OpArgMismatchErr by the meta node for the ops it does not support
+	ErrVersionMismatch = errors.New("version mismatch")
 )
 
 // http response error code and error message definitions
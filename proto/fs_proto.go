@@ -550,6 +550,20 @@ type LookupRequest struct {
 	VerAll      bool   `json:"verAll"`
 }
 
+// BatchLookupRequest defines the request to look up the names of a parent in batch.
+type BatchLookupRequest struct {
+	VolName     string   `json:"vol"`
+	PartitionID uint64   `json:"pid"`
+	ParentID    uint64   `json:"pino"`
+	Names       []string `json:"names"`
+	VerSeq      uint64   `json:"seq"`
+}
+
+// BatchLookupResponse defines the response to the batch lookup request, the names not found are absent.
+type BatchLookupResponse struct {
+	Children []Dentry `json:"children"`
+}
+
 type DetryInfo struct {
 	Inode  uint64 `json:"ino"`
 	Mode   uint32 `json:"mode"`
@@ -647,10 +661,25 @@ type ReadDirLimitRequest struct {
 	Limit       uint64 `json:"limit"`
 	VerSeq      uint64 `json:"seq"`
 	VerOpt      uint8  `json:"VerOpt"`
+	// Reverse iterates the dentries in descending order, the marker is the upper bound then.
+	Reverse bool `json:"reverse"`
+	// WithAttrs returns the basic attributes of the children as well.
+	WithAttrs bool `json:"withAttrs,omitempty"`
+}
+
+// DentryAttr is the basic attributes of the inode of a dentry.
+type DentryAttr struct {
+	Size       uint64 `json:"sz"`
+	ModifyTime int64  `json:"mt"`
+	Mode       uint32 `json:"mode"`
+	Nlink      uint32 `json:"nlink"`
 }
 
 type ReadDirLimitResponse struct {
 	Children []Dentry `json:"children"`
+	// Attrs are the attributes of the children in the same order if WithAttrs is requested,
+	// the attribute is nil if the inode is not in the meta partition of the parent.
+	Attrs []*DentryAttr `json:"attrs,omitempty"`
 }
 
 // AppendExtentKeyRequest defines the request to append an extent key.
@@ -671,6 +700,15 @@ type AppendExtentKeyWithCheckRequest struct {
 	IsSplit        bool
 }
 
+// MergeExtentKeysRequest defines the request to replace the adjacent extent keys of an inode with the merged one.
+type MergeExtentKeysRequest struct {
+	VolName     string      `json:"vol"`
+	PartitionID uint64      `json:"pid"`
+	Inode       uint64      `json:"ino"`
+	Extent      ExtentKey   `json:"ek"`
+	Extents     []ExtentKey `json:"eks"`
+}
+
 // AppendObjExtentKeyRequest defines the request to append an obj extent key.
 type AppendObjExtentKeysRequest struct {
 	VolName     string         `json:"vol"`
@@ -833,6 +871,14 @@ type RemoveXAttrRequest struct {
 	VerSeq      uint64 `json:"seq"`
 }
 
+type BatchRemoveXAttrRequest struct {
+	VolName     string   `json:"vol"`
+	PartitionId uint64   `json:"pid"`
+	Inode       uint64   `json:"ino"`
+	Keys        []string `json:"keys"`
+	VerSeq      uint64   `json:"seq"`
+}
+
 type ListXAttrRequest struct {
 	VolName     string `json:"vol"`
 	PartitionId uint64 `json:"pid"`
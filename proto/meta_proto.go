@@ -83,6 +83,8 @@ type QuotaLimitedInfo struct {
 type QuotaReportInfo struct {
 	QuotaId  uint32
 	UsedInfo QuotaUsedInfo
+	// NearLimit is set if the used files or bytes reach the soft threshold of the limits
+	NearLimit bool
 }
 
 type QuotaInfo struct {
@@ -102,6 +104,8 @@ type QuotaHeartBeatInfo struct {
 	QuotaId     uint32
 	LimitedInfo QuotaLimitedInfo
 	Enable      bool
+	MaxFiles    uint64
+	MaxBytes    uint64
 }
 
 type MetaQuotaInfos struct {
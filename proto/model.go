@@ -46,6 +46,7 @@ type MetaNodeInfo struct {
 	PersistenceMetaPartitions []uint64
 	RdOnly                    bool
 	CpuUtil                   float64 `json:"cpuUtil"`
+	Version                   string  `json:"version"`
 }
 
 // DataNode stores all the information about a data node
@@ -360,6 +361,20 @@ type DiskInfos struct {
 	Disks []DiskInfo
 }
 
+// BadDiskRecord is a period during which a disk is reported bad by the heartbeats of its data node.
+type BadDiskRecord struct {
+	DiskPath    string
+	FirstSeen   int64  // unix time of the first heartbeat reporting the disk bad
+	LastSeen    int64  // unix time of the last heartbeat reporting the disk bad
+	RecoverTime int64  // unix time of the heartbeat reporting the disk healthy again, 0 if it is still bad
+	ErrorCount  uint64 // count of the heartbeats reporting the disk bad
+}
+
+type BadDiskHistory struct {
+	Address string
+	Records []BadDiskRecord
+}
+
 type DiscardDataPartitionInfos struct {
 	DiscardDps []DataPartitionInfo
 }
@@ -66,7 +66,13 @@ const (
 	MetaSendTimeout
 	BuffersTotalLimit
 	MaxStreamerLimit
+	FsyncCoalesceWindow
 	EnableAudit
+	CapacityWarnThreshold
+	CapacityFullReadonly
+	HedgeReadDelay
+	HedgeReadMaxPercent
+	ReadPreference
 
 	LocallyProf
 	MinWriteAbleDataPartitionCnt
@@ -160,6 +166,12 @@ func InitMountOptions(opts []MountOption) {
 	opts[BcacheBatchCnt] = MountOption{"bcacheBatchCnt", "The block cache get meta count", "", int64(100000)}
 	opts[BcacheCheckIntervalS] = MountOption{"bcacheCheckIntervalS", "The block cache check interval", "", int64(300)}
 	opts[EnableAudit] = MountOption{"enableAudit", "enable client audit logging", "", false}
+	opts[FsyncCoalesceWindow] = MountOption{"fsyncCoalesceWindow", "Coalesce fsyncs of different files within the window in milliseconds, 0 means disabled", "", int64(0)}
+	opts[CapacityWarnThreshold] = MountOption{"capacityWarnThreshold", "Warn on mount if the used ratio of the volume reaches the percentage, 0 means disabled", "", int64(0)}
+	opts[CapacityFullReadonly] = MountOption{"capacityFullReadonly", "Mount as readonly if the used ratio of the volume reaches capacityWarnThreshold", "", false}
+	opts[HedgeReadDelay] = MountOption{"hedgeReadDelay", "Send a backup follower read to another replica if the first one does not respond within the delay in milliseconds, 0 means disabled", "", int64(0)}
+	opts[HedgeReadMaxPercent] = MountOption{"hedgeReadMaxPercent", "The maximum percentage of the reads which send a backup read", "", int64(10)}
+	opts[ReadPreference] = MountOption{"readPreference", "The policy to choose the replica of a follower read and to fail over, one of leader-first, nearest-first and follower-round-robin, empty for the default one", "", ""}
 	opts[RequestTimeout] = MountOption{"requestTimeout", "The Request Expiration Time", "", int64(0)}
 	opts[MinWriteAbleDataPartitionCnt] = MountOption{
 		"minWriteAbleDataPartitionCnt",
@@ -324,7 +336,13 @@ type MountOptions struct {
 	MetaSendTimeout              int64
 	BuffersTotalLimit            int64
 	MaxStreamerLimit             int64
+	FsyncCoalesceWindow          int64
 	EnableAudit                  bool
+	CapacityWarnThreshold        int64
+	CapacityFullReadonly         bool
+	HedgeReadDelay               int64
+	HedgeReadMaxPercent          int64
+	ReadPreference               string
 	RequestTimeout               int64
 	MinWriteAbleDataPartitionCnt int
 	FileSystemName               string
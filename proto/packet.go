@@ -104,6 +104,8 @@ const (
 	OpMetaBatchGetXAttr      uint8 = 0x39
 	OpMetaExtentAddWithCheck uint8 = 0x3A // Append extent key with discard extents check
 	OpMetaReadDirLimit       uint8 = 0x3D
+	OpMetaBatchLookup        uint8 = 0x3E
+	OpMetaExtentsMerge       uint8 = 0x3F // Replace the adjacent extent keys with the merged one
 
 	// Operations: Master -> MetaNode
 	OpCreateMetaPartition           uint8 = 0x40
@@ -142,6 +144,7 @@ const (
 	OpDataPartitionTryToLeader      uint8 = 0x69
 	OpQos                           uint8 = 0x6A
 	OpStopDataPartitionRepair       uint8 = 0x6B
+	OpVerifyCreateDataPartition     uint8 = 0x6C // the dry run of OpCreateDataPartition, rejected by the older data nodes
 
 	// Operations: MultipartInfo
 	OpCreateMultipart  uint8 = 0x70
@@ -221,8 +224,9 @@ const (
 	OpMetaBatchObjExtentsAdd uint8 = 0xD0
 	OpMetaClearInodeCache    uint8 = 0xD1
 
-	OpMetaBatchSetXAttr uint8 = 0xD2
-	OpMetaGetAllXAttr   uint8 = 0xD3
+	OpMetaBatchSetXAttr    uint8 = 0xD2
+	OpMetaGetAllXAttr      uint8 = 0xD3
+	OpMetaBatchRemoveXAttr uint8 = 0xD4
 
 	// transaction error
 
@@ -362,6 +366,14 @@ func (p *Packet) String() string {
 		p.ReqID, p.GetOpMsg(), p.PartitionID, p.GetResultMsg(), p.ExtentID, p.ExtentOffset, p.KernelOffset, p.ExtentType, p.VerSeq, p.Size)
 }
 
+// DebugString returns all the header fields of the packet, used for protocol debugging.
+func (p *Packet) DebugString() string {
+	return fmt.Sprintf("Magic(%#x)ExtentType(%#x)Op(%v)ResultCode(%v)RemainingFollowers(%v)CRC(%v)Size(%v)ArgLen(%v)"+
+		"PartitionID(%v)ExtentID(%v)ExtentOffset(%v)ReqID(%v)KernelOffset(%v)VerSeq(%v)VerList(%v)",
+		p.Magic, p.ExtentType, p.GetOpMsg(), p.GetResultMsg(), p.RemainingFollowers, p.CRC, p.Size, p.ArgLen,
+		p.PartitionID, p.ExtentID, p.ExtentOffset, p.ReqID, p.KernelOffset, p.VerSeq, len(p.VerList))
+}
+
 // GetStoreType returns the store type.
 func (p *Packet) GetStoreType() (m string) {
 	if IsNormalExtentType(p.ExtentType) {
@@ -438,6 +450,8 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaReadDir"
 	case OpMetaReadDirLimit:
 		m = "OpMetaReadDirLimit"
+	case OpMetaBatchLookup:
+		m = "OpMetaBatchLookup"
 	case OpMetaInodeGet:
 		m = "OpMetaInodeGet"
 	case OpMetaBatchInodeGet:
@@ -446,6 +460,8 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaExtentsAdd"
 	case OpMetaExtentAddWithCheck:
 		m = "OpMetaExtentAddWithCheck"
+	case OpMetaExtentsMerge:
+		m = "OpMetaExtentsMerge"
 	case OpMetaObjExtentAdd:
 		m = "OpMetaObjExtentAdd"
 	case OpMetaExtentsDel:
@@ -544,6 +560,8 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaGetXAttr"
 	case OpMetaRemoveXAttr:
 		m = "OpMetaRemoveXAttr"
+	case OpMetaBatchRemoveXAttr:
+		m = "OpMetaBatchRemoveXAttr"
 	case OpMetaListXAttr:
 		m = "OpMetaListXAttr"
 	case OpMetaBatchGetXAttr:
@@ -596,6 +614,8 @@ func (p *Packet) GetOpMsg() (m string) {
 		m = "OpMetaGetInodeQuota"
 	case OpStopDataPartitionRepair:
 		m = "OpStopDataPartitionRepair"
+	case OpVerifyCreateDataPartition:
+		m = "OpVerifyCreateDataPartition"
 	case OpLcNodeHeartbeat:
 		m = "OpLcNodeHeartbeat"
 	case OpLcNodeScan:
@@ -798,19 +818,22 @@ func (p *Packet) UnmarshalVersionSlice(cnt int, d []byte) error {
 	return nil
 }
 
-// MarshalData marshals the packet data.
+// MarshalData marshals the packet data, and compresses it if needed.
 func (p *Packet) MarshalData(v interface{}) error {
 	data, err := json.Marshal(v)
-	if err == nil {
-		p.Data = data
-		p.Size = uint32(len(p.Data))
+	if err != nil {
+		return err
 	}
-	return err
+	return p.compressData(data)
 }
 
-// UnmarshalData unmarshals the packet data.
+// UnmarshalData decompresses the packet data if it is compressed, and unmarshals it.
 func (p *Packet) UnmarshalData(v interface{}) error {
-	return json.Unmarshal(p.Data, v)
+	data, err := p.decompressData()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
 }
 
 // WriteToNoDeadLineConn writes through the connection without deadline.
@@ -872,6 +895,76 @@ func (p *Packet) WriteToConn(c net.Conn) (err error) {
 	return
 }
 
+// WritePacketsToConn writes the packets to the connection in order with one deadline for all of them,
+// the packets are gathered into a vectored write to save the syscalls of forwarding them one by one.
+// It stops on the first error and returns the number of the packets sent completely.
+func WritePacketsToConn(c net.Conn, packets []*Packet, timeoutSec int) (sent int, err error) {
+	if len(packets) == 0 {
+		return
+	}
+	if timeoutSec <= 0 {
+		timeoutSec = WriteDeadlineTime
+	}
+	var (
+		bufs    = make(net.Buffers, 0, len(packets)*3)
+		sizes   = make([]int64, 0, len(packets))
+		headers = make([][]byte, 0, len(packets))
+		marshal error
+	)
+	defer func() {
+		for _, header := range headers {
+			Buffers.Put(header)
+		}
+	}()
+	for _, p := range packets {
+		headSize := util.PacketHeaderSize
+		if p.Opcode == OpRandomWriteVer || p.ExtentType&MultiVersionFlag > 0 {
+			headSize = util.PacketHeaderVerSize
+		}
+		var verData []byte
+		if p.IsVersionList() {
+			if verData, marshal = p.MarshalVersionSlice(); marshal != nil {
+				log.LogErrorf("WritePacketsToConn: marshal version info of packet(%v) failed, err %s", p.GetUniqueLogId(), marshal.Error())
+				break
+			}
+		}
+		header, err1 := Buffers.Get(headSize)
+		if err1 != nil {
+			header = make([]byte, headSize)
+		} else {
+			headers = append(headers, header)
+		}
+		p.MarshalHeader(header)
+		size := int64(len(header))
+		bufs = append(bufs, header)
+		if len(verData) > 0 {
+			bufs = append(bufs, verData)
+			size += int64(len(verData))
+		}
+		bufs = append(bufs, p.Arg[:int(p.ArgLen)])
+		size += int64(p.ArgLen)
+		if p.Data != nil && p.Size != 0 {
+			bufs = append(bufs, p.Data[:p.Size])
+			size += int64(p.Size)
+		}
+		sizes = append(sizes, size)
+	}
+
+	c.SetWriteDeadline(time.Now().Add(time.Duration(timeoutSec) * time.Second))
+	n, err := bufs.WriteTo(c)
+	for _, size := range sizes {
+		if n < size {
+			break
+		}
+		n -= size
+		sent++
+	}
+	if err == nil {
+		err = marshal
+	}
+	return
+}
+
 // ReadFull is a wrapper function of io.ReadFull.
 func ReadFull(c net.Conn, buf *[]byte, readSize int) (err error) {
 	*buf = make([]byte, readSize)
@@ -972,6 +1065,77 @@ func (p *Packet) ReadFromConnWithVer(c net.Conn, timeoutSec int) (err error) {
 	return nil
 }
 
+// MaxReaderPacketSize is the maximum size of a packet read by ReadFromReader, the one of a block read or
+// written through a data node plus the header.
+const MaxReaderPacketSize = util.BlockSize + util.PacketHeaderSize
+
+// ReadFromReader reads a packet from the given reader, it recognizes the version header
+// and the version list the same way as ReadFromConnWithVer, used to decode captured streams.
+// io.EOF is returned only if the reader is exhausted at a packet boundary. The packets larger
+// than MaxReaderPacketSize are refused before the body is allocated.
+func (p *Packet) ReadFromReader(r io.Reader) (err error) {
+	header := make([]byte, util.PacketHeaderSize)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+	if err = p.UnmarshalHeader(header); err != nil {
+		return
+	}
+	if size := uint64(util.PacketHeaderSize) + uint64(p.ArgLen) + uint64(p.Size); size > MaxReaderPacketSize {
+		return fmt.Errorf("packet size %v exceeds %v", size, MaxReaderPacketSize)
+	}
+
+	if p.ExtentType&MultiVersionFlag > 0 {
+		ver := make([]byte, 8)
+		if _, err = io.ReadFull(r, ver); err != nil {
+			return unexpectedEOF(err)
+		}
+		p.VerSeq = binary.BigEndian.Uint64(ver)
+	}
+
+	if p.IsVersionList() {
+		cntByte := make([]byte, 2)
+		if _, err = io.ReadFull(r, cntByte); err != nil {
+			return unexpectedEOF(err)
+		}
+		cnt := binary.BigEndian.Uint16(cntByte)
+		if size := util.PacketHeaderSize + int(cnt)*verInfoCnt; size > MaxReaderPacketSize {
+			return fmt.Errorf("version list size %v exceeds %v", size, MaxReaderPacketSize)
+		}
+		verData := make([]byte, int(cnt)*verInfoCnt)
+		if _, err = io.ReadFull(r, verData); err != nil {
+			return unexpectedEOF(err)
+		}
+		if err = p.UnmarshalVersionSlice(int(cnt), verData); err != nil {
+			return
+		}
+	}
+
+	if p.ArgLen > 0 {
+		p.Arg = make([]byte, int(p.ArgLen))
+		if _, err = io.ReadFull(r, p.Arg); err != nil {
+			return unexpectedEOF(err)
+		}
+	}
+
+	size := p.Size
+	if p.IsReadOperation() && p.ResultCode == OpInitResultCode {
+		size = 0
+	}
+	p.Data = make([]byte, size)
+	if _, err = io.ReadFull(r, p.Data); err != nil {
+		return unexpectedEOF(err)
+	}
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
 // ReadFromConn reads the data from the given connection.
 func (p *Packet) ReadFromConn(c net.Conn, timeoutSec int) (err error) {
 	if timeoutSec != NoReadDeadlineTime {
@@ -1026,6 +1190,7 @@ func (p *Packet) PacketOkReply() {
 
 // PacketOkWithBody sets the result code as OpOk, and sets the body with the give data.
 func (p *Packet) PacketOkWithBody(reply []byte) {
+	p.ExtentType &^= compressFlags
 	p.Size = uint32(len(reply))
 	p.Data = make([]byte, p.Size)
 	copy(p.Data[:p.Size], reply)
@@ -1033,8 +1198,29 @@ func (p *Packet) PacketOkWithBody(reply []byte) {
 	p.ArgLen = 0
 }
 
+// PacketOkWithData sets the result code as OpOk, and sets the body with the marshaled v,
+// which is compressed only if the request is compressed.
+func (p *Packet) PacketOkWithData(v interface{}) (err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if p.IsCompressed() {
+		if err = p.compressData(data); err != nil {
+			return
+		}
+	} else {
+		p.Data = data
+		p.Size = uint32(len(p.Data))
+	}
+	p.ResultCode = OpOk
+	p.ArgLen = 0
+	return
+}
+
 // attention use for tmp byte arr, eg: json marshal data
 func (p *Packet) PacketOkWithByte(reply []byte) {
+	p.ExtentType &^= compressFlags
 	p.Size = uint32(len(reply))
 	p.Data = reply
 	p.ResultCode = OpOk
@@ -1043,6 +1229,7 @@ func (p *Packet) PacketOkWithByte(reply []byte) {
 
 // PacketErrorWithBody sets the packet with error code whose body is filled with the given data.
 func (p *Packet) PacketErrorWithBody(code uint8, reply []byte) {
+	p.ExtentType &^= compressFlags
 	p.Size = uint32(len(reply))
 	p.Data = make([]byte, p.Size)
 	copy(p.Data[:p.Size], reply)
@@ -1050,6 +1237,26 @@ func (p *Packet) PacketErrorWithBody(code uint8, reply []byte) {
 	p.ArgLen = 0
 }
 
+// NewErrorReply returns a reply packet of the op with the error code, whose body is the error message.
+func NewErrorReply(op uint8, code uint8, err error) *Packet {
+	p := NewPacket()
+	p.Opcode = op
+	var reply []byte
+	if err != nil {
+		reply = []byte(err.Error())
+	}
+	p.PacketErrorWithBody(code, reply)
+	return p
+}
+
+// SetReplyFrom copies the fields which identify the request from src, so that the reply matches the request.
+func (p *Packet) SetReplyFrom(src *Packet) {
+	p.ReqID = src.ReqID
+	p.PartitionID = src.PartitionID
+	p.ExtentID = src.ExtentID
+	p.ExtentOffset = src.ExtentOffset
+}
+
 func (p *Packet) SetPacketHasPrepare() {
 	p.setPacketPrefix()
 	p.HasPrepare = true
@@ -0,0 +1,134 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sync/atomic"
+
+	"github.com/cubefs/cubefs/util/compressor"
+)
+
+// The compress flags are set in ExtentType when the packet data is compressed.
+// A peer only compresses the data of a request if the compression of the opcode is enabled by SetCompression,
+// and only compresses the data of a reply if the request was compressed, so the peers without the flags still
+// interoperate with the ones having them.
+const (
+	CompressGzipFlag = 0x20
+	CompressZstdFlag = 0x10
+
+	compressFlags = CompressGzipFlag | CompressZstdFlag
+)
+
+var (
+	compressOps      [256]int32
+	compressEncoding atomic.Value // string
+
+	// compressibleOps are the opcodes whose requests are decoded by UnmarshalData on the meta node,
+	// the requests of the others are unmarshaled directly and never compressed.
+	compressibleOps = map[uint8]bool{
+		OpMetaReadDirLimit:  true,
+		OpMetaBatchInodeGet: true,
+	}
+)
+
+func init() {
+	compressEncoding.Store(compressor.EncodingZstd)
+}
+
+// SetCompression enables or disables the data compression of the requests of the opcode,
+// only the opcodes whose requests are decompressed by the peer can be enabled.
+func SetCompression(op uint8, enabled bool) error {
+	var v int32
+	if enabled {
+		if !compressibleOps[op] {
+			return fmt.Errorf("compression of opcode(%#x) is not supported", op)
+		}
+		v = 1
+	}
+	atomic.StoreInt32(&compressOps[op], v)
+	return nil
+}
+
+// IsCompressionEnabled returns whether the data compression of the requests of the opcode is enabled.
+func IsCompressionEnabled(op uint8) bool {
+	return atomic.LoadInt32(&compressOps[op]) == 1
+}
+
+// SetCompressionEncoding sets the encoding used to compress the requests, gzip or zstd.
+func SetCompressionEncoding(encoding string) error {
+	if encodingCompressFlag(encoding) == 0 {
+		return fmt.Errorf("unsupported compression encoding(%v)", encoding)
+	}
+	compressEncoding.Store(encoding)
+	return nil
+}
+
+func encodingCompressFlag(encoding string) uint8 {
+	switch encoding {
+	case compressor.EncodingGzip:
+		return CompressGzipFlag
+	case compressor.EncodingZstd:
+		return CompressZstdFlag
+	}
+	return 0
+}
+
+// IsCompressed returns whether the packet data is compressed.
+func (p *Packet) IsCompressed() bool {
+	return p.ExtentType&compressFlags != 0
+}
+
+func (p *Packet) compressEncoding() string {
+	switch {
+	case p.ExtentType&CompressGzipFlag != 0:
+		return compressor.EncodingGzip
+	case p.ExtentType&CompressZstdFlag != 0:
+		return compressor.EncodingZstd
+	}
+	return ""
+}
+
+// compressData compresses the data if the packet is already compressed, which is the reply of a compressed
+// request, or the compression of the opcode is enabled. Size and CRC are set to the compressed data.
+func (p *Packet) compressData(data []byte) (err error) {
+	if !p.IsCompressed() {
+		if !IsCompressionEnabled(p.Opcode) {
+			p.Data = data
+			p.Size = uint32(len(p.Data))
+			return
+		}
+		p.ExtentType |= encodingCompressFlag(compressEncoding.Load().(string))
+	}
+	if data, err = compressor.New(p.compressEncoding()).Compress(data); err != nil {
+		return
+	}
+	p.Data = data
+	p.Size = uint32(len(p.Data))
+	p.CRC = crc32.ChecksumIEEE(p.Data)
+	return
+}
+
+// decompressData returns the decompressed data of the packet.
+func (p *Packet) decompressData() ([]byte, error) {
+	if !p.IsCompressed() {
+		return p.Data, nil
+	}
+	if p.CRC != 0 && p.CRC != crc32.ChecksumIEEE(p.Data[:p.Size]) {
+		return nil, fmt.Errorf("compressed data crc mismatch, packet(%v)", p)
+	}
+	return compressor.New(p.compressEncoding()).Decompress(p.Data[:p.Size])
+}
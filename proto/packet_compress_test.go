@@ -0,0 +1,109 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/cubefs/cubefs/util/compressor"
+	"github.com/stretchr/testify/require"
+)
+
+func transferPacket(t *testing.T, p *Packet) *Packet {
+	client, server := net.Pipe()
+	go func() {
+		p.WriteToConn(server)
+		server.Close()
+	}()
+	got := NewPacket()
+	require.NoError(t, got.ReadFromConn(client, 5))
+	client.Close()
+	return got
+}
+
+func TestPacketCompression(t *testing.T) {
+	if Buffers == nil {
+		InitBufferPool(int64(32768))
+	}
+	defer func() {
+		SetCompression(OpMetaReadDirLimit, false)
+		SetCompressionEncoding(compressor.EncodingZstd)
+	}()
+	require.Error(t, SetCompressionEncoding("lz4"))
+	// the requests of the opcodes not decoded by UnmarshalData are never compressed
+	require.Error(t, SetCompression(OpMetaLookup, true))
+	require.False(t, IsCompressionEnabled(OpMetaLookup))
+	require.NoError(t, SetCompression(OpMetaLookup, false))
+
+	resp := &ReadDirLimitResponse{}
+	for i := 0; i < 1000; i++ {
+		resp.Children = append(resp.Children, Dentry{Name: fmt.Sprintf("file_%d", i), Inode: uint64(i), Type: 0x1a4})
+	}
+
+	// the request is not compressed while the compression of the opcode is disabled
+	req := NewPacketReqID()
+	req.Opcode = OpMetaReadDirLimit
+	require.NoError(t, req.MarshalData(&ReadDirLimitRequest{ParentID: 1, Limit: 1000}))
+	require.False(t, req.IsCompressed())
+	got := transferPacket(t, req)
+	require.False(t, got.IsCompressed())
+	// a plain request gets a plain reply
+	require.NoError(t, got.PacketOkWithData(resp))
+	require.False(t, got.IsCompressed())
+
+	for _, encoding := range []string{compressor.EncodingGzip, compressor.EncodingZstd} {
+		require.NoError(t, SetCompressionEncoding(encoding))
+		require.NoError(t, SetCompression(OpMetaReadDirLimit, true))
+
+		req = NewPacketReqID()
+		req.Opcode = OpMetaReadDirLimit
+		require.NoError(t, req.MarshalData(&ReadDirLimitRequest{ParentID: 1, Limit: 1000}))
+		require.True(t, req.IsCompressed())
+		require.Equal(t, encoding, req.compressEncoding())
+
+		received := transferPacket(t, req)
+		require.True(t, received.IsCompressed())
+		readReq := &ReadDirLimitRequest{}
+		require.NoError(t, received.UnmarshalData(readReq))
+		require.Equal(t, uint64(1), readReq.ParentID)
+		require.Equal(t, uint64(1000), readReq.Limit)
+
+		// the reply of a compressed request is compressed with the same encoding
+		require.NoError(t, SetCompression(OpMetaReadDirLimit, false))
+		require.NoError(t, received.PacketOkWithData(resp))
+		require.True(t, received.IsCompressed())
+		plain, err := json.Marshal(resp)
+		require.NoError(t, err)
+		require.Less(t, int(received.Size), len(plain))
+
+		reply := transferPacket(t, received)
+		require.Equal(t, received.Size, reply.Size)
+		require.Equal(t, received.CRC, reply.CRC)
+		readResp := &ReadDirLimitResponse{}
+		require.NoError(t, reply.UnmarshalData(readResp))
+		require.Equal(t, resp, readResp)
+
+		// a corrupted body is detected by the crc
+		reply.Data[0]++
+		require.Error(t, reply.UnmarshalData(&ReadDirLimitResponse{}))
+
+		// an error reply carries a plain body
+		received.PacketErrorWithBody(OpErr, []byte("mp not found"))
+		require.False(t, received.IsCompressed())
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package proto
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewErrorReply(t *testing.T) {
+	if Buffers == nil {
+		InitBufferPool(int64(32768))
+	}
+	req := NewPacketReqID()
+	req.Opcode = OpWrite
+	req.PartitionID = 10
+	req.ExtentID = 1025
+	req.ExtentOffset = 4096
+
+	cases := []struct {
+		code uint8
+		err  error
+		msg  string
+	}{
+		{OpErr, errors.New("extent not found"), "Err: extent not found"},
+		{OpAgain, errors.New("raft not ready"), "Again: raft not ready"},
+		{OpDiskNoSpaceErr, errors.New("no space"), "DiskNoSpaceErr"},
+		{OpErr, nil, "Err: "},
+	}
+	for _, c := range cases {
+		reply := NewErrorReply(req.Opcode, c.code, c.err)
+		reply.SetReplyFrom(req)
+
+		client, server := net.Pipe()
+		go func() {
+			reply.WriteToConn(server)
+			server.Close()
+		}()
+		got := NewPacket()
+		require.NoError(t, got.ReadFromConn(client, 5))
+		client.Close()
+
+		require.Equal(t, req.Opcode, got.Opcode)
+		require.Equal(t, c.code, got.ResultCode)
+		require.Equal(t, req.ReqID, got.ReqID)
+		require.Equal(t, req.PartitionID, got.PartitionID)
+		require.Equal(t, req.ExtentID, got.ExtentID)
+		require.Equal(t, req.ExtentOffset, got.ExtentOffset)
+		require.Equal(t, c.msg, got.GetResultMsg())
+		if c.err != nil {
+			require.Equal(t, c.err.Error(), string(got.Data))
+		} else {
+			require.Empty(t, got.Data)
+		}
+	}
+}
+
+func TestWritePacketsToConn(t *testing.T) {
+	if Buffers == nil {
+		InitBufferPool(int64(32768))
+	}
+	newPackets := func(count int) []*Packet {
+		packets := make([]*Packet, 0, count)
+		for i := 0; i < count; i++ {
+			p := NewPacketReqID()
+			p.Opcode = OpWrite
+			p.PartitionID = 10
+			p.ExtentID = 1025
+			p.ExtentOffset = int64(i * 4096)
+			p.Data = []byte(fmt.Sprintf("data-%v", i))
+			p.Size = uint32(len(p.Data))
+			p.Arg = []byte("follower")
+			p.ArgLen = uint32(len(p.Arg))
+			packets = append(packets, p)
+		}
+		return packets
+	}
+
+	packets := newPackets(5)
+	client, server := net.Pipe()
+	done := make(chan struct{})
+	var (
+		sent int
+		err  error
+	)
+	go func() {
+		sent, err = WritePacketsToConn(server, packets, 5)
+		server.Close()
+		close(done)
+	}()
+	for _, p := range packets {
+		got := NewPacket()
+		require.NoError(t, got.ReadFromConn(client, 5))
+		require.Equal(t, p.ReqID, got.ReqID)
+		require.Equal(t, p.ExtentOffset, got.ExtentOffset)
+		require.Equal(t, string(p.Arg), string(got.Arg[:got.ArgLen]))
+		require.Equal(t, string(p.Data), string(got.Data[:got.Size]))
+	}
+	<-done
+	require.NoError(t, err)
+	require.Equal(t, len(packets), sent)
+	client.Close()
+
+	// the peer goes away after reading two packets
+	packets = newPackets(5)
+	client, server = net.Pipe()
+	done = make(chan struct{})
+	go func() {
+		sent, err = WritePacketsToConn(server, packets, 5)
+		server.Close()
+		close(done)
+	}()
+	for _, p := range packets[:2] {
+		got := NewPacket()
+		require.NoError(t, got.ReadFromConn(client, 5))
+		require.Equal(t, p.ReqID, got.ReqID)
+	}
+	client.Close()
+	<-done
+	require.Error(t, err)
+	require.Equal(t, 2, sent)
+}
@@ -247,6 +247,21 @@ func (policy *UserPolicy) RemoveAuthorizedVol(volume string) {
 	delete(policy.AuthorizedVols, volume)
 }
 
+// RenameVol moves the owned and authorized policies of volume oldName to newName.
+func (policy *UserPolicy) RenameVol(oldName, newName string) {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	for i, ownVol := range policy.OwnVols {
+		if ownVol == oldName {
+			policy.OwnVols[i] = newName
+		}
+	}
+	if values, ok := policy.AuthorizedVols[oldName]; ok {
+		policy.AuthorizedVols[newName] = values
+		delete(policy.AuthorizedVols, oldName)
+	}
+}
+
 func (policy *UserPolicy) SetPerm(volume string, perm Permission) {
 	policy.mu.Lock()
 	defer policy.mu.Unlock()
@@ -130,6 +130,8 @@ func (p *FollowerPacket) identificationErrorResultCode(errLog string, errMsg str
 		p.ResultCode = proto.OpTryOtherAddr
 	} else if strings.Contains(errMsg, raft.ErrStopped.Error()) {
 		p.ResultCode = proto.OpTryOtherAddr
+	} else if strings.Contains(errMsg, storage.ReadonlyDataPartitionError.Error()) {
+		p.ResultCode = proto.OpNotPerm
 	} else {
 		log.LogErrorf("action[identificationErrorResultCode] error %v, errmsg %v", errLog, errMsg)
 		p.ResultCode = proto.OpIntraGroupNetErr
@@ -429,7 +431,8 @@ func (p *Packet) identificationErrorResultCode(errLog string, errMsg string) {
 	} else if strings.Contains(errMsg, storage.ParameterMismatchError.Error()) ||
 		strings.Contains(errMsg, ErrorUnknownOp.Error()) {
 		p.ResultCode = proto.OpArgMismatchErr
-	} else if strings.Contains(errMsg, proto.ErrDataPartitionNotExists.Error()) {
+	} else if strings.Contains(errMsg, proto.ErrDataPartitionNotExists.Error()) ||
+		strings.Contains(errMsg, storage.BlockCrcMismatchError.Error()) {
 		p.ResultCode = proto.OpTryOtherAddr
 	} else if strings.Contains(errMsg, storage.ExtentNotFoundError.Error()) ||
 		strings.Contains(errMsg, storage.ExtentHasBeenDeletedError.Error()) {
@@ -451,6 +454,8 @@ func (p *Packet) identificationErrorResultCode(errLog string, errMsg string) {
 		p.ResultCode = proto.OpReadRepairExtentAgain
 	} else if strings.Contains(errMsg, storage.ForbiddenDataPartitionError.Error()) {
 		p.ResultCode = proto.OpForbidErr
+	} else if strings.Contains(errMsg, storage.ReadonlyDataPartitionError.Error()) {
+		p.ResultCode = proto.OpNotPerm
 	} else {
 		log.LogErrorf("action[identificationErrorResultCode] error %v, errmsg %v", errLog, errMsg)
 		p.ResultCode = proto.OpIntraGroupNetErr
@@ -481,6 +486,7 @@ func (p *Packet) IsMasterCommand() bool {
 		proto.OpVersionOperation,
 		proto.OpLoadDataPartition,
 		proto.OpCreateDataPartition,
+		proto.OpVerifyCreateDataPartition,
 		proto.OpDeleteDataPartition,
 		proto.OpDecommissionDataPartition,
 		proto.OpAddDataPartitionRaftMember,
@@ -0,0 +1,76 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package repl
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+)
+
+var jsonLog int32
+
+// SetJSONLog makes the packets log the requests and responses as json objects instead of text,
+// so that they can be indexed by log pipelines.
+func SetJSONLog(enable bool) {
+	if enable {
+		atomic.StoreInt32(&jsonLog, 1)
+	} else {
+		atomic.StoreInt32(&jsonLog, 0)
+	}
+}
+
+func IsJSONLog() bool {
+	return atomic.LoadInt32(&jsonLog) == 1
+}
+
+type packetLog struct {
+	Action     string `json:"action"`
+	ReqID      int64  `json:"reqID"`
+	Op         string `json:"op"`
+	Partition  uint64 `json:"partition"`
+	Extent     uint64 `json:"extent"`
+	ResultCode string `json:"resultCode"`
+	LatencyMs  int64  `json:"latencyMs"`
+	Remote     string `json:"remote"`
+	Forward    bool   `json:"forward"`
+	Err        string `json:"err,omitempty"`
+}
+
+// LogMessage formats the log of the packet, as json if SetJSONLog is enabled.
+func (p *Packet) LogMessage(action, remote string, start int64, err error) (m string) {
+	if !IsJSONLog() {
+		return p.Packet.LogMessage(action, remote, start, err)
+	}
+	l := &packetLog{
+		Action:     action,
+		ReqID:      p.ReqID,
+		Op:         p.GetOpMsg(),
+		Partition:  p.PartitionID,
+		Extent:     p.ExtentID,
+		ResultCode: p.GetResultMsg(),
+		LatencyMs:  (time.Now().UnixNano() - start) / 1e6,
+		Remote:     remote,
+		Forward:    p.IsForwardPkt(),
+	}
+	if err != nil {
+		l.Err = err.Error()
+	}
+	data, e := json.Marshal(l)
+	if e != nil {
+		return p.Packet.LogMessage(action, remote, start, err)
+	}
+	return string(data)
+}
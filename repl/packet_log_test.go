@@ -0,0 +1,63 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package repl
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketJSONLog(t *testing.T) {
+	p := NewPacket()
+	p.ReqID = 100
+	p.Opcode = proto.OpStreamRead
+	p.PartitionID = 10
+	p.ExtentID = 1025
+	p.ResultCode = proto.OpOk
+	start := time.Now().Add(-5 * time.Millisecond).UnixNano()
+
+	// text by default
+	m := p.LogMessage(ActionWriteToClient, "127.0.0.1:17030", start, nil)
+	require.Equal(t, p.Packet.LogMessage(ActionWriteToClient, "127.0.0.1:17030", start, nil), m)
+	require.Error(t, json.Unmarshal([]byte(m), &map[string]interface{}{}))
+
+	SetJSONLog(true)
+	defer SetJSONLog(false)
+	fields := make(map[string]interface{})
+	m = p.LogMessage(ActionWriteToClient, "127.0.0.1:17030", start, nil)
+	require.NoError(t, json.Unmarshal([]byte(m), &fields))
+	for _, key := range []string{"action", "reqID", "op", "partition", "extent", "resultCode", "latencyMs", "remote", "forward"} {
+		require.Contains(t, fields, key)
+	}
+	require.NotContains(t, fields, "err")
+	require.Equal(t, float64(100), fields["reqID"])
+	require.Equal(t, p.GetOpMsg(), fields["op"])
+	require.Equal(t, float64(10), fields["partition"])
+	require.Equal(t, p.GetResultMsg(), fields["resultCode"])
+	require.Equal(t, "127.0.0.1:17030", fields["remote"])
+	require.GreaterOrEqual(t, fields["latencyMs"].(float64), float64(5))
+
+	p.ResultCode = proto.OpErr
+	fields = make(map[string]interface{})
+	m = p.LogMessage(ActionWriteToClient, "127.0.0.1:17030", start, errors.New("io error"))
+	require.NoError(t, json.Unmarshal([]byte(m), &fields))
+	require.Equal(t, "io error", fields["err"])
+	require.Equal(t, p.GetResultMsg(), fields["resultCode"])
+}
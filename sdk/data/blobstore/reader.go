@@ -283,7 +283,7 @@ func (reader *Reader) readSliceRange(ctx context.Context, rs *rwSlice) (err erro
 	if reader.enableBcache {
 		readN, err = reader.bc.Get(cacheKey, buf, rs.rOffset, rs.rSize)
 		if err == nil {
-			reader.ec.BcacheHealth = true
+			reader.ec.SetBcacheHealth(true)
 			if readN == int(rs.rSize) {
 
 				// L1 cache hit.
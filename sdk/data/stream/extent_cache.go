@@ -292,6 +292,24 @@ func (cache *ExtentCache) RemoveDiscard(discardExtents []proto.ExtentKey) {
 	}
 }
 
+// Merge replaces the extent keys with the merged one if they are still in the cache.
+func (cache *ExtentCache) Merge(merged *proto.ExtentKey, eks []proto.ExtentKey) bool {
+	cache.Lock()
+	defer cache.Unlock()
+	for i := range eks {
+		found := cache.root.Get(&eks[i])
+		if found == nil || !found.(*proto.ExtentKey).Equals(&eks[i]) {
+			return false
+		}
+	}
+	for i := range eks {
+		cache.root.Delete(&eks[i])
+	}
+	cache.root.ReplaceOrInsert(merged)
+	log.LogDebugf("ExtentCache Merge: ino(%v) eks(%v) merged(%v)", cache.inode, eks, merged)
+	return true
+}
+
 func (cache *ExtentCache) TruncDiscard(size uint64) {
 	cache.Lock()
 	defer cache.Unlock()
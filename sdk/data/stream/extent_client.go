@@ -18,6 +18,7 @@ import (
 	"container/list"
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -40,14 +41,30 @@ import (
 type (
 	SplitExtentKeyFunc  func(parentInode, inode uint64, key proto.ExtentKey) error
 	AppendExtentKeyFunc func(parentInode, inode uint64, key proto.ExtentKey, discard []proto.ExtentKey) (int, error)
+	MergeExtentKeysFunc func(parentInode, inode uint64, key proto.ExtentKey, merged []proto.ExtentKey) error
 	GetExtentsFunc      func(inode uint64) (uint64, uint64, []proto.ExtentKey, error)
+	GetExtentsByVerFunc func(inode uint64, verSeq uint64) (uint64, uint64, []proto.ExtentKey, error)
 	TruncateFunc        func(inode, size uint64, fullPath string) error
 	EvictIcacheFunc     func(inode uint64)
 	LoadBcacheFunc      func(key string, buf []byte, offset uint64, size uint32) (int, error)
 	CacheBcacheFunc     func(key string, buf []byte) error
 	EvictBacheFunc      func(key string) error
+	BcacheHealthFunc    func(healthy bool)
 )
 
+// MetricsSink receives the latency and size of the successful reads and writes of the extent client,
+// e.g. to feed prometheus histograms. It's called synchronously on the IO path, so it must be cheap and
+// safe for concurrent use.
+//
+// The latency ranges from tens of microseconds for a read hitting the cache to seconds for a request
+// retried on other replicas, exponential buckets like prometheus.ExponentialBuckets(0.00005, 2, 18),
+// 50us to ~6.5s, cover it. The size is at most the request size of the fuse or the gateway, 4KB to
+// a few MB, so exponential buckets by 4 from 4KB fit it.
+type MetricsSink interface {
+	ObserveRead(d time.Duration, size int)
+	ObserveWrite(d time.Duration, size int)
+}
+
 const (
 	MaxMountRetryLimit = 6
 	MountRetryInterval = time.Second * 5
@@ -63,6 +80,11 @@ const (
 	kHighWatermarkPct    = 1.01
 	slowStreamerEvictNum = 10
 	fastStreamerEvictNum = 10000
+
+	bcacheProbeInterval = time.Minute
+
+	// the extents bigger than bcache.BigExtentSize are not cached once so many of them are being cached
+	maxInflightL1BigBlock = 10
 )
 
 var (
@@ -112,12 +134,34 @@ type ExtentConfig struct {
 	VerReadSeq        uint64
 	OnAppendExtentKey AppendExtentKeyFunc
 	OnSplitExtentKey  SplitExtentKeyFunc
+	OnMergeExtentKeys MergeExtentKeysFunc
 	OnGetExtents      GetExtentsFunc
+	OnGetExtentsByVer GetExtentsByVerFunc
 	OnTruncate        TruncateFunc
 	OnEvictIcache     EvictIcacheFunc
 	OnLoadBcache      LoadBcacheFunc
 	OnCacheBcache     CacheBcacheFunc
 	OnEvictBcache     EvictBacheFunc
+	// OnBcacheHealthChange is invoked when the block cache turns unhealthy or recovers, may be nil.
+	OnBcacheHealthChange BcacheHealthFunc
+	// MetricsSink observes the latency of the reads and writes, may be nil.
+	MetricsSink MetricsSink
+	// FsyncCoalesceWindow coalesces the fsyncs of different inodes issued within the window, 0 means disabled.
+	FsyncCoalesceWindow time.Duration
+	// HedgeReadDelay sends a backup follower read to another replica if the first one does not respond
+	// within the delay, 0 means disabled. HedgeReadMaxPercent bounds the backup reads by the percentage of all the reads.
+	HedgeReadDelay      time.Duration
+	HedgeReadMaxPercent int64
+
+	// ConnPool provides the connections to the data nodes, StreamConnPool is used if it is nil.
+	ConnPool wrapper.ConnPool
+	// ReadPreference is the policy to choose the replica of a follower read and to fail over,
+	// one of wrapper.ReadPreferXXX, empty for the default one.
+	ReadPreference string
+	// MergeAdjacentExtents merges the physically adjacent extent keys of a file, e.g. the ones left by many
+	// small appends, into one on the meta node through OnMergeExtentKeys after a flush, and reads the adjacent
+	// extents not merged yet with one request instead of one for each extent key.
+	MergeAdjacentExtents bool
 
 	DisableMetaCache             bool
 	MinWriteAbleDataPartitionCnt int
@@ -135,7 +179,7 @@ type ExtentClient struct {
 	streamers          map[uint64]*Streamer
 	streamerList       *list.List
 	streamerLock       sync.Mutex
-	maxStreamerLimit   int
+	maxStreamerLimit   int // the streamers beyond it are evicted in background, 0 means no eviction
 	readLimiter        *rate.Limiter
 	writeLimiter       *rate.Limiter
 	disableMetaCache   bool
@@ -143,21 +187,30 @@ type ExtentClient struct {
 	volumeName         string
 	bcacheEnable       bool
 	bcacheDir          string
-	BcacheHealth       bool
+	bcacheHealth       int32
 	preload            bool
 	LimitManager       *manager.LimitManager
 	dataWrapper        *wrapper.Wrapper
 	appendExtentKey    AppendExtentKeyFunc
 	splitExtentKey     SplitExtentKeyFunc
+	mergeExtentKeys    MergeExtentKeysFunc // May be null, must check before using
 	getExtents         GetExtentsFunc
+	getExtentsByVer    GetExtentsByVerFunc // May be null, must check before using
 	truncate           TruncateFunc
 	evictIcache        EvictIcacheFunc // May be null, must check before using
 	loadBcache         LoadBcacheFunc
 	cacheBcache        CacheBcacheFunc
 	evictBcache        EvictBacheFunc
+	onBcacheHealth     BcacheHealthFunc
+	metricsSink        MetricsSink
 	inflightL1cache    sync.Map
 	inflightL1BigBlock int32
 	multiVerMgr        *MultiVerMgr
+	fsyncCoalescer     *fsyncCoalescer
+	readHedger         *readHedger
+	extentMerger       *extentMerger
+	stopC              chan struct{}
+	stopOnce           sync.Once
 }
 
 func (client *ExtentClient) UidIsLimited(uid uint32) bool {
@@ -258,22 +311,44 @@ retry:
 
 	client.appendExtentKey = config.OnAppendExtentKey
 	client.splitExtentKey = config.OnSplitExtentKey
+	client.mergeExtentKeys = config.OnMergeExtentKeys
 	client.getExtents = config.OnGetExtents
+	client.getExtentsByVer = config.OnGetExtentsByVer
 	client.truncate = config.OnTruncate
 	client.evictIcache = config.OnEvictIcache
 	client.dataWrapper.InitFollowerRead(config.FollowerRead)
 	client.dataWrapper.SetNearRead(config.NearRead)
+	client.dataWrapper.SetConnPool(config.ConnPool)
+	if err = client.dataWrapper.SetReadPreference(config.ReadPreference); err != nil {
+		client.dataWrapper.Stop()
+		return nil, err
+	}
 	client.loadBcache = config.OnLoadBcache
 	client.cacheBcache = config.OnCacheBcache
 	client.evictBcache = config.OnEvictBcache
+	client.onBcacheHealth = config.OnBcacheHealthChange
+	client.metricsSink = config.MetricsSink
 	client.volumeType = config.VolumeType
 	client.volumeName = config.Volume
 	client.bcacheEnable = config.BcacheEnable
 	client.bcacheDir = config.BcacheDir
 	client.multiVerMgr.verReadSeq = client.dataWrapper.GetReadVerSeq()
-	client.BcacheHealth = true
+	client.bcacheHealth = 1
 	client.preload = config.Preload
 	client.disableMetaCache = config.DisableMetaCache
+	client.stopC = make(chan struct{})
+	if config.FsyncCoalesceWindow > 0 {
+		client.fsyncCoalescer = newFsyncCoalescer(config.FsyncCoalesceWindow, client.Flush)
+	}
+	if config.HedgeReadDelay > 0 {
+		client.readHedger = newReadHedger(config.HedgeReadDelay, config.HedgeReadMaxPercent)
+	}
+	if config.MergeAdjacentExtents {
+		client.extentMerger = &extentMerger{}
+	}
+	if client.bcacheEnable {
+		go client.backgroundProbeBcache()
+	}
 
 	var readLimit, writeLimit rate.Limit
 	if config.ReadRate <= 0 {
@@ -289,26 +364,36 @@ retry:
 	client.readLimiter = rate.NewLimiter(readLimit, defaultReadLimitBurst)
 	client.writeLimiter = rate.NewLimiter(writeLimit, defaultWriteLimitBurst)
 
-	if config.MaxStreamerLimit <= 0 {
-		client.disableMetaCache = true
+	client.initStreamerEviction(config.MaxStreamerLimit)
+	return
+}
+
+// initStreamerEviction sets up the eviction of the streamers kept by the meta cache.
+// DisableMetaCache and MaxStreamerLimit are independent of each other:
+//
+//	meta cache off:                the streamers are dropped once closed, nothing to evict
+//	meta cache on, limit > 0:      the closed streamers are kept, the ones beyond the limit are evicted in background
+//	meta cache on, limit <= 0:     the closed streamers are kept without eviction
+func (client *ExtentClient) initStreamerEviction(maxStreamerLimit int64) {
+	client.streamerList = list.New()
+	if client.disableMetaCache || maxStreamerLimit <= 0 {
+		log.LogInfof("streamer eviction is off, disableMetaCache(%v) maxStreamerLimit(%v)",
+			client.disableMetaCache, maxStreamerLimit)
 		return
 	}
 
-	if config.MaxStreamerLimit <= defaultStreamerLimit {
+	if maxStreamerLimit <= defaultStreamerLimit {
 		client.maxStreamerLimit = defaultStreamerLimit
-	} else if config.MaxStreamerLimit > defMaxStreamerLimit {
+	} else if maxStreamerLimit > defMaxStreamerLimit {
 		client.maxStreamerLimit = defMaxStreamerLimit
 	} else {
-		client.maxStreamerLimit = int(config.MaxStreamerLimit)
+		client.maxStreamerLimit = int(maxStreamerLimit)
 	}
 
 	client.maxStreamerLimit += fastStreamerEvictNum
 
 	log.LogInfof("max streamer limit %d", client.maxStreamerLimit)
-	client.streamerList = list.New()
 	go client.backgroundEvictStream()
-
-	return
 }
 
 func (client *ExtentClient) GetEnablePosixAcl() bool {
@@ -401,7 +486,7 @@ func (client *ExtentClient) OpenStreamWithCache(inode uint64, needBCache bool) e
 	if !ok {
 		s = NewStreamer(client, inode)
 		client.streamers[inode] = s
-		if !client.disableMetaCache && needBCache {
+		if client.maxStreamerLimit > 0 && needBCache {
 			client.streamerList.PushFront(inode)
 		}
 	}
@@ -519,35 +604,95 @@ func (client *ExtentClient) Write(inode uint64, offset int, data []byte, flags i
 		s.GetExtents()
 	})
 
+	begin := time.Now()
 	write, err = s.IssueWriteRequest(offset, data, flags, checkFunc)
 	if err != nil {
 		log.LogError(errors.Stack(err))
 		exporter.Warning(err.Error())
+		return
 	}
+	client.observeWrite(begin, write)
+	return
+}
+
+// WriteV writes the buffers to the file contiguously from offset, it saves the caller from
+// concatenating them into one buffer.
+func (client *ExtentClient) WriteV(ctx context.Context, inode uint64, offset int, bufs [][]byte, flags int, checkFunc func() error) (write int, err error) {
+	prefix := fmt.Sprintf("WriteV{ino(%v)offset(%v)bufs(%v)}", inode, offset, len(bufs))
+	s := client.GetStreamer(inode)
+	if s == nil {
+		log.LogErrorf("Prefix(%v): stream is not opened yet", prefix)
+		return 0, syscall.EBADF
+	}
+
+	s.once.Do(func() {
+		// TODO unhandled error
+		s.GetExtents()
+	})
+
+	begin := time.Now()
+	write, err = s.IssueWriteVRequest(ctx, offset, bufs, flags, checkFunc)
+	if err != nil {
+		log.LogError(errors.Stack(err))
+		exporter.Warning(err.Error())
+		return
+	}
+	client.observeWrite(begin, write)
 	return
 }
 
 func (client *ExtentClient) Truncate(mw *meta.MetaWrapper, parentIno uint64, inode uint64, size int, fullPath string) error {
+	return client.truncateWithSummary(mw, mw.EnableSummary, parentIno, inode, size, fullPath, nil)
+}
+
+// TruncateHint carries what the caller already knows about a truncation to save the
+// metanode round-trips of the summary update.
+type TruncateHint struct {
+	// OldSize is the size of the inode before the truncation, valid if HasOldSize is set.
+	OldSize    uint64
+	HasOldSize bool
+	// SkipSummary leaves the summary update to the caller, e.g. the ones reconciling summaries separately.
+	SkipSummary bool
+}
+
+// TruncateWithHint is the same as Truncate except that the InodeGet before the truncation is skipped
+// if the old size is given, and the summary update is skipped if asked to.
+func (client *ExtentClient) TruncateWithHint(mw *meta.MetaWrapper, parentIno uint64, inode uint64, size int, fullPath string, hint *TruncateHint) error {
+	return client.truncateWithSummary(mw, mw.EnableSummary, parentIno, inode, size, fullPath, hint)
+}
+
+// summaryUpdater is the part of the meta wrapper used to keep the summary up to date on truncation.
+type summaryUpdater interface {
+	InodeGet_ll(inode uint64) (*proto.InodeInfo, error)
+	UpdateSummary_ll(parentIno uint64, filesInc int64, dirsInc int64, bytesInc int64)
+}
+
+func (client *ExtentClient) truncateWithSummary(mw summaryUpdater, enableSummary bool, parentIno uint64, inode uint64, size int, fullPath string, hint *TruncateHint) error {
 	prefix := fmt.Sprintf("Truncate{ino(%v)size(%v)}", inode, size)
 	s := client.GetStreamer(inode)
 	if s == nil {
 		log.LogErrorf("Prefix(%v): stream is not opened yet", prefix)
 		return syscall.EBADF
 	}
-	var info *proto.InodeInfo
 	var err error
 	var oldSize uint64
-	if mw.EnableSummary {
-		// TODO: err ???
-		info, _ = mw.InodeGet_ll(inode)
-		oldSize = info.Size
+	updateSummary := enableSummary && (hint == nil || !hint.SkipSummary)
+	if updateSummary {
+		if hint != nil && hint.HasOldSize {
+			oldSize = hint.OldSize
+		} else if info, getErr := mw.InodeGet_ll(inode); getErr == nil {
+			oldSize = info.Size
+		} else {
+			log.LogWarnf("Prefix(%v): get old size failed, skip summary update, err(%v)", prefix, getErr)
+			updateSummary = false
+		}
 	}
 	err = s.IssueTruncRequest(size, fullPath)
 	if err != nil {
 		err = errors.Trace(err, prefix)
 		log.LogError(errors.Stack(err))
 	}
-	if mw.EnableSummary {
+	if updateSummary {
 		go mw.UpdateSummary_ll(parentIno, 0, 0, int64(size)-int64(oldSize))
 	}
 
@@ -563,6 +708,97 @@ func (client *ExtentClient) Flush(inode uint64) error {
 	return s.IssueFlushRequest()
 }
 
+// FlushGroup flushes the inodes together and returns after all of them are durable. The flush requests
+// are handed to all the streamers first, so the inodes are flushed concurrently. If ctx is done before
+// that, ctx.Err() is returned and the flushes go on in the background.
+func (client *ExtentClient) FlushGroup(ctx context.Context, inodes []uint64) error {
+	streamers := make([]*Streamer, 0, len(inodes))
+	seen := make(map[uint64]struct{}, len(inodes))
+	for _, inode := range inodes {
+		if _, ok := seen[inode]; ok {
+			continue
+		}
+		seen[inode] = struct{}{}
+		s := client.GetStreamer(inode)
+		if s == nil {
+			log.LogErrorf("FlushGroup: stream is not opened yet, ino(%v)", inode)
+			return syscall.EBADF
+		}
+		streamers = append(streamers, s)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	requests := make([]*FlushRequest, 0, len(streamers))
+	for _, s := range streamers {
+		requests = append(requests, s.issueFlush())
+	}
+	var err error
+	for i, request := range requests {
+		select {
+		case <-request.done:
+		case <-ctx.Done():
+			log.LogWarnf("FlushGroup: %v of %v inodes flushed, err(%v)", i, len(requests), ctx.Err())
+			return ctx.Err()
+		}
+		if request.err != nil && err == nil {
+			log.LogErrorf("FlushGroup: ino(%v) err(%v)", streamers[i].inode, request.err)
+			err = request.err
+		}
+		flushRequestPool.Put(request)
+	}
+	return err
+}
+
+// FlushOrdered flushes the groups of inodes one after another, a group is flushed only after all the inodes
+// of the groups before it are durable. It stops at the first group failed to flush.
+func (client *ExtentClient) FlushOrdered(ctx context.Context, groups ...[]uint64) error {
+	for i, inodes := range groups {
+		if err := client.FlushGroup(ctx, inodes); err != nil {
+			log.LogErrorf("FlushOrdered: group(%v) of %v failed, err(%v)", i, len(groups), err)
+			return err
+		}
+	}
+	return nil
+}
+
+// IsDirty tells whether the inode has data not flushed yet, i.e. pending write requests or dirty extent
+// handlers, without forcing a flush. It returns false if the stream is not open.
+func (client *ExtentClient) IsDirty(ctx context.Context, inode uint64) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	client.streamerLock.Lock()
+	s, ok := client.streamers[inode]
+	client.streamerLock.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return s.isDirty(), nil
+}
+
+// Sync flushes the dirty data of the inode for fsync, the fsyncs of different inodes
+// are coalesced into batches if FsyncCoalesceWindow is configured.
+func (client *ExtentClient) Sync(inode uint64) error {
+	if client.fsyncCoalescer == nil {
+		return client.Flush(inode)
+	}
+	return client.fsyncCoalescer.sync(inode)
+}
+
+func (client *ExtentClient) observeRead(begin time.Time, size int) {
+	if client.metricsSink != nil {
+		client.metricsSink.ObserveRead(time.Since(begin), size)
+	}
+}
+
+func (client *ExtentClient) observeWrite(begin time.Time, size int) {
+	if client.metricsSink != nil {
+		client.metricsSink.ObserveWrite(time.Since(begin), size)
+	}
+}
+
 func (client *ExtentClient) Read(inode uint64, data []byte, offset int, size int) (read int, err error) {
 	// log.LogErrorf("======> ExtentClient Read Enter, inode(%v), len(data)=(%v), offset(%v), size(%v).", inode, len(data), offset, size)
 	// t1 := time.Now()
@@ -580,16 +816,108 @@ func (client *ExtentClient) Read(inode uint64, data []byte, offset int, size int
 		s.GetExtents()
 	})
 
+	begin := time.Now()
 	err = s.IssueFlushRequest()
 	if err != nil {
 		return
 	}
 
 	read, err = s.read(data, offset, size)
+	if err == nil || err == io.EOF {
+		client.observeRead(begin, read)
+	}
 	// log.LogErrorf("======> ExtentClient Read Exit, inode(%v), time[%v us].", inode, time.Since(t1).Microseconds())
 	return
 }
 
+// ReadExtentAtVer reads the data of the file range as of the snapshot version verSeq. The extent keys of the
+// version are fetched from the meta node, and the holes among them read as zeros. It bypasses the streamer,
+// so the version of the opened stream and the read version of the client are left untouched.
+func (client *ExtentClient) ReadExtentAtVer(ctx context.Context, inode uint64, data []byte, offset int, size int, verSeq uint64) (read int, err error) {
+	bgTime := stat.BeginStat()
+	defer func() {
+		stat.EndStat("read-extent-ver", err, bgTime, 1)
+	}()
+
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	if size == 0 {
+		return
+	}
+	if offset < 0 || size < 0 || len(data) < size {
+		log.LogErrorf("ReadExtentAtVer: invalid range, ino(%v) offset(%v) size(%v) len(data)(%v)",
+			inode, offset, size, len(data))
+		return 0, syscall.EINVAL
+	}
+	if client.getExtentsByVer == nil {
+		return 0, syscall.ENOTSUP
+	}
+
+	_, fileSize, extents, err := client.getExtentsByVer(inode, verSeq)
+	if err != nil {
+		log.LogErrorf("ReadExtentAtVer: get extents failed, ino(%v) verSeq(%v) err(%v)", inode, verSeq, err)
+		return
+	}
+	if offset >= int(fileSize) {
+		return
+	}
+	if end := int(fileSize); offset+size > end {
+		size = end - offset
+	}
+
+	for i := range extents {
+		ek := &extents[i]
+		ekStart, ekEnd := int(ek.FileOffset), int(ek.FileOffset)+int(ek.Size)
+		if ekEnd <= offset+read || ekStart >= offset+size {
+			continue
+		}
+		// the hole before the extent key reads as zeros
+		if ekStart > offset+read {
+			for j := read; j < ekStart-offset; j++ {
+				data[j] = 0
+			}
+			read = ekStart - offset
+		}
+		readSize := util.Min(ekEnd, offset+size) - (offset + read)
+		var n int
+		if n, err = client.readExtentKey(ctx, inode, ek, data[read:read+readSize], offset+read); err != nil {
+			log.LogErrorf("ReadExtentAtVer: ino(%v) ek(%v) verSeq(%v) offset(%v) size(%v) err(%v)",
+				inode, ek, verSeq, offset+read, readSize, err)
+			return
+		}
+		read += n
+	}
+	for j := read; j < size; j++ {
+		data[j] = 0
+	}
+	read = size
+	log.LogDebugf("ReadExtentAtVer: ino(%v) verSeq(%v) offset(%v) read(%v) extents(%v)",
+		inode, verSeq, offset, read, len(extents))
+	return
+}
+
+// readExtentKey reads the data of the extent key at the file offset, not through the streamer.
+func (client *ExtentClient) readExtentKey(ctx context.Context, inode uint64, ek *proto.ExtentKey, data []byte, fileOffset int) (read int, err error) {
+	dp, err := client.dataWrapper.GetDataPartition(ek.PartitionId)
+	if err != nil {
+		return
+	}
+	if dp.IsDiscard {
+		log.LogWarnf("readExtentKey: datapartition %v is discard", dp.PartitionID)
+		return 0, DpDiscardError
+	}
+	reader := NewExtentReader(inode, ek, dp, client.dataWrapper.FollowerRead(), !proto.IsCold(client.volumeType))
+	req := NewExtentRequest(fileOffset, len(data), data, ek)
+	if client.readLimiter != nil {
+		client.readLimiter.Wait(ctx)
+	}
+	if client.LimitManager != nil {
+		client.LimitManager.ReadAlloc(ctx, len(data))
+	}
+	return reader.Read(req)
+}
+
 func (client *ExtentClient) ReadExtent(inode uint64, ek *proto.ExtentKey, data []byte, offset int, size int) (read int, err error, isStream bool) {
 	bgTime := stat.BeginStat()
 	defer func() {
@@ -640,10 +968,10 @@ func (client *ExtentClient) ReadExtent(inode uint64, ek *proto.ExtentKey, data [
 			go func() {
 				log.LogDebugf("ReadExtent L2->L1 Enter cacheKey(%v),client.shouldBcache(%v),needCache(%v)", cacheKey, client.shouldBcache(), needCache)
 				if err := client.cacheBcache(cacheKey, buf); err != nil {
-					client.BcacheHealth = false
+					client.SetBcacheHealth(false)
 					log.LogDebugf("ReadExtent L2->L1 failed, err(%v), set BcacheHealth to false.", err)
 				}
-				log.LogDebugf("ReadExtent L2->L1 Exit cacheKey(%v),client.BcacheHealth(%v),needCache(%v)", cacheKey, client.BcacheHealth, needCache)
+				log.LogDebugf("ReadExtent L2->L1 Exit cacheKey(%v),client.BcacheHealth(%v),needCache(%v)", cacheKey, client.IsBcacheHealth(), needCache)
 			}()
 		}
 		return
@@ -685,7 +1013,58 @@ func (client *ExtentClient) GetRate() string {
 }
 
 func (client *ExtentClient) shouldBcache() bool {
-	return client.bcacheEnable && client.BcacheHealth
+	return client.bcacheEnable && client.IsBcacheHealth()
+}
+
+// IsBcacheHealth returns whether the local block cache is considered usable.
+func (client *ExtentClient) IsBcacheHealth() bool {
+	return atomic.LoadInt32(&client.bcacheHealth) == 1
+}
+
+// SetBcacheHealth updates the block cache health flag, and notifies
+// OnBcacheHealthChange only when the flag really transitions.
+func (client *ExtentClient) SetBcacheHealth(healthy bool) {
+	var from, to int32 = 1, 0
+	if healthy {
+		from, to = 0, 1
+	}
+	if !atomic.CompareAndSwapInt32(&client.bcacheHealth, from, to) {
+		return
+	}
+	log.LogWarnf("action[SetBcacheHealth] vol(%v) block cache health changed to %v", client.volumeName, healthy)
+	if client.onBcacheHealth != nil {
+		client.onBcacheHealth(healthy)
+	}
+}
+
+// probeBcache writes and evicts a probe block which can never collide with
+// the data of a real inode, the cache is marked healthy again on success.
+func (client *ExtentClient) probeBcache() {
+	if client.IsBcacheHealth() || client.cacheBcache == nil {
+		return
+	}
+	probeKey := util.GenerateKey(client.volumeName, 0, 0)
+	if err := client.cacheBcache(probeKey, []byte(probeKey)); err != nil {
+		log.LogDebugf("action[probeBcache] vol(%v) block cache still unhealthy, err(%v)", client.volumeName, err)
+		return
+	}
+	if client.evictBcache != nil {
+		client.evictBcache(probeKey)
+	}
+	client.SetBcacheHealth(true)
+}
+
+func (client *ExtentClient) backgroundProbeBcache() {
+	t := time.NewTicker(bcacheProbeInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-client.stopC:
+			return
+		case <-t.C:
+			client.probeBcache()
+		}
+	}
 }
 
 func getRate(lim *rate.Limiter) string {
@@ -725,6 +1104,9 @@ func (client *ExtentClient) Close() error {
 	for _, inode := range inodes {
 		_ = client.EvictStream(inode)
 	}
+	client.stopOnce.Do(func() {
+		close(client.stopC)
+	})
 	client.dataWrapper.Stop()
 	return nil
 }
@@ -744,6 +1126,11 @@ func (client *ExtentClient) GetDataPartitionForWrite() error {
 	return err
 }
 
+// SetWriteExclusion avoids writing to the data partition until ttl expires, e.g. after the caller hit a failure on it.
+func (client *ExtentClient) SetWriteExclusion(partitionID uint64, ttl time.Duration) {
+	client.dataWrapper.SetWriteExclusion(partitionID, ttl)
+}
+
 func (client *ExtentClient) UpdateDataPartitionForColdVolume() error {
 	return client.dataWrapper.UpdateDataPartition()
 }
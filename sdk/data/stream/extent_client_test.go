@@ -0,0 +1,139 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/manager"
+	"github.com/cubefs/cubefs/util"
+	"golang.org/x/time/rate"
+)
+
+func TestStreamerEvictionConfig(t *testing.T) {
+	cases := []struct {
+		disableMetaCache bool
+		maxStreamerLimit int64
+		expectLimit      int
+	}{
+		{true, 0, 0},
+		{true, 100000, 0},
+		{false, 0, 0},
+		{false, -1, 0},
+		{false, 100000, 100000 + fastStreamerEvictNum},
+		{false, 1, defaultStreamerLimit + fastStreamerEvictNum},
+	}
+	for _, c := range cases {
+		client := &ExtentClient{
+			streamers:        make(map[uint64]*Streamer),
+			disableMetaCache: c.disableMetaCache,
+			multiVerMgr:      &MultiVerMgr{verList: &proto.VolVersionInfoList{}},
+		}
+		client.initStreamerEviction(c.maxStreamerLimit)
+		// the meta cache is never turned off by the limit
+		if client.disableMetaCache != c.disableMetaCache {
+			t.Fatalf("case %+v: expect disableMetaCache %v, got %v", c, c.disableMetaCache, client.disableMetaCache)
+		}
+		// the background evictor runs only with a positive limit
+		if client.maxStreamerLimit != c.expectLimit {
+			t.Fatalf("case %+v: expect max streamer limit %v, got %v", c, c.expectLimit, client.maxStreamerLimit)
+		}
+
+		// only the streamers to evict are tracked
+		if err := client.OpenStreamWithCache(1, true); err != nil {
+			t.Fatalf("case %+v: open stream: %v", c, err)
+		}
+		expectTracked := 0
+		if c.expectLimit > 0 {
+			expectTracked = 1
+		}
+		if tracked := client.streamerList.Len(); tracked != expectTracked {
+			t.Fatalf("case %+v: expect %v tracked streamers, got %v", c, expectTracked, tracked)
+		}
+	}
+}
+
+func TestBcacheHealthProbe(t *testing.T) {
+	data := bytes.Repeat([]byte("data"), 1024)
+	eks := []proto.ExtentKey{{FileOffset: 0, PartitionId: 1, ExtentId: 1025, Size: 4096}}
+	client, cache := newPrewarmTestClient(t, 1, eks, map[uint64][]byte{1025: data})
+	client.readLimiter = rate.NewLimiter(rate.Inf, defaultReadLimitBurst)
+	client.LimitManager = manager.NewLimitManager(client)
+	var (
+		transitions []bool
+		evicted     []string
+	)
+	client.onBcacheHealth = func(healthy bool) { transitions = append(transitions, healthy) }
+	client.evictBcache = func(key string) error {
+		evicted = append(evicted, key)
+		return nil
+	}
+	cacheKey := util.GenerateKey("vol", 1, 0)
+
+	client.SetBcacheHealth(false)
+	client.SetBcacheHealth(false)
+	buf := make([]byte, 1000)
+	read, err, isStream := client.ReadExtent(1, &eks[0], buf, 100, len(buf))
+	if err != nil || read != len(buf) || !bytes.Equal(buf, data[100:1100]) {
+		t.Fatalf("ReadExtent: read %v err %v", read, err)
+	}
+	if !isStream {
+		t.Fatal("the unhealthy block cache should be bypassed")
+	}
+
+	// the cache is still unhealthy while the probe fails
+	client.cacheBcache = func(key string, buf []byte) error { return errors.New("disk error") }
+	client.probeBcache()
+	if client.IsBcacheHealth() || len(evicted) != 0 {
+		t.Fatalf("a failed probe should not re-enable the block cache, evicted %v", evicted)
+	}
+
+	client.cacheBcache = cache.put
+	client.probeBcache()
+	if !client.IsBcacheHealth() {
+		t.Fatal("a successful probe should re-enable the block cache")
+	}
+	if probeKey := util.GenerateKey("vol", 0, 0); !reflect.DeepEqual(evicted, []string{probeKey}) {
+		t.Fatalf("expect the probe block %v evicted, got %v", probeKey, evicted)
+	}
+	if !reflect.DeepEqual(transitions, []bool{false, true}) {
+		t.Fatalf("expect the health notified on the transitions only, got %v", transitions)
+	}
+
+	read, err, isStream = client.ReadExtent(1, &eks[0], buf, 100, len(buf))
+	if err != nil || read != len(buf) || !bytes.Equal(buf, data[100:1100]) {
+		t.Fatalf("ReadExtent: read %v err %v", read, err)
+	}
+	if isStream {
+		t.Fatal("the healthy block cache should be used")
+	}
+	for i := 0; ; i++ {
+		if got, ok := cache.get(cacheKey); ok {
+			if !bytes.Equal(got, data) {
+				t.Fatal("the whole extent should be cached")
+			}
+			break
+		}
+		if i == 100 {
+			t.Fatal("the extent is not cached")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
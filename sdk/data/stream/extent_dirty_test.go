@@ -0,0 +1,60 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIsDirty(t *testing.T) {
+	// the streamer has no stream writer running, so the requests stay pending
+	s := &Streamer{inode: 1, request: make(chan interface{}, 64), dirtylist: NewDirtyExtentList(), isOpen: true}
+	client := &ExtentClient{streamers: map[uint64]*Streamer{1: s}}
+	s.client = client
+	ctx := context.Background()
+
+	check := func(inode uint64, expect bool) {
+		t.Helper()
+		dirty, err := client.IsDirty(ctx, inode)
+		if err != nil {
+			t.Fatalf("IsDirty: ino(%v) err %v", inode, err)
+		}
+		if dirty != expect {
+			t.Fatalf("expect ino(%v) dirty %v, got %v", inode, expect, dirty)
+		}
+	}
+	check(1, false)
+	// the stream is not open
+	check(2, false)
+
+	// a write request waiting for the stream writer
+	s.request <- &WriteRequest{}
+	check(1, true)
+	<-s.request
+	check(1, false)
+
+	// a dirty handler waiting for the flush
+	s.dirtylist.Put(&ExtentHandler{})
+	check(1, true)
+	s.dirtylist.Remove(s.dirtylist.Get())
+	check(1, false)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := client.IsDirty(canceled, 1); err == nil {
+		t.Fatalf("expect the canceled IsDirty to fail")
+	}
+}
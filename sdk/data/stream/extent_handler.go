@@ -417,10 +417,11 @@ func (eh *ExtentHandler) cleanup() (err error) {
 		conn := eh.conn
 		eh.conn = nil
 		// TODO unhandled error
+		connPool := getConnPool(eh.dp.ClientWrapper)
 		if status := eh.getStatus(); status >= ExtentStatusRecovery {
-			StreamConnPool.PutConnect(conn, true)
+			connPool.PutConnect(conn, true)
 		} else {
-			StreamConnPool.PutConnect(conn, false)
+			connPool.PutConnect(conn, false)
 		}
 	}
 	return
@@ -590,7 +591,7 @@ func (eh *ExtentHandler) allocateExtent() (err error) {
 			extID = int(eh.key.ExtentId)
 		}
 
-		if conn, err = StreamConnPool.GetConnect(dp.Hosts[0]); err != nil {
+		if conn, err = getConnPool(dp.ClientWrapper).GetConnect(dp.Hosts[0]); err != nil {
 			log.LogWarnf("allocateExtent: failed to create connection, eh(%v) err(%v) dp(%v) exclude(%v)",
 				eh, err, dp, exclude)
 			// If storeMode is tinyExtentType and can't create connection, we also check host status.
@@ -625,16 +626,17 @@ func (eh *ExtentHandler) createExtent(dp *wrapper.DataPartition) (extID int, err
 		stat.EndStat("createExtent", err, bgTime, 1)
 	}()
 
-	conn, err := StreamConnPool.GetConnect(dp.Hosts[0])
+	connPool := getConnPool(dp.ClientWrapper)
+	conn, err := connPool.GetConnect(dp.Hosts[0])
 	if err != nil {
 		return extID, errors.Trace(err, "createExtent: failed to create connection, eh(%v) datapartionHosts(%v)", eh, dp.Hosts[0])
 	}
 
 	defer func() {
 		if err != nil {
-			StreamConnPool.PutConnect(conn, true)
+			connPool.PutConnect(conn, true)
 		} else {
-			StreamConnPool.PutConnect(conn, false)
+			connPool.PutConnect(conn, false)
 		}
 	}()
 
@@ -0,0 +1,177 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"sync/atomic"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// the extent keys merged on a flush at most, so that the merge doesn't stall the writes of the streamer
+const maxMergeExtentKeys = 64
+
+// ExtentMergeStat is the number of the extent keys before and after merging the adjacent ones on the
+// meta node, and the one of the extents to read before and after merging the reads of the adjacent ones.
+type ExtentMergeStat struct {
+	Before      uint64 `json:"before"`
+	After       uint64 `json:"after"`
+	ReadsBefore uint64 `json:"readsBefore"`
+	ReadsAfter  uint64 `json:"readsAfter"`
+}
+
+type extentMerger struct {
+	before      uint64
+	after       uint64
+	readsBefore uint64
+	readsAfter  uint64
+}
+
+// adjacentExtentKeys returns the first run of the adjacent extent keys, i.e. the ones of the same normal extent
+// with contiguous file and extent offsets and the same seq, up to limit keys. skip excludes the keys in use.
+func adjacentExtentKeys(eks []*proto.ExtentKey, limit int, skip func(ek *proto.ExtentKey) bool) []proto.ExtentKey {
+	var run []proto.ExtentKey
+	for _, ek := range eks {
+		if ek.PartitionId == 0 || ek.ExtentId == 0 || storage.IsTinyExtent(ek.ExtentId) || skip(ek) {
+			if len(run) > 1 {
+				return run
+			}
+			run = run[:0]
+			continue
+		}
+		if len(run) > 0 {
+			last := &run[len(run)-1]
+			if !last.IsSequenceWithSameSeq(ek) || last.IsSplit() != ek.IsSplit() {
+				if len(run) > 1 {
+					return run
+				}
+				run = run[:0]
+			}
+		}
+		run = append(run, *ek)
+		if len(run) >= limit {
+			return run
+		}
+	}
+	if len(run) > 1 {
+		return run
+	}
+	return nil
+}
+
+// mergeExtentKeys replaces a run of the adjacent extent keys of the streamer with the merged one on the meta
+// node, the extents are kept since the merged key covers the same data. It's done after a flush, and skips the
+// extent of the open handler since the handler extends its key by the file offset it started with.
+func (s *Streamer) mergeExtentKeys() {
+	if s.client.extentMerger == nil || s.client.mergeExtentKeys == nil || (s.client.bcacheEnable && s.needBCache) {
+		return
+	}
+	var open *proto.ExtentKey
+	if s.handler != nil && s.handler.key != nil {
+		open = s.handler.key
+	}
+	eks := adjacentExtentKeys(s.extents.List(), maxMergeExtentKeys, func(ek *proto.ExtentKey) bool {
+		return open != nil && ek.IsSameExtent(open)
+	})
+	if len(eks) == 0 {
+		return
+	}
+
+	merged := eks[0]
+	if eks[0].SnapInfo != nil {
+		snapInfo := *eks[0].SnapInfo
+		merged.SnapInfo = &snapInfo
+	}
+	merged.CRC = 0
+	for _, ek := range eks[1:] {
+		merged.Size += ek.Size
+	}
+	if err := s.client.mergeExtentKeys(s.parentInode, s.inode, merged, eks); err != nil {
+		log.LogWarnf("mergeExtentKeys: ino(%v) eks(%v) err(%v)", s.inode, eks, err)
+		return
+	}
+	if !s.extents.Merge(&merged, eks) {
+		log.LogWarnf("mergeExtentKeys: ino(%v) eks(%v) changed, refresh the extents", s.inode, eks)
+		if err := s.extents.RefreshForce(s.inode, s.client.getExtents); err != nil {
+			log.LogErrorf("mergeExtentKeys: ino(%v) refresh err(%v)", s.inode, err)
+		}
+	}
+	atomic.AddUint64(&s.client.extentMerger.before, uint64(len(eks)))
+	atomic.AddUint64(&s.client.extentMerger.after, 1)
+	log.LogDebugf("mergeExtentKeys: ino(%v) eks(%v) merged(%v)", s.inode, eks, merged)
+}
+
+// canMergeExtent tells whether the read of right can be done along with the one of left, which is
+// the case if the extent keys are physically adjacent and the reads cover them to the adjoining end.
+func canMergeExtent(left, right *ExtentRequest) bool {
+	if left.ExtentKey == nil || right.ExtentKey == nil {
+		return false
+	}
+	lek, rek := left.ExtentKey, right.ExtentKey
+	if lek.PartitionId == 0 || lek.ExtentId == 0 || storage.IsTinyExtent(lek.ExtentId) || lek.IsSplit() || rek.IsSplit() {
+		return false
+	}
+	return lek.IsSequenceWithSameSeq(rek) &&
+		left.FileOffset+left.Size == int(lek.FileOffset)+int(lek.Size) &&
+		right.FileOffset == int(rek.FileOffset)
+}
+
+// merge coalesces the reads of the physically adjacent extents, i.e. those in the same extent with
+// contiguous file and extent offsets, so that they are read as one. The extent keys themselves are
+// left as they are, the merged requests carry a copy spanning the adjacent keys.
+func (m *extentMerger) merge(offset int, data []byte, requests []*ExtentRequest) []*ExtentRequest {
+	merged := make([]*ExtentRequest, 0, len(requests))
+	var extents uint64
+	for _, req := range requests {
+		if req.ExtentKey != nil {
+			extents++
+		}
+		if len(merged) == 0 || !canMergeExtent(merged[len(merged)-1], req) {
+			merged = append(merged, req)
+			continue
+		}
+		last := merged[len(merged)-1]
+		ek := *last.ExtentKey
+		ek.Size += req.ExtentKey.Size
+		size := last.Size + req.Size
+		start := last.FileOffset - offset
+		merged[len(merged)-1] = NewExtentRequest(last.FileOffset, size, data[start:start+size], &ek)
+	}
+	if extents > 0 {
+		atomic.AddUint64(&m.readsBefore, extents)
+		atomic.AddUint64(&m.readsAfter, extents-uint64(len(requests)-len(merged)))
+	}
+	return merged
+}
+
+func (m *extentMerger) stat() *ExtentMergeStat {
+	return &ExtentMergeStat{
+		Before:      atomic.LoadUint64(&m.before),
+		After:       atomic.LoadUint64(&m.after),
+		ReadsBefore: atomic.LoadUint64(&m.readsBefore),
+		ReadsAfter:  atomic.LoadUint64(&m.readsAfter),
+	}
+}
+
+// GetExtentMergeStat returns the number of the extent keys and of the extents read before and after merging
+// the adjacent ones, nil if MergeAdjacentExtents is not enabled.
+func (client *ExtentClient) GetExtentMergeStat() *ExtentMergeStat {
+	if client.extentMerger == nil {
+		return nil
+	}
+	return client.extentMerger.stat()
+}
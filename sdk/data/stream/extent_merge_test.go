@@ -0,0 +1,184 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/manager"
+	"golang.org/x/time/rate"
+)
+
+func TestMergeAdjacentExtents(t *testing.T) {
+	const (
+		ekSize   = 4096
+		appends  = 64
+		extentID = 1024
+	)
+	// the extents on the data nodes
+	extents := map[uint64][]byte{
+		extentID:     make([]byte, appends*ekSize),
+		extentID + 1: make([]byte, ekSize),
+	}
+	for _, e := range extents {
+		rand.Read(e)
+	}
+
+	// many small appends to the same extent, then one to another extent and a hole at last
+	eks := make([]proto.ExtentKey, 0, appends+1)
+	for i := 0; i < appends; i++ {
+		eks = append(eks, proto.ExtentKey{
+			FileOffset: uint64(i * ekSize), PartitionId: 1, ExtentId: extentID,
+			ExtentOffset: uint64(i * ekSize), Size: ekSize,
+		})
+	}
+	eks = append(eks, proto.ExtentKey{FileOffset: appends * ekSize, PartitionId: 1, ExtentId: extentID + 1, Size: ekSize})
+	fileSize := (appends + 2) * ekSize
+	cache := NewExtentCache(1)
+	cache.update(1, uint64(fileSize), false, eks)
+
+	read := func(requests []*ExtentRequest) {
+		for _, req := range requests {
+			if req.ExtentKey == nil {
+				continue
+			}
+			off := int(req.ExtentKey.ExtentOffset) + req.FileOffset - int(req.ExtentKey.FileOffset)
+			copy(req.Data, extents[req.ExtentKey.ExtentId][off:off+req.Size])
+		}
+	}
+
+	merger := &extentMerger{}
+	for _, r := range []struct{ offset, size int }{
+		{0, fileSize},
+		{ekSize / 2, fileSize - ekSize},
+		{3*ekSize + 1, 5 * ekSize},
+		{(appends - 1) * ekSize, 2 * ekSize},
+	} {
+		expect := make([]byte, r.size)
+		requests := cache.PrepareReadRequests(r.offset, r.size, expect)
+		read(requests)
+
+		data := make([]byte, r.size)
+		merged := merger.merge(r.offset, data, cache.PrepareReadRequests(r.offset, r.size, data))
+		read(merged)
+		if !bytes.Equal(expect, data) {
+			t.Fatalf("read (%v, %v) differs after merging", r.offset, r.size)
+		}
+		if len(requests) > 3 && len(merged) >= len(requests) {
+			t.Fatalf("read (%v, %v) expect fewer requests than %v, got %v", r.offset, r.size, len(requests), len(merged))
+		}
+	}
+
+	// the whole file is read as 2 extents plus the hole
+	merged := merger.merge(0, make([]byte, fileSize), cache.PrepareReadRequests(0, fileSize, make([]byte, fileSize)))
+	if len(merged) != 3 || merged[0].Size != appends*ekSize || merged[0].ExtentKey.Size != appends*ekSize {
+		t.Fatalf("unexpected merged requests %v", merged)
+	}
+	if cached := cache.List(); len(cached) != len(eks) || cached[0].Size != ekSize {
+		t.Fatalf("the cached extent keys should be left as they are, got %v", cached)
+	}
+	stat := merger.stat()
+	if stat.ReadsBefore <= stat.ReadsAfter || stat.ReadsAfter == 0 || stat.Before != 0 {
+		t.Fatalf("unexpected merge stat %+v", stat)
+	}
+}
+
+func TestMergeExtentKeysOnFlush(t *testing.T) {
+	const (
+		inode    = 1
+		ekSize   = 4096
+		appends  = maxMergeExtentKeys + 16
+		extentID = 1024
+	)
+	store := map[uint64][]byte{
+		extentID:     make([]byte, appends*ekSize),
+		extentID + 1: make([]byte, ekSize),
+	}
+	for _, e := range store {
+		rand.Read(e)
+	}
+	// many small appends to the same extent, then one to another extent
+	eks := make([]proto.ExtentKey, 0, appends+1)
+	for i := 0; i < appends; i++ {
+		eks = append(eks, proto.ExtentKey{
+			FileOffset: uint64(i * ekSize), PartitionId: 1, ExtentId: extentID,
+			ExtentOffset: uint64(i * ekSize), Size: ekSize,
+		})
+	}
+	eks = append(eks, proto.ExtentKey{FileOffset: appends * ekSize, PartitionId: 1, ExtentId: extentID + 1, Size: ekSize})
+	fileSize := (appends + 1) * ekSize
+
+	client, _ := newPrewarmTestClient(t, inode, eks, store)
+	client.bcacheEnable = false
+	client.readLimiter = rate.NewLimiter(rate.Inf, defaultReadLimitBurst)
+	client.LimitManager = manager.NewLimitManager(client)
+	client.extentMerger = &extentMerger{}
+	// the meta node replaces the keys
+	var merges [][]proto.ExtentKey
+	client.mergeExtentKeys = func(parentInode, ino uint64, key proto.ExtentKey, merged []proto.ExtentKey) error {
+		size := uint32(0)
+		for _, ek := range merged {
+			size += ek.Size
+		}
+		if ino != inode || key.FileOffset != merged[0].FileOffset || key.ExtentOffset != merged[0].ExtentOffset || key.Size != size {
+			t.Errorf("unexpected merged key %v of %v", key, merged)
+		}
+		merges = append(merges, merged)
+		return nil
+	}
+	s := client.GetStreamer(inode)
+	s.dirtylist = NewDirtyExtentList()
+
+	read := func() []byte {
+		data := make([]byte, fileSize)
+		if n, err := client.Read(inode, data, 0, fileSize); err != nil || n != fileSize {
+			t.Fatalf("Read: n %v err %v", n, err)
+		}
+		return data
+	}
+	flush := func() {
+		req := &FlushRequest{done: make(chan struct{}, 1)}
+		s.handleRequest(req)
+		if req.err != nil {
+			t.Fatalf("flush: %v", req.err)
+		}
+	}
+	expect := read()
+
+	// a flush merges maxMergeExtentKeys keys at most, the next one the merged key and the rest of the adjacent ones
+	flush()
+	if len(merges) != 1 || len(merges[0]) != maxMergeExtentKeys || len(s.extents.List()) != len(eks)-maxMergeExtentKeys+1 {
+		t.Fatalf("unexpected merges %v, cached %v", len(merges), len(s.extents.List()))
+	}
+	flush()
+	cached := s.extents.List()
+	if len(merges) != 2 || len(merges[1]) != appends-maxMergeExtentKeys+1 || len(cached) != 2 || cached[0].Size != appends*ekSize {
+		t.Fatalf("unexpected merges %v, cached %v", len(merges), cached)
+	}
+	flush()
+	if len(merges) != 2 {
+		t.Fatalf("no adjacent keys should be left, merges %v", len(merges))
+	}
+	if got := read(); !bytes.Equal(expect, got) {
+		t.Fatal("the read differs after merging")
+	}
+	stat := client.GetExtentMergeStat()
+	if stat.Before != appends+1 || stat.After != 2 {
+		t.Fatalf("unexpected merge stat %+v", stat)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"hash/crc32"
 	"net"
 	"strings"
+	"sync/atomic"
 
 	"github.com/cubefs/cubefs/proto"
 	"github.com/cubefs/cubefs/sdk/data/wrapper"
@@ -34,6 +35,7 @@ type ExtentReader struct {
 	dp           *wrapper.DataPartition
 	followerRead bool
 	retryRead    bool
+	hedger       *readHedger
 }
 
 // NewExtentReader returns a new extent reader.
@@ -55,23 +57,82 @@ func (reader *ExtentReader) String() (m string) {
 
 // Read reads the extent request.
 func (reader *ExtentReader) Read(req *ExtentRequest) (readBytes int, err error) {
+	sc := NewStreamConn(reader.dp, reader.followerRead)
+	if reader.hedger != nil && reader.followerRead {
+		if backupAddr := reader.backupAddr(sc.currAddr); backupAddr != "" {
+			return reader.hedgedRead(req, sc, backupAddr)
+		}
+	}
+	return reader.read(req, req.Data, sc, nil)
+}
+
+// backupAddr returns another available replica for the backup read in the order of the read preference,
+// empty if there is none.
+func (reader *ExtentReader) backupAddr(primaryAddr string) string {
+	hosts := sortByStatus(reader.dp, false)
+	if reader.dp.ClientWrapper.ReadPreference() != wrapper.ReadPreferDefault {
+		hosts = partitionByStatus(reader.dp, preferredHosts(reader.dp, atomic.LoadUint64(&reader.dp.Epoch)), false)
+	}
+	for _, addr := range hosts {
+		if addr != "" && addr != primaryAddr {
+			return addr
+		}
+	}
+	return ""
+}
+
+// hedgedRead reads from the primary replica, and from the backup replica as well if the primary one is slow.
+// The primary read goes to the request directly, the backup one to its own buffer allocated once it is sent.
+func (reader *ExtentReader) hedgedRead(req *ExtentRequest, sc *StreamConn, backupAddr string) (readBytes int, err error) {
+	var backupData []byte
+	readBytes, err, byBackup := reader.hedger.do(
+		func(c *readCancel) (int, error) {
+			return reader.read(req, req.Data, sc, c)
+		},
+		func(c *readCancel) (int, error) {
+			backupData = make([]byte, req.Size)
+			return reader.read(req, backupData, newFollowerStreamConn(reader.dp, backupAddr), c)
+		})
+	if err != nil || !byBackup {
+		return
+	}
+	log.LogDebugf("ExtentReader hedgedRead: backup addr(%v) wins, primary addr(%v) req(%v)", backupAddr, sc.currAddr, req)
+	copy(req.Data, backupData[:readBytes])
+	return
+}
+
+// read reads the extent request into data through the stream connection, c cancels the read if it is not nil.
+func (reader *ExtentReader) read(req *ExtentRequest, data []byte, sc *StreamConn, c *readCancel) (readBytes int, err error) {
 	offset := req.FileOffset - int(reader.key.FileOffset) + int(reader.key.ExtentOffset)
 	size := req.Size
 
 	reqPacket := NewReadPacket(reader.key, offset, size, reader.inode, req.FileOffset, reader.followerRead)
-	sc := NewStreamConn(reader.dp, reader.followerRead)
 
 	log.LogDebugf("ExtentReader Read enter: size(%v) req(%v) reqPacket(%v)", size, req, reqPacket)
 
-	err = sc.Send(&reader.retryRead, reqPacket, func(conn *net.TCPConn) (error, bool) {
+	err = sc.Send(&reader.retryRead, reqPacket, func(conn *net.TCPConn) (replyErr error, again bool) {
+		if c != nil {
+			if !c.attach(conn) {
+				return errReadCanceled, false
+			}
+			defer func() {
+				// the connection closed by the cancel is force closed rather than put back to the pool
+				if c.detach() && replyErr == nil {
+					replyErr, again = errReadCanceled, false
+				}
+			}()
+		}
 		readBytes = 0
 		for readBytes < size {
 			replyPacket := NewReply(reqPacket.ReqID, reader.dp.PartitionID, reqPacket.ExtentID)
 			bufSize := util.Min(util.ReadBlockSize, size-readBytes)
-			replyPacket.Data = req.Data[readBytes : readBytes+bufSize]
+			replyPacket.Data = data[readBytes : readBytes+bufSize]
 			e := replyPacket.readFromConn(conn, proto.ReadDeadlineTime)
 
 			if e != nil {
+				if c != nil && c.isCanceled() {
+					return errReadCanceled, false
+				}
 				log.LogWarnf("Extent Reader Read: failed to read from connect, ino(%v) req(%v) readBytes(%v) err(%v)", reader.inode, reqPacket, readBytes, e)
 				// Upon receiving TryOtherAddrError, other hosts will be retried.
 				return TryOtherAddrError, false
@@ -94,7 +155,9 @@ func (reader *ExtentReader) Read(req *ExtentRequest) (readBytes int, err error)
 		return nil, false
 	})
 
-	if err != nil {
+	if err == errReadCanceled {
+		log.LogDebugf("ExtentReader Read canceled: addr(%v) req(%v) reqPacket(%v)", sc.currAddr, req, reqPacket)
+	} else if err != nil {
 		// if cold vol and cach is invaild
 		if !reader.retryRead && (err == TryOtherAddrError || strings.Contains(err.Error(), "ExistErr")) {
 			log.LogWarnf("Extent Reader Read: err(%v) req(%v) reqPacket(%v)", err, req, reqPacket)
@@ -0,0 +1,151 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+)
+
+// startExtentReplica serves the reads with the data of the extents in store, like the extent store of a data
+// node it knows nothing about the versions.
+func startExtentReplica(t *testing.T, store map[uint64][]byte) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				for {
+					req := new(proto.Packet)
+					if err := req.ReadFromConnWithVer(conn, proto.NoReadDeadlineTime); err != nil {
+						return
+					}
+					data, ok := store[req.ExtentID]
+					if !ok || req.ExtentOffset+int64(req.Size) > int64(len(data)) {
+						reply := NewReply(req.ReqID, req.PartitionID, req.ExtentID)
+						reply.ResultCode = proto.OpNotExistErr
+						reply.Data = []byte("extent not exist")
+						reply.Size = uint32(len(reply.Data))
+						if err := reply.writeToConn(conn); err != nil {
+							return
+						}
+						continue
+					}
+					// reply in blocks of util.ReadBlockSize like a data node
+					for off := int64(0); off < int64(req.Size); off += util.ReadBlockSize {
+						size := util.Min(util.ReadBlockSize, int(int64(req.Size)-off))
+						reply := NewReply(req.ReqID, req.PartitionID, req.ExtentID)
+						reply.ResultCode = proto.OpOk
+						reply.ExtentOffset = req.ExtentOffset + off
+						reply.Size = uint32(size)
+						reply.Data = data[reply.ExtentOffset : reply.ExtentOffset+int64(size)]
+						if err := reply.writeToConn(conn); err != nil {
+							return
+						}
+					}
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestReadExtentAtVer(t *testing.T) {
+	if proto.Buffers == nil {
+		proto.InitBufferPool(int64(32768))
+	}
+	oldData := bytes.Repeat([]byte("old "), 1024)
+	newData := bytes.Repeat([]byte("new "), 256)
+	tailData := bytes.Repeat([]byte("tail"), 512)
+	addr := startExtentReplica(t, map[uint64][]byte{1025: oldData, 1026: newData, 1027: tailData})
+
+	dp := &proto.DataPartitionResponse{PartitionID: 1, Hosts: []string{addr}, LeaderAddr: addr}
+	client := &ExtentClient{dataWrapper: newTestWrapper(t, "vol", dp)}
+
+	// version 1 has the old extent only, version 2 overwrites its head and appends another extent after a hole
+	versions := map[uint64]struct {
+		size    uint64
+		extents []proto.ExtentKey
+	}{
+		1: {4096, []proto.ExtentKey{{FileOffset: 0, PartitionId: 1, ExtentId: 1025, Size: 4096}}},
+		2: {8192, []proto.ExtentKey{
+			{FileOffset: 0, PartitionId: 1, ExtentId: 1026, Size: 1024},
+			{FileOffset: 1024, PartitionId: 1, ExtentId: 1025, ExtentOffset: 1024, Size: 3072},
+			{FileOffset: 6144, PartitionId: 1, ExtentId: 1027, Size: 2048},
+		}},
+	}
+	client.getExtentsByVer = func(inode uint64, verSeq uint64) (uint64, uint64, []proto.ExtentKey, error) {
+		v, ok := versions[verSeq]
+		if !ok {
+			return 0, 0, nil, syscall.ENOENT
+		}
+		return 0, v.size, v.extents, nil
+	}
+
+	readAt := func(verSeq uint64, offset, size int) []byte {
+		t.Helper()
+		data := bytes.Repeat([]byte{0xff}, size)
+		n, err := client.ReadExtentAtVer(context.Background(), 1, data, offset, size, verSeq)
+		if err != nil {
+			t.Fatalf("read at version %v: err(%v)", verSeq, err)
+		}
+		return data[:n]
+	}
+
+	// the same file range reads the content of each version
+	if got := readAt(1, 0, 8192); !bytes.Equal(got, oldData) {
+		t.Fatalf("expect the data of version 1 up to its size, got %v bytes", len(got))
+	}
+	expect := append(append(append([]byte{}, newData...), oldData[1024:]...), make([]byte, 2048)...)
+	expect = append(expect, tailData...)
+	if got := readAt(2, 0, 8192); !bytes.Equal(got, expect) {
+		t.Fatalf("expect the data of version 2 with the hole read as zeros")
+	}
+	if got := readAt(2, 512, 1024); !bytes.Equal(got, expect[512:1536]) {
+		t.Fatalf("expect the partial data of version 2 across the extent keys")
+	}
+	if got := readAt(1, 512, 1024); !bytes.Equal(got, oldData[512:1536]) {
+		t.Fatalf("expect the partial data of version 1")
+	}
+	if got := readAt(1, 4096, 10); len(got) != 0 {
+		t.Fatalf("expect nothing read beyond the size of version 1, got %v bytes", len(got))
+	}
+
+	if _, err := client.ReadExtentAtVer(context.Background(), 1, make([]byte, 10), 0, 10, 3); err != syscall.ENOENT {
+		t.Fatalf("expect ENOENT for a version not exist, got %v", err)
+	}
+	if _, err := client.ReadExtentAtVer(context.Background(), 1, make([]byte, 5), 0, 10, 1); err != syscall.EINVAL {
+		t.Fatalf("expect EINVAL for the buffer smaller than the size, got %v", err)
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.ReadExtentAtVer(canceled, 1, make([]byte, 10), 0, 10, 1); err == nil {
+		t.Fatalf("expect the canceled read to fail")
+	}
+}
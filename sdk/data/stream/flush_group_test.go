@@ -0,0 +1,109 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"context"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// flushRecorder stands for the stream writers, it records the order the inodes are flushed in.
+type flushRecorder struct {
+	sync.Mutex
+	flushed []uint64
+	delays  map[uint64]time.Duration
+	errs    map[uint64]error
+}
+
+func (r *flushRecorder) serve(s *Streamer) {
+	for req := range s.request {
+		request, ok := req.(*FlushRequest)
+		if !ok {
+			continue
+		}
+		time.Sleep(r.delays[s.inode])
+		r.Lock()
+		r.flushed = append(r.flushed, s.inode)
+		r.Unlock()
+		request.err = r.errs[s.inode]
+		request.done <- struct{}{}
+	}
+}
+
+func (r *flushRecorder) take() []uint64 {
+	r.Lock()
+	defer r.Unlock()
+	flushed := r.flushed
+	r.flushed = nil
+	return flushed
+}
+
+func TestFlushGroup(t *testing.T) {
+	recorder := &flushRecorder{
+		delays: map[uint64]time.Duration{1: 100 * time.Millisecond, 2: 10 * time.Millisecond, 3: 50 * time.Millisecond},
+		errs:   map[uint64]error{4: syscall.EIO},
+	}
+	client := &ExtentClient{streamers: make(map[uint64]*Streamer)}
+	for inode := uint64(1); inode <= 4; inode++ {
+		s := &Streamer{inode: inode, client: client, request: make(chan interface{}, 64), isOpen: true}
+		client.streamers[inode] = s
+		go recorder.serve(s)
+		defer close(s.request)
+	}
+	ctx := context.Background()
+
+	// all the inodes are flushed before the call returns, concurrently rather than one by one,
+	// so the fast ones finish first
+	if err := client.FlushGroup(ctx, []uint64{1, 2, 3, 2}); err != nil {
+		t.Fatalf("FlushGroup: err %v", err)
+	}
+	flushed := recorder.take()
+	if len(flushed) != 3 || flushed[0] != 2 || flushed[1] != 3 || flushed[2] != 1 {
+		t.Fatalf("expect inodes [2 3 1] flushed once each, got %v", flushed)
+	}
+
+	// the slow group is fully flushed before the next one starts
+	if err := client.FlushOrdered(ctx, []uint64{1, 3}, []uint64{2}); err != nil {
+		t.Fatalf("FlushOrdered: err %v", err)
+	}
+	if flushed = recorder.take(); len(flushed) != 3 || flushed[2] != 2 {
+		t.Fatalf("expect inode 2 flushed last, got %v", flushed)
+	}
+
+	// the error of any inode fails the group, and the groups after it are not flushed
+	if err := client.FlushOrdered(ctx, []uint64{2, 4}, []uint64{3}); err != syscall.EIO {
+		t.Fatalf("expect EIO, got %v", err)
+	}
+	if flushed = recorder.take(); len(flushed) != 2 {
+		t.Fatalf("expect the first group flushed only, got %v", flushed)
+	}
+
+	// no inode is flushed if any of them is not open
+	if err := client.FlushGroup(ctx, []uint64{2, 5}); err != syscall.EBADF {
+		t.Fatalf("expect EBADF, got %v", err)
+	}
+	canceled, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := client.FlushGroup(canceled, []uint64{1}); err != context.DeadlineExceeded {
+		t.Fatalf("expect the deadline exceeded, got %v", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if flushed = recorder.take(); len(flushed) != 1 || flushed[0] != 1 {
+		t.Fatalf("expect the flush goes on in the background, got %v", flushed)
+	}
+}
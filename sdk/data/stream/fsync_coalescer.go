@@ -0,0 +1,86 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/util/log"
+)
+
+type fsyncResult struct {
+	done chan struct{}
+	err  error
+}
+
+// fsyncCoalescer gathers the fsyncs issued within a window and syncs them as one batch.
+// Fsyncs of the same inode in a batch share a single flush, the inodes of a batch are flushed concurrently,
+// and every caller returns only after the flush of its inode, which starts after the caller joined, is done.
+type fsyncCoalescer struct {
+	window  time.Duration
+	flush   func(inode uint64) error
+	mu      sync.Mutex
+	pending map[uint64]*fsyncResult
+	batches uint64
+	syncs   uint64
+}
+
+func newFsyncCoalescer(window time.Duration, flush func(inode uint64) error) *fsyncCoalescer {
+	return &fsyncCoalescer{
+		window: window,
+		flush:  flush,
+	}
+}
+
+func (c *fsyncCoalescer) sync(inode uint64) error {
+	atomic.AddUint64(&c.syncs, 1)
+	c.mu.Lock()
+	if c.pending == nil {
+		c.pending = make(map[uint64]*fsyncResult)
+		time.AfterFunc(c.window, c.commit)
+	}
+	res, ok := c.pending[inode]
+	if !ok {
+		res = &fsyncResult{done: make(chan struct{})}
+		c.pending[inode] = res
+	}
+	c.mu.Unlock()
+
+	<-res.done
+	return res.err
+}
+
+func (c *fsyncCoalescer) commit() {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	atomic.AddUint64(&c.batches, 1)
+	log.LogDebugf("fsyncCoalescer commit: inodes(%v)", len(batch))
+	for inode, res := range batch {
+		go func(inode uint64, res *fsyncResult) {
+			res.err = c.flush(inode)
+			close(res.done)
+		}(inode, res)
+	}
+}
+
+// stat returns the number of fsyncs and the number of batches they are coalesced into.
+func (c *fsyncCoalescer) stat() (syncs, batches uint64) {
+	return atomic.LoadUint64(&c.syncs), atomic.LoadUint64(&c.batches)
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFsyncCoalescer(t *testing.T) {
+	const (
+		files  = 64
+		rounds = 5
+	)
+	var (
+		mu      sync.Mutex
+		written = make(map[uint64]int)
+		durable = make(map[uint64]int)
+		flushes int64
+	)
+	// the fake flush makes everything written before it starts durable
+	flush := func(inode uint64) error {
+		atomic.AddInt64(&flushes, 1)
+		mu.Lock()
+		version := written[inode]
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		if version > durable[inode] {
+			durable[inode] = version
+		}
+		mu.Unlock()
+		return nil
+	}
+	c := newFsyncCoalescer(5*time.Millisecond, flush)
+
+	var wg sync.WaitGroup
+	for i := 0; i < files; i++ {
+		// two writers share each inode
+		for j := 0; j < 2; j++ {
+			wg.Add(1)
+			go func(inode uint64) {
+				defer wg.Done()
+				for r := 0; r < rounds; r++ {
+					mu.Lock()
+					written[inode]++
+					version := written[inode]
+					mu.Unlock()
+					if err := c.sync(inode); err != nil {
+						t.Errorf("sync inode(%v) failed: %v", inode, err)
+						return
+					}
+					mu.Lock()
+					ok := durable[inode] >= version
+					mu.Unlock()
+					if !ok {
+						t.Errorf("fsync of inode(%v) returned before version(%v) is durable", inode, version)
+						return
+					}
+				}
+			}(uint64(i + 1))
+		}
+	}
+	wg.Wait()
+
+	syncs, batches := c.stat()
+	if syncs != files*2*rounds {
+		t.Fatalf("expect %v fsyncs, got %v", files*2*rounds, syncs)
+	}
+	if batches >= syncs || uint64(atomic.LoadInt64(&flushes)) >= syncs {
+		t.Fatalf("fsyncs are not coalesced: syncs(%v) batches(%v) flushes(%v)", syncs, batches, flushes)
+	}
+	t.Logf("syncs(%v) batches(%v) flushes(%v)", syncs, batches, flushes)
+}
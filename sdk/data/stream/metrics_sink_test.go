@@ -0,0 +1,119 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/manager"
+	"golang.org/x/time/rate"
+)
+
+type fakeMetricsSink struct {
+	sync.Mutex
+	reads  []int
+	writes []int
+	maxDur time.Duration
+}
+
+func (f *fakeMetricsSink) ObserveRead(d time.Duration, size int) {
+	f.Lock()
+	defer f.Unlock()
+	f.reads = append(f.reads, size)
+	if d > f.maxDur {
+		f.maxDur = d
+	}
+}
+
+func (f *fakeMetricsSink) ObserveWrite(d time.Duration, size int) {
+	f.Lock()
+	defer f.Unlock()
+	f.writes = append(f.writes, size)
+	if d > f.maxDur {
+		f.maxDur = d
+	}
+}
+
+func TestMetricsSink(t *testing.T) {
+	// a client without sink must not panic
+	client := &ExtentClient{}
+	client.observeRead(time.Now(), 4096)
+	client.observeWrite(time.Now(), 4096)
+
+	sink := &fakeMetricsSink{}
+	client.metricsSink = sink
+	begin := time.Now().Add(-time.Millisecond)
+	client.observeRead(begin, 4096)
+	client.observeWrite(begin, 128*1024)
+	client.observeRead(begin, 0)
+
+	if len(sink.reads) != 2 || sink.reads[0] != 4096 || sink.reads[1] != 0 {
+		t.Fatalf("unexpected reads %v", sink.reads)
+	}
+	if len(sink.writes) != 1 || sink.writes[0] != 128*1024 {
+		t.Fatalf("unexpected writes %v", sink.writes)
+	}
+	if sink.maxDur < time.Millisecond {
+		t.Errorf("expect latency at least 1ms, got %v", sink.maxDur)
+	}
+}
+
+func TestMetricsSinkThroughStreamer(t *testing.T) {
+	sink := &fakeMetricsSink{}
+
+	data := bytes.Repeat([]byte("read"), 1024)
+	eks := []proto.ExtentKey{{FileOffset: 0, PartitionId: 1, ExtentId: 1025, Size: 4096}}
+	client, _ := newPrewarmTestClient(t, 1, eks, map[uint64][]byte{1025: data})
+	client.bcacheEnable = false
+	client.readLimiter = rate.NewLimiter(rate.Inf, defaultReadLimitBurst)
+	client.LimitManager = manager.NewLimitManager(client)
+	client.metricsSink = sink
+	buf := make([]byte, 1000)
+	if read, err := client.Read(1, buf, 100, len(buf)); err != nil || read != len(buf) {
+		t.Fatalf("Read: read %v err %v", read, err)
+	}
+	if !bytes.Equal(buf, data[100:1100]) {
+		t.Fatal("unexpected data read")
+	}
+
+	file := make([]byte, 64)
+	wclient, stop := newWriteVTestClient(1, file, 32)
+	defer stop()
+	wclient.metricsSink = sink
+	if write, err := wclient.Write(1, 0, []byte("hello"), 0, nil); err != nil || write != 5 {
+		t.Fatalf("Write: write %v err %v", write, err)
+	}
+	if write, err := wclient.WriteV(context.Background(), 1, 5, [][]byte{[]byte(" cube"), []byte("fs")}, 0, nil); err != nil || write != 7 {
+		t.Fatalf("WriteV: write %v err %v", write, err)
+	}
+	// the failed write is not observed
+	if _, err := wclient.Write(1, 32, []byte("fail"), 0, nil); err == nil {
+		t.Fatal("the write at the fail offset should fail")
+	}
+
+	sink.Lock()
+	defer sink.Unlock()
+	if len(sink.reads) != 1 || sink.reads[0] != len(buf) {
+		t.Fatalf("unexpected reads %v", sink.reads)
+	}
+	if len(sink.writes) != 2 || sink.writes[0] != 5 || sink.writes[1] != 7 {
+		t.Fatalf("unexpected writes %v", sink.writes)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cubefs/cubefs/blockcache/bcache"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/buf"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const prewarmConcurrency = 4
+
+// ExtentRange is a range of the file to prewarm.
+type ExtentRange struct {
+	Offset uint64
+	Size   uint64
+}
+
+// extentsInRanges returns the extents overlapping any of the ranges, in the order of the extents.
+func extentsInRanges(eks []*proto.ExtentKey, ranges []ExtentRange) (ret []*proto.ExtentKey) {
+	for _, ek := range eks {
+		for _, r := range ranges {
+			if r.Size > 0 && ek.FileOffset < r.Offset+r.Size && r.Offset < ek.FileOffset+uint64(ek.Size) {
+				ret = append(ret, ek)
+				break
+			}
+		}
+	}
+	return
+}
+
+// Prewarm reads the extents overlapping the ranges of the inode into the local block cache, without returning
+// the data. Like the reads, it does not cache a file bigger than bcache.MaxFileSize, and skips the extents being
+// cached and the big extents once too many of them are being cached. It stops issuing reads once ctx is done.
+func (client *ExtentClient) Prewarm(ctx context.Context, inode uint64, ranges []ExtentRange) (err error) {
+	if !client.shouldBcache() || client.cacheBcache == nil {
+		return fmt.Errorf("Prewarm: block cache is not available, ino(%v)", inode)
+	}
+	s := client.GetStreamer(inode)
+	if s == nil {
+		return fmt.Errorf("Prewarm: stream is not opened yet, ino(%v)", inode)
+	}
+	s.once.Do(func() {
+		s.GetExtents()
+	})
+	if err = s.IssueFlushRequest(); err != nil {
+		return
+	}
+	if filesize, _ := s.extents.Size(); filesize > bcache.MaxFileSize {
+		return fmt.Errorf("Prewarm: file size(%v) exceeds the block cache limit, ino(%v)", filesize, inode)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		errOnce sync.Once
+		sem     = make(chan struct{}, prewarmConcurrency)
+	)
+	setErr := func(e error) {
+		errOnce.Do(func() { err = e })
+	}
+	for _, ek := range extentsInRanges(s.extents.List(), ranges) {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			setErr(ctx.Err())
+			break
+		}
+		wg.Add(1)
+		go func(ek *proto.ExtentKey) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if e := client.prewarmExtent(s, ek); e != nil {
+				log.LogWarnf("Prewarm: ino(%v) ek(%v) err(%v)", inode, ek, e)
+				setErr(e)
+			}
+		}(ek)
+	}
+	wg.Wait()
+	return
+}
+
+func (client *ExtentClient) prewarmExtent(s *Streamer, ek *proto.ExtentKey) (err error) {
+	cacheKey := util.GenerateKey(client.volumeName, s.inode, ek.FileOffset)
+	if _, loaded := client.inflightL1cache.LoadOrStore(cacheKey, true); loaded {
+		return
+	}
+	defer client.inflightL1cache.Delete(cacheKey)
+
+	// limit big block cache
+	if s.exceedBlockSize(ek.Size) {
+		if atomic.LoadInt32(&client.inflightL1BigBlock) > maxInflightL1BigBlock {
+			return
+		}
+		atomic.AddInt32(&client.inflightL1BigBlock, 1)
+		defer atomic.AddInt32(&client.inflightL1BigBlock, -1)
+	}
+
+	reader, err := s.GetExtentReader(ek)
+	if err != nil {
+		return
+	}
+	var data []byte
+	if ek.Size == bcache.MaxBlockSize {
+		data = buf.BCachePool.Get()
+		defer buf.BCachePool.Put(data)
+	} else {
+		data = make([]byte, ek.Size)
+	}
+	req := NewExtentRequest(int(ek.FileOffset), int(ek.Size), data, ek)
+	readBytes, err := reader.Read(req)
+	if err != nil {
+		return
+	}
+	if readBytes != len(data) {
+		return fmt.Errorf("prewarmExtent: read %v bytes of the extent, expect %v", readBytes, len(data))
+	}
+	if err = client.cacheBcache(cacheKey, data); err != nil {
+		client.SetBcacheHealth(false)
+	}
+	return
+}
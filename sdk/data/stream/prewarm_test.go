@@ -0,0 +1,212 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/cubefs/cubefs/blockcache/bcache"
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util"
+)
+
+func TestExtentsInRanges(t *testing.T) {
+	eks := []*proto.ExtentKey{
+		{FileOffset: 0, Size: 100},
+		{FileOffset: 100, Size: 100},
+		{FileOffset: 200, Size: 100},
+		{FileOffset: 400, Size: 100},
+	}
+	cases := []struct {
+		ranges []ExtentRange
+		expect []*proto.ExtentKey
+	}{
+		{nil, nil},
+		{[]ExtentRange{{Offset: 0, Size: 0}}, nil},
+		{[]ExtentRange{{Offset: 0, Size: 1}}, eks[:1]},
+		{[]ExtentRange{{Offset: 99, Size: 2}}, eks[:2]},
+		{[]ExtentRange{{Offset: 100, Size: 100}}, eks[1:2]},
+		// the hole between the extents
+		{[]ExtentRange{{Offset: 300, Size: 100}}, nil},
+		// the overlapping ranges select an extent once
+		{[]ExtentRange{{Offset: 250, Size: 200}, {Offset: 210, Size: 10}}, eks[2:]},
+		{[]ExtentRange{{Offset: 0, Size: 1000}}, eks},
+	}
+	for i, c := range cases {
+		if got := extentsInRanges(eks, c.ranges); !reflect.DeepEqual(got, c.expect) {
+			t.Fatalf("case %v: got %v, expect %v", i, got, c.expect)
+		}
+	}
+}
+
+func TestPrewarmWithoutBcache(t *testing.T) {
+	client := &ExtentClient{streamers: make(map[uint64]*Streamer)}
+	if err := client.Prewarm(context.Background(), 1, []ExtentRange{{Offset: 0, Size: 1}}); err == nil {
+		t.Fatal("prewarm without the block cache should fail")
+	}
+}
+
+// prewarmCache records the blocks written to the block cache.
+type prewarmCache struct {
+	sync.Mutex
+	blocks map[string][]byte
+}
+
+func (c *prewarmCache) put(key string, buf []byte) error {
+	c.Lock()
+	defer c.Unlock()
+	c.blocks[key] = append([]byte(nil), buf...)
+	return nil
+}
+
+func (c *prewarmCache) get(key string) ([]byte, bool) {
+	c.Lock()
+	defer c.Unlock()
+	data, ok := c.blocks[key]
+	return data, ok
+}
+
+// newPrewarmTestClient returns a client caching into cache with an opened streamer of inode, whose extents are eks
+// and read from store, and whose flushes always succeed.
+func newPrewarmTestClient(t *testing.T, inode uint64, eks []proto.ExtentKey, store map[uint64][]byte) (*ExtentClient, *prewarmCache) {
+	if proto.Buffers == nil {
+		proto.InitBufferPool(int64(32768))
+	}
+	addr := startExtentReplica(t, store)
+	dp := &proto.DataPartitionResponse{PartitionID: 1, Hosts: []string{addr}, LeaderAddr: addr}
+
+	cache := &prewarmCache{blocks: make(map[string][]byte)}
+	s := &Streamer{inode: inode, request: make(chan interface{}, 64), isOpen: true, extents: NewExtentCache(inode)}
+	s.once.Do(func() {})
+	for i := range eks {
+		s.extents.Append(&eks[i], true)
+	}
+	client := &ExtentClient{
+		streamers:    map[uint64]*Streamer{inode: s},
+		dataWrapper:  newTestWrapper(t, "vol", dp),
+		volumeName:   "vol",
+		bcacheEnable: true,
+		bcacheHealth: 1,
+		cacheBcache:  cache.put,
+	}
+	s.client = client
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+	go func() {
+		for {
+			select {
+			case req := <-s.request:
+				request := req.(*FlushRequest)
+				request.err = nil
+				request.done <- struct{}{}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return client, cache
+}
+
+func TestPrewarm(t *testing.T) {
+	const inode = 1
+	data1 := bytes.Repeat([]byte("a"), 4096)
+	data2 := bytes.Repeat([]byte("b"), 2048)
+	data3 := bytes.Repeat([]byte("c"), 1024)
+	eks := []proto.ExtentKey{
+		{FileOffset: 0, PartitionId: 1, ExtentId: 1025, Size: 4096},
+		{FileOffset: 4096, PartitionId: 1, ExtentId: 1026, Size: 2048},
+		{FileOffset: 6144, PartitionId: 1, ExtentId: 1027, Size: 1024},
+	}
+	client, cache := newPrewarmTestClient(t, inode, eks, map[uint64][]byte{1025: data1, 1026: data2, 1027: data3})
+	key := func(ek proto.ExtentKey) string {
+		return util.GenerateKey("vol", inode, ek.FileOffset)
+	}
+
+	// the extent being cached by a read is skipped
+	client.inflightL1cache.Store(key(eks[1]), true)
+	if err := client.Prewarm(context.Background(), inode, []ExtentRange{{Offset: 0, Size: 8192}}); err != nil {
+		t.Fatalf("Prewarm: %v", err)
+	}
+	if got, ok := cache.get(key(eks[0])); !ok || !bytes.Equal(got, data1) {
+		t.Fatalf("the first extent should be cached, cached %v", ok)
+	}
+	if _, ok := cache.get(key(eks[1])); ok {
+		t.Fatal("the extent being cached should be skipped")
+	}
+	if got, ok := cache.get(key(eks[2])); !ok || !bytes.Equal(got, data3) {
+		t.Fatalf("the third extent should be cached, cached %v", ok)
+	}
+	if _, ok := client.inflightL1cache.Load(key(eks[0])); ok {
+		t.Fatal("the cached extent should not be left in flight")
+	}
+
+	// the extent is cached once the read finished
+	client.inflightL1cache.Delete(key(eks[1]))
+	if err := client.Prewarm(context.Background(), inode, []ExtentRange{{Offset: 4096, Size: 1}}); err != nil {
+		t.Fatalf("Prewarm: %v", err)
+	}
+	if got, ok := cache.get(key(eks[1])); !ok || !bytes.Equal(got, data2) {
+		t.Fatalf("the second extent should be cached, cached %v", ok)
+	}
+}
+
+func TestPrewarmCanceled(t *testing.T) {
+	eks := []proto.ExtentKey{{FileOffset: 0, PartitionId: 1, ExtentId: 1025, Size: 4096}}
+	client, cache := newPrewarmTestClient(t, 1, eks, map[uint64][]byte{1025: make([]byte, 4096)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.Prewarm(ctx, 1, []ExtentRange{{Offset: 0, Size: 4096}}); err != context.Canceled {
+		t.Fatalf("expect a canceled prewarm to fail with %v, got %v", context.Canceled, err)
+	}
+	if len(cache.blocks) != 0 {
+		t.Fatalf("a canceled prewarm should cache nothing, cached %v blocks", len(cache.blocks))
+	}
+}
+
+func TestPrewarmLimits(t *testing.T) {
+	big := bytes.Repeat([]byte("big "), (bcache.BigExtentSize+4)/4)
+	eks := []proto.ExtentKey{{FileOffset: 0, PartitionId: 1, ExtentId: 1025, Size: uint32(len(big))}}
+	client, cache := newPrewarmTestClient(t, 1, eks, map[uint64][]byte{1025: big})
+
+	client.inflightL1BigBlock = maxInflightL1BigBlock + 1
+	if err := client.Prewarm(context.Background(), 1, []ExtentRange{{Offset: 0, Size: 1}}); err != nil {
+		t.Fatalf("the big extent should be skipped once too many are being cached, got %v", err)
+	}
+	if client.inflightL1BigBlock != maxInflightL1BigBlock+1 || len(cache.blocks) != 0 {
+		t.Fatalf("the skipped big extent should not be cached, in flight %v cached %v",
+			client.inflightL1BigBlock, len(cache.blocks))
+	}
+
+	client.inflightL1BigBlock = 0
+	if err := client.Prewarm(context.Background(), 1, []ExtentRange{{Offset: 0, Size: 1}}); err != nil {
+		t.Fatalf("Prewarm: %v", err)
+	}
+	if got, ok := cache.get(util.GenerateKey("vol", 1, 0)); !ok || !bytes.Equal(got, big) {
+		t.Fatalf("the big extent should be cached while few are being cached, cached %v", ok)
+	}
+	if client.inflightL1BigBlock != 0 {
+		t.Fatalf("the big extent should not be left in flight, got %v", client.inflightL1BigBlock)
+	}
+
+	client.GetStreamer(1).extents.SetSize(bcache.MaxFileSize+1, true)
+	if err := client.Prewarm(context.Background(), 1, []ExtentRange{{Offset: 0, Size: 1}}); err == nil {
+		t.Fatal("a file bigger than the block cache limit should not be prewarmed")
+	}
+}
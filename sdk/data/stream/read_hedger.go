@@ -0,0 +1,191 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cubefs/cubefs/util/errors"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const defaultHedgeReadMaxPercent = 10
+
+var errReadCanceled = errors.New("read canceled")
+
+// readCancel aborts a read leg, the connection in use is closed so that the blocking read returns at once.
+type readCancel struct {
+	mu       sync.Mutex
+	canceled bool
+	conn     *net.TCPConn
+	detached chan struct{} // closed once the leg stops using the attached connection
+	done     chan struct{}
+}
+
+func newReadCancel() *readCancel {
+	return &readCancel{done: make(chan struct{})}
+}
+
+// attach binds the connection to be closed on cancel, it returns false if the leg is already canceled.
+func (c *readCancel) attach(conn *net.TCPConn) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.canceled {
+		return false
+	}
+	c.conn = conn
+	c.detached = make(chan struct{})
+	return true
+}
+
+// detach unbinds the connection, it returns true if the leg is canceled meanwhile, the connection is closed then
+// and must not be put back to the pool.
+func (c *readCancel) detach() (canceled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn = nil
+	if c.detached != nil {
+		close(c.detached)
+		c.detached = nil
+	}
+	return c.canceled
+}
+
+func (c *readCancel) cancel() {
+	c.cancelWith(false)
+}
+
+// cancelAndWait cancels the leg and waits for it to stop using the attached connection,
+// so that it no longer writes to its buffer.
+func (c *readCancel) cancelAndWait() {
+	c.cancelWith(true)
+}
+
+func (c *readCancel) cancelWith(wait bool) {
+	c.mu.Lock()
+	detached := c.detached
+	if !c.canceled {
+		c.canceled = true
+		close(c.done)
+		if c.conn != nil {
+			c.conn.Close()
+		}
+	}
+	c.mu.Unlock()
+	if wait && detached != nil {
+		<-detached
+	}
+}
+
+func (c *readCancel) isCanceled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.canceled
+}
+
+type readLeg func(c *readCancel) (readBytes int, err error)
+
+type legResult struct {
+	readBytes int
+	err       error
+	backup    bool
+}
+
+// readHedger sends a backup read to another replica if the first replica does not respond within the delay,
+// the first successful response wins and the other read is canceled.
+// The backup reads are bounded by maxPercent of all the reads, so the load on the replicas is not doubled.
+type readHedger struct {
+	delay      time.Duration
+	maxPercent uint64
+	reads      uint64
+	hedges     uint64
+	wins       uint64
+}
+
+func newReadHedger(delay time.Duration, maxPercent int64) *readHedger {
+	if maxPercent <= 0 || maxPercent > 100 {
+		maxPercent = defaultHedgeReadMaxPercent
+	}
+	return &readHedger{
+		delay:      delay,
+		maxPercent: uint64(maxPercent),
+	}
+}
+
+// allow reserves a backup read if the hedge rate is under the bound.
+func (h *readHedger) allow() bool {
+	reads := atomic.LoadUint64(&h.reads)
+	for {
+		hedges := atomic.LoadUint64(&h.hedges)
+		if hedges*100 >= reads*h.maxPercent {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&h.hedges, hedges, hedges+1) {
+			return true
+		}
+	}
+}
+
+// do runs the primary read, and the backup read as well if the primary one is slow.
+// The backup leg must read into its own buffer, since it may still be running when do returns. The primary leg
+// is waited for once it loses, so it may read into the destination.
+func (h *readHedger) do(primary, backup readLeg) (readBytes int, err error, byBackup bool) {
+	atomic.AddUint64(&h.reads, 1)
+	results := make(chan legResult, 2)
+	primaryCancel, backupCancel := newReadCancel(), newReadCancel()
+	go func() {
+		n, e := primary(primaryCancel)
+		results <- legResult{readBytes: n, err: e}
+	}()
+
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+	select {
+	case r := <-results:
+		return r.readBytes, r.err, false
+	case <-timer.C:
+	}
+	if !h.allow() {
+		r := <-results
+		return r.readBytes, r.err, false
+	}
+
+	go func() {
+		n, e := backup(backupCancel)
+		results <- legResult{readBytes: n, err: e, backup: true}
+	}()
+	r := <-results
+	if r.err != nil {
+		log.LogWarnf("readHedger: backup(%v) read failed, wait for the other one, err(%v)", r.backup, r.err)
+		r = <-results
+	}
+	if r.backup {
+		primaryCancel.cancelAndWait()
+		if r.err == nil {
+			atomic.AddUint64(&h.wins, 1)
+		}
+	} else {
+		backupCancel.cancel()
+	}
+	return r.readBytes, r.err, r.backup
+}
+
+// stat returns the number of reads, backup reads and backup reads which win.
+func (h *readHedger) stat() (reads, hedges, wins uint64) {
+	return atomic.LoadUint64(&h.reads), atomic.LoadUint64(&h.hedges), atomic.LoadUint64(&h.wins)
+}
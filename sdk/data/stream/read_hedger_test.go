@@ -0,0 +1,222 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/wrapper"
+)
+
+// startFakeReplica serves the reads with the data after the delay.
+func startFakeReplica(t *testing.T, data []byte, delay time.Duration) string {
+	return startRecordingReplica(t, data, delay, nil)
+}
+
+// startRecordingReplica serves the reads like startFakeReplica, and passes the requests to served if it is not nil.
+func startRecordingReplica(t *testing.T, data []byte, delay time.Duration, served func(req *Packet)) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				for {
+					req := new(Packet)
+					if err := req.readFromConn(conn, proto.NoReadDeadlineTime); err != nil {
+						return
+					}
+					if served != nil {
+						served(req)
+					}
+					time.Sleep(delay)
+					reply := NewReply(req.ReqID, req.PartitionID, req.ExtentID)
+					reply.ResultCode = proto.OpOk
+					reply.ExtentOffset = req.ExtentOffset
+					reply.Size = req.Size
+					reply.Data = data[req.ExtentOffset : req.ExtentOffset+int64(req.Size)]
+					if err := reply.writeToConn(conn); err != nil {
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestReadHedger(t *testing.T) {
+	const (
+		delay = 20 * time.Millisecond
+		slow  = 2 * time.Second
+	)
+	if proto.Buffers == nil {
+		proto.InitBufferPool(int64(32768))
+	}
+	data := bytes.Repeat([]byte("hedged read "), 1024)
+	slowAddr := startFakeReplica(t, data, slow)
+	var (
+		lock      sync.Mutex
+		backupOps []uint8
+	)
+	fastAddr := startRecordingReplica(t, data, 0, func(req *Packet) {
+		lock.Lock()
+		backupOps = append(backupOps, req.Opcode)
+		lock.Unlock()
+	})
+
+	w := &wrapper.Wrapper{HostsStatus: map[string]bool{slowAddr: true, fastAddr: true}}
+	dp := &wrapper.DataPartition{ClientWrapper: w}
+	dp.PartitionID = 1
+	dp.Hosts = []string{slowAddr, fastAddr}
+	dp.LeaderAddr = slowAddr
+	key := &proto.ExtentKey{PartitionId: 1, ExtentId: 1025, Size: uint32(len(data))}
+	hedger := newReadHedger(delay, 50)
+	reader := NewExtentReader(1, key, dp, true, true)
+	reader.hedger = hedger
+
+	readFromSlow := func() time.Duration {
+		// the follower read picks the replica by the epoch, let it choose the slow one
+		dp.Epoch = 1
+		req := NewExtentRequest(0, len(data), make([]byte, len(data)), key)
+		start := time.Now()
+		n, err := reader.Read(req)
+		elapsed := time.Since(start)
+		if err != nil || n != len(data) || !bytes.Equal(req.Data, data) {
+			t.Fatalf("read: n(%v) err(%v) equal(%v)", n, err, bytes.Equal(req.Data, data))
+		}
+		return elapsed
+	}
+
+	// the backup read to the fast replica wins
+	if elapsed := readFromSlow(); elapsed >= slow/2 {
+		t.Fatalf("hedged read takes %v, the slow replica responds in %v", elapsed, slow)
+	}
+	if reads, hedges, wins := hedger.stat(); reads != 1 || hedges != 1 || wins != 1 {
+		t.Fatalf("reads(%v) hedges(%v) wins(%v)", reads, hedges, wins)
+	}
+	// the backup read is a follower read, which leaves the leader as it is
+	lock.Lock()
+	if len(backupOps) != 1 || backupOps[0] != proto.OpStreamFollowerRead {
+		t.Fatalf("backup opcodes %v", backupOps)
+	}
+	lock.Unlock()
+	if dp.LeaderAddr != slowAddr {
+		t.Fatalf("leader changed to %v", dp.LeaderAddr)
+	}
+
+	// the backup reads are bounded by the percentage, so the next read waits for the slow replica
+	if elapsed := readFromSlow(); elapsed < slow {
+		t.Fatalf("read takes %v, expect no hedge", elapsed)
+	}
+	if reads, hedges, _ := hedger.stat(); reads != 2 || hedges != 1 {
+		t.Fatalf("reads(%v) hedges(%v)", reads, hedges)
+	}
+	if elapsed := readFromSlow(); elapsed >= slow/2 {
+		t.Fatalf("hedged read takes %v", elapsed)
+	}
+
+	// the loser is canceled, and the fast primary read does not hedge
+	hedger = newReadHedger(delay, 100)
+	primaryCanceled := make(chan struct{})
+	_, err, byBackup := hedger.do(
+		func(c *readCancel) (int, error) {
+			select {
+			case <-c.done:
+				close(primaryCanceled)
+				return 0, errReadCanceled
+			case <-time.After(slow):
+				return 1, nil
+			}
+		},
+		func(c *readCancel) (int, error) {
+			return 2, nil
+		})
+	if err != nil || !byBackup {
+		t.Fatalf("err(%v) byBackup(%v)", err, byBackup)
+	}
+	select {
+	case <-primaryCanceled:
+	case <-time.After(slow / 2):
+		t.Fatal("the primary read is not canceled")
+	}
+	_, hedges, _ := hedger.stat()
+	n, err, byBackup := hedger.do(
+		func(c *readCancel) (int, error) { return 1, nil },
+		func(c *readCancel) (int, error) { return 2, nil })
+	if n != 1 || err != nil || byBackup {
+		t.Fatalf("n(%v) err(%v) byBackup(%v)", n, err, byBackup)
+	}
+	if _, after, _ := hedger.stat(); after != hedges {
+		t.Fatalf("hedges %v -> %v", hedges, after)
+	}
+}
+
+func TestReadCancelWaitsForDetach(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			defer conn.Close()
+			conn.Read(make([]byte, 1))
+		}
+	}()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newReadCancel()
+	if !c.attach(conn.(*net.TCPConn)) {
+		t.Fatal("attach to a leg not canceled")
+	}
+	waited := make(chan struct{})
+	go func() {
+		c.cancelAndWait()
+		close(waited)
+	}()
+	// the connection is closed at once, and the cancel waits for the leg to stop using it
+	if _, err = conn.Read(make([]byte, 1)); err == nil {
+		t.Fatal("read from the connection closed by the cancel")
+	}
+	select {
+	case <-waited:
+		t.Fatal("cancelAndWait returns before the leg detaches")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// the leg is told not to put the connection back to the pool
+	if !c.detach() {
+		t.Fatal("detach from a canceled leg")
+	}
+	<-waited
+	if c.attach(conn.(*net.TCPConn)) {
+		t.Fatal("attach to a canceled leg")
+	}
+}
@@ -44,10 +44,21 @@ type GetReplyFunc func(conn *net.TCPConn) (err error, again bool)
 type StreamConn struct {
 	dp       *wrapper.DataPartition
 	currAddr string
+	follower bool // a follower read fails over to the other hosts without taking them as the leader
 }
 
 var StreamConnPool = util.NewConnectPool()
 
+// getConnPool returns the connection pool of the client wrapper, StreamConnPool if it is not set.
+func getConnPool(w *wrapper.Wrapper) wrapper.ConnPool {
+	if w != nil {
+		if pool := w.ConnPool(); pool != nil {
+			return pool
+		}
+	}
+	return StreamConnPool
+}
+
 // NewStreamConn returns a new stream connection.
 func NewStreamConn(dp *wrapper.DataPartition, follower bool) (sc *StreamConn) {
 	if !follower {
@@ -73,6 +84,17 @@ func NewStreamConn(dp *wrapper.DataPartition, follower bool) (sc *StreamConn) {
 		}
 	}()
 
+	if dp.ClientWrapper.ReadPreference() != wrapper.ReadPreferDefault {
+		sc = &StreamConn{
+			dp:       dp,
+			follower: true,
+		}
+		if hosts := readHosts(dp, atomic.AddUint64(&dp.Epoch, 1)); len(hosts) > 0 {
+			sc.currAddr = hosts[0]
+		}
+		return
+	}
+
 	if dp.ClientWrapper.NearRead() {
 		sc = &StreamConn{
 			dp:       dp,
@@ -97,6 +119,15 @@ func NewStreamConn(dp *wrapper.DataPartition, follower bool) (sc *StreamConn) {
 	return
 }
 
+// newFollowerStreamConn returns the stream connection of a follower read from the addr.
+func newFollowerStreamConn(dp *wrapper.DataPartition, addr string) *StreamConn {
+	return &StreamConn{
+		dp:       dp,
+		currAddr: addr,
+		follower: true,
+	}
+}
+
 // String returns the string format of the stream connection.
 func (sc *StreamConn) String() string {
 	return fmt.Sprintf("Partition(%v) CurrentAddr(%v) Hosts(%v)", sc.dp.PartitionID, sc.currAddr, sc.dp.Hosts)
@@ -107,7 +138,9 @@ func (sc *StreamConn) String() string {
 func (sc *StreamConn) Send(retry *bool, req *Packet, getReply GetReplyFunc) (err error) {
 	for i := 0; i < StreamSendMaxRetry; i++ {
 		err = sc.sendToDataPartition(req, retry, getReply)
-		if err == nil || err == proto.ErrCodeVersionOp || !*retry || err == TryOtherAddrError || strings.Contains(err.Error(), "OpForbidErr") {
+		if err == nil || err == proto.ErrCodeVersionOp || !*retry || err == TryOtherAddrError || err == errReadCanceled ||
+			strings.Contains(err.Error(), "OpForbidErr") || strings.Contains(err.Error(), "NotPerm") {
+			// the partition is forbidden or set readonly by hand, retrying does not help
 			return
 		}
 		log.LogWarnf("StreamConn Send: err(%v)", err)
@@ -117,16 +150,17 @@ func (sc *StreamConn) Send(retry *bool, req *Packet, getReply GetReplyFunc) (err
 }
 
 func (sc *StreamConn) sendToDataPartition(req *Packet, retry *bool, getReply GetReplyFunc) (err error) {
-	conn, err := StreamConnPool.GetConnect(sc.currAddr)
+	connPool := getConnPool(sc.dp.ClientWrapper)
+	conn, err := connPool.GetConnect(sc.currAddr)
 	if err == nil {
 		log.LogDebugf("req opcode %v, conn %v", req.Opcode, conn)
 		err = sc.sendToConn(conn, req, getReply)
 		if err == nil {
-			StreamConnPool.PutConnect(conn, false)
+			connPool.PutConnect(conn, false)
 			return
 		}
 		log.LogWarnf("sendToDataPartition: send to curr addr failed, addr(%v) reqPacket(%v) err(%v)", sc.currAddr, req, err)
-		StreamConnPool.PutConnect(conn, true)
+		connPool.PutConnect(conn, true)
 		if err != TryOtherAddrError || !*retry {
 			return
 		}
@@ -134,23 +168,31 @@ func (sc *StreamConn) sendToDataPartition(req *Packet, retry *bool, getReply Get
 		log.LogWarnf("sendToDataPartition: get connection to curr addr failed, addr(%v) reqPacket(%v) err(%v)", sc.currAddr, req, err)
 	}
 
-	hosts := sortByStatus(sc.dp, true)
+	var hosts []string
+	preferred := sc.follower && sc.dp.ClientWrapper.ReadPreference() != wrapper.ReadPreferDefault
+	if preferred {
+		hosts = failoverHosts(readHosts(sc.dp, atomic.LoadUint64(&sc.dp.Epoch)), sc.currAddr)
+	} else {
+		hosts = sortByStatus(sc.dp, true)
+	}
 
 	for _, addr := range hosts {
 		log.LogWarnf("sendToDataPartition: try addr(%v) reqPacket(%v)", addr, req)
-		conn, err = StreamConnPool.GetConnect(addr)
+		conn, err = connPool.GetConnect(addr)
 		if err != nil {
 			log.LogWarnf("sendToDataPartition: failed to get connection to addr(%v) reqPacket(%v) err(%v)", addr, req, err)
 			continue
 		}
 		sc.currAddr = addr
-		sc.dp.LeaderAddr = addr
+		if !sc.follower {
+			sc.dp.LeaderAddr = addr
+		}
 		err = sc.sendToConn(conn, req, getReply)
 		if err == nil {
-			StreamConnPool.PutConnect(conn, false)
+			connPool.PutConnect(conn, false)
 			return
 		}
-		StreamConnPool.PutConnect(conn, true)
+		connPool.PutConnect(conn, true)
 		if err != TryOtherAddrError {
 			return
 		}
@@ -190,8 +232,6 @@ func (sc *StreamConn) sendToConn(conn *net.TCPConn, req *Packet, getReply GetRep
 // If param selectAll is true, hosts with status(true) is in front and hosts with status(false) is in behind.
 // If param selectAll is false, only return hosts with status(true).
 func sortByStatus(dp *wrapper.DataPartition, selectAll bool) (hosts []string) {
-	var failedHosts []string
-	hostsStatus := dp.ClientWrapper.HostsStatus
 	var dpHosts []string
 	if dp.ClientWrapper.FollowerRead() && dp.ClientWrapper.NearRead() {
 		dpHosts = dp.NearHosts
@@ -202,6 +242,14 @@ func sortByStatus(dp *wrapper.DataPartition, selectAll bool) (hosts []string) {
 		dpHosts = dp.Hosts
 	}
 
+	return partitionByStatus(dp, dpHosts, selectAll)
+}
+
+// partitionByStatus returns the available hosts in front of the others, keeping the order of each part.
+// If param selectAll is false, only the available hosts are returned.
+func partitionByStatus(dp *wrapper.DataPartition, dpHosts []string, selectAll bool) (hosts []string) {
+	var failedHosts []string
+	hostsStatus := dp.ClientWrapper.HostsStatus
 	for _, addr := range dpHosts {
 		status, ok := hostsStatus[addr]
 		if ok {
@@ -212,7 +260,7 @@ func sortByStatus(dp *wrapper.DataPartition, selectAll bool) (hosts []string) {
 			}
 		} else {
 			failedHosts = append(failedHosts, addr)
-			log.LogWarnf("sortByStatus: can not find host[%v] in HostsStatus, dp[%d]", addr, dp.PartitionID)
+			log.LogWarnf("partitionByStatus: can not find host[%v] in HostsStatus, dp[%d]", addr, dp.PartitionID)
 		}
 	}
 
@@ -223,6 +271,62 @@ func sortByStatus(dp *wrapper.DataPartition, selectAll bool) (hosts []string) {
 	return
 }
 
+// readHosts returns the hosts of the data partition in the order of the read preference of the client,
+// the available ones first. The epoch rotates the followers for the round robin policy.
+func readHosts(dp *wrapper.DataPartition, epoch uint64) []string {
+	return partitionByStatus(dp, preferredHosts(dp, epoch), true)
+}
+
+// preferredHosts returns the hosts of the data partition in the order of the read preference of the client.
+func preferredHosts(dp *wrapper.DataPartition, epoch uint64) []string {
+	var ordered []string
+	switch dp.ClientWrapper.ReadPreference() {
+	case wrapper.ReadPreferLeaderFirst:
+		ordered = append(ordered, dp.LeaderAddr)
+		for _, addr := range dp.Hosts {
+			if addr != dp.LeaderAddr {
+				ordered = append(ordered, addr)
+			}
+		}
+	case wrapper.ReadPreferNearestFirst:
+		ordered = dp.NearHosts
+		if len(ordered) == 0 {
+			ordered = dp.Hosts
+		}
+	case wrapper.ReadPreferFollowerRoundRobin:
+		var followers []string
+		for _, addr := range dp.Hosts {
+			if addr != dp.LeaderAddr {
+				followers = append(followers, addr)
+			}
+		}
+		for i := range followers {
+			ordered = append(ordered, followers[(int(epoch%uint64(len(followers)))+i)%len(followers)])
+		}
+		ordered = append(ordered, dp.LeaderAddr)
+	default:
+		ordered = dp.Hosts
+	}
+	return ordered
+}
+
+// failoverHosts moves the failed host to the end of the hosts.
+func failoverHosts(hosts []string, failed string) []string {
+	ret := make([]string, 0, len(hosts))
+	found := false
+	for _, addr := range hosts {
+		if addr == failed {
+			found = true
+			continue
+		}
+		ret = append(ret, addr)
+	}
+	if found {
+		ret = append(ret, failed)
+	}
+	return ret
+}
+
 func getNearestHost(dp *wrapper.DataPartition) string {
 	hostsStatus := dp.ClientWrapper.HostsStatus
 	for _, addr := range dp.NearHosts {
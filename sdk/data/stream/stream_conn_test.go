@@ -0,0 +1,290 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/wrapper"
+	"github.com/cubefs/cubefs/util"
+)
+
+// fakeConnPool refuses the connections to the down hosts, and records the connections got and put.
+type fakeConnPool struct {
+	sync.Mutex
+	down  map[string]bool
+	gets  []string
+	puts  int
+	fails int
+}
+
+func (p *fakeConnPool) GetConnect(addr string) (*net.TCPConn, error) {
+	p.Lock()
+	defer p.Unlock()
+	p.gets = append(p.gets, addr)
+	if p.down[addr] {
+		return nil, errors.New("host is down")
+	}
+	return util.DailTimeOut(addr, time.Second)
+}
+
+func (p *fakeConnPool) PutConnect(c *net.TCPConn, forceClose bool) {
+	p.Lock()
+	defer p.Unlock()
+	p.puts++
+	if forceClose {
+		p.fails++
+	}
+	c.Close()
+}
+
+func TestStreamConnWithConnPool(t *testing.T) {
+	if proto.Buffers == nil {
+		proto.InitBufferPool(int64(32768))
+	}
+	data := bytes.Repeat([]byte("conn pool "), 100)
+	addr := startFakeReplica(t, data, 0)
+	const downAddr = "127.0.0.1:1"
+	pool := &fakeConnPool{down: map[string]bool{downAddr: true}}
+
+	w := &wrapper.Wrapper{HostsStatus: map[string]bool{downAddr: true, addr: true}}
+	w.SetConnPool(pool)
+	dp := &wrapper.DataPartition{ClientWrapper: w}
+	dp.PartitionID = 1
+	dp.Hosts = []string{downAddr, addr}
+	dp.LeaderAddr = downAddr
+
+	key := &proto.ExtentKey{PartitionId: 1, ExtentId: 1025, Size: uint32(len(data))}
+	req := NewReadPacket(key, 0, len(data), 0, 0, false)
+	var reply *Packet
+	retry := true
+	err := NewStreamConn(dp, false).Send(&retry, req, func(conn *net.TCPConn) (error, bool) {
+		reply = new(Packet)
+		return reply.readFromConn(conn, proto.ReadDeadlineTime), false
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if reply.ResultCode != proto.OpOk || reply.ReqID != req.ReqID {
+		t.Fatalf("reply(%v) req(%v)", reply, req)
+	}
+	// the leader is down, the request is retried on the other host through the injected pool
+	if len(pool.gets) != 3 || pool.gets[0] != downAddr || pool.gets[2] != addr {
+		t.Fatalf("gets(%v)", pool.gets)
+	}
+	if pool.puts != 1 || pool.fails != 0 {
+		t.Fatalf("puts(%v) fails(%v)", pool.puts, pool.fails)
+	}
+	if dp.LeaderAddr != addr {
+		t.Fatalf("leader(%v)", dp.LeaderAddr)
+	}
+
+	// without the injected pool the default one is used
+	if getConnPool(&wrapper.Wrapper{}) != StreamConnPool || getConnPool(nil) != StreamConnPool {
+		t.Fatalf("default pool is not StreamConnPool")
+	}
+}
+
+func TestStreamConnNotPermNoRetry(t *testing.T) {
+	if proto.Buffers == nil {
+		proto.InitBufferPool(int64(32768))
+	}
+	data := bytes.Repeat([]byte("not perm "), 100)
+	addr := startFakeReplica(t, data, 0)
+	w := &wrapper.Wrapper{HostsStatus: map[string]bool{addr: true}}
+	w.SetConnPool(&fakeConnPool{})
+	dp := &wrapper.DataPartition{ClientWrapper: w}
+	dp.PartitionID = 1
+	dp.Hosts = []string{addr}
+	dp.LeaderAddr = addr
+
+	key := &proto.ExtentKey{PartitionId: 1, ExtentId: 1025, Size: uint32(len(data))}
+	req := NewReadPacket(key, 0, len(data), 0, 0, false)
+	calls := 0
+	retry := true
+	// the partition set readonly replies OpNotPerm, which is returned at once rather than retried
+	err := NewStreamConn(dp, false).Send(&retry, req, func(conn *net.TCPConn) (error, bool) {
+		calls++
+		reply := new(Packet)
+		if err := reply.readFromConn(conn, proto.ReadDeadlineTime); err != nil {
+			return err, false
+		}
+		reply.ResultCode = proto.OpNotPerm
+		return errors.New("checkStreamReply: ResultCode(" + reply.GetResultMsg() + ") NOK"), false
+	})
+	if err == nil || calls != 1 {
+		t.Fatalf("err(%v) calls(%v)", err, calls)
+	}
+}
+
+func TestReadPreference(t *testing.T) {
+	const (
+		leader = "10.0.0.1:17310" // the farthest
+		near   = "10.0.0.2:17310" // the nearest
+		middle = "10.0.0.3:17310"
+	)
+	w := &wrapper.Wrapper{HostsStatus: map[string]bool{leader: true, near: true, middle: true}}
+	dp := &wrapper.DataPartition{ClientWrapper: w}
+	dp.PartitionID = 1
+	dp.Hosts = []string{leader, near, middle}
+	dp.LeaderAddr = leader
+	dp.NearHosts = []string{near, middle, leader}
+
+	if err := w.SetReadPreference("random"); err == nil {
+		t.Fatalf("unknown read preference is accepted")
+	}
+
+	cases := []struct {
+		policy string
+		epoch  uint64
+		hosts  []string
+	}{
+		{wrapper.ReadPreferDefault, 0, []string{leader, near, middle}},
+		{wrapper.ReadPreferLeaderFirst, 0, []string{leader, near, middle}},
+		{wrapper.ReadPreferNearestFirst, 0, []string{near, middle, leader}},
+		{wrapper.ReadPreferFollowerRoundRobin, 0, []string{near, middle, leader}},
+		{wrapper.ReadPreferFollowerRoundRobin, 1, []string{middle, near, leader}},
+	}
+	for _, c := range cases {
+		if err := w.SetReadPreference(c.policy); err != nil {
+			t.Fatalf("policy(%v): %v", c.policy, err)
+		}
+		if hosts := readHosts(dp, c.epoch); !reflect.DeepEqual(hosts, c.hosts) {
+			t.Errorf("policy(%v) epoch(%v): expect %v, got %v", c.policy, c.epoch, c.hosts, hosts)
+		}
+	}
+
+	// the round robin policy spreads the reads over the followers
+	w.SetReadPreference(wrapper.ReadPreferFollowerRoundRobin)
+	first, second := NewStreamConn(dp, true).currAddr, NewStreamConn(dp, true).currAddr
+	if first == second || first == leader || second == leader {
+		t.Errorf("round robin picks %v and %v", first, second)
+	}
+
+	// the backup read of a hedged read follows the read preference as well
+	reader := NewExtentReader(1, &proto.ExtentKey{PartitionId: 1, ExtentId: 1025}, dp, true, true)
+	for _, c := range []struct {
+		policy          string
+		primary, backup string
+	}{
+		{wrapper.ReadPreferDefault, leader, near},
+		{wrapper.ReadPreferLeaderFirst, leader, near},
+		{wrapper.ReadPreferNearestFirst, near, middle},
+		{wrapper.ReadPreferFollowerRoundRobin, near, middle},
+	} {
+		w.SetReadPreference(c.policy)
+		dp.Epoch = 0
+		if backup := reader.backupAddr(c.primary); backup != c.backup {
+			t.Errorf("policy(%v): expect backup %v, got %v", c.policy, c.backup, backup)
+		}
+	}
+
+	// the unavailable hosts are tried at last
+	w.SetReadPreference(wrapper.ReadPreferNearestFirst)
+	w.HostsStatus[near] = false
+	if hosts := readHosts(dp, 0); !reflect.DeepEqual(hosts, []string{middle, leader, near}) {
+		t.Errorf("unexpected hosts %v", hosts)
+	}
+	if sc := NewStreamConn(dp, true); sc.currAddr != middle {
+		t.Errorf("expect %v, got %v", middle, sc.currAddr)
+	}
+	if backup := reader.backupAddr(middle); backup != leader {
+		t.Errorf("expect backup %v, got %v", leader, backup)
+	}
+}
+
+func TestReadPreferenceFailover(t *testing.T) {
+	if proto.Buffers == nil {
+		proto.InitBufferPool(int64(32768))
+	}
+	data := bytes.Repeat([]byte("failover "), 100)
+	far := startFakeReplica(t, data, 0)
+	const (
+		nearest = "127.0.0.1:1"
+		middle  = "127.0.0.1:2"
+	)
+	pool := &fakeConnPool{down: map[string]bool{nearest: true, middle: true}}
+	w := &wrapper.Wrapper{HostsStatus: map[string]bool{nearest: true, middle: true, far: true}}
+	w.SetConnPool(pool)
+	w.SetReadPreference(wrapper.ReadPreferNearestFirst)
+	dp := &wrapper.DataPartition{ClientWrapper: w}
+	dp.PartitionID = 1
+	dp.Hosts = []string{far, middle, nearest}
+	dp.LeaderAddr = far
+	dp.NearHosts = []string{nearest, middle, far}
+
+	key := &proto.ExtentKey{PartitionId: 1, ExtentId: 1025, Size: uint32(len(data))}
+	req := NewReadPacket(key, 0, len(data), 0, 0, true)
+	var reply *Packet
+	retry := true
+	err := NewStreamConn(dp, true).Send(&retry, req, func(conn *net.TCPConn) (error, bool) {
+		reply = new(Packet)
+		return reply.readFromConn(conn, proto.ReadDeadlineTime), false
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if reply.ResultCode != proto.OpOk {
+		t.Fatalf("reply(%v)", reply)
+	}
+	// the hosts are tried in the order of the distance, and the failed one is not retried first
+	if !reflect.DeepEqual(pool.gets, []string{nearest, middle, far}) {
+		t.Fatalf("gets(%v)", pool.gets)
+	}
+	if dp.LeaderAddr != far {
+		t.Fatalf("leader changed to %v", dp.LeaderAddr)
+	}
+}
+
+func TestFollowerStreamConnFailover(t *testing.T) {
+	if proto.Buffers == nil {
+		proto.InitBufferPool(int64(32768))
+	}
+	data := bytes.Repeat([]byte("follower "), 100)
+	replica := startFakeReplica(t, data, 0)
+	const (
+		leader = "127.0.0.1:1"
+		down   = "127.0.0.1:2"
+	)
+	pool := &fakeConnPool{down: map[string]bool{leader: true, down: true}}
+	w := &wrapper.Wrapper{HostsStatus: map[string]bool{leader: true, down: true, replica: true}}
+	w.SetConnPool(pool)
+	dp := &wrapper.DataPartition{ClientWrapper: w}
+	dp.PartitionID = 1
+	dp.Hosts = []string{leader, down, replica}
+	dp.LeaderAddr = leader
+
+	// a follower read, e.g. the backup one of a hedged read, fails over without taking the replica as the leader
+	key := &proto.ExtentKey{PartitionId: 1, ExtentId: 1025, Size: uint32(len(data))}
+	req := NewReadPacket(key, 0, len(data), 0, 0, true)
+	retry := true
+	sc := newFollowerStreamConn(dp, down)
+	err := sc.Send(&retry, req, func(conn *net.TCPConn) (error, bool) {
+		return new(Packet).readFromConn(conn, proto.ReadDeadlineTime), false
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if sc.currAddr != replica || dp.LeaderAddr != leader {
+		t.Fatalf("currAddr(%v) leader(%v)", sc.currAddr, dp.LeaderAddr)
+	}
+}
@@ -121,6 +121,7 @@ func (s *Streamer) GetExtentReader(ek *proto.ExtentKey) (*ExtentReader, error) {
 	}
 
 	reader := NewExtentReader(s.inode, ek, partition, s.client.dataWrapper.FollowerRead(), retryRead)
+	reader.hedger = s.client.readHedger
 	return reader, nil
 }
 
@@ -157,6 +158,10 @@ func (s *Streamer) read(data []byte, offset int, size int) (total int, err error
 	}
 
 	filesize, _ := s.extents.Size()
+	// the block cache is keyed by the extent keys, so leave them as they are if it is used
+	if s.client.extentMerger != nil && !(s.client.bcacheEnable && s.needBCache) {
+		requests = s.client.extentMerger.merge(offset, data, requests)
+	}
 	log.LogDebugf("read: ino(%v) requests(%v) filesize(%v)", s.inode, requests, filesize)
 	for _, req := range requests {
 		log.LogDebugf("action[streamer.read] req %v", req)
@@ -216,7 +221,7 @@ func (s *Streamer) read(data []byte, offset int, size int) (total int, err error
 
 			if s.client.bcacheEnable && s.needBCache && filesize <= bcache.MaxFileSize {
 				// limit big block cache
-				if s.exceedBlockSize(req.ExtentKey.Size) && atomic.LoadInt32(&s.client.inflightL1BigBlock) > 10 {
+				if s.exceedBlockSize(req.ExtentKey.Size) && atomic.LoadInt32(&s.client.inflightL1BigBlock) > maxInflightL1BigBlock {
 					// do nothing
 				} else {
 					select {
@@ -71,6 +71,7 @@ type WriteRequest struct {
 	err        error
 	done       chan struct{}
 	checkFunc  func() error
+	prev       *WriteRequest // the previous request of the same WriteV, skipped if it fails
 }
 
 // FlushRequest defines a flush request.
@@ -134,16 +135,81 @@ func (s *Streamer) IssueWriteRequest(offset int, data []byte, flags int, checkFu
 	return
 }
 
+// IssueWriteVRequest writes the buffers one after another from offset without concatenating them,
+// the writes of the other callers can't interleave between the buffers. The requests are queued under
+// the lock in order and waited for after it is released, the ones after a failed request are skipped.
+func (s *Streamer) IssueWriteVRequest(ctx context.Context, offset int, bufs [][]byte, flags int, checkFunc func() error) (write int, err error) {
+	if atomic.LoadInt32(&s.status) >= StreamerError {
+		return 0, errors.New(fmt.Sprintf("IssueWriteVRequest: stream writer in error status, ino(%v)", s.inode))
+	}
+
+	var prev *WriteRequest
+	requests := make([]*WriteRequest, 0, len(bufs))
+	fileOffset := offset
+	s.writeLock.Lock()
+	for _, data := range bufs {
+		if len(data) == 0 {
+			continue
+		}
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		request := writeRequestPool.Get().(*WriteRequest)
+		request.data = data
+		request.fileOffset = fileOffset
+		request.size = len(data)
+		request.flags = flags
+		request.done = make(chan struct{}, 1)
+		request.checkFunc = checkFunc
+		request.prev = prev
+		s.request <- request
+		requests = append(requests, request)
+		prev = request
+		fileOffset += len(data)
+	}
+	s.writeLock.Unlock()
+
+	var writeErr error
+	for _, request := range requests {
+		<-request.done
+		if writeErr == nil {
+			write += request.writeBytes
+			writeErr = request.err
+		}
+	}
+	// put back after all done, the handler of a request reads the previous one
+	for _, request := range requests {
+		request.prev = nil
+		writeRequestPool.Put(request)
+	}
+	if writeErr != nil {
+		err = writeErr
+	}
+	return
+}
+
+// isDirty tells whether there are requests waiting for the stream writer or dirty handlers to flush,
+// a pending request is taken as dirty since it may be a write.
+func (s *Streamer) isDirty() bool {
+	return len(s.request) > 0 || s.dirtylist.Len() > 0
+}
+
 func (s *Streamer) IssueFlushRequest() error {
-	request := flushRequestPool.Get().(*FlushRequest)
-	request.done = make(chan struct{}, 1)
-	s.request <- request
+	request := s.issueFlush()
 	<-request.done
 	err := request.err
 	flushRequestPool.Put(request)
 	return err
 }
 
+// issueFlush hands a flush request to the stream writer without waiting for it.
+func (s *Streamer) issueFlush() *FlushRequest {
+	request := flushRequestPool.Get().(*FlushRequest)
+	request.done = make(chan struct{}, 1)
+	s.request <- request
+	return request
+}
+
 func (s *Streamer) IssueReleaseRequest() error {
 	request := releaseRequestPool.Get().(*ReleaseRequest)
 	request.done = make(chan struct{}, 1)
@@ -280,17 +346,30 @@ func (s *Streamer) handleRequest(request interface{}) {
 		s.open()
 		request.done <- struct{}{}
 	case *WriteRequest:
-		request.writeBytes, request.err = s.write(request.data, request.fileOffset, request.size, request.flags, request.checkFunc)
+		if request.prev != nil && request.prev.err != nil {
+			request.writeBytes, request.err = 0, request.prev.err
+		} else {
+			request.writeBytes, request.err = s.write(request.data, request.fileOffset, request.size, request.flags, request.checkFunc)
+		}
 		request.done <- struct{}{}
 	case *TruncRequest:
 		request.err = s.truncate(request.size, request.fullPath)
 		request.done <- struct{}{}
 	case *FlushRequest:
 		request.err = s.flush()
+		flushed := request.err == nil
 		request.done <- struct{}{}
+		// merge after replying so that the flush doesn't wait for it
+		if flushed {
+			s.mergeExtentKeys()
+		}
 	case *ReleaseRequest:
 		request.err = s.release()
+		released := request.err == nil
 		request.done <- struct{}{}
+		if released {
+			s.mergeExtentKeys()
+		}
 	case *EvictRequest:
 		request.err = s.evict()
 		request.done <- struct{}{}
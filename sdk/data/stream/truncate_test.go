@@ -0,0 +1,108 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+)
+
+type fakeSummaryUpdater struct {
+	size     uint64
+	inodeGet int32
+	bytesInc chan int64
+}
+
+func (f *fakeSummaryUpdater) InodeGet_ll(inode uint64) (*proto.InodeInfo, error) {
+	atomic.AddInt32(&f.inodeGet, 1)
+	return &proto.InodeInfo{Inode: inode, Size: f.size}, nil
+}
+
+func (f *fakeSummaryUpdater) UpdateSummary_ll(parentIno uint64, filesInc int64, dirsInc int64, bytesInc int64) {
+	f.bytesInc <- bytesInc
+}
+
+// newTruncateTestClient returns a client with an opened streamer of inode whose truncations always succeed.
+func newTruncateTestClient(inode uint64) (*ExtentClient, func()) {
+	s := &Streamer{inode: inode, request: make(chan interface{}, 64), isOpen: true}
+	s.once.Do(func() {})
+	client := &ExtentClient{streamers: map[uint64]*Streamer{inode: s}}
+	s.client = client
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case req := <-s.request:
+				req.(*TruncRequest).done <- struct{}{}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return client, func() { close(stop) }
+}
+
+func TestTruncateWithHint(t *testing.T) {
+	client, stop := newTruncateTestClient(1)
+	defer stop()
+	mw := &fakeSummaryUpdater{size: 100, bytesInc: make(chan int64, 4)}
+	expectSummary := func(bytesInc int64) {
+		select {
+		case inc := <-mw.bytesInc:
+			if inc != bytesInc {
+				t.Fatalf("expect summary bytes inc %v, got %v", bytesInc, inc)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expect summary update of %v", bytesInc)
+		}
+	}
+
+	// without a hint the old size comes from the metanode
+	if err := client.truncateWithSummary(mw, true, 2, 1, 40, "", nil); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	expectSummary(-60)
+	if n := atomic.LoadInt32(&mw.inodeGet); n != 1 {
+		t.Fatalf("expect 1 InodeGet, got %v", n)
+	}
+
+	// the given old size saves the InodeGet
+	if err := client.truncateWithSummary(mw, true, 2, 1, 10, "", &TruncateHint{OldSize: 40, HasOldSize: true}); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	expectSummary(-30)
+	if n := atomic.LoadInt32(&mw.inodeGet); n != 1 {
+		t.Fatalf("expect InodeGet skipped, got %v calls", n)
+	}
+
+	// no metanode round-trip at all if the summary update is skipped or disabled
+	if err := client.truncateWithSummary(mw, true, 2, 1, 0, "", &TruncateHint{SkipSummary: true}); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	if err := client.truncateWithSummary(mw, false, 2, 1, 0, "", nil); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	select {
+	case inc := <-mw.bytesInc:
+		t.Fatalf("unexpected summary update %v", inc)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if n := atomic.LoadInt32(&mw.inodeGet); n != 1 {
+		t.Fatalf("expect InodeGet skipped, got %v calls", n)
+	}
+}
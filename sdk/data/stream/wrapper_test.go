@@ -0,0 +1,73 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/sdk/data/wrapper"
+)
+
+// testClientInfo is the client of a test wrapper, which never reports the flow.
+type testClientInfo struct{}
+
+func (testClientInfo) GetFlowInfo() (*proto.ClientReportLimitInfo, bool)       { return nil, false }
+func (testClientInfo) UpdateFlowInfo(limit *proto.LimitRsp2Client)             {}
+func (testClientInfo) SetClientID(id uint64) error                             { return nil }
+func (testClientInfo) UpdateLatestVer(verList *proto.VolVersionInfoList) error { return nil }
+func (testClientInfo) GetReadVer() uint64                                      { return 0 }
+func (testClientInfo) GetLatestVer() uint64                                    { return 0 }
+func (testClientInfo) GetVerMgr() *proto.VolVersionInfoList                    { return nil }
+
+// newTestWrapper returns a wrapper of the volume holding the given data partitions, loaded from a fake master
+// on which the hosts of the partitions are all active.
+func newTestWrapper(t *testing.T, volName string, partitions ...*proto.DataPartitionResponse) *wrapper.Wrapper {
+	cluster := &proto.ClusterView{}
+	for _, dp := range partitions {
+		for _, host := range dp.Hosts {
+			cluster.DataNodes = append(cluster.DataNodes, proto.NodeView{Addr: host, IsActive: true})
+		}
+	}
+	replies := map[string]interface{}{
+		proto.AdminGetIP:           &proto.ClusterInfo{Cluster: "test"},
+		proto.AdminGetVol:          &proto.SimpleVolView{Name: volName},
+		proto.ClientDataPartitions: &proto.DataPartitionsView{DataPartitions: partitions},
+		proto.AdminGetCluster:      cluster,
+	}
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := replies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		reply := &proto.HTTPReply{Code: proto.ErrCodeSuccess, Msg: "success", Data: data}
+		if err := json.NewEncoder(w).Encode(reply); err != nil {
+			t.Errorf("encode reply failed: %v", err)
+		}
+	}))
+	t.Cleanup(master.Close)
+
+	w, err := wrapper.NewDataPartitionWrapper(testClientInfo{}, volName, []string{strings.TrimPrefix(master.URL, "http://")}, false, 0, 0)
+	if err != nil {
+		t.Fatalf("new wrapper: %v", err)
+	}
+	t.Cleanup(w.Stop)
+	return w
+}
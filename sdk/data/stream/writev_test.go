@@ -0,0 +1,135 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stream
+
+import (
+	"bytes"
+	"context"
+	"syscall"
+	"testing"
+)
+
+// newWriteVTestClient returns a client with an opened streamer of inode whose write requests
+// are copied to file like handleRequest, the write at failOffset fails.
+func newWriteVTestClient(inode uint64, file []byte, failOffset int) (*ExtentClient, func()) {
+	s := &Streamer{inode: inode, request: make(chan interface{}, 64), isOpen: true}
+	s.once.Do(func() {})
+	client := &ExtentClient{streamers: map[uint64]*Streamer{inode: s}}
+	s.client = client
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case req := <-s.request:
+				request := req.(*WriteRequest)
+				if request.prev != nil && request.prev.err != nil {
+					request.writeBytes, request.err = 0, request.prev.err
+				} else if request.fileOffset == failOffset {
+					request.writeBytes, request.err = 0, syscall.EIO
+				} else {
+					request.writeBytes, request.err = copy(file[request.fileOffset:], request.data), nil
+				}
+				request.done <- struct{}{}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return client, func() { close(stop) }
+}
+
+func TestWriteV(t *testing.T) {
+	file := make([]byte, 64)
+	client, stop := newWriteVTestClient(1, file, -1)
+	defer stop()
+
+	bufs := [][]byte{[]byte("hello "), nil, []byte("cube"), []byte("fs")}
+	write, err := client.WriteV(context.Background(), 1, 4, bufs, 0, nil)
+	if err != nil {
+		t.Fatalf("WriteV: %v", err)
+	}
+	if write != 12 {
+		t.Fatalf("expect 12 bytes written, got %v", write)
+	}
+	if !bytes.Equal(file[4:16], []byte("hello cubefs")) {
+		t.Fatalf("unexpected file content %q", file[:16])
+	}
+
+	if _, err = client.WriteV(context.Background(), 2, 0, bufs, 0, nil); err != syscall.EBADF {
+		t.Fatalf("expect EBADF for a not opened inode, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if write, err = client.WriteV(ctx, 1, 0, bufs, 0, nil); err == nil || write != 0 {
+		t.Fatalf("expect a canceled WriteV to write nothing, write %v err %v", write, err)
+	}
+}
+
+func TestWriteVStopsOnError(t *testing.T) {
+	file := make([]byte, 64)
+	client, stop := newWriteVTestClient(1, file, 6)
+	defer stop()
+
+	bufs := [][]byte{[]byte("hello "), []byte("cube"), []byte("fs")}
+	write, err := client.WriteV(context.Background(), 1, 0, bufs, 0, nil)
+	if err != syscall.EIO {
+		t.Fatalf("expect EIO, got %v", err)
+	}
+	if write != 6 {
+		t.Fatalf("expect only the first buffer written, got %v", write)
+	}
+	if !bytes.Equal(file[:12], append([]byte("hello "), make([]byte, 6)...)) {
+		t.Fatalf("the buffers after the failed one should not be written, got %q", file[:12])
+	}
+}
+
+func writeVBenchBufs() [][]byte {
+	bufs := make([][]byte, 32)
+	for i := range bufs {
+		bufs[i] = bytes.Repeat([]byte{byte(i)}, 32*1024)
+	}
+	return bufs
+}
+
+func BenchmarkWriteV(b *testing.B) {
+	bufs := writeVBenchBufs()
+	file := make([]byte, len(bufs)*32*1024)
+	client, stop := newWriteVTestClient(1, file, -1)
+	defer stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.WriteV(context.Background(), 1, 0, bufs, 0, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConcatWrite(b *testing.B) {
+	bufs := writeVBenchBufs()
+	file := make([]byte, len(bufs)*32*1024)
+	client, stop := newWriteVTestClient(1, file, -1)
+	defer stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.Write(1, 0, bytes.Join(bufs, nil), 0, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -17,6 +17,7 @@ package wrapper
 import (
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -146,7 +147,27 @@ func (dp *DataPartition) GetAllAddrs() string {
 	return strings.Join(dp.Hosts[1:], proto.AddrSplit) + proto.AddrSplit
 }
 
+// partitionExcludeKey is the key in the exclude map to exclude the data partition itself rather than its hosts.
+func partitionExcludeKey(partitionID uint64) string {
+	return string(appendPartitionExcludeKey(nil, partitionID))
+}
+
+func appendPartitionExcludeKey(b []byte, partitionID uint64) []byte {
+	b = append(b, "dp("...)
+	b = strconv.AppendUint(b, partitionID, 10)
+	return append(b, ')')
+}
+
 func isExcluded(dp *DataPartition, exclude map[string]struct{}) bool {
+	if len(exclude) == 0 {
+		return false
+	}
+	// the key is built on the stack and looked up without converting it to a string,
+	// since every candidate is checked while selecting a partition
+	var buf [32]byte
+	if _, exist := exclude[string(appendPartitionExcludeKey(buf[:0], dp.PartitionID))]; exist {
+		return true
+	}
 	for _, host := range dp.Hosts {
 		if _, exist := exclude[host]; exist {
 			return true
@@ -17,6 +17,7 @@ package wrapper
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/cubefs/cubefs/util/log"
 )
@@ -118,12 +119,51 @@ func (w *Wrapper) refreshDpSelector(partitions []*DataPartition) {
 }
 
 // getDataPartitionForWrite returns an available data partition for write.
+// The partitions set by SetWriteExclusion are skipped unless no other partition is available.
 func (w *Wrapper) GetDataPartitionForWrite(exclude map[string]struct{}) (*DataPartition, error) {
 	w.Lock.RLock()
 	dpSelector := w.dpSelector
 	w.Lock.RUnlock()
 
-	return dpSelector.Select(exclude)
+	excludes := w.withWriteExclusion(exclude)
+	dp, err := dpSelector.Select(excludes)
+	if err != nil && len(excludes) != len(exclude) {
+		log.LogWarnf("GetDataPartitionForWrite: no data partition available with the write exclusion(%v), ignore it",
+			excludes)
+		return dpSelector.Select(exclude)
+	}
+	return dp, err
+}
+
+// SetWriteExclusion stops selecting the data partition for write until ttl expires, e.g. after writing to it failed.
+func (w *Wrapper) SetWriteExclusion(partitionID uint64, ttl time.Duration) {
+	w.writeExclusion.Store(partitionID, time.Now().Add(ttl))
+	log.LogInfof("SetWriteExclusion: exclude dp(%v) for write in %v", partitionID, ttl)
+}
+
+// withWriteExclusion returns a copy of exclude with the partitions excluded for write added,
+// or exclude itself if there is none. The expired exclusions are removed.
+func (w *Wrapper) withWriteExclusion(exclude map[string]struct{}) map[string]struct{} {
+	var excludes map[string]struct{}
+	now := time.Now()
+	w.writeExclusion.Range(func(key, value interface{}) bool {
+		if now.After(value.(time.Time)) {
+			w.writeExclusion.Delete(key)
+			return true
+		}
+		if excludes == nil {
+			excludes = make(map[string]struct{}, len(exclude)+1)
+			for k := range exclude {
+				excludes[k] = struct{}{}
+			}
+		}
+		excludes[partitionExcludeKey(key.(uint64))] = struct{}{}
+		return true
+	})
+	if excludes == nil {
+		return exclude
+	}
+	return excludes
 }
 
 func (w *Wrapper) RemoveDataPartitionForWrite(partitionID uint64) {
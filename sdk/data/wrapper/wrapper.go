@@ -51,6 +51,24 @@ type SimpleClientInfo interface {
 	GetVerMgr() *proto.VolVersionInfoList
 }
 
+// ConnPool provides the connections to the data nodes.
+type ConnPool interface {
+	GetConnect(targetAddr string) (c *net.TCPConn, err error)
+	PutConnect(c *net.TCPConn, forceClose bool)
+}
+
+// The policies to choose the replica of a follower read, and the order to try the others if it fails.
+const (
+	// ReadPreferDefault picks the nearest host if near read is enabled, or the available hosts by turns.
+	ReadPreferDefault = ""
+	// ReadPreferLeaderFirst reads from the leader, and then the followers in the order of the hosts.
+	ReadPreferLeaderFirst = "leader-first"
+	// ReadPreferNearestFirst reads from the hosts in the order of the distance.
+	ReadPreferNearestFirst = "nearest-first"
+	// ReadPreferFollowerRoundRobin reads from the followers by turns, and the leader at last.
+	ReadPreferFollowerRoundRobin = "follower-round-robin"
+)
+
 // Wrapper TODO rename. This name does not reflect what it is doing.
 type Wrapper struct {
 	Lock                  sync.RWMutex
@@ -63,6 +81,8 @@ type Wrapper struct {
 	followerRead          bool
 	followerReadClientCfg bool
 	nearRead              bool
+	nearReadClientCfg     bool
+	readPreference        string
 	dpSelectorChanged     bool
 	dpSelectorName        string
 	dpSelectorParm        string
@@ -71,6 +91,8 @@ type Wrapper struct {
 	stopC                 chan struct{}
 
 	dpSelector DataPartitionSelector
+	// the data partitions excluded for write temporarily, key: partition id, value: expire time
+	writeExclusion sync.Map
 
 	HostsStatus map[string]bool
 	Uids        map[uint32]*proto.UidSimpleInfo
@@ -82,6 +104,7 @@ type Wrapper struct {
 	verConfReadSeq              uint64
 	verReadSeq                  uint64
 	SimpleClient                SimpleClientInfo
+	connPool                    ConnPool
 }
 
 func (w *Wrapper) GetMasterClient() *masterSDK.MasterClient {
@@ -213,6 +236,7 @@ func (w *Wrapper) GetSimpleVolView() (err error) {
 	}
 
 	w.followerRead = view.FollowerRead
+	w.nearRead = view.NearRead
 	w.dpSelectorName = view.DpSelectorName
 	w.dpSelectorParm = view.DpSelectorParm
 	w.volType = view.VolType
@@ -339,6 +363,12 @@ func (w *Wrapper) updateSimpleVolView() (err error) {
 		w.followerRead = view.FollowerRead
 	}
 
+	if w.nearRead != view.NearRead && !w.nearReadClientCfg {
+		log.LogDebugf("UpdateSimpleVolView: update nearRead from old(%v) to new(%v)",
+			w.nearRead, view.NearRead)
+		w.nearRead = view.NearRead
+	}
+
 	if w.dpSelectorName != view.DpSelectorName || w.dpSelectorParm != view.DpSelectorParm {
 		log.LogDebugf("UpdateSimpleVolView: update dpSelector from old(%v %v) to new(%v %v)",
 			w.dpSelectorName, w.dpSelectorParm, view.DpSelectorName, view.DpSelectorParm)
@@ -370,7 +400,7 @@ func (w *Wrapper) updateDataPartitionByRsp(isInit bool, DataPartitions []*proto.
 			continue
 		}
 		dp := convert(partition)
-		if w.followerRead && w.nearRead {
+		if w.followerRead && (w.nearRead || w.readPreference == ReadPreferNearestFirst) {
 			dp.NearHosts = w.sortHostsByDistance(dp.Hosts)
 		}
 		log.LogInfof("updateDataPartition: dp(%v)", dp)
@@ -497,7 +527,7 @@ func (w *Wrapper) replaceOrInsertPartition(dp *DataPartition) {
 		old.ReplicaNum = dp.ReplicaNum
 		old.Hosts = dp.Hosts
 		old.IsDiscard = dp.IsDiscard
-		old.NearHosts = dp.Hosts
+		old.NearHosts = dp.NearHosts
 
 		dp.Metrics = old.Metrics
 	} else {
@@ -599,8 +629,11 @@ func (w *Wrapper) updateDataNodeStatus() (err error) {
 	return
 }
 
-func (w *Wrapper) SetNearRead(nearRead bool) {
-	w.nearRead = nearRead
+// SetNearRead enables near read if the client config or the volume default enables it,
+// the volume default is followed only when the client doesn't enable it.
+func (w *Wrapper) SetNearRead(clientConfig bool) {
+	w.nearReadClientCfg = clientConfig
+	w.nearRead = w.nearReadClientCfg || w.nearRead
 	log.LogInfof("SetNearRead: set nearRead to %v", w.nearRead)
 }
 
@@ -608,6 +641,32 @@ func (w *Wrapper) NearRead() bool {
 	return w.nearRead
 }
 
+// SetReadPreference sets the policy to choose the replica of a follower read and to fail over,
+// empty for the default one.
+func (w *Wrapper) SetReadPreference(policy string) error {
+	switch policy {
+	case ReadPreferDefault, ReadPreferLeaderFirst, ReadPreferNearestFirst, ReadPreferFollowerRoundRobin:
+	default:
+		return fmt.Errorf("unknown read preference(%v)", policy)
+	}
+	w.readPreference = policy
+	log.LogInfof("SetReadPreference: set readPreference to %v", policy)
+	return nil
+}
+
+func (w *Wrapper) ReadPreference() string {
+	return w.readPreference
+}
+
+// SetConnPool sets the pool of the connections to the data nodes, nil for the default one.
+func (w *Wrapper) SetConnPool(pool ConnPool) {
+	w.connPool = pool
+}
+
+func (w *Wrapper) ConnPool() ConnPool {
+	return w.connPool
+}
+
 // Sort hosts by distance form local
 func (w *Wrapper) sortHostsByDistance(srcHosts []string) []string {
 	hosts := make([]string, len(srcHosts))
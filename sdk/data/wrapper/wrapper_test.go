@@ -0,0 +1,159 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package wrapper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	masterSDK "github.com/cubefs/cubefs/sdk/master"
+)
+
+func newVolViewMaster(t *testing.T, view *proto.SimpleVolView) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != proto.AdminGetVol {
+			http.NotFound(w, r)
+			return
+		}
+		reply := &proto.HTTPReply{Code: proto.ErrCodeSuccess, Msg: "success", Data: view}
+		if err := json.NewEncoder(w).Encode(reply); err != nil {
+			t.Errorf("encode reply failed: %v", err)
+		}
+	}))
+}
+
+func newTestWrapper(addr string) *Wrapper {
+	return &Wrapper{
+		volName: "vol",
+		mc:      masterSDK.NewMasterClient([]string{addr}, false),
+	}
+}
+
+func TestReadPolicyInheritVolumeDefault(t *testing.T) {
+	view := &proto.SimpleVolView{Name: "vol", FollowerRead: true, NearRead: true}
+	ts := newVolViewMaster(t, view)
+	defer ts.Close()
+	addr := strings.TrimPrefix(ts.URL, "http://")
+
+	// client mounts without follower/near read options
+	w := newTestWrapper(addr)
+	if err := w.GetSimpleVolView(); err != nil {
+		t.Fatalf("get simple vol view failed: %v", err)
+	}
+	w.InitFollowerRead(false)
+	w.SetNearRead(false)
+	if !w.FollowerRead() || !w.NearRead() {
+		t.Fatalf("client should inherit volume default, followerRead(%v) nearRead(%v)", w.FollowerRead(), w.NearRead())
+	}
+
+	// volume default changes are followed by clients without the options
+	view.FollowerRead = false
+	view.NearRead = false
+	if err := w.updateSimpleVolView(); err != nil {
+		t.Fatalf("update simple vol view failed: %v", err)
+	}
+	if w.FollowerRead() || w.NearRead() {
+		t.Fatalf("client should follow volume default, followerRead(%v) nearRead(%v)", w.FollowerRead(), w.NearRead())
+	}
+
+	// the options set by client are kept
+	w = newTestWrapper(addr)
+	if err := w.GetSimpleVolView(); err != nil {
+		t.Fatalf("get simple vol view failed: %v", err)
+	}
+	w.InitFollowerRead(true)
+	w.SetNearRead(true)
+	if err := w.updateSimpleVolView(); err != nil {
+		t.Fatalf("update simple vol view failed: %v", err)
+	}
+	if !w.FollowerRead() || !w.NearRead() {
+		t.Fatalf("client config should be kept, followerRead(%v) nearRead(%v)", w.FollowerRead(), w.NearRead())
+	}
+}
+
+func TestWriteExclusion(t *testing.T) {
+	selector, _ := newDefaultRandomSelector("")
+	partitions := []*DataPartition{
+		{DataPartitionResponse: proto.DataPartitionResponse{PartitionID: 1, Hosts: []string{"192.168.0.1:17310"}}},
+		{DataPartitionResponse: proto.DataPartitionResponse{PartitionID: 2, Hosts: []string{"192.168.0.2:17310"}}},
+	}
+	if err := selector.Refresh(partitions); err != nil {
+		t.Fatalf("refresh selector failed: %v", err)
+	}
+	w := &Wrapper{dpSelector: selector}
+	selectIDs := func(exclude map[string]struct{}) map[uint64]bool {
+		ids := make(map[uint64]bool)
+		for i := 0; i < 100; i++ {
+			dp, err := w.GetDataPartitionForWrite(exclude)
+			if err != nil {
+				t.Fatalf("select dp failed: %v", err)
+			}
+			ids[dp.PartitionID] = true
+		}
+		return ids
+	}
+
+	w.SetWriteExclusion(1, 100*time.Millisecond)
+	exclude := make(map[string]struct{})
+	if ids := selectIDs(exclude); ids[1] || !ids[2] {
+		t.Fatalf("excluded dp should be skipped, selected %v", ids)
+	}
+	// the caller's exclude is not modified
+	if len(exclude) != 0 {
+		t.Fatalf("exclude should not be modified: %v", exclude)
+	}
+	// the exclusion is ignored if no other dp is available
+	if ids := selectIDs(map[string]struct{}{"192.168.0.2:17310": {}}); !ids[1] || ids[2] {
+		t.Fatalf("excluded dp should be selected as the last resort, selected %v", ids)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if ids := selectIDs(nil); !ids[1] {
+		t.Fatalf("dp should be selected after the exclusion expired, selected %v", ids)
+	}
+	if _, ok := w.writeExclusion.Load(uint64(1)); ok {
+		t.Fatalf("expired exclusion should be removed")
+	}
+}
+
+func TestIsExcluded(t *testing.T) {
+	dp := &DataPartition{DataPartitionResponse: proto.DataPartitionResponse{PartitionID: 12345, Hosts: []string{"192.168.0.1:17310"}}}
+	if partitionExcludeKey(12345) != "dp(12345)" {
+		t.Fatalf("unexpected exclude key %v", partitionExcludeKey(12345))
+	}
+	for _, c := range []struct {
+		exclude  map[string]struct{}
+		excluded bool
+	}{
+		{nil, false},
+		{map[string]struct{}{"192.168.0.2:17310": {}, partitionExcludeKey(1234): {}}, false},
+		{map[string]struct{}{"192.168.0.1:17310": {}}, true},
+		{map[string]struct{}{partitionExcludeKey(12345): {}}, true},
+	} {
+		if isExcluded(dp, c.exclude) != c.excluded {
+			t.Fatalf("exclude(%v) expect excluded %v", c.exclude, c.excluded)
+		}
+	}
+	// checking a candidate does not allocate
+	exclude := map[string]struct{}{partitionExcludeKey(1): {}}
+	if allocs := testing.AllocsPerRun(100, func() { isExcluded(dp, exclude) }); allocs != 0 {
+		t.Fatalf("isExcluded allocates %v times", allocs)
+	}
+}
@@ -424,54 +424,6 @@ func (c *ClusterClient) DataNodeList(ctx context.Context) ([]DataNode, error) {
 	return result, nil
 }
 
-func (c *ClusterClient) DataNodeListTest(ctx context.Context, num int64) ([]DataNode, error) {
-	req := client.NewRequest(ctx, `query($num: int64){
-			dataNodeListTest(num: $num){
-				addr
-				availableSpace
-				badDisks
-				carry
-				dataPartitionCount
-				dataPartitionReports{
-					diskPath
-					extentCount
-					isLeader
-					needCompare
-					partitionID
-					partitionStatus
-					total
-					used
-					volName
-				}
-				iD
-				isActive
-				nodeSetID
-				persistenceDataPartitions
-				reportTime
-				selectedTimes
-				total
-				usageRatio
-				used
-				zoneName
-			}
-
-		}`)
-
-	req.Var("num", num)
-
-	rep, err := c.Query(ctx, proto.AdminClusterAPI, req)
-	if err != nil {
-		return nil, err
-	}
-	result := make([]DataNode, 0)
-
-	if err := rep.GetValueByType(&result, "dataNodeListTest"); err != nil {
-		return nil, err
-	}
-
-	return result, nil
-}
-
 func (c *ClusterClient) MasterList(ctx context.Context) ([]MasterInfo, error) {
 	req := client.NewRequest(ctx, `query(){
 			masterList{
@@ -235,6 +235,15 @@ func (api *AdminAPI) DeleteVolumeWithAuthNode(volName, authKey, clientIDKey stri
 	return
 }
 
+func (api *AdminAPI) RenameVolume(volName, newName, authKey string) (err error) {
+	request := newRequest(get, proto.AdminRenameVol).Header(api.h)
+	request.addParam("name", volName)
+	request.addParam("newName", newName)
+	request.addParam("authKey", authKey)
+	_, err = api.mc.serveRequest(request)
+	return
+}
+
 func (api *AdminAPI) UnDeleteVolume(volName, authKey string, status bool) (err error) {
 	request := newRequest(get, proto.AdminDeleteVol)
 	request.addParam("name", volName)
@@ -261,6 +270,7 @@ func (api *AdminAPI) UpdateVolume(
 	request.addParam("zoneName", vv.ZoneName)
 	request.addParam("capacity", strconv.FormatUint(vv.Capacity, 10))
 	request.addParam("followerRead", strconv.FormatBool(vv.FollowerRead))
+	request.addParam("nearRead", strconv.FormatBool(vv.NearRead))
 	request.addParam("ebsBlkSize", strconv.Itoa(vv.ObjBlockSize))
 	request.addParam("cacheCap", strconv.FormatUint(vv.CacheCapacity, 10))
 	request.addParam("cacheAction", strconv.Itoa(vv.CacheAction))
@@ -600,6 +610,13 @@ func (api *AdminAPI) QueryBadDisks() (badDisks *proto.DiskInfos, err error) {
 	return
 }
 
+// QueryBadDiskHistory returns the bad disk records of the data node, or of all the data nodes if addr is empty.
+func (api *AdminAPI) QueryBadDiskHistory(addr string) (histories []proto.BadDiskHistory, err error) {
+	histories = make([]proto.BadDiskHistory, 0)
+	err = api.mc.requestWith(&histories, newRequest(get, proto.QueryBadDiskHistory).Header(api.h).addParam("addr", addr))
+	return
+}
+
 func (api *AdminAPI) QueryDisks(addr string) (disks *proto.DiskInfos, err error) {
 	disks = &proto.DiskInfos{}
 	err = api.mc.requestWith(disks, newRequest(get, proto.QueryDisks).Header(api.h).
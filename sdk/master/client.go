@@ -16,10 +16,12 @@ package master
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
@@ -43,7 +45,10 @@ const (
 	post = http.MethodPost
 )
 
-var ErrNoValidMaster = errors.New("no valid master")
+var (
+	ErrNoValidMaster = errors.New("no valid master")
+	ErrTimeout       = errors.New("request master timeout")
+)
 
 type MasterCLientWithResolver struct {
 	MasterClient
@@ -58,6 +63,7 @@ type MasterClient struct {
 	useSSL      bool
 	leaderAddr  string
 	timeout     time.Duration
+	deadline    time.Duration
 	clientIDKey string
 
 	adminAPI  *AdminAPI
@@ -130,6 +136,21 @@ func (c *MasterClient) SetTimeout(timeout uint16) {
 	c.Unlock()
 }
 
+// SetRequestTimeout changes the timeout of each http request sent to a master.
+func (c *MasterClient) SetRequestTimeout(timeout time.Duration) {
+	c.Lock()
+	c.timeout = timeout
+	c.Unlock()
+}
+
+// SetDeadline limits the total time of an operation, including the retries on all masters.
+// Zero means no limit.
+func (c *MasterClient) SetDeadline(deadline time.Duration) {
+	c.Lock()
+	c.deadline = deadline
+	c.Unlock()
+}
+
 func (c *MasterClient) SetClientIDKey(clientIDKey string) {
 	c.Lock()
 	c.clientIDKey = clientIDKey
@@ -137,9 +158,23 @@ func (c *MasterClient) SetClientIDKey(clientIDKey string) {
 }
 
 func (c *MasterClient) serveRequest(r *request) (repsData []byte, err error) {
+	ctx := context.Background()
+	if deadline := c.getDeadline(r); deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, deadline)
+		defer cancel()
+	}
+	return c.serveRequestWithContext(ctx, r)
+}
+
+func (c *MasterClient) serveRequestWithContext(ctx context.Context, r *request) (repsData []byte, err error) {
 	leaderAddr, nodes := c.prepareRequest()
 	host := leaderAddr
 	for i := -1; i < len(nodes); i++ {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("%w: operation deadline exceeded, last err(%v)", ErrTimeout, err)
+			return
+		}
 		if i == -1 {
 			if host == "" {
 				continue
@@ -153,7 +188,7 @@ func (c *MasterClient) serveRequest(r *request) (repsData []byte, err error) {
 			schema = "https"
 		}
 		url := fmt.Sprintf("%s://%s%s", schema, host, r.path)
-		resp, err = c.httpRequest(r.method, url, r)
+		resp, err = c.httpRequest(ctx, r.method, url, r)
 		if err != nil {
 			log.LogErrorf("serveRequest: send http request fail: method(%v) url(%v) err(%v)", r.method, url, err)
 			continue
@@ -176,7 +211,7 @@ func (c *MasterClient) serveRequest(r *request) (repsData []byte, err error) {
 				err = ErrNoValidMaster
 				return
 			}
-			repsData, err = c.serveRequest(r)
+			repsData, err = c.serveRequestWithContext(ctx, r)
 			return
 		case http.StatusOK:
 			if leaderAddr != host {
@@ -246,16 +281,33 @@ func (c *MasterClient) prepareRequest() (addr string, nodes []string) {
 	return
 }
 
-func (c *MasterClient) httpRequest(method, url string, r *request) (resp *http.Response, err error) {
-	client := http.DefaultClient
-	if !r.noTimeout {
-		client.Timeout = c.timeout
+// getTimeout returns the timeout of each http request of the operation, zero means no limit.
+func (c *MasterClient) getTimeout(r *request) time.Duration {
+	if r.noTimeout {
+		return 0
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return c.timeout
+}
+
+// getDeadline returns the total time limit of the operation, zero means no limit.
+func (c *MasterClient) getDeadline(r *request) time.Duration {
+	if r.noTimeout {
+		return 0
 	}
+	c.RLock()
+	defer c.RUnlock()
+	return c.deadline
+}
+
+func (c *MasterClient) httpRequest(ctx context.Context, method, url string, r *request) (resp *http.Response, err error) {
+	client := &http.Client{Timeout: c.getTimeout(r)}
 	reader := bytes.NewReader(r.body)
 	var req *http.Request
 	fullUrl := c.mergeRequestUrl(url, r.params)
 	log.LogDebugf("httpRequest: method(%v) url(%v) bodyLength[%v].", method, fullUrl, len(r.body))
-	if req, err = http.NewRequest(method, fullUrl, reader); err != nil {
+	if req, err = http.NewRequestWithContext(ctx, method, fullUrl, reader); err != nil {
 		return
 	}
 	req.Header.Set("Content-Type", "application/json")
@@ -263,7 +315,12 @@ func (c *MasterClient) httpRequest(method, url string, r *request) (resp *http.R
 	for k, v := range r.header {
 		req.Header.Set(k, v)
 	}
-	resp, err = client.Do(req)
+	if resp, err = client.Do(req); err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			err = fmt.Errorf("%w: %v", ErrTimeout, err)
+		}
+	}
 	return
 }
 
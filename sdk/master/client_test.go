@@ -0,0 +1,78 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package master
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/stretchr/testify/require"
+)
+
+// newDelayedMaster starts a mock master which replies to every request after delay.
+func newDelayedMaster(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		data, _ := json.Marshal(&proto.HTTPReply{Code: proto.ErrCodeSuccess, Msg: "success"})
+		w.Write(data)
+	}))
+}
+
+func TestMasterClientTimeout(t *testing.T) {
+	fast := newDelayedMaster(0)
+	defer fast.Close()
+	slow := newDelayedMaster(time.Second)
+	defer slow.Close()
+	fastAddr := strings.TrimPrefix(fast.URL, "http://")
+	slowAddr := strings.TrimPrefix(slow.URL, "http://")
+
+	// the slow master times out and the request is retried on the fast one
+	mc := NewMasterClient([]string{slowAddr, fastAddr}, false)
+	mc.SetRequestTimeout(100 * time.Millisecond)
+	require.NoError(t, mc.request(newRequest(get, proto.AdminGetIP)))
+	require.Equal(t, fastAddr, mc.Leader())
+
+	// every master times out
+	mc = NewMasterClient([]string{slowAddr, slowAddr}, false)
+	mc.SetRequestTimeout(100 * time.Millisecond)
+	start := time.Now()
+	err := mc.request(newRequest(get, proto.AdminGetIP))
+	require.True(t, errors.Is(err, ErrTimeout), "err: %v", err)
+	require.Less(t, time.Since(start), time.Second)
+
+	// the deadline cuts the retries short
+	mc = NewMasterClient([]string{slowAddr, slowAddr, slowAddr, fastAddr}, false)
+	mc.SetRequestTimeout(200 * time.Millisecond)
+	mc.SetDeadline(300 * time.Millisecond)
+	start = time.Now()
+	err = mc.request(newRequest(get, proto.AdminGetIP))
+	require.True(t, errors.Is(err, ErrTimeout), "err: %v", err)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// requests without timeout ignore both limits
+	start = time.Now()
+	require.NoError(t, mc.request(newRequest(get, proto.AdminGetIP).NoTimeout()))
+	require.GreaterOrEqual(t, time.Since(start), time.Second)
+}
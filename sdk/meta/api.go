@@ -15,6 +15,7 @@
 package meta
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	syslog "log"
@@ -376,6 +377,24 @@ func (mw *MetaWrapper) BatchGetExpiredMultipart(prefix string, days int) (expire
 	return
 }
 
+// InodeGetWithContext gets the inode from the meta node, and gives up once the context is done.
+// Unlike InodeGet_ll, it neither retries with the refreshed meta partitions nor updates the caches.
+func (mw *MetaWrapper) InodeGetWithContext(ctx context.Context, inode uint64) (*proto.InodeInfo, error) {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		log.LogErrorf("InodeGetWithContext: No such partition, ino(%v)", inode)
+		return nil, syscall.ENOENT
+	}
+	status, info, err := mw.igetWithContext(ctx, mp, inode, mw.VerReadSeq)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil || status != statusOK {
+		return nil, statusToErrno(status)
+	}
+	return info, nil
+}
+
 func (mw *MetaWrapper) InodeGet_ll(inode uint64) (*proto.InodeInfo, error) {
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
@@ -1414,6 +1433,23 @@ func (mw *MetaWrapper) AppendExtentKey(parentInode, inode uint64, ek proto.Exten
 	return statusOK, nil
 }
 
+// MergeExtentKeys replaces the adjacent extent keys of the inode with the merged key spanning them, the
+// extents covered are kept. Used as a callback by stream sdk.
+func (mw *MetaWrapper) MergeExtentKeys(parentInode, inode uint64, ek proto.ExtentKey, eks []proto.ExtentKey) error {
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		return syscall.ENOENT
+	}
+
+	status, err := mw.mergeExtentKeys(mp, inode, ek, eks)
+	if err != nil || status != statusOK {
+		log.LogErrorf("MergeExtentKeys: inode(%v) ek(%v) eks(%v) err(%v) status(%v)", inode, ek, eks, err, status)
+		return statusToErrno(status)
+	}
+	log.LogDebugf("MergeExtentKeys: ino(%v) ek(%v) eks(%v)", inode, ek, eks)
+	return nil
+}
+
 // AppendExtentKeys append multiple extent key into specified inode with single request.
 func (mw *MetaWrapper) AppendExtentKeys(inode uint64, eks []proto.ExtentKey) error {
 	mp := mw.getPartitionByInode(inode)
@@ -1447,17 +1483,22 @@ func (mw *MetaWrapper) AppendObjExtentKeys(inode uint64, eks []proto.ObjExtentKe
 }
 
 func (mw *MetaWrapper) GetExtents(inode uint64) (gen uint64, size uint64, extents []proto.ExtentKey, err error) {
+	return mw.GetExtentsByVer(inode, mw.VerReadSeq)
+}
+
+// GetExtentsByVer returns the extents of the inode as of the snapshot version verSeq.
+func (mw *MetaWrapper) GetExtentsByVer(inode uint64, verSeq uint64) (gen uint64, size uint64, extents []proto.ExtentKey, err error) {
 	mp := mw.getPartitionByInode(inode)
 	if mp == nil {
 		return 0, 0, nil, syscall.ENOENT
 	}
 
-	resp, err := mw.getExtents(mp, inode)
+	resp, err := mw.getExtents(mp, inode, verSeq)
 	if err != nil {
 		if resp != nil {
 			err = statusToErrno(resp.Status)
 		}
-		log.LogErrorf("GetExtents: ino(%v) err(%v)", inode, err)
+		log.LogErrorf("GetExtents: ino(%v) verSeq(%v) err(%v)", inode, verSeq, err)
 		return 0, 0, nil, err
 	}
 	extents = resp.Extents
@@ -2089,6 +2130,23 @@ func (mw *MetaWrapper) XAttrDel_ll(inode uint64, name string) error {
 	return nil
 }
 
+// BatchXAttrDel_ll removes the xattrs of the inode in one request.
+func (mw *MetaWrapper) BatchXAttrDel_ll(inode uint64, names []string) error {
+	var err error
+	mp := mw.getPartitionByInode(inode)
+	if mp == nil {
+		log.LogErrorf("BatchXAttrDel_ll: no such partition, inode(%v)", inode)
+		return syscall.ENOENT
+	}
+	var status int
+	status, err = mw.batchRemoveXAttr(mp, inode, names)
+	if err != nil || status != statusOK {
+		return statusToErrno(status)
+	}
+	log.LogDebugf("BatchXAttrDel_ll: remove xattrs, inode(%v) names(%v) status(%v)", inode, names, status)
+	return nil
+}
+
 func (mw *MetaWrapper) XAttrsList_ll(inode uint64) ([]string, error) {
 	var err error
 	mp := mw.getPartitionByInode(inode)
@@ -15,8 +15,10 @@
 package meta
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"sync"
 	"syscall"
 	"time"
 
@@ -57,6 +59,11 @@ func (mw *MetaWrapper) putConn(mc *MetaConn, err error) {
 }
 
 func (mw *MetaWrapper) sendToMetaPartition(mp *MetaPartition, req *proto.Packet) (*proto.Packet, error) {
+	return mw.sendToMetaPartitionWithContext(context.Background(), mp, req)
+}
+
+// sendToMetaPartitionWithContext is the same as sendToMetaPartition, but gives up once the context is done.
+func (mw *MetaWrapper) sendToMetaPartitionWithContext(ctx context.Context, mp *MetaPartition, req *proto.Packet) (*proto.Packet, error) {
 	var (
 		resp    *proto.Packet
 		err     error
@@ -96,7 +103,7 @@ func (mw *MetaWrapper) sendToMetaPartition(mp *MetaPartition, req *proto.Packet)
 	}
 
 sendWithList:
-	resp, err = mc.send(req, lastSeq)
+	resp, err = mc.sendWithContext(ctx, req, lastSeq)
 	if err == nil && !resp.ShouldRetry() && !resp.ShouldRetryWithVersionList() {
 		mw.putConn(mc, err)
 		goto out
@@ -118,13 +125,17 @@ retry:
 	start = time.Now()
 	for i := 0; i <= SendRetryLimit; i++ {
 		for j, addr = range mp.Members {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+				goto out
+			}
 			mc, err = mw.getConn(mp.PartitionID, addr)
 			errs[j] = err
 			if err != nil {
 				log.LogWarnf("sendToMetaPartition: getConn failed and continue to retry, req(%v) mp(%v) addr(%v) err(%v)", req, mp, addr, err)
 				continue
 			}
-			resp, err = mc.send(req, lastSeq)
+			resp, err = mc.sendWithContext(ctx, req, lastSeq)
 			mw.putConn(mc, err)
 			if err == nil && !resp.ShouldRetry() {
 				goto out
@@ -143,7 +154,12 @@ retry:
 		sendRetryInterval := time.Duration(SendRetryInterval+i*delta) * time.Millisecond
 		log.LogWarnf("sendToMetaPartition: req(%v) mp(%v) retry in (%v), retry_iteration (%v), retry_totalTime (%v)", req, mp,
 			sendRetryInterval, i+1, time.Since(start))
-		time.Sleep(sendRetryInterval)
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			goto out
+		case <-time.After(sendRetryInterval):
+		}
 	}
 
 out:
@@ -151,12 +167,46 @@ out:
 	if mw.Client != nil && resp != nil { // For compatibility with LcNode, the client checks whether it is nil
 		mw.checkVerFromMeta(resp)
 	}
+	if resp != nil {
+		if mismatchErr := mw.checkArgMismatch(addr, req, resp); mismatchErr != nil {
+			return resp, mismatchErr
+		}
+	}
+	if err != nil && ctx.Err() != nil {
+		return nil, errors.New(fmt.Sprintf("sendToMetaPartition canceled: req(%v) mp(%v) errs(%v) err(%v)", req, mp, errs, ctx.Err()))
+	}
 	if err != nil || resp == nil {
 		return nil, errors.New(fmt.Sprintf("sendToMetaPartition failed: req(%v) mp(%v) errs(%v) resp(%v)", req, mp, errs, resp))
 	}
 	return resp, nil
 }
 
+// sendWithContext is the same as send, but closes the connection to abort the io once the context is done.
+func (mc *MetaConn) sendWithContext(ctx context.Context, req *proto.Packet, verSeq uint64) (resp *proto.Packet, err error) {
+	if ctx.Done() == nil {
+		return mc.send(req, verSeq)
+	}
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			mc.conn.Close()
+		case <-stop:
+		}
+	}()
+	resp, err = mc.send(req, verSeq)
+	close(stop)
+	wg.Wait()
+	if ctx.Err() != nil {
+		// the connection may be closed, so it is never put back to the pool as a healthy one
+		return nil, ctx.Err()
+	}
+	return
+}
+
 func (mc *MetaConn) send(req *proto.Packet, verSeq uint64) (resp *proto.Packet, err error) {
 	req.ExtentType |= proto.MultiVersionFlag
 	req.VerSeq = verSeq
@@ -17,6 +17,7 @@ package meta
 import (
 	gerrors "errors"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -131,6 +132,9 @@ type MetaWrapper struct {
 	usedSize   uint64
 	inodeCount uint64
 
+	// unix nano of the last successful volume stat query to the master
+	lastMasterContact int64
+
 	authenticate bool
 	Ticket       auth.Ticket
 	accessToken  proto.APIAccessReq
@@ -168,6 +172,10 @@ type MetaWrapper struct {
 	VerReadSeq uint64
 	LastVerSeq uint64
 	Client     wrapper.SimpleClientInfo
+
+	// versions of the meta nodes, used to diagnose the version skew
+	metaNodeVersions       sync.Map
+	metaNodeVersionQueries sync.Map // the meta nodes whose version is being queried from the master
 }
 
 type uniqidRange struct {
@@ -294,6 +302,15 @@ func (mw *MetaWrapper) Cluster() string {
 	return mw.cluster
 }
 
+// LastMasterContact returns the time of the last successful query to the master, zero if there is none.
+func (mw *MetaWrapper) LastMasterContact() time.Time {
+	nano := atomic.LoadInt64(&mw.lastMasterContact)
+	if nano == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nano)
+}
+
 func (mw *MetaWrapper) LocalIP() string {
 	return mw.localIP
 }
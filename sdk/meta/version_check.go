@@ -0,0 +1,108 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+// the versions of the meta nodes are reported to the master by heartbeats, cache them for a while
+// to avoid querying the master on every mismatched request.
+const metaNodeVersionExpiration = time.Minute
+
+const unknownVersion = "unknown"
+
+type metaNodeVersion struct {
+	version    string
+	updateTime time.Time
+}
+
+// VersionMismatchError is returned for a request the meta node rejects as of an op it does not support, which is
+// often caused by the version skew during a rolling upgrade. The versions are the ones known when it is rejected.
+type VersionMismatchError struct {
+	Addr          string
+	ClientVersion string
+	ServerVersion string
+	Op            string
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("version incompatible: client expects %v, server(%v) supports %v, op(%v)",
+		e.ClientVersion, e.Addr, e.ServerVersion, e.Op)
+}
+
+// getMetaNodeVersion returns the cached version the meta node advertised to the master, unknownVersion if it
+// is not cached yet. The master is queried in the background if the cache is missing or expired, so that
+// the requests are not blocked, the expired version is returned in the meantime.
+func (mw *MetaWrapper) getMetaNodeVersion(addr string) string {
+	v, ok := mw.metaNodeVersions.Load(addr)
+	if ok && time.Since(v.(*metaNodeVersion).updateTime) < metaNodeVersionExpiration {
+		return v.(*metaNodeVersion).version
+	}
+	mw.refreshMetaNodeVersion(addr)
+	if ok {
+		return v.(*metaNodeVersion).version
+	}
+	return unknownVersion
+}
+
+// refreshMetaNodeVersion queries the version of the meta node from the master in the background,
+// one query is in flight for a meta node at most.
+func (mw *MetaWrapper) refreshMetaNodeVersion(addr string) {
+	if mw.mc == nil {
+		return
+	}
+	if _, loaded := mw.metaNodeVersionQueries.LoadOrStore(addr, struct{}{}); loaded {
+		return
+	}
+	go func() {
+		defer mw.metaNodeVersionQueries.Delete(addr)
+		version := unknownVersion
+		info, err := mw.mc.NodeAPI().GetMetaNode(addr)
+		if err != nil {
+			log.LogWarnf("refreshMetaNodeVersion: get meta node(%v) from master failed, err(%v)", addr, err)
+		} else if info.Version != "" {
+			version = info.Version
+		}
+		mw.metaNodeVersions.Store(addr, &metaNodeVersion{version: version, updateTime: time.Now()})
+	}()
+}
+
+// isVersionMismatchReply tells whether the meta node rejects the request as of an op it does not support.
+func isVersionMismatchReply(resp *proto.Packet) bool {
+	return resp.ResultCode == proto.OpArgMismatchErr &&
+		strings.HasPrefix(string(resp.Data), proto.ErrVersionMismatch.Error())
+}
+
+// checkArgMismatch returns a VersionMismatchError if the meta node replies that it does not support the op of the
+// request, the versions of the client and the meta node are filled in to explain it. The other responses, e.g. an
+// OpArgMismatchErr of an invalid argument, are kept as they are.
+func (mw *MetaWrapper) checkArgMismatch(addr string, req, resp *proto.Packet) error {
+	if !isVersionMismatchReply(resp) {
+		return nil
+	}
+	clientVersion := proto.Version
+	if clientVersion == "" {
+		clientVersion = unknownVersion
+	}
+	mismatch := &VersionMismatchError{Addr: addr, ClientVersion: clientVersion, ServerVersion: mw.getMetaNodeVersion(addr), Op: req.GetOpMsg()}
+	log.LogErrorf("sendToMetaPartition: %v, req(%v) resp(%v)", mismatch, req, string(resp.Data))
+	return mismatch
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	masterSDK "github.com/cubefs/cubefs/sdk/master"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckArgMismatch(t *testing.T) {
+	const oldNode = "192.168.0.1:17210"
+	var queries int32
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&queries, 1)
+		info := &proto.MetaNodeInfo{Addr: r.URL.Query().Get("addr"), Version: "3.3.0"}
+		data, _ := json.Marshal(&proto.HTTPReply{Code: proto.ErrCodeSuccess, Msg: "success", Data: info})
+		w.Write(data)
+	}))
+	defer master.Close()
+
+	clientVersion := proto.Version
+	proto.Version = "3.4.0"
+	defer func() { proto.Version = clientVersion }()
+
+	mw := &MetaWrapper{mc: masterSDK.NewMasterClient([]string{strings.TrimPrefix(master.URL, "http://")}, false)}
+	req := proto.NewPacket()
+	req.Opcode = proto.OpMetaCreateInode
+	resp := proto.NewPacket()
+	resp.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(proto.ErrVersionMismatch.Error()+": unknown opcode(1)"))
+
+	// the meta node says so, the versions are filled in once known
+	err := mw.checkArgMismatch(oldNode, req, resp)
+	mismatch, ok := err.(*VersionMismatchError)
+	assert.True(t, ok)
+	assert.Equal(t, &VersionMismatchError{Addr: oldNode, ClientVersion: "3.4.0", ServerVersion: unknownVersion, Op: req.GetOpMsg()}, mismatch)
+	assert.Eventually(t, func() bool {
+		_, ok := mw.metaNodeVersions.Load(oldNode)
+		return ok
+	}, time.Second, 10*time.Millisecond)
+	err = mw.checkArgMismatch(oldNode, req, resp)
+	assert.Equal(t, &VersionMismatchError{Addr: oldNode, ClientVersion: "3.4.0", ServerVersion: "3.3.0", Op: req.GetOpMsg()}, err)
+	assert.Contains(t, err.Error(), "version incompatible: client expects 3.4.0, server("+oldNode+") supports 3.3.0")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&queries))
+
+	// an invalid argument is kept although the versions differ
+	resp.PacketErrorWithBody(proto.OpArgMismatchErr, []byte("invalid name"))
+	assert.NoError(t, mw.checkArgMismatch(oldNode, req, resp))
+	assert.NoError(t, mw.checkArgMismatch(oldNode, req, resp))
+
+	// the other result codes are not checked
+	resp.PacketErrorWithBody(proto.OpErr, []byte(proto.ErrVersionMismatch.Error()))
+	assert.NoError(t, mw.checkArgMismatch(oldNode, req, resp))
+	resp.PacketOkReply()
+	assert.NoError(t, mw.checkArgMismatch(oldNode, req, resp))
+
+	// the version of the meta node is unknown without the master
+	mw = &MetaWrapper{}
+	resp.PacketErrorWithBody(proto.OpArgMismatchErr, []byte(proto.ErrVersionMismatch.Error()))
+	err = mw.checkArgMismatch(oldNode, req, resp)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "supports unknown")
+}
@@ -159,6 +159,7 @@ func (mw *MetaWrapper) updateVolStatInfo() (err error) {
 		log.LogWarnf("updateVolStatInfo: get volume status fail: volume(%v) err(%v)", mw.volname, err)
 		return
 	}
+	atomic.StoreInt64(&mw.lastMasterContact, time.Now().UnixNano())
 
 	if info.UsedSize > info.TotalSize {
 		log.LogInfof("volume(%v) queried usedSize(%v) is larger than totalSize(%v), force set usedSize as totalSize",
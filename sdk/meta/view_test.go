@@ -0,0 +1,59 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package meta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	masterSDK "github.com/cubefs/cubefs/sdk/master"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastMasterContact(t *testing.T) {
+	var down int32
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		info := &proto.VolStatInfo{Name: r.URL.Query().Get("name"), TotalSize: 100, UsedSize: 10}
+		data, _ := json.Marshal(&proto.HTTPReply{Code: proto.ErrCodeSuccess, Msg: "success", Data: info})
+		w.Write(data)
+	}))
+	defer master.Close()
+
+	mw := &MetaWrapper{volname: "vol", mc: masterSDK.NewMasterClient([]string{strings.TrimPrefix(master.URL, "http://")}, false)}
+	assert.True(t, mw.LastMasterContact().IsZero())
+
+	before := time.Now()
+	assert.NoError(t, mw.updateVolStatInfo())
+	contact := mw.LastMasterContact()
+	assert.False(t, contact.Before(before))
+	total, used, _ := mw.Statfs()
+	assert.Equal(t, uint64(100), total)
+	assert.Equal(t, uint64(10), used)
+
+	// a failed query keeps the last successful contact
+	atomic.StoreInt32(&down, 1)
+	assert.Error(t, mw.updateVolStatInfo())
+	assert.Equal(t, contact, mw.LastMasterContact())
+}
@@ -39,6 +39,8 @@ var (
 	VerNotConsistentError            = errors.New("ver not consistent")
 	SnapshotNeedNewExtentError       = errors.New("snapshot need new extent error")
 	NoDiskReadRepairExtentTokenError = errors.New("no disk read repair extent token")
+	BlockCrcMismatchError            = errors.New("block crc mismatch")
+	ReadonlyDataPartitionError       = errors.New("the data partition is readonly")
 )
 
 func newParameterError(format string, a ...interface{}) error {
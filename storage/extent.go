@@ -54,6 +54,7 @@ type ExtentInfo struct {
 	SnapshotDataOff     uint64 `json:"snapSize"`
 	SnapPreAllocDataOff uint64 `json:"snapPreAllocSize"`
 	ApplyID             uint64 `json:"applyID"`
+	CrcMismatch         bool   `json:"crcMismatch,omitempty"` // failed the block crc verification, to repair in full
 }
 
 func (ei *ExtentInfo) TotalSize() uint64 {
@@ -515,6 +516,43 @@ func (e *Extent) punchDelete(offset, size int64) (hasDelete bool, err error) {
 	return
 }
 
+// holeStat walks the data and the holes of the extent below size, it returns the size of the data,
+// the count and the largest size of the holes.
+func (e *Extent) holeStat(size int64) (used int64, holes int, largestHole int64, err error) {
+	e.Lock()
+	defer e.Unlock()
+	var dataStart, dataEnd int64
+	for offset := int64(0); offset < size; offset = dataEnd {
+		if dataStart, err = e.file.Seek(offset, SEEK_DATA); err != nil {
+			if !strings.Contains(err.Error(), syscall.ENXIO.Error()) {
+				return
+			}
+			// no data after offset
+			dataStart, err = size, nil
+		}
+		if dataStart > size {
+			dataStart = size
+		}
+		if dataStart > offset {
+			holes++
+			if dataStart-offset > largestHole {
+				largestHole = dataStart - offset
+			}
+		}
+		if dataStart == size {
+			break
+		}
+		if dataEnd, err = e.file.Seek(dataStart, SEEK_HOLE); err != nil {
+			return
+		}
+		if dataEnd > size {
+			dataEnd = size
+		}
+		used += dataEnd - dataStart
+	}
+	return
+}
+
 func (e *Extent) getRealBlockCnt() (blockNum int64) {
 	stat := new(syscall.Stat_t)
 	syscall.Stat(e.filePath, stat)
@@ -0,0 +1,227 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path"
+	"syscall"
+	"time"
+
+	"github.com/cubefs/cubefs/util"
+	"github.com/cubefs/cubefs/util/log"
+)
+
+const (
+	ExtentIndexFileName = "EXTENT_INDEX"
+
+	// ExtentIndexPersistInterval is the interval of persisting the extent index by the backend task.
+	ExtentIndexPersistInterval = 10 * time.Minute
+
+	extentIndexMagic      uint32 = 0x45494458 // "EIDX"
+	extentIndexVersion    uint32 = 1
+	extentIndexHeaderSize        = 16
+	extentIndexEntrySize         = 32
+	// extents modified recently may be updated in memory later than on disk, they are left to the scan
+	extentIndexStableTime = 10 * time.Second
+)
+
+// extentIndexEntry records the data size of a normal extent, the computing of which scans the holes of the file.
+// The entry is valid only if the size and modify time of the file are unchanged.
+type extentIndexEntry struct {
+	extentID  uint64
+	fileSize  int64
+	fileMtime int64 // nanoseconds
+	dataSize  int64
+}
+
+func (entry *extentIndexEntry) match(info os.FileInfo) bool {
+	return entry.fileSize == info.Size() && entry.fileMtime == info.ModTime().UnixNano()
+}
+
+// extentInfo builds the extent info as restoring the extent from the file system.
+func (entry *extentIndexEntry) extentInfo(info os.FileInfo) (ei *ExtentInfo) {
+	ei = &ExtentInfo{
+		FileID:          entry.extentID,
+		Size:            uint64(entry.dataSize),
+		SnapshotDataOff: util.ExtentSize,
+		ModifyTime:      info.ModTime().Unix(),
+	}
+	if info.Size() > util.ExtentSize {
+		ei.SnapshotDataOff = uint64(info.Size())
+	}
+	if ts, ok := info.Sys().(*syscall.Stat_t); ok {
+		ei.AccessTime = time.Unix(int64(ts.Atim.Sec), int64(ts.Atim.Nsec)).Unix()
+	}
+	return
+}
+
+func marshalExtentIndex(entries map[uint64]*extentIndexEntry) (data []byte) {
+	data = make([]byte, extentIndexHeaderSize+len(entries)*extentIndexEntrySize+4)
+	binary.BigEndian.PutUint32(data[0:4], extentIndexMagic)
+	binary.BigEndian.PutUint32(data[4:8], extentIndexVersion)
+	binary.BigEndian.PutUint64(data[8:16], uint64(len(entries)))
+	off := extentIndexHeaderSize
+	for _, entry := range entries {
+		binary.BigEndian.PutUint64(data[off:off+8], entry.extentID)
+		binary.BigEndian.PutUint64(data[off+8:off+16], uint64(entry.fileSize))
+		binary.BigEndian.PutUint64(data[off+16:off+24], uint64(entry.fileMtime))
+		binary.BigEndian.PutUint64(data[off+24:off+32], uint64(entry.dataSize))
+		off += extentIndexEntrySize
+	}
+	binary.BigEndian.PutUint32(data[off:], crc32.ChecksumIEEE(data[:off]))
+	return
+}
+
+func unmarshalExtentIndex(data []byte) (entries map[uint64]*extentIndexEntry, err error) {
+	if len(data) < extentIndexHeaderSize+4 {
+		return nil, fmt.Errorf("extent index too short: %v", len(data))
+	}
+	if magic := binary.BigEndian.Uint32(data[0:4]); magic != extentIndexMagic {
+		return nil, fmt.Errorf("invalid extent index magic: %x", magic)
+	}
+	if version := binary.BigEndian.Uint32(data[4:8]); version != extentIndexVersion {
+		return nil, fmt.Errorf("unsupported extent index version: %v", version)
+	}
+	count := binary.BigEndian.Uint64(data[8:16])
+	if uint64(len(data)) != extentIndexHeaderSize+count*extentIndexEntrySize+4 {
+		return nil, fmt.Errorf("extent index size %v mismatches count %v", len(data), count)
+	}
+	off := len(data) - 4
+	if crc := crc32.ChecksumIEEE(data[:off]); crc != binary.BigEndian.Uint32(data[off:]) {
+		return nil, fmt.Errorf("extent index crc mismatch")
+	}
+	entries = make(map[uint64]*extentIndexEntry, count)
+	for off = extentIndexHeaderSize; off < len(data)-4; off += extentIndexEntrySize {
+		entry := &extentIndexEntry{
+			extentID:  binary.BigEndian.Uint64(data[off : off+8]),
+			fileSize:  int64(binary.BigEndian.Uint64(data[off+8 : off+16])),
+			fileMtime: int64(binary.BigEndian.Uint64(data[off+16 : off+24])),
+			dataSize:  int64(binary.BigEndian.Uint64(data[off+24 : off+32])),
+		}
+		entries[entry.extentID] = entry
+	}
+	return
+}
+
+// loadExtentIndex returns the persisted extent index, nil if it is missing or broken.
+func (s *ExtentStore) loadExtentIndex() (entries map[uint64]*extentIndexEntry) {
+	data, err := os.ReadFile(path.Join(s.dataPath, ExtentIndexFileName))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.LogWarnf("[loadExtentIndex] partition(%v) read extent index: %v", s.partitionID, err)
+		}
+		return nil
+	}
+	if entries, err = unmarshalExtentIndex(data); err != nil {
+		log.LogWarnf("[loadExtentIndex] partition(%v) %v, scan all extents", s.partitionID, err)
+		return nil
+	}
+	return
+}
+
+// PersistExtentIndex persists the data sizes of the normal extents, so that restoring the store
+// can skip scanning the files which are unchanged since then.
+// Only the files changed since the last persistence are scanned here.
+func (s *ExtentStore) PersistExtentIndex() (count int, err error) {
+	s.indexMutex.Lock()
+	defer s.indexMutex.Unlock()
+
+	files, err := os.ReadDir(s.dataPath)
+	if err != nil {
+		return
+	}
+	var (
+		info     os.FileInfo
+		e        *Extent
+		extentID uint64
+		isExtent bool
+		stable   = time.Now().Add(-extentIndexStableTime)
+		entries  = make(map[uint64]*extentIndexEntry, len(s.indexEntries))
+	)
+	for _, f := range files {
+		if extentID, isExtent = s.ExtentID(f.Name()); !isExtent || IsTinyExtent(extentID) {
+			continue
+		}
+		if info, err = f.Info(); err != nil {
+			err = nil
+			continue
+		}
+		if info.ModTime().After(stable) {
+			continue
+		}
+		if entry, ok := s.indexEntries[extentID]; ok && entry.match(info) {
+			entries[extentID] = entry
+			continue
+		}
+		if e, err = s.extent(extentID); err != nil {
+			log.LogWarnf("[PersistExtentIndex] partition(%v) %v", s.partitionID, err)
+			err = nil
+			continue
+		}
+		entries[extentID] = &extentIndexEntry{
+			extentID:  extentID,
+			fileSize:  info.Size(),
+			fileMtime: info.ModTime().UnixNano(),
+			dataSize:  e.Size(),
+		}
+		e.Close()
+	}
+
+	name := path.Join(s.dataPath, ExtentIndexFileName)
+	tmpName := name + ".tmp"
+	if err = writeFileSync(tmpName, marshalExtentIndex(entries)); err != nil {
+		return
+	}
+	if err = os.Rename(tmpName, name); err != nil {
+		return
+	}
+	s.indexEntries = entries
+	s.indexPersistTime = time.Now()
+	log.LogInfof("[PersistExtentIndex] partition(%v) extents(%v)", s.partitionID, len(entries))
+	return len(entries), nil
+}
+
+// ExtentIndexLoadStat returns the number of extents restored from the extent index and scanned from disk.
+func (s *ExtentStore) ExtentIndexLoadStat() (fromIndex, fromDisk int) {
+	return s.loadedFromIndex, s.loadedFromDisk
+}
+
+func (s *ExtentStore) persistExtentIndexPeriodically() {
+	s.indexMutex.Lock()
+	due := time.Since(s.indexPersistTime) >= ExtentIndexPersistInterval
+	s.indexMutex.Unlock()
+	if !due {
+		return
+	}
+	if _, err := s.PersistExtentIndex(); err != nil {
+		log.LogErrorf("[persistExtentIndexPeriodically] partition(%v) err: %v", s.partitionID, err)
+	}
+}
+
+func writeFileSync(name string, data []byte) (err error) {
+	fp, err := os.OpenFile(name, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o666)
+	if err != nil {
+		return
+	}
+	defer fp.Close()
+	if _, err = fp.Write(data); err != nil {
+		return
+	}
+	return fp.Sync()
+}
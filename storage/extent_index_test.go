@@ -0,0 +1,125 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage_test
+
+import (
+	"hash/crc32"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/cubefs/cubefs/proto"
+	"github.com/cubefs/cubefs/storage"
+	"github.com/cubefs/cubefs/util"
+	"github.com/stretchr/testify/require"
+)
+
+// ageExtentFiles sets the modify time of all files back, as if they were written a while ago.
+func ageExtentFiles(t *testing.T, dir string) {
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	old := time.Now().Add(-time.Hour)
+	for _, f := range files {
+		require.NoError(t, os.Chtimes(path.Join(dir, f.Name()), old, old))
+	}
+}
+
+func extentsByID(t *testing.T, s *storage.ExtentStore) map[uint64]*storage.ExtentInfo {
+	extents := make(map[uint64]*storage.ExtentInfo)
+	for _, ei := range s.DumpExtents() {
+		extents[ei.FileID] = ei
+	}
+	return extents
+}
+
+func TestExtentIndex(t *testing.T) {
+	const extentCount = 8
+	dir, clean, err := getTestPathExtentStore()
+	require.NoError(t, err)
+	defer clean()
+
+	s, err := storage.NewExtentStore(dir, 0, 1*util.GB, proto.PartitionTypeNormal, true)
+	require.NoError(t, err)
+	data := []byte(dataStr)
+	crc := crc32.ChecksumIEEE(data)
+	ids := make([]uint64, 0, extentCount)
+	for i := 0; i < extentCount; i++ {
+		id, err := s.NextExtentID()
+		require.NoError(t, err)
+		require.NoError(t, s.Create(id))
+		for j := 0; j <= i; j++ {
+			_, err = s.Write(id, int64(j*len(data)), int64(len(data)), data, crc, storage.AppendWriteType, true, false)
+			require.NoError(t, err)
+		}
+		ids = append(ids, id)
+	}
+	ageExtentFiles(t, dir)
+	count, err := s.PersistExtentIndex()
+	require.NoError(t, err)
+	require.Equal(t, extentCount, count)
+	s.Close()
+
+	// restore without the index as the reference
+	require.NoError(t, os.Rename(path.Join(dir, storage.ExtentIndexFileName), path.Join(dir, "index.bak")))
+	s, err = storage.NewExtentStore(dir, 0, 1*util.GB, proto.PartitionTypeNormal, false)
+	require.NoError(t, err)
+	expected := extentsByID(t, s)
+	_, fromDisk := s.ExtentIndexLoadStat()
+	require.Equal(t, len(expected), fromDisk)
+	s.Close()
+
+	// every normal extent is restored from the index
+	require.NoError(t, os.Rename(path.Join(dir, "index.bak"), path.Join(dir, storage.ExtentIndexFileName)))
+	s, err = storage.NewExtentStore(dir, 0, 1*util.GB, proto.PartitionTypeNormal, false)
+	require.NoError(t, err)
+	fromIndex, fromDisk := s.ExtentIndexLoadStat()
+	require.Equal(t, extentCount, fromIndex)
+	require.Equal(t, len(expected)-extentCount, fromDisk)
+	actual := extentsByID(t, s)
+	require.Equal(t, len(expected), len(actual))
+	for id, ei := range expected {
+		require.NotNil(t, actual[id])
+		require.Equal(t, ei.Size, actual[id].Size, "extent %v", id)
+		require.Equal(t, ei.SnapshotDataOff, actual[id].SnapshotDataOff, "extent %v", id)
+		require.Equal(t, ei.ModifyTime, actual[id].ModifyTime, "extent %v", id)
+	}
+	actualCrc, err := s.Read(ids[extentCount-1], 0, int64(len(data)), make([]byte, len(data)), false)
+	require.NoError(t, err)
+	require.Equal(t, crc, actualCrc)
+
+	// an extent changed after the index was persisted is scanned again
+	_, err = s.Write(ids[0], int64(len(data)), int64(len(data)), data, crc, storage.AppendWriteType, true, false)
+	require.NoError(t, err)
+	s.Close()
+	s, err = storage.NewExtentStore(dir, 0, 1*util.GB, proto.PartitionTypeNormal, false)
+	require.NoError(t, err)
+	fromIndex, fromDisk = s.ExtentIndexLoadStat()
+	require.Equal(t, extentCount-1, fromIndex)
+	require.Equal(t, len(expected)-extentCount+1, fromDisk)
+	ei, err := s.Watermark(ids[0])
+	require.NoError(t, err)
+	require.EqualValues(t, 2*len(data), ei.Size)
+	s.Close()
+
+	// a broken index is ignored
+	require.NoError(t, os.WriteFile(path.Join(dir, storage.ExtentIndexFileName), []byte("broken"), 0o666))
+	s, err = storage.NewExtentStore(dir, 0, 1*util.GB, proto.PartitionTypeNormal, false)
+	require.NoError(t, err)
+	defer s.Close()
+	fromIndex, fromDisk = s.ExtentIndexLoadStat()
+	require.Equal(t, 0, fromIndex)
+	require.Equal(t, len(expected), fromDisk)
+}
@@ -136,6 +136,12 @@ type ExtentStore struct {
 	partitionType                     int
 	ApplyId                           uint64
 	ApplyIdMutex                      sync.RWMutex
+
+	indexMutex       sync.Mutex
+	indexEntries     map[uint64]*extentIndexEntry // extent index persisted or validated last time
+	indexPersistTime time.Time
+	loadedFromIndex  int
+	loadedFromDisk   int
 }
 
 func MkdirAll(name string) (err error) {
@@ -339,27 +345,50 @@ func (s *ExtentStore) initBaseFileID() error {
 		isExtent bool
 		e        *Extent
 		ei       *ExtentInfo
+		info     os.FileInfo
 		loadErr  error
+		index    = s.loadExtentIndex()
+		stable   = time.Now().Add(-extentIndexStableTime)
 	)
+	s.indexEntries = make(map[uint64]*extentIndexEntry)
 	for _, f := range files {
 		if extentID, isExtent = s.ExtentID(f.Name()); !isExtent {
 			continue
 		}
 
-		if e, loadErr = s.extent(extentID); loadErr != nil {
-			log.LogError("[initBaseFileID] load extent error", loadErr)
-			continue
-		}
+		info, loadErr = f.Info()
+		entry, ok := index[extentID]
+		if loadErr == nil && ok && !IsTinyExtent(extentID) && entry.match(info) {
+			// the file is unchanged since the index was persisted, skip scanning it
+			ei = entry.extentInfo(info)
+			s.indexEntries[extentID] = entry
+			s.loadedFromIndex++
+		} else {
+			if e, loadErr = s.extent(extentID); loadErr != nil {
+				log.LogError("[initBaseFileID] load extent error", loadErr)
+				continue
+			}
 
-		ei = &ExtentInfo{FileID: extentID}
-		ei.UpdateExtentInfo(e, 0)
-		atomic.StoreInt64(&ei.AccessTime, e.accessTime)
+			ei = &ExtentInfo{FileID: extentID}
+			ei.UpdateExtentInfo(e, 0)
+			atomic.StoreInt64(&ei.AccessTime, e.accessTime)
+			e.Close()
+			s.loadedFromDisk++
+
+			if info != nil && !IsTinyExtent(extentID) && info.ModTime().Before(stable) {
+				s.indexEntries[extentID] = &extentIndexEntry{
+					extentID:  extentID,
+					fileSize:  info.Size(),
+					fileMtime: info.ModTime().UnixNano(),
+					dataSize:  e.Size(),
+				}
+			}
+		}
 
 		s.eiMutex.Lock()
 		s.extentInfoMap[extentID] = ei
 		s.eiMutex.Unlock()
 
-		e.Close()
 		if !IsTinyExtent(extentID) && extentID > baseFileID {
 			baseFileID = extentID
 		}
@@ -368,7 +397,9 @@ func (s *ExtentStore) initBaseFileID() error {
 		baseFileID = MinExtentID
 	}
 	atomic.StoreUint64(&s.baseExtentID, baseFileID)
-	log.LogInfof("datadir(%v) maxBaseId(%v)", s.dataPath, baseFileID)
+	s.indexPersistTime = time.Now()
+	log.LogInfof("datadir(%v) maxBaseId(%v) loadedFromIndex(%v) loadedFromDisk(%v)",
+		s.dataPath, baseFileID, s.loadedFromIndex, s.loadedFromDisk)
 	runtime.GC()
 	return nil
 }
@@ -458,6 +489,31 @@ func (s *ExtentStore) Read(extentID uint64, offset, size int64, nbuf []byte, isR
 	return
 }
 
+// VerifyBlockCrc checks the data read from the normal extent at offset against the stored crc of the blocks.
+// Only the blocks entirely covered by the data and having a stored crc are checked.
+func (s *ExtentStore) VerifyBlockCrc(extentID uint64, offset int64, data []byte) (err error) {
+	if IsTinyExtent(extentID) {
+		return
+	}
+	e, err := s.extentWithHeaderByExtentID(extentID)
+	if err != nil {
+		return
+	}
+	end := offset + int64(len(data))
+	for blockNo := (offset + util.BlockSize - 1) / util.BlockSize; (blockNo+1)*util.BlockSize <= end; blockNo++ {
+		expect := e.GetCrc(blockNo)
+		if expect == 0 {
+			continue
+		}
+		start := blockNo*util.BlockSize - offset
+		if actual := crc32.ChecksumIEEE(data[start : start+util.BlockSize]); actual != expect {
+			return errors.Trace(BlockCrcMismatchError, "extent(%v) block(%v) expect crc(%v) actual crc(%v)",
+				extentID, blockNo, expect, actual)
+		}
+	}
+	return
+}
+
 func (s *ExtentStore) DumpExtents() (extInfos SortedExtentInfos) {
 	s.eiMutex.RLock()
 	for _, v := range s.extentInfoMap {
@@ -557,6 +613,10 @@ func (s *ExtentStore) Close() {
 		return
 	}
 
+	if _, err := s.PersistExtentIndex(); err != nil {
+		log.LogWarnf("[Close] partition(%v) persist extent index: %v", s.partitionID, err)
+	}
+
 	// Release cache
 	s.cache.Flush()
 	s.cache.Clear()
@@ -887,6 +947,30 @@ type ExtentDeleted struct {
 	Size     uint64 `json:"size"`
 }
 
+type TinyExtentStat struct {
+	ExtentID    uint64 `json:"extentID"`
+	Size        int64  `json:"size"`
+	UsedSize    int64  `json:"usedSize"`
+	HoleCount   int    `json:"holeCount"`
+	LargestHole int64  `json:"largestHole"`
+}
+
+// GetTinyExtentStats returns the fragmentation of the tiny extents, the holes are left by the deleted files.
+func (s *ExtentStore) GetTinyExtentStats() (stats []*TinyExtentStat, err error) {
+	for _, ei := range s.getTinyExtentInfo() {
+		var e *Extent
+		if e, err = s.extentWithHeader(ei); err != nil {
+			return
+		}
+		stat := &TinyExtentStat{ExtentID: ei.FileID, Size: int64(ei.Size)}
+		if stat.UsedSize, stat.HoleCount, stat.LargestHole, err = e.holeStat(stat.Size); err != nil {
+			return nil, fmt.Errorf("extent(%v) hole stat: %v", ei.FileID, err)
+		}
+		stats = append(stats, stat)
+	}
+	return
+}
+
 func (s *ExtentStore) GetHasDeleteTinyRecords() (extentDes []ExtentDeleted, err error) {
 	data := make([]byte, DeleteTinyRecordSize)
 	offset := int64(0)
@@ -1082,6 +1166,7 @@ func (arr ExtentInfoArr) Swap(i, j int)      { arr[i], arr[j] = arr[j], arr[i] }
 func (s *ExtentStore) BackendTask() {
 	s.autoComputeExtentCrc()
 	s.cleanExpiredNormalExtentDeleteCache()
+	s.persistExtentIndexPeriodically()
 }
 
 func (s *ExtentStore) cleanExpiredNormalExtentDeleteCache() {
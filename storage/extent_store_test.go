@@ -256,3 +256,61 @@ func TestExtentStores(t *testing.T) {
 		ExtentStoreTest(t, ty)
 	}
 }
+
+func TestTinyExtentStat(t *testing.T) {
+	path, clean, err := getTestPathExtentStore()
+	require.NoError(t, err)
+	defer clean()
+	s, err := storage.NewExtentStore(path, 0, 1*util.GB, proto.PartitionTypeNormal, true)
+	require.NoError(t, err)
+	defer s.Close()
+
+	const (
+		id       = storage.TinyExtentStartID
+		fileSize = 64 * util.KB
+	)
+	data := make([]byte, fileSize)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	crc := crc32.ChecksumIEEE(data)
+	// write 4 files of the same size to the tiny extent
+	for i := 0; i < 4; i++ {
+		_, err = s.Write(id, int64(i*fileSize), fileSize, data, crc, storage.AppendWriteType, true, false)
+		require.NoError(t, err)
+	}
+
+	getStat := func() *storage.TinyExtentStat {
+		stats, err := s.GetTinyExtentStats()
+		require.NoError(t, err)
+		require.Len(t, stats, storage.TinyExtentCount)
+		for _, stat := range stats {
+			if stat.ExtentID == id {
+				return stat
+			}
+			require.Zero(t, stat.UsedSize)
+			require.Zero(t, stat.HoleCount)
+		}
+		t.Fatalf("tiny extent %v not found", id)
+		return nil
+	}
+	stat := getStat()
+	require.EqualValues(t, 4*fileSize, stat.Size)
+	require.EqualValues(t, 4*fileSize, stat.UsedSize)
+	require.Zero(t, stat.HoleCount)
+	require.Zero(t, stat.LargestHole)
+
+	// delete the 2nd and the 4th files, then the 1st one to merge the holes
+	require.NoError(t, s.MarkDelete(id, fileSize, fileSize))
+	require.NoError(t, s.MarkDelete(id, 3*fileSize, fileSize))
+	stat = getStat()
+	require.EqualValues(t, 2*fileSize, stat.UsedSize)
+	require.Equal(t, 2, stat.HoleCount)
+	require.EqualValues(t, fileSize, stat.LargestHole)
+
+	require.NoError(t, s.MarkDelete(id, 0, fileSize))
+	stat = getStat()
+	require.EqualValues(t, fileSize, stat.UsedSize)
+	require.Equal(t, 2, stat.HoleCount)
+	require.EqualValues(t, 2*fileSize, stat.LargestHole)
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package btree
+
+import "math"
+
+// Stats describes the shape of a btree.
+type Stats struct {
+	Degree int `json:"degree"`
+	Items  int `json:"items"`
+	// Height is the number of levels, every leaf of a btree is at the same level.
+	Height int `json:"height"`
+	Nodes  int `json:"nodes"`
+	// FillFactor is the ratio of items to the item slots of all nodes.
+	FillFactor float64 `json:"fillFactor"`
+	// LookupCost is the estimated number of item comparisons of a lookup from the root to a leaf.
+	LookupCost float64 `json:"lookupCost"`
+	// Sampled is true if some levels have more than maxNodes nodes and are estimated from samples.
+	Sampled bool `json:"sampled"`
+}
+
+// Stats walks the tree level by level and returns its shape.
+// At most maxNodes nodes of a level are visited, a larger level is sampled evenly and the node count
+// of the next level is extrapolated from the samples. A non-positive maxNodes visits every node.
+// Stats is a read operation, it's safe to call concurrently with other reads but not with writes.
+func (t *BTree) Stats(maxNodes int) (s Stats) {
+	s.Degree = t.degree
+	s.Items = t.length
+	if t.root == nil {
+		return
+	}
+
+	var (
+		level = []*node{t.root}
+		count = 1.0
+		nodes float64
+	)
+	for len(level) > 0 {
+		if maxNodes > 0 && len(level) > maxNodes {
+			sampled := make([]*node, 0, maxNodes)
+			step := float64(len(level)) / float64(maxNodes)
+			for i := 0; i < maxNodes; i++ {
+				sampled = append(sampled, level[int(float64(i)*step)])
+			}
+			level = sampled
+			s.Sampled = true
+		}
+
+		var items, children int
+		next := make([]*node, 0)
+		for _, n := range level {
+			items += len(n.items)
+			children += len(n.children)
+			next = append(next, n.children...)
+		}
+		s.Height++
+		nodes += count
+		s.LookupCost += math.Log2(float64(items)/float64(len(level)) + 1)
+		count *= float64(children) / float64(len(level))
+		level = next
+	}
+
+	s.Nodes = int(math.Round(nodes))
+	s.FillFactor = float64(s.Items) / (nodes * float64(t.maxItems()))
+	return
+}
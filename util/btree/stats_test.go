@@ -0,0 +1,77 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package btree
+
+import (
+	"math"
+	"testing"
+)
+
+// countNodes returns the node count and height of the subtree by a full recursive walk.
+func countNodes(n *node) (nodes, height int) {
+	nodes, height = 1, 1
+	for _, child := range n.children {
+		cn, ch := countNodes(child)
+		nodes += cn
+		height = ch + 1
+	}
+	return
+}
+
+func TestStats(t *testing.T) {
+	tr := New(2)
+	if s := tr.Stats(0); s.Height != 0 || s.Nodes != 0 || s.Items != 0 {
+		t.Fatalf("empty tree stats: %+v", s)
+	}
+	for i := 0; i < 3; i++ {
+		tr.ReplaceOrInsert(Int(i))
+	}
+	if s := tr.Stats(0); s.Height != 1 || s.Nodes != 1 || s.Items != 3 || s.FillFactor != 1 || s.Sampled {
+		t.Fatalf("full root stats: %+v", s)
+	}
+	// the fourth item splits the root
+	tr.ReplaceOrInsert(Int(3))
+	if s := tr.Stats(0); s.Height != 2 || s.Nodes != 3 || s.Items != 4 {
+		t.Fatalf("split root stats: %+v", s)
+	}
+
+	tr = New(8)
+	for _, item := range perm(200000) {
+		tr.ReplaceOrInsert(item)
+	}
+	nodes, height := countNodes(tr.root)
+
+	exact := tr.Stats(0)
+	if exact.Sampled || exact.Nodes != nodes || exact.Height != height || exact.Items != 200000 {
+		t.Fatalf("exact stats %+v, expect nodes(%v) height(%v)", exact, nodes, height)
+	}
+	if exact.FillFactor <= 0.5 || exact.FillFactor > 1 {
+		t.Fatalf("unexpected fill factor %v", exact.FillFactor)
+	}
+	if exact.LookupCost <= 0 || exact.LookupCost > float64(height)*math.Log2(float64(tr.maxItems()+1)) {
+		t.Fatalf("unexpected lookup cost %v", exact.LookupCost)
+	}
+
+	sampled := tr.Stats(64)
+	if !sampled.Sampled || sampled.Height != height || sampled.Items != 200000 {
+		t.Fatalf("sampled stats %+v, expect height(%v)", sampled, height)
+	}
+	if diff := math.Abs(float64(sampled.Nodes-nodes)) / float64(nodes); diff > 0.1 {
+		t.Fatalf("sampled nodes(%v) is too far from nodes(%v)", sampled.Nodes, nodes)
+	}
+	if diff := math.Abs(sampled.LookupCost-exact.LookupCost) / exact.LookupCost; diff > 0.1 {
+		t.Fatalf("sampled lookup cost(%v) is too far from %v", sampled.LookupCost, exact.LookupCost)
+	}
+}
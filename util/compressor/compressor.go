@@ -14,7 +14,10 @@
 
 package compressor
 
-const EncodingGzip = "gzip"
+const (
+	EncodingGzip = "gzip"
+	EncodingZstd = "zstd"
+)
 
 // Compressor bytes compressor.
 // TODO: add stream Compressor.
@@ -33,6 +36,7 @@ var compressors = make(map[string]func() Compressor)
 func init() {
 	compressors[""] = func() Compressor { return none{} }
 	compressors[EncodingGzip] = func() Compressor { return gzipCompressor{} }
+	compressors[EncodingZstd] = func() Compressor { return zstdCompressor{} }
 }
 
 func New(encoding string) Compressor {
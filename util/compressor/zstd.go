@@ -0,0 +1,35 @@
+// Copyright 2024 The CubeFS Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package compressor
+
+import (
+	"github.com/klauspost/compress/zstd"
+)
+
+// the encoder and decoder are safe for concurrent use with EncodeAll and DecodeAll.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+type zstdCompressor struct{}
+
+func (zstdCompressor) Compress(pb []byte) ([]byte, error) {
+	return zstdEncoder.EncodeAll(pb, make([]byte, 0, len(pb)/2)), nil
+}
+
+func (zstdCompressor) Decompress(cb []byte) ([]byte, error) {
+	return zstdDecoder.DecodeAll(cb, nil)
+}
@@ -167,6 +167,30 @@ func (cp *ConnectPool) Close() {
 	})
 }
 
+type ConnectPoolStat struct {
+	TotalIdleConns int                  `json:"totalIdleConns"`
+	Pools          map[string]*PoolStat `json:"pools"`
+}
+
+type PoolStat struct {
+	Addr      string `json:"addr"`
+	IdleConns int    `json:"idleConns"`
+	Capacity  int    `json:"capacity"`
+}
+
+// GetStat returns the idle connections kept for each target.
+func (cp *ConnectPool) GetStat() *ConnectPoolStat {
+	stat := &ConnectPoolStat{Pools: make(map[string]*PoolStat)}
+	cp.RLock()
+	for target, pool := range cp.pools {
+		poolStat := &PoolStat{Addr: target, IdleConns: len(pool.objects), Capacity: pool.maxcap}
+		stat.Pools[target] = poolStat
+		stat.TotalIdleConns += poolStat.IdleConns
+	}
+	cp.RUnlock()
+	return stat
+}
+
 type Pool struct {
 	objects        chan *Object
 	mincap         int
@@ -451,6 +451,7 @@ func (l *Log) Flush() {
 
 const (
 	SetLogLevelPath = "/loglevel/set"
+	GetLogLevelPath = "/loglevel/get"
 )
 
 func SetLogLevel(w http.ResponseWriter, r *http.Request) {
@@ -484,6 +485,34 @@ func SetLogLevel(w http.ResponseWriter, r *http.Request) {
 	buildSuccessResp(w, "set log level success")
 }
 
+func levelName(level Level) string {
+	switch level {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case FatalLevel:
+		return "fatal"
+	case CriticalLevel:
+		return "critical"
+	default:
+		return fmt.Sprintf("unknown(%d)", level)
+	}
+}
+
+// GetLogLevel returns the current log level, which can be changed by SetLogLevel at runtime.
+func GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	if gLog == nil {
+		buildFailureResp(w, http.StatusServiceUnavailable, "log is not initialized")
+		return
+	}
+	buildSuccessResp(w, levelName(gLog.level))
+}
+
 func buildSuccessResp(w http.ResponseWriter, data interface{}) {
 	buildJSONResp(w, http.StatusOK, data, "")
 }
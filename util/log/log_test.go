@@ -17,7 +17,9 @@ package log
 // These tests are too simple.
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"syscall"
@@ -165,3 +167,44 @@ func createFile(logFilePath string, modTime bool) (err error) {
 	}
 	return
 }
+
+func TestSetAndGetLogLevel(t *testing.T) {
+	old := gLog
+	defer func() { gLog = old }()
+	gLog = &Log{level: ErrorLevel}
+
+	getLevel := func() string {
+		w := httptest.NewRecorder()
+		GetLogLevel(w, httptest.NewRequest(http.MethodGet, GetLogLevelPath, nil))
+		reply := &struct {
+			Code int    `json:"code"`
+			Data string `json:"data"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), reply); err != nil || reply.Code != http.StatusOK {
+			t.Fatalf("get log level: code(%v) err(%v)", reply.Code, err)
+		}
+		return reply.Data
+	}
+	setLevel := func(level string) int {
+		w := httptest.NewRecorder()
+		SetLogLevel(w, httptest.NewRequest(http.MethodGet, SetLogLevelPath+"?level="+level, nil))
+		return w.Code
+	}
+
+	if level := getLevel(); level != "error" {
+		t.Fatalf("level %v, expect error", level)
+	}
+	if code := setLevel("DEBUG"); code != http.StatusOK {
+		t.Fatalf("set level: code %v", code)
+	}
+	if level := getLevel(); level != "debug" {
+		t.Fatalf("level %v, expect debug", level)
+	}
+	// the invalid level is rejected and the level is not changed
+	if code := setLevel("verbose"); code != http.StatusBadRequest {
+		t.Fatalf("set invalid level: code %v", code)
+	}
+	if level := getLevel(); level != "debug" {
+		t.Fatalf("level %v, expect debug", level)
+	}
+}